@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"mysql-golang-mcp/config"
+)
+
+// Identity is an authenticated client and what it may access
+type Identity struct {
+	Client      string
+	Role        Role
+	Connections []string
+
+	// MySQLUser is the client's own MySQL account, when it sent one in the
+	// X-MySQL-User header
+	MySQLUser *MySQLCredential
+}
+
+// MySQLCredential is a MySQL account and password a client's statements run
+// as, on Connections (every connection when empty)
+type MySQLCredential struct {
+	User        string
+	Password    string
+	Connections []string
+}
+
+// AppliesTo reports whether statements on the named connection run as this account
+func (c *MySQLCredential) AppliesTo(name string) bool {
+	if len(c.Connections) == 0 {
+		return true
+	}
+	for _, conn := range c.Connections {
+		if conn == name {
+			return true
+		}
+	}
+	return false
+}
+
+// CanUseConnection reports whether the client may use the named connection.
+// An identity with no connection list may use every connection.
+func (id *Identity) CanUseConnection(name string) bool {
+	if len(id.Connections) == 0 {
+		return true
+	}
+	for _, c := range id.Connections {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+type contextKey struct{}
+
+// WithIdentity returns a context carrying the client identity
+func WithIdentity(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the client identity, or nil for unauthenticated (stdio) requests
+func FromContext(ctx context.Context) *Identity {
+	id, _ := ctx.Value(contextKey{}).(*Identity)
+	return id
+}
+
+// Authenticator resolves API keys from the http config to client identities
+type Authenticator struct {
+	keys []config.APIKey
+}
+
+// NewAuthenticator creates an Authenticator for the configured API keys
+func NewAuthenticator(cfg *config.HTTPConfig) *Authenticator {
+	return &Authenticator{keys: cfg.APIKeys}
+}
+
+// Authenticate returns the identity for the request's API key, taken from an
+// "Authorization: Bearer <key>" or "X-API-Key" header. An X-MySQL-User header
+// must name one of the key's mysql_users; its password is the
+// X-MySQL-Password header, or the entry's configured password without one.
+func (a *Authenticator) Authenticate(r *http.Request) (*Identity, error) {
+	key := r.Header.Get("X-API-Key")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		key = strings.TrimSpace(bearer)
+	}
+	if key == "" {
+		return nil, fmt.Errorf("missing API key")
+	}
+
+	for _, k := range a.keys {
+		if subtle.ConstantTimeCompare([]byte(k.Key), []byte(key)) == 1 {
+			id := &Identity{Client: k.Client, Role: Role(k.Role), Connections: k.Connections}
+			if name := r.Header.Get("X-MySQL-User"); name != "" {
+				user, ok := k.MySQLUsers[name]
+				if !ok {
+					return nil, fmt.Errorf("MySQL user '%s' is not allowed for client '%s'", name, k.Client)
+				}
+				password := r.Header.Get("X-MySQL-Password")
+				if password == "" {
+					password = user.Password
+				}
+				if password == "" {
+					return nil, fmt.Errorf("X-MySQL-Password is required for MySQL user '%s'", name)
+				}
+				id.MySQLUser = &MySQLCredential{User: user.User, Password: password, Connections: user.Connections}
+			}
+			return id, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid API key")
+}
+
+// Middleware rejects requests without a valid API key before they reach the MCP server
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := a.Authenticate(r); err != nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ContextFunc attaches the authenticated identity to the context of each MCP message
+func (a *Authenticator) ContextFunc(ctx context.Context, r *http.Request) context.Context {
+	id, err := a.Authenticate(r)
+	if err != nil {
+		return ctx
+	}
+	return WithIdentity(ctx, id)
+}
@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Role is a client's permission level; each role includes the tools of the roles below it
+type Role string
+
+const (
+	RoleReader Role = "reader"
+	RoleWriter Role = "writer"
+	RoleAdmin  Role = "admin"
+)
+
+var roleRank = map[Role]int{RoleReader: 1, RoleWriter: 2, RoleAdmin: 3}
+
+// toolRoles maps each tool to the minimum role allowed to call it.
+// Tools not listed here require admin.
+var toolRoles = map[string]Role{
+	// Introspection and reads
+	"list_connections":        RoleReader,
+	"connection_health":       RoleReader,
+	"list_databases":          RoleReader,
+	"list_tables":             RoleReader,
+	"describe_table":          RoleReader,
+	"describe_database":       RoleReader,
+	"get_create_statement":    RoleReader,
+	"get_indexes":             RoleReader,
+	"get_table_sizes":         RoleReader,
+	"storage_report":          RoleReader,
+	"get_column_search":       RoleReader,
+	"get_charset_info":        RoleReader,
+	"check_charsets":          RoleReader,
+	"list_routines":           RoleReader,
+	"describe_routine":        RoleReader,
+	"list_views":              RoleReader,
+	"describe_view":           RoleReader,
+	"list_triggers":           RoleReader,
+	"get_trigger":             RoleReader,
+	"list_events":             RoleReader,
+	"describe_event":          RoleReader,
+	"get_partitions":          RoleReader,
+	"explain_error":           RoleReader,
+	"generate_models":         RoleReader,
+	"profile_table":           RoleReader,
+	"sample_representative":   RoleReader,
+	"diagnose_locks":          RoleReader,
+	"get_last_deadlock":       RoleReader,
+	"show_activity":           RoleReader,
+	"top_queries":             RoleReader,
+	"mysql_select":            RoleReader,
+	"suggest_queries":         RoleReader,
+	"mysql_select_multi":      RoleReader,
+	"diff_queries":            RoleReader,
+	"lint_query":              RoleReader,
+	"mysql_explain":           RoleReader,
+	"optimizer_trace":         RoleReader,
+	"recommend_indexes":       RoleReader,
+	"mysql_select_structured": RoleReader,
+	"list_fulltext_indexes":   RoleReader,
+	"search_text":             RoleReader,
+	"json_extract":            RoleReader,
+	"list_collections":        RoleReader,
+	"find_documents":          RoleReader,
+	"row_history":             RoleReader,
+	"open_cursor":             RoleReader,
+	"fetch_cursor":            RoleReader,
+	"close_cursor":            RoleReader,
+	"open_session":            RoleReader,
+	"create_temp_table":       RoleReader,
+	"populate_temp_table":     RoleReader,
+	"session_query":           RoleReader,
+	"close_session":           RoleReader,
+
+	// Data modification
+	"mysql_insert":            RoleWriter,
+	"flush_writes":            RoleWriter,
+	"mysql_update":            RoleWriter,
+	"mysql_delete":            RoleWriter,
+	"mysql_insert_rows":       RoleWriter,
+	"generate_test_data":      RoleWriter,
+	"copy_table_data":         RoleWriter,
+	"mysql_update_structured": RoleWriter,
+	"mysql_delete_structured": RoleWriter,
+	"mysql_write_by_pk":       RoleWriter,
+	"undo_last_write":         RoleWriter,
+	"mysql_call":              RoleWriter,
+	"begin_transaction":       RoleWriter,
+	"transaction_execute":     RoleWriter,
+	"commit_transaction":      RoleWriter,
+	"rollback_transaction":    RoleWriter,
+}
+
+// CanUseTool reports whether the role may call the named tool
+func (r Role) CanUseTool(tool string) bool {
+	required, ok := toolRoles[tool]
+	if !ok {
+		required = RoleAdmin
+	}
+	return roleRank[r] >= roleRank[required]
+}
+
+// CheckConnection returns an error if the client in ctx may not use the connection.
+// Requests without an identity (stdio transport) are always allowed.
+func CheckConnection(ctx context.Context, connection string) error {
+	id := FromContext(ctx)
+	if id == nil || id.CanUseConnection(connection) {
+		return nil
+	}
+	return fmt.Errorf("client '%s' is not permitted to use connection '%s'", id.Client, connection)
+}
+
+// ToolMiddleware enforces the client's role and connection grants before a tool handler runs
+func ToolMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id := FromContext(ctx)
+		if id == nil {
+			return mcp.NewToolResultError("unauthenticated request"), nil
+		}
+
+		if !id.Role.CanUseTool(request.Params.Name) {
+			return mcp.NewToolResultError(fmt.Sprintf("client '%s' (role %s) is not permitted to call %s", id.Client, id.Role, request.Params.Name)), nil
+		}
+
+		for _, connection := range requestedConnections(request) {
+			if err := CheckConnection(ctx, connection); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		// mysql_select_multi, top_queries and flush_writes default to every
+		// connection, and explain_error searches every connection's error
+		// history, so restricted clients must name them
+		defaultsToAll := request.Params.Name == "mysql_select_multi" || request.Params.Name == "top_queries" ||
+			request.Params.Name == "flush_writes" || request.Params.Name == "explain_error"
+		if defaultsToAll && len(id.Connections) > 0 && len(requestedConnections(request)) == 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("client '%s' must list connections explicitly", id.Client)), nil
+		}
+
+		return next(ctx, request)
+	}
+}
+
+// ToolFilter hides tools the client's role may not call from tools/list
+func ToolFilter(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+	id := FromContext(ctx)
+	if id == nil {
+		return nil
+	}
+
+	allowed := make([]mcp.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if id.Role.CanUseTool(tool.Name) {
+			allowed = append(allowed, tool)
+		}
+	}
+	return allowed
+}
+
+// requestedConnections returns the connection names a tool call targets
+func requestedConnections(request mcp.CallToolRequest) []string {
+	var names []string
+	for _, param := range []string{"connection", "left_connection", "right_connection", "source_connection", "target_connection"} {
+		if connection, ok := request.Params.Arguments[param].(string); ok && connection != "" {
+			names = append(names, connection)
+		}
+	}
+	if raw, ok := request.Params.Arguments["connections"].([]interface{}); ok {
+		for _, c := range raw {
+			if name, ok := c.(string); ok && name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
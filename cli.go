@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"mysql-golang-mcp/db"
+)
+
+// runQuery implements `mysql-mcp query`: it runs one read-only statement with
+// the same checks as mysql_select and prints the result
+func runQuery(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("query", flag.ExitOnError)
+	cf := addConfigFlags(fs)
+	connection := fs.String("connection", "", "Named connection to use (required)")
+	query := fs.String("sql", "", "SELECT, SHOW, DESCRIBE or EXPLAIN statement to run (required)")
+	database := fs.String("database", "", "Default database for unqualified table names (uses the connection's if not set)")
+	maxRows := fs.Int("max-rows", 0, "Maximum rows to return (capped at the connection's max_rows)")
+	format := fs.String("format", "table", "Output format: table, pretty, compact, or columnar")
+	fs.Parse(args)
+
+	if *connection == "" || *query == "" {
+		return fmt.Errorf("query needs --connection and --sql")
+	}
+	if err := db.ValidateQueryType(*query, db.QueryTypeSelect, db.QueryTypeShow, db.QueryTypeDescribe, db.QueryTypeExplain); err != nil {
+		return err
+	}
+	if *maxRows < 0 {
+		return fmt.Errorf("--max-rows must be at least 1")
+	}
+
+	cfg, _, logFile, err := cf.loadWithLogging()
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	manager := db.NewManager(cfg)
+	defer manager.Close()
+
+	queryResult, err := manager.ExecuteQueryWithOptions(*connection, *query, db.QueryOptions{
+		ExcludeSoftDeleted: true,
+		Database:           *database,
+		MaxRows:            *maxRows,
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeQueryResult(out, *format, queryResult)
+}
+
+// writeQueryResult prints a query result as an aligned text table or as JSON
+func writeQueryResult(out io.Writer, format string, r *db.QueryResult) error {
+	var v interface{} = r
+	switch format {
+	case "table":
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join(r.Columns, "\t"))
+		for _, row := range r.Rows {
+			cells := make([]string, len(r.Columns))
+			for i, col := range r.Columns {
+				if row[col] == nil {
+					cells[i] = "NULL"
+				} else {
+					cells[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(fmt.Sprint(row[col]))
+				}
+			}
+			fmt.Fprintln(w, strings.Join(cells, "\t"))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		summary := fmt.Sprintf("(%d rows, %dms)", r.Count, r.ExecutionMs)
+		if r.Truncated {
+			summary = fmt.Sprintf("(%d rows, truncated at max rows, %dms)", r.Count, r.ExecutionMs)
+		}
+		fmt.Fprintln(out, summary)
+		for _, warning := range r.Warnings {
+			fmt.Fprintf(out, "%s %d: %s\n", warning.Level, warning.Code, warning.Message)
+		}
+		return nil
+	case "columnar":
+		v = r.Columnar()
+		fallthrough
+	case "compact":
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(out, "%s\n", data)
+		return err
+	case "pretty":
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(out, "%s\n", data)
+		return err
+	default:
+		return fmt.Errorf("--format must be one of table, pretty, compact, columnar")
+	}
+}
+
+// runSchema implements `mysql-mcp schema dump`: it prints the CREATE TABLE
+// statements of a database in foreign-key dependency order, ready to replay
+func runSchema(args []string, out io.Writer) error {
+	if len(args) == 0 || args[0] != "dump" {
+		return fmt.Errorf("usage: mysql-mcp schema dump --connection NAME [--database DB] [--tables a,b]")
+	}
+
+	fs := flag.NewFlagSet("schema dump", flag.ExitOnError)
+	cf := addConfigFlags(fs)
+	connection := fs.String("connection", "", "Named connection to use (required)")
+	database := fs.String("database", "", "Database to dump (uses the connection's if not set)")
+	tableList := fs.String("tables", "", "Comma-separated tables to dump (defaults to every base table)")
+	fs.Parse(args[1:])
+
+	if *connection == "" {
+		return fmt.Errorf("schema dump needs --connection")
+	}
+
+	cfg, _, logFile, err := cf.loadWithLogging()
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	manager := db.NewManager(cfg)
+	defer manager.Close()
+
+	var tables []string
+	for _, table := range strings.Split(*tableList, ",") {
+		if table = strings.TrimSpace(table); table != "" {
+			tables = append(tables, table)
+		}
+	}
+	if len(tables) == 0 {
+		if tables, err = manager.BaseTables(*connection, *database); err != nil {
+			return err
+		}
+	}
+
+	deps, err := manager.ForeignKeyDependencies(*connection, *database)
+	if err != nil {
+		return err
+	}
+	tables, cyclic := db.SortByDependencies(tables, deps)
+	if len(cyclic) > 0 {
+		fmt.Fprintf(out, "-- Tables in a foreign key cycle, which need SET FOREIGN_KEY_CHECKS = 0 to replay: %s\n\n", strings.Join(cyclic, ", "))
+	}
+
+	for _, table := range tables {
+		ddl, err := manager.ShowCreateTable(*connection, *database, table)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%s;\n\n", ddl)
+	}
+	return nil
+}
@@ -1,10 +1,14 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 )
 
 // ConnectionConfig holds settings for a single database connection
@@ -16,46 +20,699 @@ type ConnectionConfig struct {
 	Database string `json:"database"`
 	ReadOnly bool   `json:"read_only"`
 	MaxRows  int    `json:"max_rows"`
+
+	// Default makes this the connection tools use when a call omits the
+	// connection argument; a config with a single connection defaults to it.
+	// Aliases are other names tool calls may use for the connection.
+	Default bool     `json:"default"`
+	Aliases []string `json:"aliases"`
+
+	// TenantDatabasePattern names the per-tenant schemas on a multi-tenant
+	// server, with one %d (numeric tenant ids) or %s placeholder, e.g.
+	// "tenant_%d". Tools given a tenant_id run in that tenant's schema and
+	// refuse statements that name any other database.
+	TenantDatabasePattern string `json:"tenant_database_pattern"`
+
+	// Role tags the server's replication role (primary or replica). Replicas
+	// refuse every write, even when read_only is false and the account could
+	// write, so an analytics replica is never written to by mistake.
+	Role string `json:"role"`
+
+	// Charset and Collation set the session character set (SET NAMES) for
+	// every pooled connection; Charset defaults to utf8mb4
+	Charset   string `json:"charset"`
+	Collation string `json:"collation"`
+
+	// Environment (dev/staging/prod), Description and RiskTier (low/medium/high)
+	// are surfaced to clients so they can apply caution per connection
+	Environment string `json:"environment"`
+	Description string `json:"description"`
+	RiskTier    string `json:"risk_tier"`
+
+	// MaxConcurrentQueries limits how many statements may run at once on this
+	// connection; further requests wait up to QueueTimeoutSeconds for a slot
+	MaxConcurrentQueries int `json:"max_concurrent_queries"`
+	QueueTimeoutSeconds  int `json:"queue_timeout_seconds"`
+
+	// IdleTimeoutSeconds closes the connection's whole pool once no tool has
+	// used it for this long; it is reopened on next use. Pooled connections
+	// are replaced after MaxLifetimeSeconds, so none is held open for days.
+	IdleTimeoutSeconds int `json:"idle_timeout_seconds"`
+	MaxLifetimeSeconds int `json:"max_lifetime_seconds"`
+
+	// MaxEstimatedRowsExamined refuses SELECTs whose EXPLAIN estimate exceeds
+	// this many rows examined (0 disables the check)
+	MaxEstimatedRowsExamined int64 `json:"max_estimated_rows_examined"`
+
+	// ShowActivityUserHost includes user and host in show_activity output
+	// (redacted by default); AllowKillQuery enables the kill_query tool
+	ShowActivityUserHost bool `json:"show_activity_user_host"`
+	AllowKillQuery       bool `json:"allow_kill_query"`
+
+	// AllowBinlog enables the binlog_events tool, which reads the server's
+	// binary logs and so every database's recent writes
+	AllowBinlog bool `json:"allow_binlog"`
+
+	// AllowAdmin enables the user management tools (create_user,
+	// grant_privileges, list_users, rotate_password)
+	AllowAdmin bool `json:"allow_admin"`
+
+	// AllowDDL enables the guarded DDL tools (e.g. create_or_replace_view)
+	AllowDDL bool `json:"allow_ddl"`
+
+	// AlterMaxTableMB refuses mysql_alter statements that are not instant on
+	// tables larger than this many megabytes, unless forced
+	AlterMaxTableMB int `json:"alter_max_table_mb"`
+
+	// MaxExecutionTimeMs makes the server abort SELECTs that run longer than
+	// this many milliseconds; 0 leaves them to the client-side timeout
+	MaxExecutionTimeMs int `json:"max_execution_time_ms"`
+
+	// TransactionTimeoutSeconds rolls back transactions opened with
+	// begin_transaction that are not committed within this window
+	TransactionTimeoutSeconds int `json:"transaction_timeout_seconds"`
+
+	// WriteBatchSeconds holds INSERTs from mysql_insert in one transaction
+	// that is committed once no INSERT has arrived for this many seconds, or
+	// when it reaches WriteBatchMaxStatements; 0 commits each INSERT on its own
+	WriteBatchSeconds       int `json:"write_batch_seconds"`
+	WriteBatchMaxStatements int `json:"write_batch_max_statements"`
+
+	// Tables holds per-table settings keyed by table name
+	Tables map[string]*TableConfig `json:"tables"`
+
+	// SoftDeleteMode rewrites DELETEs on tables with a soft_delete_column into
+	// UPDATEs that stamp the column, and hides soft-deleted rows from mysql_select
+	SoftDeleteMode bool `json:"soft_delete_mode"`
+
+	// BackupBeforeWrite snapshots the rows an UPDATE or DELETE will change
+	// before running it. Snapshots go to BackupTable on this connection when
+	// set, otherwise they are appended to the BackupFile JSONL file. Writes
+	// that would change more than BackupMaxRows rows are refused.
+	BackupBeforeWrite bool   `json:"backup_before_write"`
+	BackupTable       string `json:"backup_table"`
+	BackupFile        string `json:"backup_file"`
+	BackupMaxRows     int    `json:"backup_max_rows"`
+
+	// LintSelects attaches lint_query findings to every mysql_select result
+	LintSelects bool `json:"lint_selects"`
+
+	// CacheTTLSeconds serves repeated identical SELECTs from mysql_select and
+	// mysql_select_structured from a read cache for this long; 0 disables it.
+	// At most CacheMaxEntries results are kept per connection.
+	CacheTTLSeconds int `json:"cache_ttl_seconds"`
+	CacheMaxEntries int `json:"cache_max_entries"`
+
+	// RequireApproval queues UPDATE, DELETE, ALTER and unsafe write statements
+	// until a human approves them (see Config.Approval)
+	RequireApproval bool `json:"require_approval"`
+
+	// BlockedPatterns are regular expressions (case-insensitive) matched
+	// against every statement before it runs on this connection; a statement
+	// matching any of them is refused, even by mysql_execute_unsafe
+	BlockedPatterns []string `json:"blocked_patterns"`
+
+	// RequireQualifiedWrites refuses INSERT, UPDATE and DELETE statements that
+	// reference a table without its database (db.table), so a write cannot land
+	// in the wrong default database
+	RequireQualifiedWrites bool `json:"require_qualified_writes"`
+
+	// RowHistory describes the connection's history (audit) table convention,
+	// which row_history reads prior versions of rows from
+	RowHistory *RowHistoryConfig `json:"row_history"`
+
+	// StorageHistoryTable is a table on this connection holding periodic
+	// snapshots of information_schema.TABLES sizes, from which storage_report
+	// computes growth; MySQL keeps no size history of its own
+	StorageHistoryTable string `json:"storage_history_table"`
+
+	// OnlineSchemaChange enables online_alter, which runs ALTER TABLE through
+	// gh-ost or pt-online-schema-change instead of a native ALTER
+	OnlineSchemaChange *OnlineSchemaChangeConfig `json:"online_schema_change"`
+
+	// PasswordFile is read for the password instead of Password when set
+	// (e.g. a mounted secret that is rotated in place)
+	PasswordFile string `json:"password_file"`
+
+	// PasswordRotation enables rotate_password, which changes the
+	// connection's own MySQL password and stores the new one where it is
+	// read from
+	PasswordRotation *PasswordRotationConfig `json:"password_rotation"`
+
+	// Coercion renders result values the way the application reads them
+	// (booleans, zero dates, time zone and format of DATETIME values)
+	Coercion *CoercionConfig `json:"coercion"`
+
+	// AnonymizeSalt keys the hash anonymization rule (HMAC-SHA256), so hashed
+	// values cannot be matched against hashes of guessed values
+	AnonymizeSalt string `json:"anonymize_salt"`
+
+	// Original, unexpanded values kept so secret references can be re-resolved
+	rawUser     string
+	rawPassword string
+
+	// literalCredentials marks credentials supplied by a client, which are
+	// never resolved as secret references
+	literalCredentials bool
+
+	blockedPatterns []*regexp.Regexp
+}
+
+// TableConfig holds settings for a single table
+type TableConfig struct {
+	// SoftDeleteColumn is a nullable DATETIME/TIMESTAMP column that marks a row
+	// deleted when set (e.g. deleted_at)
+	SoftDeleteColumn string `json:"soft_delete_column"`
+
+	// HistoryTable is the table holding this table's prior row versions,
+	// overriding the row_history naming convention
+	HistoryTable string `json:"history_table"`
+
+	// AllowedColumns, when set, are the only columns of the table queries can
+	// read; SELECT * is narrowed to them
+	AllowedColumns []string `json:"allowed_columns"`
+
+	// Anonymize maps columns to the rule copy_table_data applies to their
+	// values when copying the table off this connection: hash, faker or nullify
+	Anonymize map[string]string `json:"anonymize"`
+}
+
+// AnonymizeRules lists the supported tables.<table>.anonymize rules
+var AnonymizeRules = []string{"hash", "faker", "nullify"}
+
+// RowHistoryConfig describes history tables: for each table, a table named
+// with TableSuffix holds a copy of the row per change, with the time of the
+// change in TimestampColumn and optionally the kind of change in
+// OperationColumn. Image says whether each copy is the row after the change
+// (as AFTER INSERT/UPDATE triggers write it) or before it.
+type RowHistoryConfig struct {
+	TableSuffix     string `json:"table_suffix"`
+	TimestampColumn string `json:"timestamp_column"`
+	OperationColumn string `json:"operation_column"`
+	Image           string `json:"image"`
+}
+
+// OnlineSchemaChangeConfig selects the online schema change tool: Tool is
+// gh-ost or pt-online-schema-change, Path the binary to run (found on PATH
+// by Tool's name when empty), and Args extra flags passed to every run (e.g.
+// --allow-on-master or --max-load)
+type OnlineSchemaChangeConfig struct {
+	Tool string   `json:"tool"`
+	Path string   `json:"path"`
+	Args []string `json:"args"`
+}
+
+// PasswordRotationConfig configures rotate_password: Command (with Args) is
+// run with the new password on stdin to store it in a secrets manager, and
+// Length is the length of generated passwords (default 32). Without Command
+// the new password is only written to the connection's password_file.
+type PasswordRotationConfig struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+	Length  int      `json:"length"`
+}
+
+// CoercionConfig renders result values with application semantics instead
+// of MySQL's representation. TinyintAsBool renders every TINYINT column as a
+// boolean, since the driver does not report TINYINT(1)'s display width;
+// BooleanColumns names the columns rendered as booleans regardless of their
+// type. ZeroDatesAsNull renders 0000-00-00 dates as null. TimeZone (an IANA
+// name) converts DATETIME and TIMESTAMP values from UTC, the zone they are
+// read in, and DatetimeFormat renders them as rfc3339 (default), sql
+// (YYYY-MM-DD hh:mm:ss) or unix seconds.
+type CoercionConfig struct {
+	TinyintAsBool   bool     `json:"tinyint_as_bool"`
+	BooleanColumns  []string `json:"boolean_columns"`
+	ZeroDatesAsNull bool     `json:"zero_dates_as_null"`
+	TimeZone        string   `json:"time_zone"`
+	DatetimeFormat  string   `json:"datetime_format"`
+
+	location *time.Location
+}
+
+// DatetimeFormats lists the supported coercion.datetime_format values
+var DatetimeFormats = []string{"rfc3339", "sql", "unix"}
+
+// Location returns the time zone DATETIME values are rendered in, or nil to
+// keep UTC
+func (c *CoercionConfig) Location() *time.Location {
+	return c.location
+}
+
+// IsBooleanColumn reports whether a result column is listed in boolean_columns
+func (c *CoercionConfig) IsBooleanColumn(column string) bool {
+	for _, name := range c.BooleanColumns {
+		if strings.EqualFold(name, column) {
+			return true
+		}
+	}
+	return false
 }
 
 // Config holds all database connections
 type Config struct {
+	// Include lists config files (JSON or YAML, glob patterns allowed) merged
+	// before this one, relative to this file's directory
+	Include []string `json:"include,omitempty"`
+
 	Connections map[string]*ConnectionConfig `json:"connections"`
+
+	// DisableRawSQL removes every tool that accepts free-form SQL, leaving only
+	// the structured and introspection tools
+	DisableRawSQL bool `json:"disable_raw_sql"`
+
+	// ValidateOnStartup connects to every connection at boot and reports failures
+	ValidateOnStartup bool `json:"validate_on_startup"`
+
+	// OutputFormat is the default JSON rendering for tool results: pretty
+	// (indented), compact, or columnar (column list plus value arrays)
+	OutputFormat string `json:"output_format"`
+
+	// SafeNumbers renders integers beyond 2^53 as strings in tool results, so
+	// clients parsing JSON numbers as doubles do not silently round them
+	SafeNumbers bool `json:"safe_numbers"`
+
+	// GeometryFormat renders spatial values in tool results as wkt
+	// (default), geojson, or wkb (hex-encoded)
+	GeometryFormat string `json:"geometry_format"`
+
+	// Log configures the server's rotating log file; logging is disabled when unset
+	Log *LogConfig `json:"log"`
+
+	// HTTP configures the HTTP (SSE) transport and its client API keys
+	HTTP *HTTPConfig `json:"http"`
+
+	// Approval configures the webhook that pending statements are posted to
+	// on connections with require_approval
+	Approval *ApprovalConfig `json:"approval"`
+
+	// Tracing exports OpenTelemetry spans for tool calls and statements;
+	// tracing is disabled when unset
+	Tracing *TracingConfig `json:"tracing"`
+
+	// StatementTag is a template for the SQL comment prepended to every
+	// statement, so the slow log and processlist show which connection, tool,
+	// client and tool call ran it; statements are not tagged when unset
+	StatementTag string `json:"statement_tag"`
+
+	// QueryLibraryFile persists the query templates suggest_queries learns
+	// from successful SELECTs, so they outlive the server; they are kept in
+	// memory only when unset
+	QueryLibraryFile string `json:"query_library_file"`
+
+	// AllowRuntimeConnections registers the add_connection tool, which adds
+	// connections while the server runs. Connections it persists are written
+	// to RuntimeConnectionsFile, which is merged into the config at startup.
+	AllowRuntimeConnections bool   `json:"allow_runtime_connections"`
+	RuntimeConnectionsFile  string `json:"runtime_connections_file"`
+
+	// RuntimeConnectionEnvVars lists the environment variables add_connection
+	// may reference as ${VAR}; its arguments are otherwise taken literally,
+	// so a caller cannot send the server's secrets to a host it chose
+	RuntimeConnectionEnvVars []string `json:"runtime_connection_env_vars"`
+
+	// baseDir is the directory of the config, where default files are placed
+	baseDir string
+}
+
+// StatementTagFields lists the placeholders a statement_tag template may use
+var StatementTagFields = []string{"{connection}", "{tool}", "{client}", "{request_id}"}
+
+// ApprovalConfig holds settings for the write-ahead approval queue.
+// CallbackURL is the externally reachable base URL of the approval callback
+// endpoint; CallbackAddr is where it listens under the stdio transport (the
+// http transport serves it on http.addr).
+type ApprovalConfig struct {
+	WebhookURL     string `json:"webhook_url"`
+	CallbackURL    string `json:"callback_url"`
+	CallbackAddr   string `json:"callback_addr"`
+	TimeoutSeconds int    `json:"timeout_seconds"`
 }
 
-// LoadConfig loads configuration from a JSON file
+// HTTPConfig holds settings for serving multiple clients over HTTP
+type HTTPConfig struct {
+	Addr    string   `json:"addr"`
+	APIKeys []APIKey `json:"api_keys"`
+}
+
+// APIKey maps a client's key to its role (reader, writer, admin) and the
+// connections it may use (all connections when empty)
+type APIKey struct {
+	Client      string   `json:"client"`
+	Key         string   `json:"key"`
+	Role        string   `json:"role"`
+	Connections []string `json:"connections"`
+
+	// MySQLUsers are the MySQL accounts the client may run its statements
+	// as, keyed by the name it sends in the X-MySQL-User header
+	MySQLUsers map[string]*MySQLUser `json:"mysql_users"`
+}
+
+// MySQLUser is a MySQL account a client may run its statements as instead
+// of the connection's user, on Connections (every connection when empty).
+// User defaults to the entry's name. Without a Password, the client sends
+// its own credential, such as a short-lived token, in X-MySQL-Password.
+type MySQLUser struct {
+	User        string   `json:"user"`
+	Password    string   `json:"password"`
+	Connections []string `json:"connections"`
+}
+
+// LogConfig holds settings for the server log file
+type LogConfig struct {
+	Path      string `json:"path"`
+	Level     string `json:"level"`
+	MaxSizeMB int    `json:"max_size_mb"`
+	MaxFiles  int    `json:"max_files"`
+}
+
+// TracingConfig holds settings for exporting OpenTelemetry spans over
+// OTLP/HTTP. Endpoint is a collector URL (http://localhost:4318) or a
+// host:port, which is reached over HTTPS unless Insecure is set. Header
+// values may be ${VAR} references.
+type TracingConfig struct {
+	Endpoint    string            `json:"endpoint"`
+	Insecure    bool              `json:"insecure"`
+	Headers     map[string]string `json:"headers"`
+	ServiceName string            `json:"service_name"`
+
+	// SampleRatio is the fraction of traces kept, from 0 to 1 (default 1)
+	SampleRatio *float64 `json:"sample_ratio"`
+}
+
+// OutputFormats lists the supported output_format values
+var OutputFormats = []string{"pretty", "compact", "columnar"}
+
+// GeometryFormats lists the supported geometry_format values
+var GeometryFormats = []string{"wkt", "geojson", "wkb"}
+
+// LoadConfig loads configuration from a JSON or YAML file, merged after the
+// files it includes
 func LoadConfig(path string) (*Config, error) {
-	data, err := os.ReadFile(path)
+	set := newConfigSet()
+	if err := set.load(path, nil); err != nil {
+		return nil, err
+	}
+	return finishConfig(set, filepath.Dir(path))
+}
+
+// LoadConfigDir loads configuration from every JSON and YAML file in a
+// directory, merged in file name order
+func LoadConfigDir(dir string) (*Config, error) {
+	files, err := configDirFiles(dir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return nil, err
+	}
+	set := newConfigSet()
+	for _, file := range files {
+		if err := set.load(file, nil); err != nil {
+			return nil, err
+		}
 	}
+	return finishConfig(set, dir)
+}
 
-	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+// finishConfig decodes merged config files, applies defaults, and validates
+// the result. Default backup files are placed in baseDir, and relative
+// query_library_file and runtime_connections_file paths are resolved against it.
+func finishConfig(set *configSet, baseDir string) (*Config, error) {
+	if err := set.loadRuntimeConnections(baseDir); err != nil {
+		return nil, err
+	}
+	cfg, err := set.decode()
+	if err != nil {
+		return nil, err
 	}
+	cfg.baseDir = baseDir
 
 	// Apply defaults and validate
 	for name, conn := range cfg.Connections {
 		if err := validateAndApplyDefaults(name, conn); err != nil {
 			return nil, err
 		}
+
+		// Backups default to a file next to the config so they survive restarts
+		if conn.BackupTable == "" && conn.BackupFile == "" {
+			conn.BackupFile = filepath.Join(baseDir, "mysql-mcp-backups.jsonl")
+		}
 	}
 
 	if len(cfg.Connections) == 0 {
 		return nil, fmt.Errorf("no connections defined in config")
 	}
+	if err := validateConnectionNames(cfg.Connections); err != nil {
+		return nil, err
+	}
+
+	if cfg.QueryLibraryFile != "" && !filepath.IsAbs(cfg.QueryLibraryFile) {
+		cfg.QueryLibraryFile = filepath.Join(baseDir, cfg.QueryLibraryFile)
+	}
+	if cfg.AllowRuntimeConnections {
+		cfg.RuntimeConnectionsFile = runtimeConnectionsPath(cfg.RuntimeConnectionsFile, baseDir)
+	}
+
+	switch cfg.OutputFormat {
+	case "":
+		cfg.OutputFormat = "pretty"
+	case "pretty", "compact", "columnar":
+	default:
+		return nil, fmt.Errorf("output_format must be one of %s", strings.Join(OutputFormats, ", "))
+	}
+
+	switch cfg.GeometryFormat {
+	case "":
+		cfg.GeometryFormat = "wkt"
+	case "wkt", "geojson", "wkb":
+	default:
+		return nil, fmt.Errorf("geometry_format must be one of %s", strings.Join(GeometryFormats, ", "))
+	}
+
+	if cfg.Log != nil {
+		if err := validateLogConfig(cfg.Log); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Tracing != nil {
+		if err := validateTracingConfig(cfg.Tracing); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateStatementTag(cfg.StatementTag); err != nil {
+		return nil, err
+	}
+
+	if cfg.HTTP != nil {
+		if err := validateHTTPConfig(cfg.HTTP, cfg.Connections); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Approval != nil {
+		validateApprovalConfig(cfg.Approval)
+	}
+	for name, conn := range cfg.Connections {
+		if conn.RequireApproval && (cfg.Approval == nil || cfg.Approval.WebhookURL == "") {
+			return nil, fmt.Errorf("connection '%s': require_approval needs approval.webhook_url", name)
+		}
+	}
+
+	return cfg, nil
+}
+
+// validateConnectionNames checks that at most one connection is the default
+// and that every alias names exactly one connection
+func validateConnectionNames(connections map[string]*ConnectionConfig) error {
+	names := make([]string, 0, len(connections))
+	for name := range connections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
 
-	return &cfg, nil
+	var defaultName string
+	owners := make(map[string]string)
+	for _, name := range names {
+		conn := connections[name]
+		if conn.Default {
+			if defaultName != "" {
+				return fmt.Errorf("connections '%s' and '%s' are both marked default", defaultName, name)
+			}
+			defaultName = name
+		}
+		for _, alias := range conn.Aliases {
+			// '@' is reserved for the connections of per-user MySQL accounts
+			if alias == "" || strings.Contains(alias, "@") {
+				return fmt.Errorf("connection '%s': invalid alias %q", name, alias)
+			}
+			if _, exists := connections[alias]; exists {
+				return fmt.Errorf("connection '%s': alias '%s' is the name of another connection", name, alias)
+			}
+			if owner, exists := owners[alias]; exists {
+				return fmt.Errorf("alias '%s' is declared by both connections '%s' and '%s'", alias, owner, name)
+			}
+			owners[alias] = name
+		}
+	}
+	return nil
 }
 
+// DefaultConnection returns the connection tool calls use when they omit
+// one: the connection marked default, or the only connection. It is empty
+// when several connections are configured and none is the default.
+func (c *Config) DefaultConnection() string {
+	for name, conn := range c.Connections {
+		if conn.Default || len(c.Connections) == 1 {
+			return name
+		}
+	}
+	return ""
+}
+
+// ResolveConnection returns the connection an alias stands for. Connection
+// names and unknown names are returned unchanged.
+func (c *Config) ResolveConnection(name string) string {
+	if _, exists := c.Connections[name]; exists {
+		return name
+	}
+	for connName, conn := range c.Connections {
+		for _, alias := range conn.Aliases {
+			if alias == name {
+				return connName
+			}
+		}
+	}
+	return name
+}
+
+// validateLogConfig validates the log section and applies default values
+func validateLogConfig(log *LogConfig) error {
+	log.Path = expandEnvVar(log.Path)
+
+	switch strings.ToLower(log.Level) {
+	case "":
+		log.Level = "info"
+	case "debug", "info", "warn", "error":
+	default:
+		return fmt.Errorf("log: level must be one of debug, info, warn, error")
+	}
+	if log.MaxSizeMB <= 0 {
+		log.MaxSizeMB = 10
+	}
+	if log.MaxFiles < 0 {
+		return fmt.Errorf("log: max_files must not be negative")
+	}
+	if log.MaxFiles == 0 {
+		log.MaxFiles = 5
+	}
+	return nil
+}
+
+// validateTracingConfig validates the tracing section and applies default values
+func validateTracingConfig(tracing *TracingConfig) error {
+	tracing.Endpoint = expandEnvVar(tracing.Endpoint)
+	if tracing.Endpoint == "" {
+		return fmt.Errorf("tracing: endpoint is required")
+	}
+	for name, value := range tracing.Headers {
+		tracing.Headers[name] = expandEnvVar(value)
+	}
+	if tracing.ServiceName == "" {
+		tracing.ServiceName = "mysql-mcp"
+	}
+	if tracing.SampleRatio == nil {
+		ratio := 1.0
+		tracing.SampleRatio = &ratio
+	}
+	if *tracing.SampleRatio < 0 || *tracing.SampleRatio > 1 {
+		return fmt.Errorf("tracing: sample_ratio must be between 0 and 1")
+	}
+	return nil
+}
+
+// validateStatementTag checks that a statement_tag template stays a plain
+// comment: it cannot close the comment or turn it into a MySQL executable
+// comment
+func validateStatementTag(tag string) error {
+	if strings.Contains(tag, "*/") || strings.Contains(tag, "/*") {
+		return fmt.Errorf("statement_tag must not contain /* or */")
+	}
+	if strings.HasPrefix(strings.TrimSpace(tag), "!") || strings.HasPrefix(strings.TrimSpace(tag), "+") {
+		return fmt.Errorf("statement_tag must not start with ! or +, which MySQL treats as executable comments and optimizer hints")
+	}
+	return nil
+}
+
+// validateHTTPConfig validates the http section and applies default values
+func validateHTTPConfig(http *HTTPConfig, connections map[string]*ConnectionConfig) error {
+	if http.Addr == "" {
+		http.Addr = ":8080"
+	}
+
+	for i := range http.APIKeys {
+		key := &http.APIKeys[i]
+		key.Key = expandEnvVar(key.Key)
+
+		if key.Client == "" {
+			return fmt.Errorf("http: api_keys[%d]: client is required", i)
+		}
+		if key.Key == "" {
+			return fmt.Errorf("http: api key for client '%s' is empty", key.Client)
+		}
+		switch key.Role {
+		case "reader", "writer", "admin":
+		default:
+			return fmt.Errorf("http: client '%s': role must be one of reader, writer, admin", key.Client)
+		}
+		for _, name := range key.Connections {
+			if _, exists := connections[name]; !exists {
+				return fmt.Errorf("http: client '%s': unknown connection '%s'", key.Client, name)
+			}
+		}
+		for name, user := range key.MySQLUsers {
+			if user == nil {
+				user = &MySQLUser{}
+				key.MySQLUsers[name] = user
+			}
+			if user.User == "" {
+				user.User = name
+			}
+			user.Password = expandEnvVar(user.Password)
+			for _, conn := range user.Connections {
+				if _, exists := connections[conn]; !exists {
+					return fmt.Errorf("http: client '%s': mysql_users '%s': unknown connection '%s'", key.Client, name, conn)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateApprovalConfig applies default values to the approval section
+func validateApprovalConfig(approval *ApprovalConfig) {
+	// Webhook URLs (e.g. Slack incoming webhooks) embed a secret
+	approval.WebhookURL = expandEnvVar(approval.WebhookURL)
+	approval.CallbackURL = strings.TrimRight(approval.CallbackURL, "/")
+	if approval.TimeoutSeconds <= 0 {
+		approval.TimeoutSeconds = 3600
+	}
+}
+
+// charsetNamePattern matches a character set or collation name
+var charsetNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
 // validateAndApplyDefaults validates connection config and applies default values
 func validateAndApplyDefaults(name string, conn *ConnectionConfig) error {
 	// Expand environment variables in sensitive fields
 	conn.Host = expandEnvVar(conn.Host)
-	conn.User = expandEnvVar(conn.User)
-	conn.Password = expandEnvVar(conn.Password)
 	conn.Database = expandEnvVar(conn.Database)
+	conn.AnonymizeSalt = expandEnvVar(conn.AnonymizeSalt)
+
+	conn.rawUser = conn.User
+	conn.rawPassword = conn.Password
+	if _, err := conn.ResolveCredentials(); err != nil {
+		return fmt.Errorf("connection '%s': %w", name, err)
+	}
 
 	if conn.Host == "" {
 		return fmt.Errorf("connection '%s': host is required", name)
@@ -74,6 +731,158 @@ func validateAndApplyDefaults(name string, conn *ConnectionConfig) error {
 	if conn.MaxRows == 0 {
 		conn.MaxRows = 1000
 	}
+	if conn.MaxConcurrentQueries <= 0 {
+		conn.MaxConcurrentQueries = 5
+	}
+	if conn.QueueTimeoutSeconds <= 0 {
+		conn.QueueTimeoutSeconds = 10
+	}
+	if conn.IdleTimeoutSeconds <= 0 {
+		conn.IdleTimeoutSeconds = 900
+	}
+	if conn.MaxLifetimeSeconds <= 0 {
+		conn.MaxLifetimeSeconds = 3600
+	}
+	if conn.TransactionTimeoutSeconds <= 0 {
+		conn.TransactionTimeoutSeconds = 60
+	}
+	if conn.MaxExecutionTimeMs < 0 {
+		return fmt.Errorf("connection '%s': max_execution_time_ms must not be negative", name)
+	}
+	if conn.TenantDatabasePattern != "" {
+		verbs := strings.Count(conn.TenantDatabasePattern, "%")
+		if verbs != 1 || (!strings.Contains(conn.TenantDatabasePattern, "%d") && !strings.Contains(conn.TenantDatabasePattern, "%s")) {
+			return fmt.Errorf("connection '%s': tenant_database_pattern must contain exactly one %%d or %%s placeholder", name)
+		}
+	}
+	if conn.WriteBatchSeconds < 0 {
+		return fmt.Errorf("connection '%s': write_batch_seconds must not be negative", name)
+	}
+	if conn.WriteBatchMaxStatements <= 0 {
+		conn.WriteBatchMaxStatements = 100
+	}
+	if conn.BackupMaxRows <= 0 {
+		conn.BackupMaxRows = 10000
+	}
+	if conn.AlterMaxTableMB <= 0 {
+		conn.AlterMaxTableMB = 1024
+	}
+	if conn.CacheTTLSeconds < 0 {
+		return fmt.Errorf("connection '%s': cache_ttl_seconds must not be negative", name)
+	}
+	if conn.CacheMaxEntries <= 0 {
+		conn.CacheMaxEntries = 100
+	}
+	if conn.Charset == "" {
+		conn.Charset = "utf8mb4"
+	}
+	// Both end up in SET NAMES, so only plain names are accepted
+	if !charsetNamePattern.MatchString(conn.Charset) {
+		return fmt.Errorf("connection '%s': invalid charset %q", name, conn.Charset)
+	}
+	if conn.Collation != "" {
+		if !charsetNamePattern.MatchString(conn.Collation) {
+			return fmt.Errorf("connection '%s': invalid collation %q", name, conn.Collation)
+		}
+		if !strings.HasPrefix(conn.Collation, conn.Charset+"_") {
+			return fmt.Errorf("connection '%s': collation %s does not belong to charset %s", name, conn.Collation, conn.Charset)
+		}
+	}
+	conn.blockedPatterns = nil
+	for i, pattern := range conn.BlockedPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("connection '%s': blocked_patterns[%d]: %w", name, i, err)
+		}
+		conn.blockedPatterns = append(conn.blockedPatterns, regexp.MustCompile("(?i)"+pattern))
+	}
+	if h := conn.RowHistory; h != nil {
+		if h.TableSuffix == "" {
+			h.TableSuffix = "_history"
+		}
+		if h.TimestampColumn == "" {
+			h.TimestampColumn = "changed_at"
+		}
+		switch h.Image {
+		case "":
+			h.Image = "after"
+		case "after", "before":
+		default:
+			return fmt.Errorf("connection '%s': row_history.image must be one of after, before", name)
+		}
+	}
+	if osc := conn.OnlineSchemaChange; osc != nil {
+		switch osc.Tool {
+		case "gh-ost", "pt-online-schema-change":
+		default:
+			return fmt.Errorf("connection '%s': online_schema_change.tool must be one of gh-ost, pt-online-schema-change", name)
+		}
+		if osc.Path == "" {
+			osc.Path = osc.Tool
+		}
+	}
+	for table, t := range conn.Tables {
+		if t == nil {
+			continue
+		}
+		for _, column := range t.AllowedColumns {
+			if strings.TrimSpace(column) == "" {
+				return fmt.Errorf("connection '%s': tables.%s.allowed_columns must not contain empty names", name, table)
+			}
+		}
+		for column, rule := range t.Anonymize {
+			if !slices.Contains(AnonymizeRules, rule) {
+				return fmt.Errorf("connection '%s': tables.%s.anonymize.%s must be one of %s", name, table, column, strings.Join(AnonymizeRules, ", "))
+			}
+		}
+	}
+	if rotation := conn.PasswordRotation; rotation != nil {
+		if rotation.Command == "" && conn.PasswordFile == "" {
+			return fmt.Errorf("connection '%s': password_rotation needs password_rotation.command or password_file to store the new password", name)
+		}
+		if rotation.Length == 0 {
+			rotation.Length = 32
+		}
+		if rotation.Length < 16 || rotation.Length > 128 {
+			return fmt.Errorf("connection '%s': password_rotation.length must be between 16 and 128", name)
+		}
+	}
+	if c := conn.Coercion; c != nil {
+		switch c.DatetimeFormat {
+		case "":
+			c.DatetimeFormat = "rfc3339"
+		case "rfc3339", "sql", "unix":
+		default:
+			return fmt.Errorf("connection '%s': coercion.datetime_format must be one of %s", name, strings.Join(DatetimeFormats, ", "))
+		}
+		c.location = nil
+		if c.TimeZone != "" {
+			location, err := time.LoadLocation(c.TimeZone)
+			if err != nil {
+				return fmt.Errorf("connection '%s': coercion.time_zone: %w", name, err)
+			}
+			c.location = location
+		}
+	}
+	switch conn.Role {
+	case "", "primary", "replica":
+	default:
+		return fmt.Errorf("connection '%s': role must be one of primary, replica", name)
+	}
+	switch conn.Environment {
+	case "", "dev", "staging", "prod":
+	default:
+		return fmt.Errorf("connection '%s': environment must be one of dev, staging, prod", name)
+	}
+
+	switch conn.RiskTier {
+	case "low", "medium", "high":
+	case "":
+		// Derive the risk tier from the environment when not set explicitly
+		conn.RiskTier = map[string]string{"dev": "low", "staging": "medium", "prod": "high", "": "medium"}[conn.Environment]
+	default:
+		return fmt.Errorf("connection '%s': risk_tier must be one of low, medium, high", name)
+	}
+
 	// ReadOnly defaults to false (Go zero value), but we want true as default
 	// Since we can't distinguish between explicit false and unset, we document
 	// that read_only defaults to true and users must explicitly set false
@@ -81,18 +890,110 @@ func validateAndApplyDefaults(name string, conn *ConnectionConfig) error {
 	return nil
 }
 
+// IsReplica reports whether the connection is tagged role: replica
+func (c *ConnectionConfig) IsReplica() bool {
+	return c.Role == "replica"
+}
+
+// ResolveCredentials re-resolves the user and password from their original
+// secret references (${VAR} syntax or password_file) and reports whether
+// either value changed. Literal credentials, such as a client's own MySQL
+// account, are left as they are.
+func (c *ConnectionConfig) ResolveCredentials() (bool, error) {
+	if c.literalCredentials {
+		return false, nil
+	}
+	user := expandEnvVar(c.rawUser)
+	password := expandEnvVar(c.rawPassword)
+
+	if c.PasswordFile != "" {
+		data, err := os.ReadFile(c.PasswordFile)
+		if err != nil {
+			return false, fmt.Errorf("failed to read password_file: %w", err)
+		}
+		password = strings.TrimRight(string(data), "\r\n")
+	}
+
+	changed := user != c.User || password != c.Password
+	c.User = user
+	c.Password = password
+	return changed, nil
+}
+
+// WithCredentials returns a copy of the connection that logs in as user with
+// password instead of its configured credentials. They are taken literally:
+// a password such as "${VAR}" is never expanded from the environment.
+func (c *ConnectionConfig) WithCredentials(user, password string) *ConnectionConfig {
+	clone := *c
+	clone.User, clone.Password, clone.PasswordFile = user, password, ""
+	clone.rawUser, clone.rawPassword = "", ""
+	clone.literalCredentials = true
+	return &clone
+}
+
+// SetPassword makes password the connection's password after it was rotated.
+// It replaces the configured password or ${VAR} reference too, so re-resolving
+// the credentials keeps it; a password_file is still read instead.
+func (c *ConnectionConfig) SetPassword(password string) {
+	c.Password = password
+	c.rawPassword = password
+}
+
+// WritePasswordFile replaces the contents of password_file with password,
+// through a temporary file renamed over it so readers never see it partly
+// written. The file keeps its permissions.
+func (c *ConnectionConfig) WritePasswordFile(password string) error {
+	mode := os.FileMode(0o600)
+	if info, err := os.Stat(c.PasswordFile); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.PasswordFile), "."+filepath.Base(c.PasswordFile)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write password_file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.WriteString(password + "\n")
+	if err == nil {
+		err = tmp.Chmod(mode)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.PasswordFile)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write password_file: %w", err)
+	}
+	return nil
+}
+
+// BlockedPattern returns the first blocked_patterns entry the statement
+// matches, or "" when it matches none
+func (c *ConnectionConfig) BlockedPattern(query string) string {
+	for i, re := range c.blockedPatterns {
+		if re.MatchString(query) {
+			return c.BlockedPatterns[i]
+		}
+	}
+	return ""
+}
+
+// envVarPattern matches a value that is a ${VAR_NAME} reference
+var envVarPattern = regexp.MustCompile(`^\$\{([^}]+)\}$`)
+
 // expandEnvVar expands ${VAR_NAME} syntax to environment variable values
 func expandEnvVar(value string) string {
-	// Match ${VAR_NAME} pattern
-	re := regexp.MustCompile(`^\$\{([^}]+)\}$`)
-	matches := re.FindStringSubmatch(value)
+	matches := envVarPattern.FindStringSubmatch(value)
 	if len(matches) == 2 {
 		return os.Getenv(matches[1])
 	}
 	return value
 }
 
-// GetConfigPath returns the config file path from env var, flag, or default
+// GetConfigPath returns the config file path from the flag, the env var, or
+// the first existing file among SearchPaths, falling back to ./config.json
 func GetConfigPath(flagValue string) string {
 	// Command line flag takes precedence
 	if flagValue != "" {
@@ -104,12 +1005,23 @@ func GetConfigPath(flagValue string) string {
 		return envPath
 	}
 
+	// Then the working directory and the user's config directories
+	for _, path := range SearchPaths() {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+
 	// Default
 	return "./config.json"
 }
 
 // DSN returns the MySQL DSN string for the connection
 func (c *ConnectionConfig) DSN() string {
-	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true&timeout=30s&readTimeout=30s&writeTimeout=30s",
-		c.User, c.Password, c.Host, c.Port, c.Database)
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true&timeout=30s&readTimeout=30s&writeTimeout=30s&charset=%s",
+		c.User, c.Password, c.Host, c.Port, c.Database, c.Charset)
+	if c.Collation != "" {
+		dsn += "&collation=" + c.Collation
+	}
+	return dsn
 }
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configSet merges config files into one raw JSON tree. Connections from
+// different files must have distinct names; any other top-level key set by
+// more than one file takes the value from the file merged last, so a file
+// overrides the files it includes.
+type configSet struct {
+	tree        map[string]interface{}
+	connections map[string]interface{}
+	sources     map[string]string
+	loaded      map[string]bool
+	problems    []string
+}
+
+func newConfigSet() *configSet {
+	return &configSet{
+		tree:        make(map[string]interface{}),
+		connections: make(map[string]interface{}),
+		sources:     make(map[string]string),
+		loaded:      make(map[string]bool),
+	}
+}
+
+// load merges a config file after the files it includes. A file included
+// more than once is merged once; an include cycle is an error.
+func (s *configSet) load(path string, stack []string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	for _, p := range stack {
+		if p == abs {
+			return fmt.Errorf("config include cycle: %s -> %s", strings.Join(stack, " -> "), abs)
+		}
+	}
+	if s.loaded[abs] {
+		return nil
+	}
+	s.loaded[abs] = true
+
+	tree, err := readConfigFile(path)
+	if err != nil {
+		return err
+	}
+
+	includes, _ := tree["include"].([]interface{})
+	delete(tree, "include")
+	for _, include := range includes {
+		pattern := include.(string)
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(path), pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("%s: invalid include %q: %w", path, include, err)
+		}
+		if len(matches) == 0 && !strings.ContainsAny(pattern, "*?[") {
+			return fmt.Errorf("%s: included file %s does not exist", path, pattern)
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			if err := s.load(match, append(stack, abs)); err != nil {
+				return err
+			}
+		}
+	}
+
+	s.merge(path, tree)
+	return nil
+}
+
+// merge adds one file's tree to the set, recording connection names defined twice
+func (s *configSet) merge(path string, tree map[string]interface{}) {
+	for key, value := range tree {
+		if key != "connections" {
+			s.tree[key] = value
+			continue
+		}
+		connections, _ := value.(map[string]interface{})
+		for _, name := range sortedKeys(connections) {
+			if previous, exists := s.sources[name]; exists {
+				s.problems = append(s.problems, fmt.Sprintf("connection '%s' is defined in both %s and %s", name, previous, path))
+				continue
+			}
+			s.sources[name] = path
+			s.connections[name] = connections[name]
+		}
+	}
+}
+
+// decode returns the merged config
+func (s *configSet) decode() (*Config, error) {
+	if len(s.problems) > 0 {
+		return nil, fmt.Errorf("invalid config:\n  - %s", strings.Join(s.problems, "\n  - "))
+	}
+	s.tree["connections"] = s.connections
+
+	data, err := json.Marshal(s.tree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge config files: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	return &cfg, nil
+}
+
+// readConfigFile reads a JSON or YAML (.yaml, .yml) config file, checks it
+// against the config schema, and returns it as a raw JSON tree
+func readConfigFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if isYAML(path) {
+		var v interface{}
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("%s: failed to parse config file: %w", path, err)
+		}
+		if v == nil {
+			v = map[string]interface{}{}
+		}
+		if data, err = json.Marshal(v); err != nil {
+			return nil, fmt.Errorf("%s: failed to parse config file: %w", path, err)
+		}
+	}
+
+	if err := checkSchema(data); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var tree map[string]interface{}
+	if err := dec.Decode(&tree); err != nil {
+		return nil, fmt.Errorf("%s: failed to parse config file: %w", path, err)
+	}
+	return tree, nil
+}
+
+// configDirFiles lists the JSON and YAML files in a config directory in name order
+func configDirFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config directory: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if name := entry.Name(); strings.EqualFold(filepath.Ext(name), ".json") || isYAML(name) {
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no .json, .yaml, or .yml files in config directory %s", dir)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// isYAML reports whether a config file is YAML by its extension
+func isYAML(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// appDirName is the directory under the user's config directory holding the config
+const appDirName = "mysql-mcp"
+
+// configFileNames are the file names looked for in each search directory
+var configFileNames = []string{"config.json", "config.yaml", "config.yml"}
+
+// SearchPaths returns the locations GetConfigPath checks, in order, when
+// neither --config nor MYSQL_MCP_CONFIG is set: the working directory, the
+// platform's user config directory ($XDG_CONFIG_HOME or ~/.config on Linux,
+// ~/Library/Application Support on macOS, %APPDATA% on Windows), and
+// ~/.config on every platform
+func SearchPaths() []string {
+	dirs := []string{"."}
+	if dir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(dir, appDirName))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".config", appDirName))
+	}
+
+	seen := make(map[string]bool)
+	var paths []string
+	for _, dir := range dirs {
+		for _, name := range configFileNames {
+			path := filepath.Join(dir, name)
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths
+}
+
+// DefaultConfigPath returns where a new config is written: config.json in the
+// platform's user config directory, or the working directory if that is unknown
+func DefaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "config.json"
+	}
+	return filepath.Join(dir, appDirName, "config.json")
+}
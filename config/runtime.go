@@ -0,0 +1,144 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// runtimeConnectionsPath resolves runtime_connections_file against the
+// config's directory, defaulting to mysql-mcp-connections.json there
+func runtimeConnectionsPath(file, baseDir string) string {
+	if file == "" {
+		return filepath.Join(baseDir, "mysql-mcp-connections.json")
+	}
+	if !filepath.IsAbs(file) {
+		return filepath.Join(baseDir, file)
+	}
+	return file
+}
+
+// loadRuntimeConnections merges the connections add_connection persisted,
+// when the config allows runtime connections and the file exists
+func (s *configSet) loadRuntimeConnections(baseDir string) error {
+	if allow, _ := s.tree["allow_runtime_connections"].(bool); !allow {
+		return nil
+	}
+	file, _ := s.tree["runtime_connections_file"].(string)
+	path := runtimeConnectionsPath(file, baseDir)
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return s.load(path, nil)
+}
+
+// PrepareRuntimeConnection validates a connection added by add_connection
+// and applies its defaults. Its name must not be taken by a configured
+// connection or alias, and its settings may only reference the environment
+// variables in runtime_connection_env_vars.
+func (c *Config) PrepareRuntimeConnection(name string, conn *ConnectionConfig) error {
+	if name == "" || strings.Contains(name, "@") {
+		return fmt.Errorf("invalid connection name %q", name)
+	}
+	if c.ResolveConnection(name) != name {
+		return fmt.Errorf("connection name '%s' is an alias of connection '%s'", name, c.ResolveConnection(name))
+	}
+	if _, exists := c.Connections[name]; exists {
+		return fmt.Errorf("connection '%s' already exists", name)
+	}
+	for field, value := range map[string]string{"host": conn.Host, "user": conn.User, "password": conn.Password, "database": conn.Database} {
+		if m := envVarPattern.FindStringSubmatch(value); m != nil && !slices.Contains(c.RuntimeConnectionEnvVars, m[1]) {
+			return fmt.Errorf("%s references ${%s}, which runtime_connection_env_vars does not list", field, m[1])
+		}
+	}
+
+	if err := validateAndApplyDefaults(name, conn); err != nil {
+		return err
+	}
+	if conn.BackupTable == "" && conn.BackupFile == "" {
+		conn.BackupFile = filepath.Join(c.baseDir, "mysql-mcp-backups.jsonl")
+	}
+	return nil
+}
+
+// SaveRuntimeConnection adds a connection to runtime_connections_file, so it
+// is loaded again at startup. entry holds the connection's settings as they
+// appear in a config file, with ${VAR} references unexpanded.
+func (c *Config) SaveRuntimeConnection(name string, entry map[string]interface{}) error {
+	file, connections, err := c.readRuntimeConnections()
+	if err != nil {
+		return err
+	}
+	connections[name] = entry
+	file["connections"] = connections
+	return c.writeRuntimeConnections(file)
+}
+
+// DeleteRuntimeConnection removes a connection from runtime_connections_file,
+// so it is not loaded again at startup. It reports whether the file held it.
+func (c *Config) DeleteRuntimeConnection(name string) (bool, error) {
+	file, connections, err := c.readRuntimeConnections()
+	if err != nil {
+		return false, err
+	}
+	if _, exists := connections[name]; !exists {
+		return false, nil
+	}
+	delete(connections, name)
+	file["connections"] = connections
+	return true, c.writeRuntimeConnections(file)
+}
+
+// readRuntimeConnections parses runtime_connections_file, returning the whole
+// file and its connections object, both empty when the file does not exist
+func (c *Config) readRuntimeConnections() (map[string]interface{}, map[string]interface{}, error) {
+	file := map[string]interface{}{}
+	if data, err := os.ReadFile(c.RuntimeConnectionsFile); err == nil {
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse runtime_connections_file: %w", err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, nil, fmt.Errorf("failed to read runtime_connections_file: %w", err)
+	}
+
+	connections, _ := file["connections"].(map[string]interface{})
+	if connections == nil {
+		connections = map[string]interface{}{}
+	}
+	return file, connections, nil
+}
+
+// writeRuntimeConnections replaces runtime_connections_file with file
+func (c *Config) writeRuntimeConnections(file map[string]interface{}) error {
+	path := c.RuntimeConnectionsFile
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to write runtime_connections_file: %w", err)
+	}
+
+	// Written next to the target and renamed over it, since it holds passwords
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write runtime_connections_file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(append(data, '\n'))
+	if err == nil {
+		err = tmp.Chmod(0o600)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write runtime_connections_file: %w", err)
+	}
+	return nil
+}
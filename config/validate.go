@@ -0,0 +1,282 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// checkSchema validates raw config JSON against the Config struct before it
+// is decoded: unknown keys (with a did-you-mean suggestion), values of the
+// wrong type, and keys repeated within one object, such as two connections
+// with the same name. All problems are reported at once.
+func checkSchema(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var problems []string
+	tree, err := decodeTree(dec, "", &problems)
+	if err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+	checkValue(tree, reflect.TypeOf(Config{}), "", &problems)
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid config file:\n  - %s", strings.Join(problems, "\n  - "))
+}
+
+// decodeTree decodes one JSON value into maps, slices, and scalars, recording
+// keys that appear more than once in the same object
+func decodeTree(dec *json.Decoder, path string, problems *[]string) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		obj := make(map[string]interface{})
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := keyTok.(string)
+			value, err := decodeTree(dec, joinPath(path, key), problems)
+			if err != nil {
+				return nil, err
+			}
+			if _, exists := obj[key]; exists {
+				*problems = append(*problems, fmt.Sprintf("%s: defined more than once", joinPath(path, key)))
+			}
+			obj[key] = value
+		}
+		_, err := dec.Token()
+		return obj, err
+	case json.Delim('['):
+		var arr []interface{}
+		for i := 0; dec.More(); i++ {
+			value, err := decodeTree(dec, fmt.Sprintf("%s[%d]", path, i), problems)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		_, err := dec.Token()
+		return arr, err
+	default:
+		return tok, nil
+	}
+}
+
+// checkValue compares a decoded value against the Go type it will be decoded into
+func checkValue(v interface{}, t reflect.Type, path string, problems *[]string) {
+	if v == nil {
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	wrongType := func(expected string) {
+		*problems = append(*problems, fmt.Sprintf("%s: expected %s, got %s", displayPath(path), expected, describeJSON(v)))
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			wrongType("an object")
+			return
+		}
+		fields := jsonFields(t)
+		for _, key := range sortedKeys(obj) {
+			field, known := fields[key]
+			if !known {
+				msg := fmt.Sprintf("%s: unknown key", joinPath(path, key))
+				if suggestion := closestName(key, fields); suggestion != "" {
+					msg += fmt.Sprintf(" (did you mean %q?)", suggestion)
+				}
+				*problems = append(*problems, msg)
+				continue
+			}
+			checkValue(obj[key], field, joinPath(path, key), problems)
+		}
+	case reflect.Map:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			wrongType("an object")
+			return
+		}
+		for _, key := range sortedKeys(obj) {
+			checkValue(obj[key], t.Elem(), joinPath(path, key), problems)
+		}
+	case reflect.Slice:
+		arr, ok := v.([]interface{})
+		if !ok {
+			wrongType("a list")
+			return
+		}
+		for i, item := range arr {
+			checkValue(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), problems)
+		}
+	case reflect.String:
+		if _, ok := v.(string); !ok {
+			wrongType("a string")
+		}
+	case reflect.Bool:
+		if _, ok := v.(bool); !ok {
+			wrongType("true or false")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := v.(json.Number)
+		if !ok {
+			wrongType("a whole number")
+			return
+		}
+		if _, err := n.Int64(); err != nil {
+			wrongType("a whole number")
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, ok := v.(json.Number); !ok {
+			wrongType("a number")
+		}
+	}
+}
+
+// jsonFields maps the JSON keys of a struct's exported fields to their types
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+// closestName returns the known key nearest to an unknown one, or "" when
+// none is close enough to be a likely typo
+func closestName(key string, fields map[string]reflect.Type) string {
+	best, bestDistance := "", len(key)/3+2
+	for name := range fields {
+		if d := editDistance(strings.ToLower(key), name); d < bestDistance || (d == bestDistance && name < best) {
+			best, bestDistance = name, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// describeJSON names the JSON type of a decoded value for error messages
+func describeJSON(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return fmt.Sprintf("string %q", val)
+	case json.Number:
+		return "number " + val.String()
+	case bool:
+		return fmt.Sprintf("%t", val)
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "a list"
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+// sortedKeys returns an object's keys in order, so problems are reported deterministically
+func sortedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// joinPath appends a key to a dotted config path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// displayPath names a config path in messages, using "config" for the root
+func displayPath(path string) string {
+	if path == "" {
+		return "config"
+	}
+	return path
+}
+
+// redactedValue replaces secrets in the resolved config printed by --validate-config
+const redactedValue = "[redacted]"
+
+// Redacted returns a copy of the config with passwords, anonymization salts,
+// API keys, and the approval webhook URL (which embeds a token in Slack and
+// Teams) replaced
+func (c *Config) Redacted() *Config {
+	data, _ := json.Marshal(c)
+	var copied Config
+	_ = json.Unmarshal(data, &copied)
+
+	for _, conn := range copied.Connections {
+		if conn.Password != "" {
+			conn.Password = redactedValue
+		}
+		if conn.AnonymizeSalt != "" {
+			conn.AnonymizeSalt = redactedValue
+		}
+	}
+	if copied.HTTP != nil {
+		for i := range copied.HTTP.APIKeys {
+			copied.HTTP.APIKeys[i].Key = redactedValue
+		}
+	}
+	if copied.Approval != nil && copied.Approval.WebhookURL != "" {
+		copied.Approval.WebhookURL = redactedValue
+	}
+	if copied.Tracing != nil {
+		for name := range copied.Tracing.Headers {
+			copied.Tracing.Headers[name] = redactedValue
+		}
+	}
+	return &copied
+}
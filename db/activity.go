@@ -0,0 +1,74 @@
+package db
+
+import (
+	"fmt"
+)
+
+// activityColumns is the projection shared by both processlist sources
+const activityColumns = `ID AS id, USER AS user, HOST AS host, DB AS db, COMMAND AS command,
+	TIME AS time_seconds, STATE AS state, INFO AS query`
+
+// ShowActivity returns running statements from performance_schema.processlist
+// (falling back to information_schema.PROCESSLIST), with user and host redacted
+// unless the connection sets show_activity_user_host
+func (m *Manager) ShowActivity(connectionName string, includeIdle bool, limit int) (*QueryResult, error) {
+	connConfig, exists := m.lookupConnection(connectionName)
+	if !exists {
+		return nil, fmt.Errorf("unknown connection: %s", connectionName)
+	}
+
+	filter := "WHERE ID <> CONNECTION_ID()"
+	if !includeIdle {
+		filter += " AND COMMAND <> 'Sleep'"
+	}
+	suffix := fmt.Sprintf(" %s ORDER BY TIME DESC LIMIT %d", filter, limit)
+
+	// performance_schema.processlist does not exist on MariaDB or MySQL before 8.0.22
+	var activity *QueryResult
+	var err error
+	if info, infoErr := m.ServerInfo(connectionName); infoErr != nil || info.hasPerformanceSchemaProcesslist() {
+		activity, err = m.ExecuteQuery(connectionName, "SELECT "+activityColumns+" FROM performance_schema.processlist"+suffix)
+	} else {
+		err = fmt.Errorf("performance_schema.processlist is not available on %s %s", info.Flavor, info.Version)
+	}
+	if err != nil {
+		legacy, legacyErr := m.ExecuteQuery(connectionName, "SELECT "+activityColumns+" FROM information_schema.PROCESSLIST"+suffix)
+		if legacyErr != nil {
+			return nil, fmt.Errorf("failed to read process list: %w", err)
+		}
+		activity = legacy
+	}
+
+	if !connConfig.ShowActivityUserHost {
+		for _, row := range activity.Rows {
+			row["user"] = "[redacted]"
+			row["host"] = "[redacted]"
+		}
+	}
+
+	return activity, nil
+}
+
+// KillQuery terminates the statement running on a server thread, leaving the
+// connection itself open. Requires allow_kill_query on the connection.
+func (m *Manager) KillQuery(connectionName string, processID int64) error {
+	db, connConfig, err := m.GetConnection(connectionName)
+	if err != nil {
+		return err
+	}
+
+	if !connConfig.AllowKillQuery {
+		return fmt.Errorf("connection '%s' does not allow killing queries; set allow_kill_query: true in config to enable kill_query", connectionName)
+	}
+	if processID <= 0 {
+		return fmt.Errorf("process_id must be a positive integer")
+	}
+
+	query := fmt.Sprintf("KILL QUERY %d", processID)
+	if _, err := db.Exec(query); err != nil {
+		m.recordError(connectionName, query, err)
+		return fmt.Errorf("query execution failed: %w", err)
+	}
+
+	return nil
+}
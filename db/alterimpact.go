@@ -0,0 +1,256 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Algorithms an ALTER TABLE clause runs with, from least to most disruptive:
+// instant changes only metadata, inplace works on the table without
+// rebuilding it, rebuild rewrites the table in place while allowing
+// concurrent DML, and copy rewrites it into a new table blocking writes
+var alterAlgorithmRank = map[string]int{"instant": 0, "inplace": 1, "rebuild": 2, "copy": 3, "unknown": 4}
+
+// AlterImpact is the analysis of an ALTER TABLE run before mysql_alter
+// executes it. Algorithms are estimated from the statement's clauses
+// following MySQL 8.0 online DDL; the server has the final say.
+type AlterImpact struct {
+	Database      string           `json:"database,omitempty"`
+	Table         string           `json:"table"`
+	EstimatedRows *int64           `json:"estimated_rows,omitempty"`
+	SizeMB        *float64         `json:"size_mb,omitempty"`
+	Algorithm     string           `json:"algorithm"`
+	Operations    []AlterOperation `json:"operations"`
+	Warnings      []string         `json:"warnings,omitempty"`
+
+	// Objects that depend on the table
+	Views                  []string `json:"dependent_views,omitempty"`
+	ReferencingForeignKeys []string `json:"referencing_foreign_keys,omitempty"`
+	ForeignKeys            []string `json:"foreign_keys,omitempty"`
+	Triggers               []string `json:"triggers,omitempty"`
+
+	// Blocked is set when the statement was not run because of its size
+	Blocked     bool   `json:"blocked,omitempty"`
+	BlockReason string `json:"block_reason,omitempty"`
+}
+
+// AlterOperation is one clause of an ALTER TABLE and its estimated algorithm
+type AlterOperation struct {
+	Clause    string `json:"clause"`
+	Algorithm string `json:"algorithm"`
+	Note      string `json:"note,omitempty"`
+}
+
+// alterTablePattern matches the head of ALTER TABLE <table>
+var alterTablePattern = regexp.MustCompile(`(?is)^\s*ALTER\s+(?:ONLINE\s+|IGNORE\s+)*TABLE\s+(` +
+	identifierPart + `(?:\.` + identifierPart + `)?)\s*`)
+
+// alterClauseRule estimates the algorithm of clauses matching pattern
+type alterClauseRule struct {
+	pattern   *regexp.Regexp
+	algorithm string
+	note      string
+}
+
+// alterClauseRules are tried in order against each normalized, upper-cased
+// clause; the first match wins
+var alterClauseRules = []alterClauseRule{
+	{regexp.MustCompile(`^ADD (CONSTRAINT \S+ )?PRIMARY KEY`), "rebuild", "rebuilds the clustered index"},
+	{regexp.MustCompile(`^ADD (CONSTRAINT \S+ )?FOREIGN KEY`), "copy", "in place only with foreign_key_checks disabled"},
+	{regexp.MustCompile(`^ADD (CONSTRAINT \S+ )?CHECK`), "copy", "existing rows are validated by copying the table"},
+	{regexp.MustCompile(`^ADD FULLTEXT`), "rebuild", "the table's first FULLTEXT index rebuilds it"},
+	{regexp.MustCompile(`^ADD (CONSTRAINT \S+ )?(UNIQUE|INDEX|KEY|SPATIAL)`), "inplace", "the index is built while concurrent DML continues"},
+	{regexp.MustCompile(`^ADD PARTITION`), "inplace", ""},
+	{regexp.MustCompile(`^ADD .* STORED\b`), "copy", "stored generated columns are computed by copying the table"},
+	{regexp.MustCompile(`^ADD `), "instant", "instant on MySQL 8.0.29+ (8.0.12+ as the last column); older servers rebuild the table"},
+	{regexp.MustCompile(`^DROP PRIMARY KEY`), "copy", ""},
+	{regexp.MustCompile(`^DROP (INDEX|KEY|FOREIGN KEY|CONSTRAINT|CHECK) `), "inplace", ""},
+	{regexp.MustCompile(`^DROP PARTITION`), "inplace", "the partitions' rows are deleted"},
+	{regexp.MustCompile(`^DROP `), "instant", "instant on MySQL 8.0.29+; older servers rebuild the table"},
+	{regexp.MustCompile(`^ALTER (COLUMN )?\S+ (SET|DROP) DEFAULT`), "instant", ""},
+	{regexp.MustCompile(`^ALTER (COLUMN )?\S+ SET (VISIBLE|INVISIBLE)`), "instant", ""},
+	{regexp.MustCompile(`^ALTER INDEX`), "instant", ""},
+	{regexp.MustCompile(`^ALTER (CHECK|CONSTRAINT)`), "inplace", ""},
+	{regexp.MustCompile(`^RENAME COLUMN`), "instant", ""},
+	{regexp.MustCompile(`^RENAME (INDEX|KEY)`), "inplace", ""},
+	{regexp.MustCompile(`^RENAME`), "instant", "renames the table"},
+	{regexp.MustCompile(`^(MODIFY|CHANGE)`), "copy", "changing a column's type copies the table; renaming it, changing only its default or growing a VARCHAR within the same length-byte range is in place"},
+	{regexp.MustCompile(`^CONVERT TO`), "copy", "every text column is converted"},
+	{regexp.MustCompile(`^(DEFAULT )?(CHARACTER SET|CHARSET|COLLATE)`), "instant", "only the default for new columns changes"},
+	{regexp.MustCompile(`^(ENGINE|ROW_FORMAT|KEY_BLOCK_SIZE|FORCE)`), "rebuild", ""},
+	{regexp.MustCompile(`^ORDER BY`), "copy", ""},
+	{regexp.MustCompile(`^(COMMENT|AUTO_INCREMENT|STATS_|PACK_KEYS|MAX_ROWS|MIN_ROWS|AVG_ROW_LENGTH)`), "inplace", "metadata only"},
+	{regexp.MustCompile(`^(PARTITION BY|REORGANIZE|COALESCE|REMOVE PARTITIONING|REBUILD PARTITION)`), "copy", ""},
+	{regexp.MustCompile(`^(EXCHANGE PARTITION|ANALYZE PARTITION|CHECK PARTITION|DISCARD|IMPORT)`), "inplace", ""},
+}
+
+// alterOptionPattern matches the ALGORITHM and LOCK clauses of an ALTER TABLE
+var alterOptionPattern = regexp.MustCompile(`^(ALGORITHM|LOCK)\s*=?\s*(\w+)`)
+
+// classifyAlter splits the clauses following ALTER TABLE <table> at
+// top-level commas and estimates the algorithm of each. The overall algorithm
+// is the most disruptive one, or the one requested with ALGORITHM= when that
+// is more disruptive still.
+func classifyAlter(specs string) (string, []AlterOperation, []string) {
+	masked := blankNested(maskLiterals(specs))
+	var operations []AlterOperation
+	var warnings []string
+	overall, requested := "instant", ""
+
+	start := 0
+	for i := 0; i <= len(masked); i++ {
+		if i < len(masked) && masked[i] != ',' {
+			continue
+		}
+		clause := strings.TrimSpace(specs[start:i])
+		normalized := strings.ToUpper(strings.Join(strings.Fields(maskLiterals(clause)), " "))
+		start = i + 1
+		if clause == "" {
+			continue
+		}
+
+		if m := alterOptionPattern.FindStringSubmatch(normalized); m != nil {
+			if m[1] == "ALGORITHM" && m[2] != "DEFAULT" {
+				requested = strings.ToLower(m[2])
+			}
+			continue
+		}
+
+		op := AlterOperation{Clause: clause, Algorithm: "unknown"}
+		for _, rule := range alterClauseRules {
+			if rule.pattern.MatchString(normalized) {
+				op.Algorithm, op.Note = rule.algorithm, rule.note
+				break
+			}
+		}
+		if op.Algorithm == "unknown" {
+			warnings = append(warnings, fmt.Sprintf("could not estimate how the server runs %q; assume it copies the table", clause))
+		}
+		if alterAlgorithmRank[op.Algorithm] > alterAlgorithmRank[overall] {
+			overall = op.Algorithm
+		}
+		operations = append(operations, op)
+	}
+
+	switch {
+	case requested == "":
+	case requested == "instant" && overall != "instant", requested == "inplace" && alterAlgorithmRank[overall] > alterAlgorithmRank["rebuild"]:
+		warnings = append(warnings, fmt.Sprintf("ALGORITHM=%s was requested but the statement is estimated to need %s; the server refuses it if it cannot run it that way", strings.ToUpper(requested), overall))
+	case alterAlgorithmRank[requested] > alterAlgorithmRank[overall]:
+		overall = requested
+	}
+	return overall, operations, warnings
+}
+
+// AnalyzeAlter estimates the impact of an ALTER TABLE statement: the table's
+// size, whether the server will rebuild or copy it, and the views, foreign
+// keys and triggers depending on it. It returns nil for other ALTER statements.
+func (m *Manager) AnalyzeAlter(connectionName, query string) (*AlterImpact, error) {
+	_, connConfig, err := m.GetConnection(connectionName)
+	if err != nil {
+		return nil, err
+	}
+	trimmed := strings.TrimRight(strings.TrimSpace(query), ";")
+	loc := alterTablePattern.FindStringSubmatchIndex(trimmed)
+	if loc == nil {
+		return nil, nil
+	}
+	ref := trimmed[loc[2]:loc[3]]
+
+	impact := &AlterImpact{Database: connConfig.Database, Table: tableName(ref)}
+	if parts := strings.SplitN(ref, ".", 2); len(parts) == 2 {
+		impact.Database = unquoteIdentifier(parts[0])
+	}
+	impact.Algorithm, impact.Operations, impact.Warnings = classifyAlter(trimmed[loc[1]:])
+	if impact.Operations == nil {
+		impact.Operations = []AlterOperation{}
+	}
+	database := nullIfEmpty(impact.Database)
+
+	size, err := m.ExecuteQuery(connectionName, `SELECT TABLE_ROWS, DATA_LENGTH + INDEX_LENGTH AS SIZE_BYTES
+		FROM information_schema.TABLES WHERE TABLE_SCHEMA = COALESCE(?, DATABASE()) AND TABLE_NAME = ?`, database, impact.Table)
+	if err != nil {
+		return nil, fmt.Errorf("impact analysis failed: %w", err)
+	}
+	if len(size.Rows) > 0 {
+		impact.EstimatedRows = int64Ptr(size.Rows[0]["TABLE_ROWS"])
+		if bytes := int64Ptr(size.Rows[0]["SIZE_BYTES"]); bytes != nil {
+			mb := float64(*bytes*100/(1024*1024)) / 100
+			impact.SizeMB = &mb
+		}
+	}
+
+	switch impact.Algorithm {
+	case "copy", "unknown":
+		impact.Warnings = append(impact.Warnings, fmt.Sprintf("the table is copied%s: concurrent writes are blocked until it finishes and twice its disk space is needed", sizeSuffix(impact.SizeMB)))
+	case "rebuild":
+		impact.Warnings = append(impact.Warnings, fmt.Sprintf("the table is rebuilt in place%s: concurrent DML continues, but replicas apply the ALTER only after it finishes and lag for as long", sizeSuffix(impact.SizeMB)))
+	}
+
+	views, err := m.ExecuteQuery(connectionName, `SELECT TABLE_SCHEMA, TABLE_NAME FROM information_schema.VIEWS
+		WHERE VIEW_DEFINITION LIKE ? ESCAPE '!' ORDER BY TABLE_SCHEMA, TABLE_NAME`, "%`"+escapeLike(impact.Table)+"`%")
+	if err != nil {
+		return nil, fmt.Errorf("impact analysis failed: %w", err)
+	}
+	for _, row := range views.Rows {
+		impact.Views = append(impact.Views, stringValue(row["TABLE_SCHEMA"])+"."+stringValue(row["TABLE_NAME"]))
+	}
+
+	referencing, err := m.ExecuteQuery(connectionName, `SELECT TABLE_SCHEMA, TABLE_NAME, CONSTRAINT_NAME,
+		GROUP_CONCAT(COLUMN_NAME ORDER BY ORDINAL_POSITION SEPARATOR ', ') AS COLUMNS,
+		GROUP_CONCAT(REFERENCED_COLUMN_NAME ORDER BY ORDINAL_POSITION SEPARATOR ', ') AS REFERENCED_COLUMNS
+		FROM information_schema.KEY_COLUMN_USAGE
+		WHERE REFERENCED_TABLE_SCHEMA = COALESCE(?, DATABASE()) AND REFERENCED_TABLE_NAME = ?
+		GROUP BY TABLE_SCHEMA, TABLE_NAME, CONSTRAINT_NAME
+		ORDER BY TABLE_SCHEMA, TABLE_NAME, CONSTRAINT_NAME`, database, impact.Table)
+	if err != nil {
+		return nil, fmt.Errorf("impact analysis failed: %w", err)
+	}
+	for _, row := range referencing.Rows {
+		impact.ReferencingForeignKeys = append(impact.ReferencingForeignKeys, fmt.Sprintf("%s.%s.%s (%s) -> %s (%s)",
+			stringValue(row["TABLE_SCHEMA"]), stringValue(row["TABLE_NAME"]), stringValue(row["CONSTRAINT_NAME"]),
+			stringValue(row["COLUMNS"]), impact.Table, stringValue(row["REFERENCED_COLUMNS"])))
+	}
+
+	own, err := m.ExecuteQuery(connectionName, `SELECT CONSTRAINT_NAME, REFERENCED_TABLE_SCHEMA, REFERENCED_TABLE_NAME,
+		GROUP_CONCAT(COLUMN_NAME ORDER BY ORDINAL_POSITION SEPARATOR ', ') AS COLUMNS,
+		GROUP_CONCAT(REFERENCED_COLUMN_NAME ORDER BY ORDINAL_POSITION SEPARATOR ', ') AS REFERENCED_COLUMNS
+		FROM information_schema.KEY_COLUMN_USAGE
+		WHERE TABLE_SCHEMA = COALESCE(?, DATABASE()) AND TABLE_NAME = ? AND REFERENCED_TABLE_NAME IS NOT NULL
+		GROUP BY CONSTRAINT_NAME, REFERENCED_TABLE_SCHEMA, REFERENCED_TABLE_NAME
+		ORDER BY CONSTRAINT_NAME`, database, impact.Table)
+	if err != nil {
+		return nil, fmt.Errorf("impact analysis failed: %w", err)
+	}
+	for _, row := range own.Rows {
+		impact.ForeignKeys = append(impact.ForeignKeys, fmt.Sprintf("%s (%s) -> %s.%s (%s)",
+			stringValue(row["CONSTRAINT_NAME"]), stringValue(row["COLUMNS"]),
+			stringValue(row["REFERENCED_TABLE_SCHEMA"]), stringValue(row["REFERENCED_TABLE_NAME"]), stringValue(row["REFERENCED_COLUMNS"])))
+	}
+
+	triggers, err := m.ExecuteQuery(connectionName, `SELECT TRIGGER_NAME, ACTION_TIMING, EVENT_MANIPULATION
+		FROM information_schema.TRIGGERS
+		WHERE EVENT_OBJECT_SCHEMA = COALESCE(?, DATABASE()) AND EVENT_OBJECT_TABLE = ?
+		ORDER BY TRIGGER_NAME`, database, impact.Table)
+	if err != nil {
+		return nil, fmt.Errorf("impact analysis failed: %w", err)
+	}
+	for _, row := range triggers.Rows {
+		impact.Triggers = append(impact.Triggers, fmt.Sprintf("%s (%s %s)",
+			stringValue(row["TRIGGER_NAME"]), stringValue(row["ACTION_TIMING"]), stringValue(row["EVENT_MANIPULATION"])))
+	}
+
+	if len(impact.Views)+len(impact.ReferencingForeignKeys)+len(impact.Triggers) > 0 {
+		impact.Warnings = append(impact.Warnings, "views, foreign keys and triggers listed here may break if columns they use are renamed, dropped or retyped")
+	}
+	return impact, nil
+}
+
+// sizeSuffix renders a table size for impact warnings
+func sizeSuffix(sizeMB *float64) string {
+	if sizeMB == nil {
+		return ""
+	}
+	return fmt.Sprintf(" (%.2f MB)", *sizeMB)
+}
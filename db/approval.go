@@ -0,0 +1,250 @@
+package db
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"mysql-golang-mcp/config"
+)
+
+// approval is a risky statement held until a human approves or rejects it.
+// run executes the statement with the approval check skipped.
+type approval struct {
+	id         string
+	token      string
+	connection string
+	requested  time.Time
+	expires    time.Time
+	run        func() (interface{}, error)
+
+	status  string
+	outcome *ApprovalOutcome
+	timer   *time.Timer
+	mu      sync.Mutex
+}
+
+// PendingApproval is returned instead of a result when a statement is queued
+type PendingApproval struct {
+	ApprovalID string    `json:"approval_id"`
+	Status     string    `json:"status"`
+	Connection string    `json:"connection"`
+	SQL        string    `json:"sql"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Message    string    `json:"message"`
+}
+
+// ApprovalOutcome reports how a queued statement was decided and, once
+// approved, the result of running it
+type ApprovalOutcome struct {
+	ApprovalID string      `json:"approval_id"`
+	Status     string      `json:"status"`
+	DecidedBy  string      `json:"decided_by"`
+	Result     interface{} `json:"result,omitempty"`
+	Error      string      `json:"error,omitempty"`
+}
+
+// approvalWebhookPayload is posted to the approval webhook. Text makes the
+// message readable in Slack and Teams incoming webhooks as is.
+type approvalWebhookPayload struct {
+	Text          string    `json:"text"`
+	ApprovalID    string    `json:"approval_id"`
+	Connection    string    `json:"connection"`
+	Environment   string    `json:"environment,omitempty"`
+	StatementType string    `json:"statement_type"`
+	SQL           string    `json:"sql"`
+	RequestedAt   time.Time `json:"requested_at"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	ApproveURL    string    `json:"approve_url,omitempty"`
+	RejectURL     string    `json:"reject_url,omitempty"`
+}
+
+// Approval statuses
+const (
+	approvalPending  = "pending"
+	approvalExecuted = "executed"
+	approvalFailed   = "failed"
+	approvalRejected = "rejected"
+	approvalExpired  = "expired"
+)
+
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// needsApproval reports whether a statement must wait for approval on a connection
+func needsApproval(connConfig *config.ConnectionConfig, queryType QueryType) bool {
+	if !connConfig.RequireApproval {
+		return false
+	}
+	return queryType != QueryTypeInsert && !IsReadOnlyQueryType(queryType)
+}
+
+// requestApproval queues a statement and posts it to the approval webhook.
+// The statement is discarded if the webhook cannot be reached, since no one
+// would be told to approve it.
+func (m *Manager) requestApproval(connectionName string, connConfig *config.ConnectionConfig, query string, queryType QueryType, run func() (interface{}, error)) (*PendingApproval, error) {
+	settings := m.config.Approval
+	timeout := time.Duration(settings.TimeoutSeconds) * time.Second
+
+	label := GetQueryTypeLabel(queryType)
+	now := time.Now()
+	a := &approval{
+		id:         newApprovalID(),
+		token:      randomHex(16),
+		connection: connectionName,
+		requested:  now,
+		expires:    now.Add(timeout),
+		run:        run,
+		status:     approvalPending,
+	}
+
+	target := fmt.Sprintf("connection '%s'", connectionName)
+	if connConfig.Environment != "" {
+		target += " (" + connConfig.Environment + ")"
+	}
+	payload := approvalWebhookPayload{
+		Text:          fmt.Sprintf("Approval requested for %s on %s:\n%s\nApproval ID: %s", label, target, query, a.id),
+		ApprovalID:    a.id,
+		Connection:    connectionName,
+		Environment:   connConfig.Environment,
+		StatementType: label,
+		SQL:           query,
+		RequestedAt:   a.requested,
+		ExpiresAt:     a.expires,
+	}
+	if settings.CallbackURL != "" {
+		payload.ApproveURL = fmt.Sprintf("%s/approvals/%s/approve?token=%s", settings.CallbackURL, a.id, a.token)
+		payload.RejectURL = fmt.Sprintf("%s/approvals/%s/reject?token=%s", settings.CallbackURL, a.id, a.token)
+	}
+	if err := postWebhook(settings.WebhookURL, payload); err != nil {
+		return nil, fmt.Errorf("statement requires approval but the approval webhook failed, nothing was queued: %w", err)
+	}
+
+	a.timer = time.AfterFunc(timeout, func() { m.expireApproval(a) })
+
+	m.approvalsMu.Lock()
+	// Forget decided and expired approvals once they are a full timeout old
+	for id, old := range m.approvals {
+		if now.Sub(old.expires) > timeout {
+			delete(m.approvals, id)
+		}
+	}
+	m.approvals[a.id] = a
+	m.approvalsMu.Unlock()
+
+	slog.Info("approval requested", "approval_id", a.id, "connection", connectionName, "statement_type", label)
+
+	return &PendingApproval{
+		ApprovalID: a.id,
+		Status:     approvalPending,
+		Connection: connectionName,
+		SQL:        query,
+		ExpiresAt:  a.expires,
+		Message:    "This statement requires approval and has not run. It runs once approved through the webhook callback or the approve_pending tool.",
+	}, nil
+}
+
+// postWebhook sends a JSON payload and treats any non-2xx response as a failure
+func postWebhook(url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	resp, err := webhookClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// expireApproval discards a statement that was not decided in time
+func (m *Manager) expireApproval(a *approval) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.status != approvalPending {
+		return
+	}
+	a.status = approvalExpired
+	a.run = nil
+	slog.Warn("approval expired", "approval_id", a.id, "connection", a.connection)
+}
+
+// ApprovePending runs a queued statement. Deciding an already decided
+// statement returns the original outcome, so a callback and an
+// approve_pending call for the same ID never run it twice.
+func (m *Manager) ApprovePending(approvalID, decidedBy string) (*ApprovalOutcome, error) {
+	return m.decideApproval(approvalID, "", decidedBy, true)
+}
+
+// RejectPending discards a queued statement without running it
+func (m *Manager) RejectPending(approvalID, decidedBy string) (*ApprovalOutcome, error) {
+	return m.decideApproval(approvalID, "", decidedBy, false)
+}
+
+// DecideApprovalCallback approves or rejects a queued statement from the
+// webhook callback, which must present the token sent with the request
+func (m *Manager) DecideApprovalCallback(approvalID, token string, approve bool) (*ApprovalOutcome, error) {
+	if token == "" {
+		return nil, fmt.Errorf("missing approval token")
+	}
+	return m.decideApproval(approvalID, token, "callback", approve)
+}
+
+// decideApproval records a decision; token is checked when not empty
+func (m *Manager) decideApproval(approvalID, token, decidedBy string, approve bool) (*ApprovalOutcome, error) {
+	m.approvalsMu.Lock()
+	a, exists := m.approvals[approvalID]
+	m.approvalsMu.Unlock()
+	if !exists {
+		return nil, fmt.Errorf("unknown approval: %s", approvalID)
+	}
+	if token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) != 1 {
+		return nil, fmt.Errorf("invalid approval token")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	switch a.status {
+	case approvalPending:
+	case approvalExpired:
+		return nil, fmt.Errorf("approval expired: %s was not approved before %s and was discarded", approvalID, a.expires.Format(time.RFC3339))
+	default:
+		return a.outcome, nil
+	}
+
+	a.timer.Stop()
+	a.outcome = &ApprovalOutcome{ApprovalID: approvalID, DecidedBy: decidedBy}
+	if !approve {
+		a.status = approvalRejected
+		a.outcome.Status = approvalRejected
+		a.run = nil
+		slog.Info("approval rejected", "approval_id", approvalID, "connection", a.connection, "decided_by", decidedBy)
+		return a.outcome, nil
+	}
+
+	result, err := a.run()
+	a.run = nil
+	if err != nil {
+		a.status = approvalFailed
+		a.outcome.Error = err.Error()
+	} else {
+		a.status = approvalExecuted
+		a.outcome.Result = result
+	}
+	a.outcome.Status = a.status
+	slog.Info("approval granted", "approval_id", approvalID, "connection", a.connection, "decided_by", decidedBy, "status", a.status)
+	return a.outcome, nil
+}
+
+// newApprovalID returns a random approval identifier
+func newApprovalID() string {
+	return "apr_" + randomHex(8)
+}
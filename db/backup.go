@@ -0,0 +1,394 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"mysql-golang-mcp/config"
+)
+
+// BackupRef identifies the snapshot taken before a write
+type BackupRef struct {
+	BackupID string `json:"backup_id"`
+	Table    string `json:"table"`
+	Rows     int    `json:"rows"`
+	Location string `json:"location"`
+}
+
+// Backup is a snapshot of the rows an UPDATE or DELETE was about to change.
+// Values use the normal result encoding, except binary columns, which are
+// base64-encoded and listed in BinaryColumns.
+type Backup struct {
+	BackupID      string                   `json:"backup_id"`
+	Connection    string                   `json:"connection"`
+	Database      string                   `json:"database"`
+	Table         string                   `json:"table"`
+	Operation     string                   `json:"operation"`
+	SQL           string                   `json:"sql"`
+	CreatedAt     time.Time                `json:"created_at"`
+	Columns       []string                 `json:"columns"`
+	ColumnTypes   []ColumnType             `json:"column_types"`
+	BinaryColumns []string                 `json:"binary_columns,omitempty"`
+	Rows          []map[string]interface{} `json:"rows"`
+}
+
+// WriteOptions holds per-call overrides for write statements
+type WriteOptions struct {
+	// Backup overrides the connection's backup_before_write setting when set
+	Backup *bool
+
+	// Database runs the statement with this default database instead of the connection's
+	Database string
+
+	// Tenant runs the statement in this tenant's schema on a connection with
+	// tenant_database_pattern, refusing statements that name other databases
+	Tenant string
+
+	// Progress is called periodically while a long statement runs
+	Progress func(StatementProgress)
+
+	// Context carries the caller's trace span, so the statement's span is
+	// recorded as its child; nil starts a new trace
+	Context context.Context
+
+	// Batch adds an INSERT to the connection's write batch when it has
+	// write_batch_seconds set, instead of committing it on its own
+	Batch bool
+
+	// ReturnChanges reads the rows an UPDATE changes before and after it, in
+	// its transaction, and returns the changed columns of each row
+	ReturnChanges bool
+
+	// ReturnRows reads the rows an INSERT adds back in its transaction and
+	// returns them, found by ReturnKeys when set or else by the AUTO_INCREMENT
+	// values the INSERT generated
+	ReturnRows bool
+	ReturnKeys []map[string]interface{}
+
+	// Force runs an ALTER TABLE that the impact analysis would block for the
+	// size of its table
+	Force bool
+
+	// approved skips the require_approval queue for a statement already approved
+	approved bool
+}
+
+// backupTarget is the single table a write changes and the SELECT that reads
+// the rows it will change
+type backupTarget struct {
+	database   string
+	table      string
+	operation  string
+	selectSQL  string
+	selectArgs []interface{}
+}
+
+// singleTableUpdatePattern matches the head of UPDATE <table> SET
+var singleTableUpdatePattern = regexp.MustCompile(`(?is)^\s*UPDATE\s+(?:(?:LOW_PRIORITY|IGNORE)\s+)*(` +
+	identifierPart + `(?:\.` + identifierPart + `)?)\s+SET\s`)
+
+// clauseKeywordPattern matches the clauses that may follow UPDATE ... SET assignments
+var clauseKeywordPattern = regexp.MustCompile(`(?i)\b(?:WHERE|ORDER\s+BY|LIMIT)\b`)
+
+// maskLiterals blanks out string literal contents, keeping offsets intact, so
+// keywords and placeholders inside strings are not mistaken for SQL
+func maskLiterals(query string) string {
+	return stringLiteralPattern.ReplaceAllStringFunc(query, func(lit string) string {
+		return "'" + strings.Repeat("x", len(lit)-2) + "'"
+	})
+}
+
+// parseBackupTarget derives the SELECT ... FOR UPDATE that reads the rows a
+// single-table UPDATE or DELETE will change, reusing its WHERE, ORDER BY and
+// LIMIT clauses. Placeholder args bound in UPDATE's SET clause are dropped.
+// Unqualified tables are recorded in defaultDatabase.
+func parseBackupTarget(defaultDatabase, query string, args []interface{}) (*backupTarget, error) {
+	trimmed := strings.TrimRight(strings.TrimSpace(query), ";")
+	if hasReturningClause(trimmed) {
+		return nil, fmt.Errorf("backup_before_write does not support RETURNING")
+	}
+
+	var ref, tail, operation string
+	selectArgs := args
+	masked := maskLiterals(trimmed)
+
+	if loc := singleTableUpdatePattern.FindStringSubmatchIndex(masked); loc != nil {
+		operation = "UPDATE"
+		ref = trimmed[loc[2]:loc[3]]
+		setStart := loc[1]
+
+		// The tail starts at the first WHERE/ORDER BY/LIMIT outside parentheses
+		tailStart := len(trimmed)
+		for _, kw := range clauseKeywordPattern.FindAllStringIndex(masked[setStart:], -1) {
+			if parenDepth(masked[setStart:setStart+kw[0]]) == 0 {
+				tailStart = setStart + kw[0]
+				break
+			}
+		}
+		tail = " " + trimmed[tailStart:]
+
+		setPlaceholders := strings.Count(masked[setStart:tailStart], "?")
+		if setPlaceholders > len(args) {
+			return nil, fmt.Errorf("UPDATE has more placeholders than arguments")
+		}
+		selectArgs = args[setPlaceholders:]
+	} else if match := singleTableDeletePattern.FindStringSubmatch(trimmed); match != nil {
+		operation = "DELETE"
+		ref, tail = match[1], match[2]
+	} else {
+		return nil, fmt.Errorf("backup_before_write only supports single-table UPDATE and DELETE statements without aliases; pass backup: false to run this statement without a backup")
+	}
+
+	target := &backupTarget{
+		database:   defaultDatabase,
+		table:      tableName(ref),
+		operation:  operation,
+		selectSQL:  fmt.Sprintf("SELECT * FROM %s%s FOR UPDATE", ref, strings.TrimRight(tail, " ")),
+		selectArgs: selectArgs,
+	}
+	if parts := strings.SplitN(ref, ".", 2); len(parts) == 2 {
+		target.database = unquoteIdentifier(parts[0])
+	}
+	return target, nil
+}
+
+// parenDepth returns the parenthesis nesting depth at the end of s
+func parenDepth(s string) int {
+	return strings.Count(s, "(") - strings.Count(s, ")")
+}
+
+// isBinaryType reports whether a driver database type name holds raw bytes
+func isBinaryType(dbType string) bool {
+	switch dbType {
+	case "BINARY", "VARBINARY", "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "BIT", "GEOMETRY":
+		return true
+	default:
+		return false
+	}
+}
+
+// newBackup builds a snapshot from the rows read by a backup target
+func newBackup(connectionName, query string, target *backupTarget, snapshot *QueryResult) *Backup {
+	backup := &Backup{
+		BackupID:    newBackupID(),
+		Connection:  connectionName,
+		Database:    target.database,
+		Table:       target.table,
+		Operation:   target.operation,
+		SQL:         query,
+		CreatedAt:   time.Now().UTC(),
+		Columns:     snapshot.Columns,
+		ColumnTypes: snapshot.ColumnTypes,
+		Rows:        snapshot.Rows,
+	}
+
+	// Raw bytes do not survive JSON encoding as strings, so store them as base64
+	for _, ct := range snapshot.ColumnTypes {
+		if !isBinaryType(ct.DatabaseType) {
+			continue
+		}
+		backup.BinaryColumns = append(backup.BinaryColumns, ct.Name)
+		for _, row := range backup.Rows {
+			if s, ok := row[ct.Name].(string); ok {
+				row[ct.Name] = base64.StdEncoding.EncodeToString([]byte(s))
+			}
+		}
+	}
+	return backup
+}
+
+// executeWithSnapshot runs an UPDATE or DELETE in a transaction after locking
+// and snapshotting the rows it will change, so the snapshot matches exactly
+// what the write overwrote. With backup, the snapshot is stored before the
+// transaction commits; with returnChanges, the rows of an UPDATE are read
+// again before it commits and the columns that changed are returned.
+func (m *Manager) executeWithSnapshot(conn *sql.Conn, connectionName string, connConfig *config.ConnectionConfig, database, query string, args []interface{}, backup, returnChanges bool) (*WriteResult, error) {
+	if !backup && !singleTableUpdatePattern.MatchString(maskLiterals(query)) {
+		return nil, fmt.Errorf("return_changes only supports single-table UPDATE statements without aliases")
+	}
+	target, err := parseBackupTarget(database, query, args)
+	if err != nil {
+		return nil, err
+	}
+	if returnChanges && target.operation != "UPDATE" {
+		return nil, fmt.Errorf("return_changes only supports UPDATE statements")
+	}
+
+	// CREATE TABLE would implicitly commit, so prepare the backup table first
+	if backup && connConfig.BackupTable != "" {
+		if err := m.ensureBackupTable(conn, connectionName, connConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx := context.Background()
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var keyColumns []string
+	allowed := allowedColumns(connConfig, target.table)
+	if returnChanges {
+		if keyColumns, err = primaryKeyColumns(ctx, tx, target.database, target.table); err != nil {
+			return nil, err
+		}
+		if len(keyColumns) == 0 {
+			return nil, fmt.Errorf("return_changes needs a primary key on %s to match rows before and after the UPDATE", target.table)
+		}
+		for _, col := range keyColumns {
+			if !columnAllowed(allowed, col) {
+				return nil, fmt.Errorf("return_changes identifies rows by primary key column %s, which is not in the allowed_columns of %s", col, target.table)
+			}
+		}
+	}
+
+	start := time.Now()
+	rows, err := tx.QueryContext(ctx, target.selectSQL, target.selectArgs...)
+	if err != nil {
+		m.recordError(connectionName, target.selectSQL, err)
+		return nil, fmt.Errorf("backup query failed: %w", err)
+	}
+	snapshot, err := scanRows(rows, connConfig.BackupMaxRows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.Truncated {
+		if !backup {
+			return nil, fmt.Errorf("write would change more than backup_max_rows (%d) rows, the most return_changes reports; narrow the WHERE clause or omit return_changes", connConfig.BackupMaxRows)
+		}
+		return nil, fmt.Errorf("write would change more than backup_max_rows (%d) rows; narrow the WHERE clause or pass backup: false", connConfig.BackupMaxRows)
+	}
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		m.recordError(connectionName, query, err)
+		return nil, fmt.Errorf("query execution failed: %w", err)
+	}
+	elapsed := time.Since(start)
+	warnings := fetchWarnings(tx)
+
+	// Diffed before the backup base64-encodes binary values in the snapshot
+	var changes []UpdatedRow
+	if returnChanges {
+		if changes, err = rowChanges(ctx, tx, target, keyColumns, allowed, snapshot); err != nil {
+			return nil, err
+		}
+	}
+
+	var ref *BackupRef
+	if backup {
+		if ref, err = m.storeBackup(tx, connConfig, newBackup(connectionName, query, target, snapshot)); err != nil {
+			return nil, fmt.Errorf("failed to store backup, write rolled back: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+	if ref != nil {
+		slog.Info("write backed up", "connection", connectionName, "backup_id", ref.BackupID, "table", ref.Table, "rows", ref.Rows, "location", ref.Location)
+	}
+	slog.Debug("statement executed", "connection", connectionName, "sql", query, "duration_ms", elapsed.Milliseconds())
+
+	rowsAffected, _ := result.RowsAffected()
+	return &WriteResult{
+		RowsAffected: rowsAffected,
+		Warning:      riskWarning(connectionName, connConfig),
+		ExecutionMs:  elapsed.Milliseconds(),
+		Warnings:     warnings,
+		Connection:   connectionName,
+		Database:     database,
+		Backup:       ref,
+		Changes:      changes,
+	}, nil
+}
+
+// backupTableName returns the quoted backup table, qualified with the
+// connection's database when the name does not include one, so a session
+// switched to another database still finds it
+func backupTableName(connConfig *config.ConnectionConfig) string {
+	if parts := strings.SplitN(connConfig.BackupTable, ".", 2); len(parts) == 2 {
+		return QualifiedName(parts[0], parts[1])
+	}
+	return QualifiedName(connConfig.Database, connConfig.BackupTable)
+}
+
+// ensureBackupTable creates the connection's backup table if it does not exist
+func (m *Manager) ensureBackupTable(conn *sql.Conn, connectionName string, connConfig *config.ConnectionConfig) error {
+	m.backupMu.Lock()
+	defer m.backupMu.Unlock()
+	if m.backupTables[connectionName] {
+		return nil
+	}
+
+	_, err := conn.ExecContext(context.Background(), fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		backup_id VARCHAR(32) NOT NULL PRIMARY KEY,
+		created_at DATETIME(6) NOT NULL,
+		database_name VARCHAR(64) NOT NULL,
+		table_name VARCHAR(64) NOT NULL,
+		operation VARCHAR(16) NOT NULL,
+		row_count INT NOT NULL,
+		payload LONGTEXT NOT NULL
+	)`, backupTableName(connConfig)))
+	if err != nil {
+		return fmt.Errorf("failed to create backup table %s: %w", connConfig.BackupTable, err)
+	}
+	m.backupTables[connectionName] = true
+	return nil
+}
+
+// storeBackup saves a snapshot to the connection's backup table (inside the
+// write's transaction) or appends it to the backup file
+func (m *Manager) storeBackup(tx *sql.Tx, connConfig *config.ConnectionConfig, backup *Backup) (*BackupRef, error) {
+	payload, err := json.Marshal(backup)
+	if err != nil {
+		return nil, err
+	}
+
+	ref := &BackupRef{BackupID: backup.BackupID, Table: backup.Table, Rows: len(backup.Rows)}
+
+	if connConfig.BackupTable != "" {
+		_, err := tx.ExecContext(context.Background(),
+			fmt.Sprintf("INSERT INTO %s (backup_id, created_at, database_name, table_name, operation, row_count, payload) VALUES (?, ?, ?, ?, ?, ?, ?)", backupTableName(connConfig)),
+			backup.BackupID, backup.CreatedAt, backup.Database, backup.Table, backup.Operation, len(backup.Rows), string(payload))
+		if err != nil {
+			return nil, err
+		}
+		ref.Location = "table:" + connConfig.BackupTable
+		return ref, nil
+	}
+
+	// Serialize appends so concurrent writes never interleave lines
+	m.backupMu.Lock()
+	defer m.backupMu.Unlock()
+
+	f, err := os.OpenFile(connConfig.BackupFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Write(append(payload, '\n')); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	ref.Location = "file:" + connConfig.BackupFile
+	return ref, nil
+}
+
+// newBackupID returns a random backup identifier
+func newBackupID() string {
+	return "bak_" + randomHex(8)
+}
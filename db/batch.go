@@ -0,0 +1,365 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+
+	"mysql-golang-mcp/config"
+)
+
+// writeBatch is the open transaction INSERTs from mysql_insert are coalesced
+// into on a connection with write_batch_seconds. It is committed once no
+// INSERT has joined it for that long, when it reaches
+// write_batch_max_statements, by flush_writes, or before any other statement
+// runs on the connection. It holds one max_concurrent_queries slot while open.
+type writeBatch struct {
+	id           string
+	connection   string
+	database     string
+	tx           *sql.Tx
+	release      func()
+	started      time.Time
+	statements   int
+	rowsAffected int64
+	timer        *time.Timer
+	done         bool
+	mu           sync.Mutex
+}
+
+// WriteBatchStatus describes the write batch an INSERT joined. Its rows are
+// only visible to other sessions, and durable, once the batch is committed.
+type WriteBatchStatus struct {
+	BatchID      string `json:"batch_id"`
+	Statements   int    `json:"statements"`
+	RowsAffected int64  `json:"rows_affected"`
+	Committed    bool   `json:"committed"`
+
+	// CommitsAt is when the batch is committed unless another INSERT joins it
+	CommitsAt *time.Time `json:"commits_at,omitempty"`
+
+	// FailedBatches reports earlier batches on the connection that failed to
+	// commit, whose INSERTs were lost and must be retried
+	FailedBatches []WriteBatchOutcome `json:"failed_batches,omitempty"`
+}
+
+// WriteBatchOutcome reports how a write batch ended
+type WriteBatchOutcome struct {
+	BatchID      string `json:"batch_id"`
+	Connection   string `json:"connection"`
+	Committed    bool   `json:"committed"`
+	Statements   int    `json:"statements"`
+	RowsAffected int64  `json:"rows_affected"`
+	DurationMs   int64  `json:"duration_ms"`
+	Error        string `json:"error,omitempty"`
+}
+
+// FlushResult is the response of FlushWrites
+type FlushResult struct {
+	Batches []WriteBatchOutcome `json:"batches"`
+	Count   int                 `json:"count"`
+}
+
+// batchable reports whether a write can join the connection's write batch:
+// INSERTs that need no approval and return no rows
+func batchable(connConfig *config.ConnectionConfig, query string, queryType QueryType) bool {
+	return connConfig.WriteBatchSeconds > 0 && queryType == QueryTypeInsert &&
+		!needsApproval(connConfig, queryType) && !hasReturningClause(query)
+}
+
+// batchWrite runs an INSERT inside the connection's write batch, leaving it
+// to be committed with the INSERTs that follow it
+func (m *Manager) batchWrite(db *sql.DB, connectionName string, connConfig *config.ConnectionConfig, query string, args []interface{}, opts WriteOptions) (*WriteResult, error) {
+	database := connConfig.Database
+	if opts.Database != "" {
+		database = opts.Database
+	}
+
+	b, err := m.joinWriteBatch(opts.Context, db, connectionName, connConfig, database)
+	if err != nil {
+		return nil, err
+	}
+	defer b.mu.Unlock()
+
+	start := time.Now()
+	result, err := b.tx.ExecContext(statementContext(opts.Context), query, args...)
+	if err != nil {
+		m.recordError(connectionName, query, err)
+		// InnoDB rolls back the whole transaction on a deadlock
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1213 {
+			outcome := m.endWriteBatch(b, false)
+			return nil, fmt.Errorf("query execution failed: %w; write batch %s was rolled back with its %d pending INSERTs (%d rows), which must be retried", err, b.id, outcome.Statements, outcome.RowsAffected)
+		}
+		return nil, fmt.Errorf("query execution failed: %w", err)
+	}
+	elapsed := time.Since(start)
+	slog.Debug("statement executed", "connection", connectionName, "batch_id", b.id, "sql", query, "duration_ms", elapsed.Milliseconds())
+
+	rowsAffected, _ := result.RowsAffected()
+	lastInsertID, _ := result.LastInsertId()
+	b.statements++
+	b.rowsAffected += rowsAffected
+
+	writeResult := &WriteResult{
+		RowsAffected: rowsAffected,
+		LastInsertID: lastInsertID,
+		Warning:      riskWarning(connectionName, connConfig),
+		ExecutionMs:  elapsed.Milliseconds(),
+		Warnings:     fetchWarnings(b.tx),
+		Connection:   connectionName,
+		Database:     database,
+	}
+	status := &WriteBatchStatus{BatchID: b.id, Statements: b.statements, RowsAffected: b.rowsAffected}
+
+	if b.statements >= connConfig.WriteBatchMaxStatements {
+		outcome := m.endWriteBatch(b, true)
+		if !outcome.Committed {
+			return nil, fmt.Errorf("write batch %s reached write_batch_max_statements but failed to commit, its %d INSERTs (%d rows) were not saved and must be retried: %s", b.id, outcome.Statements, outcome.RowsAffected, outcome.Error)
+		}
+		status.Committed = true
+	} else {
+		window := time.Duration(connConfig.WriteBatchSeconds) * time.Second
+		b.timer.Reset(window)
+		commitsAt := time.Now().Add(window)
+		status.CommitsAt = &commitsAt
+	}
+	status.FailedBatches = m.takeBatchFailures(connectionName)
+	writeResult.Batch = status
+	return writeResult, nil
+}
+
+// joinWriteBatch returns the connection's write batch, locked, beginning one
+// when there is none. A batch runs on one default database, so one open on
+// another database is committed first.
+func (m *Manager) joinWriteBatch(ctx context.Context, db *sql.DB, connectionName string, connConfig *config.ConnectionConfig, database string) (*writeBatch, error) {
+	for {
+		m.batchesMu.Lock()
+		b := m.batches[connectionName]
+		m.batchesMu.Unlock()
+
+		if b == nil {
+			var err error
+			if b, err = m.beginWriteBatch(ctx, db, connectionName, connConfig, database); err != nil {
+				return nil, err
+			}
+		}
+
+		b.mu.Lock()
+		switch {
+		case b.done:
+			// Committed by its timer in the meantime
+			b.mu.Unlock()
+		case b.database != database:
+			m.storeBatchFailure(m.endWriteBatch(b, true))
+			b.mu.Unlock()
+		default:
+			return b, nil
+		}
+	}
+}
+
+// beginWriteBatch starts a write batch on a connection pinned to database and
+// registers it, unless another call registered one first
+func (m *Manager) beginWriteBatch(ctx context.Context, db *sql.DB, connectionName string, connConfig *config.ConnectionConfig, database string) (*writeBatch, error) {
+	releaseSlot, err := m.acquireSlot(connectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = statementContext(ctx)
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		releaseSlot()
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	restore := func() {}
+	if database != connConfig.Database {
+		if restore, err = useDatabase(conn, connConfig, database); err != nil {
+			conn.Close()
+			releaseSlot()
+			return nil, err
+		}
+	}
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		restore()
+		conn.Close()
+		releaseSlot()
+		return nil, fmt.Errorf("failed to begin write batch: %w", err)
+	}
+
+	b := &writeBatch{
+		id:         "batch_" + randomHex(8),
+		connection: connectionName,
+		database:   database,
+		tx:         tx,
+		release: func() {
+			restore()
+			conn.Close()
+			releaseSlot()
+		},
+		started: time.Now(),
+	}
+	// The timer is reset by every INSERT that joins the batch
+	b.timer = time.AfterFunc(time.Duration(connConfig.WriteBatchSeconds)*time.Second, func() { m.commitWriteBatch(b) })
+
+	m.batchesMu.Lock()
+	existing := m.batches[connectionName]
+	if existing == nil {
+		m.batches[connectionName] = b
+	}
+	m.batchesMu.Unlock()
+
+	if existing != nil {
+		b.mu.Lock()
+		m.endWriteBatch(b, false)
+		b.mu.Unlock()
+		return existing, nil
+	}
+	slog.Info("write batch started", "batch_id", b.id, "connection", connectionName)
+	return b, nil
+}
+
+// endWriteBatch commits or rolls back a write batch and releases its
+// connection and slot; b.mu must be held
+func (m *Manager) endWriteBatch(b *writeBatch, commit bool) WriteBatchOutcome {
+	b.timer.Stop()
+	b.done = true
+
+	m.batchesMu.Lock()
+	if m.batches[b.connection] == b {
+		delete(m.batches, b.connection)
+	}
+	m.batchesMu.Unlock()
+
+	outcome := WriteBatchOutcome{
+		BatchID:      b.id,
+		Connection:   b.connection,
+		Statements:   b.statements,
+		RowsAffected: b.rowsAffected,
+	}
+	if commit {
+		if err := b.tx.Commit(); err != nil {
+			m.recordError(b.connection, "COMMIT", err)
+			outcome.Error = err.Error()
+		} else {
+			outcome.Committed = true
+			m.invalidateCache(b.connection)
+		}
+	} else {
+		b.tx.Rollback()
+	}
+	b.release()
+	outcome.DurationMs = time.Since(b.started).Milliseconds()
+
+	if outcome.Error != "" {
+		slog.Error("write batch failed to commit", "batch_id", b.id, "connection", b.connection, "statements", b.statements, "error", outcome.Error)
+	} else {
+		slog.Info("write batch ended", "batch_id", b.id, "connection", b.connection, "committed", outcome.Committed, "statements", b.statements)
+	}
+	return outcome
+}
+
+// commitWriteBatch commits a write batch unless it already ended, keeping a
+// failure to report to the next caller
+func (m *Manager) commitWriteBatch(b *writeBatch) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.done {
+		m.storeBatchFailure(m.endWriteBatch(b, true))
+	}
+}
+
+// commitPendingWrites commits the connection's write batch, if it has one
+func (m *Manager) commitPendingWrites(connectionName string) {
+	m.batchesMu.Lock()
+	b := m.batches[connectionName]
+	m.batchesMu.Unlock()
+	if b != nil {
+		m.commitWriteBatch(b)
+	}
+}
+
+// commitAllWriteBatches commits every open write batch
+func (m *Manager) commitAllWriteBatches() {
+	m.batchesMu.Lock()
+	open := make([]*writeBatch, 0, len(m.batches))
+	for _, b := range m.batches {
+		open = append(open, b)
+	}
+	m.batchesMu.Unlock()
+
+	for _, b := range open {
+		m.commitWriteBatch(b)
+	}
+}
+
+// storeBatchFailure keeps the outcome of a batch that failed to commit until
+// flush_writes or the connection's next batched INSERT reports it
+func (m *Manager) storeBatchFailure(outcome WriteBatchOutcome) {
+	if outcome.Committed {
+		return
+	}
+	m.batchesMu.Lock()
+	defer m.batchesMu.Unlock()
+	m.batchFailures[outcome.Connection] = append(m.batchFailures[outcome.Connection], outcome)
+}
+
+// takeBatchFailures returns and forgets the connection's failed batches
+func (m *Manager) takeBatchFailures(connectionName string) []WriteBatchOutcome {
+	m.batchesMu.Lock()
+	defer m.batchesMu.Unlock()
+	failures := m.batchFailures[connectionName]
+	delete(m.batchFailures, connectionName)
+	return failures
+}
+
+// FlushWrites commits the pending write batch of a connection, or of every
+// connection when connectionName is empty, and reports batches that failed
+// to commit since the last call
+func (m *Manager) FlushWrites(connectionName string) (*FlushResult, error) {
+	var names []string
+	if connectionName != "" {
+		if _, exists := m.lookupConnection(connectionName); !exists {
+			return nil, fmt.Errorf("unknown connection: %s", connectionName)
+		}
+		names = []string{connectionName}
+	} else {
+		seen := make(map[string]bool)
+		m.batchesMu.Lock()
+		for name := range m.batches {
+			seen[name] = true
+		}
+		for name := range m.batchFailures {
+			seen[name] = true
+		}
+		m.batchesMu.Unlock()
+		names = sortedKeys(seen)
+	}
+
+	result := &FlushResult{Batches: []WriteBatchOutcome{}}
+	for _, name := range names {
+		result.Batches = append(result.Batches, m.takeBatchFailures(name)...)
+
+		m.batchesMu.Lock()
+		b := m.batches[name]
+		m.batchesMu.Unlock()
+		if b == nil {
+			continue
+		}
+		b.mu.Lock()
+		if !b.done {
+			result.Batches = append(result.Batches, m.endWriteBatch(b, true))
+		}
+		b.mu.Unlock()
+	}
+	result.Count = len(result.Batches)
+	return result, nil
+}
@@ -0,0 +1,129 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// DefaultBinlogEventLimit is the number of events binlog_events returns by default
+const DefaultBinlogEventLimit = 100
+
+// binlogNamePattern matches binary log file names, which are embedded in
+// SHOW BINLOG EVENTS as a literal
+var binlogNamePattern = regexp.MustCompile(`^[\w.-]+$`)
+
+// BinaryLogs lists the server's binary log files and the position it is
+// writing at
+type BinaryLogs struct {
+	Connection string                   `json:"connection"`
+	Logs       []map[string]interface{} `json:"logs"`
+	TotalBytes int64                    `json:"total_bytes"`
+	Current    map[string]interface{}   `json:"current,omitempty"`
+}
+
+// BinlogEvents is a page of events from one binary log file
+type BinlogEvents struct {
+	Connection string                   `json:"connection"`
+	Log        string                   `json:"log"`
+	Position   int64                    `json:"position,omitempty"`
+	Events     []map[string]interface{} `json:"events"`
+	Count      int                      `json:"count"`
+
+	// NextPosition is the position that continues after the last event when
+	// the file holds more events
+	HasMore      bool  `json:"has_more"`
+	NextPosition int64 `json:"next_position,omitempty"`
+}
+
+// checkBinlogAllowed returns an error unless the connection sets allow_binlog
+func (m *Manager) checkBinlogAllowed(connectionName string) error {
+	_, connConfig, err := m.GetConnection(connectionName)
+	if err != nil {
+		return err
+	}
+	if !connConfig.AllowBinlog {
+		return fmt.Errorf("connection '%s' does not allow reading binary logs; set allow_binlog: true in config to enable binlog_events", connectionName)
+	}
+	return nil
+}
+
+// ListBinaryLogs returns SHOW BINARY LOGS and the current file and position.
+// Requires allow_binlog on the connection.
+func (m *Manager) ListBinaryLogs(connectionName string) (*BinaryLogs, error) {
+	if err := m.checkBinlogAllowed(connectionName); err != nil {
+		return nil, err
+	}
+	result, err := m.ExecuteQuery(connectionName, "SHOW BINARY LOGS")
+	if err != nil {
+		return nil, err
+	}
+
+	logs := &BinaryLogs{Connection: connectionName, Logs: result.Rows}
+	for _, row := range result.Rows {
+		logs.TotalBytes += int64Value(row["File_size"])
+	}
+	if current, err := m.binlogStatus(connectionName); err == nil {
+		logs.Current = current
+	}
+	return logs, nil
+}
+
+// ReadBinlogEvents returns up to limit events (capped by max_rows) of a
+// binary log file starting at position, or at its start when position is 0.
+// An empty logName reads the file the server is writing now, where the most
+// recent writes are. Requires allow_binlog on the connection.
+func (m *Manager) ReadBinlogEvents(connectionName, logName string, position int64, limit int) (*BinlogEvents, error) {
+	if err := m.checkBinlogAllowed(connectionName); err != nil {
+		return nil, err
+	}
+	if position < 0 {
+		return nil, fmt.Errorf("position must not be negative")
+	}
+
+	if logName == "" {
+		current, err := m.binlogStatus(connectionName)
+		if err != nil {
+			return nil, err
+		}
+		logName = stringValue(current["File"])
+	}
+	if !binlogNamePattern.MatchString(logName) {
+		return nil, fmt.Errorf("invalid binary log name: %s", logName)
+	}
+
+	// Read one event past the page to learn whether more follow
+	query := fmt.Sprintf("SHOW BINLOG EVENTS IN '%s'", logName)
+	if position > 0 {
+		query += fmt.Sprintf(" FROM %d", position)
+	}
+	query += fmt.Sprintf(" LIMIT %d", limit+1)
+	result, err := m.ExecuteQueryWithOptions(connectionName, query, QueryOptions{MaxRows: limit})
+	if err != nil {
+		return nil, err
+	}
+
+	events := &BinlogEvents{Connection: connectionName, Log: logName, Position: position, Events: result.Rows, Count: result.Count}
+	if result.Truncated && len(result.Rows) > 0 {
+		events.HasMore = true
+		events.NextPosition = int64Value(result.Rows[len(result.Rows)-1]["End_log_pos"])
+	}
+	return events, nil
+}
+
+// binlogStatus returns the binary log file and position the server is
+// writing, from SHOW BINARY LOG STATUS or, before MySQL 8.2 and on MariaDB,
+// SHOW MASTER STATUS
+func (m *Manager) binlogStatus(connectionName string) (map[string]interface{}, error) {
+	statement := "SHOW MASTER STATUS"
+	if info, err := m.ServerInfo(connectionName); err == nil && info.hasBinaryLogStatus() {
+		statement = "SHOW BINARY LOG STATUS"
+	}
+	result, err := m.ExecuteQuery(connectionName, statement)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Rows) == 0 || stringValue(result.Rows[0]["File"]) == "" {
+		return nil, fmt.Errorf("binary logging is not enabled on connection '%s'", connectionName)
+	}
+	return result.Rows[0], nil
+}
@@ -0,0 +1,210 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Filter is a single WHERE condition in a structured query
+type Filter struct {
+	Field string      `json:"field"`
+	Op    string      `json:"op"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// OrderBy is a single ORDER BY term in a structured query
+type OrderBy struct {
+	Column string `json:"column"`
+	Desc   bool   `json:"desc,omitempty"`
+}
+
+// StructuredQuery describes a query built from structured arguments instead of raw SQL
+type StructuredQuery struct {
+	Database string
+	Table    string
+	Columns  []string
+	Filters  []Filter
+	OrderBy  []OrderBy
+	Limit    int
+}
+
+// FilterOps lists the comparison operators accepted in filters
+var FilterOps = []string{"=", "!=", "<>", "<", "<=", ">", ">=", "LIKE", "NOT LIKE", "IN", "NOT IN", "IS NULL", "IS NOT NULL", "BETWEEN"}
+
+// SpatialFilterOps lists the spatial operators accepted in filters on
+// geometry columns (see spatialCondition)
+var SpatialFilterOps = []string{"INTERSECTS", "CONTAINS", "WITHIN", "WITHIN DISTANCE"}
+
+// BuildSelect builds a parameterized SELECT statement
+func BuildSelect(q StructuredQuery) (string, []interface{}, error) {
+	if q.Table == "" {
+		return "", nil, fmt.Errorf("table is required")
+	}
+	if err := checkProtectedTable(q.Database, q.Table); err != nil {
+		return "", nil, err
+	}
+
+	projection := "*"
+	if len(q.Columns) > 0 {
+		quoted := make([]string, len(q.Columns))
+		for i, col := range q.Columns {
+			quoted[i] = QuoteIdentifier(col)
+		}
+		projection = strings.Join(quoted, ", ")
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s", projection, QualifiedName(q.Database, q.Table))
+
+	where, args, err := buildWhere(q.Filters)
+	if err != nil {
+		return "", nil, err
+	}
+	query += where + buildOrderBy(q.OrderBy) + buildLimit(q.Limit)
+
+	return query, args, nil
+}
+
+// BuildUpdate builds a parameterized UPDATE statement. At least one filter is
+// required so structured updates can never touch every row by accident.
+func BuildUpdate(q StructuredQuery, set map[string]interface{}) (string, []interface{}, error) {
+	if q.Table == "" {
+		return "", nil, fmt.Errorf("table is required")
+	}
+	if err := checkProtectedTable(q.Database, q.Table); err != nil {
+		return "", nil, err
+	}
+	if len(set) == 0 {
+		return "", nil, fmt.Errorf("at least one column to set is required")
+	}
+	if len(q.Filters) == 0 {
+		return "", nil, fmt.Errorf("at least one filter is required for structured updates")
+	}
+
+	columns := make([]string, 0, len(set))
+	for col := range set {
+		columns = append(columns, col)
+	}
+	sort.Strings(columns)
+
+	assignments := make([]string, len(columns))
+	args := make([]interface{}, 0, len(columns)+len(q.Filters))
+	for i, col := range columns {
+		assignments[i] = QuoteIdentifier(col) + " = ?"
+		args = append(args, set[col])
+	}
+
+	where, whereArgs, err := buildWhere(q.Filters)
+	if err != nil {
+		return "", nil, err
+	}
+	args = append(args, whereArgs...)
+
+	query := fmt.Sprintf("UPDATE %s SET %s", QualifiedName(q.Database, q.Table), strings.Join(assignments, ", "))
+	query += where + buildOrderBy(q.OrderBy) + buildLimit(q.Limit)
+
+	return query, args, nil
+}
+
+// BuildDelete builds a parameterized DELETE statement. At least one filter is
+// required so structured deletes can never remove every row by accident.
+func BuildDelete(q StructuredQuery) (string, []interface{}, error) {
+	if q.Table == "" {
+		return "", nil, fmt.Errorf("table is required")
+	}
+	if err := checkProtectedTable(q.Database, q.Table); err != nil {
+		return "", nil, err
+	}
+	if len(q.Filters) == 0 {
+		return "", nil, fmt.Errorf("at least one filter is required for structured deletes")
+	}
+
+	where, args, err := buildWhere(q.Filters)
+	if err != nil {
+		return "", nil, err
+	}
+
+	query := "DELETE FROM " + QualifiedName(q.Database, q.Table)
+	query += where + buildOrderBy(q.OrderBy) + buildLimit(q.Limit)
+
+	return query, args, nil
+}
+
+// buildWhere renders filters as a WHERE clause joined by AND
+func buildWhere(filters []Filter) (string, []interface{}, error) {
+	if len(filters) == 0 {
+		return "", nil, nil
+	}
+
+	conditions := make([]string, 0, len(filters))
+	var args []interface{}
+
+	for _, f := range filters {
+		if f.Field == "" {
+			return "", nil, fmt.Errorf("filter field is required")
+		}
+		col := QuoteIdentifier(f.Field)
+		op := strings.ToUpper(strings.TrimSpace(f.Op))
+
+		switch op {
+		case "=", "!=", "<>", "<", "<=", ">", ">=", "LIKE", "NOT LIKE":
+			if f.Value == nil {
+				return "", nil, fmt.Errorf("filter on '%s' with op %s requires a value (use IS NULL for nulls)", f.Field, op)
+			}
+			conditions = append(conditions, fmt.Sprintf("%s %s ?", col, op))
+			args = append(args, f.Value)
+		case "IN", "NOT IN":
+			values, ok := f.Value.([]interface{})
+			if !ok || len(values) == 0 {
+				return "", nil, fmt.Errorf("filter on '%s' with op %s requires a non-empty list value", f.Field, op)
+			}
+			placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+			conditions = append(conditions, fmt.Sprintf("%s %s (%s)", col, op, placeholders))
+			args = append(args, values...)
+		case "IS NULL", "IS NOT NULL":
+			conditions = append(conditions, fmt.Sprintf("%s %s", col, op))
+		case "BETWEEN":
+			values, ok := f.Value.([]interface{})
+			if !ok || len(values) != 2 {
+				return "", nil, fmt.Errorf("filter on '%s' with op BETWEEN requires a two-element list value", f.Field)
+			}
+			conditions = append(conditions, fmt.Sprintf("%s BETWEEN ? AND ?", col))
+			args = append(args, values...)
+		case "INTERSECTS", "CONTAINS", "WITHIN", "WITHIN DISTANCE":
+			condition, spatialArgs, err := spatialCondition(col, op, f.Value)
+			if err != nil {
+				return "", nil, fmt.Errorf("filter on '%s': %w", f.Field, err)
+			}
+			conditions = append(conditions, condition)
+			args = append(args, spatialArgs...)
+		default:
+			return "", nil, fmt.Errorf("unsupported filter op '%s'; supported ops: %s", f.Op, strings.Join(append(FilterOps, SpatialFilterOps...), ", "))
+		}
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args, nil
+}
+
+// buildOrderBy renders an ORDER BY clause
+func buildOrderBy(orderBy []OrderBy) string {
+	if len(orderBy) == 0 {
+		return ""
+	}
+
+	terms := make([]string, len(orderBy))
+	for i, o := range orderBy {
+		terms[i] = QuoteIdentifier(o.Column)
+		if o.Desc {
+			terms[i] += " DESC"
+		}
+	}
+	return " ORDER BY " + strings.Join(terms, ", ")
+}
+
+// buildLimit renders a LIMIT clause
+func buildLimit(limit int) string {
+	if limit <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" LIMIT %d", limit)
+}
@@ -0,0 +1,314 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultMaxAllowedPacket is used when the server variable cannot be read (MySQL 5.7 default)
+	defaultMaxAllowedPacket int64 = 4 * 1024 * 1024
+
+	// packetBudgetRatio leaves headroom below max_allowed_packet for protocol overhead
+	packetBudgetRatio = 0.75
+
+	// maxPlaceholders is the MySQL limit on placeholders in a single prepared statement
+	maxPlaceholders = 65535
+
+	// perValueOverhead approximates the bytes added per value by the binary protocol
+	perValueOverhead = 9
+
+	// maxBulkWarnings caps the warnings kept across all batches of a bulk insert
+	maxBulkWarnings = 100
+)
+
+// BulkInsertResult holds the result of a bulk insert operation. With Upsert,
+// RowsInserted counts rows written either way and RowsAffected follows
+// MySQL's ON DUPLICATE KEY UPDATE counting (1 per inserted row, 2 per
+// updated row, 0 per unchanged row).
+type BulkInsertResult struct {
+	RowsInserted     int64            `json:"rows_inserted"`
+	RowsAffected     int64            `json:"rows_affected,omitempty"`
+	Batches          int              `json:"batches"`
+	FailedBatches    []BulkBatchError `json:"failed_batches,omitempty"`
+	RowsFailed       int              `json:"rows_failed,omitempty"`
+	MaxAllowedPacket int64            `json:"max_allowed_packet"`
+	BatchBytesBudget int64            `json:"batch_bytes_budget"`
+	LargestBatchRows int              `json:"largest_batch_rows"`
+	Warning          string           `json:"warning,omitempty"`
+
+	// Warnings are the SHOW WARNINGS rows of each batch, such as values
+	// truncated or clamped outside strict mode
+	Warnings        []BulkWarning `json:"warnings,omitempty"`
+	WarningsOmitted int           `json:"warnings_omitted,omitempty"`
+}
+
+// BulkWarning is a warning raised by one batch. Row numbers in the message
+// count from 1 within the batch, which starts at FirstRow of the input.
+type BulkWarning struct {
+	Batch    int `json:"batch"`
+	FirstRow int `json:"first_row"`
+	Warning
+}
+
+// BulkBatchError records a batch that failed when ContinueOnError is set
+type BulkBatchError struct {
+	Batch    int    `json:"batch"`
+	FirstRow int    `json:"first_row"`
+	LastRow  int    `json:"last_row"`
+	Error    string `json:"error"`
+}
+
+// InsertOptions holds per-call settings for InsertRows
+type InsertOptions struct {
+	// BatchSize caps the rows per INSERT; batches are still kept under
+	// max_allowed_packet. 0 sizes batches by the packet budget alone.
+	BatchSize int
+
+	// Upsert adds ON DUPLICATE KEY UPDATE for UpdateColumns, or for every
+	// inserted column when UpdateColumns is empty
+	Upsert        bool
+	UpdateColumns []string
+
+	// ContinueOnError records a failed batch and carries on with the next one
+	// instead of stopping
+	ContinueOnError bool
+
+	// Progress is called after each batch with the rows processed so far
+	Progress func(done, total int)
+
+	// Context carries the caller's trace span, so the insert's span is
+	// recorded as its child; nil starts a new trace
+	Context context.Context
+}
+
+// InsertRows inserts rows into a table using multi-row INSERT statements.
+// Batches are sized by the measured width of each row against the server's
+// max_allowed_packet rather than by a fixed row count, so wide tables get
+// smaller batches and narrow tables get larger ones.
+func (m *Manager) InsertRows(connectionName, database, table string, rows []map[string]interface{}, opts InsertOptions) (*BulkInsertResult, error) {
+	run := m.startStatement(opts.Context, connectionName, "INSERT INTO "+QualifiedName(database, table)+" VALUES (?)")
+	result, err := m.insertRows(connectionName, database, table, rows, opts)
+	var inserted int64
+	if result != nil {
+		inserted = result.RowsInserted
+	}
+	m.endStatement(run, inserted, err)
+	return result, err
+}
+
+// insertRows runs a bulk insert for InsertRows
+func (m *Manager) insertRows(connectionName, database, table string, rows []map[string]interface{}, opts InsertOptions) (*BulkInsertResult, error) {
+	db, connConfig, err := m.GetConnection(connectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := m.acquireSlot(connectionName)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if err := checkReplica(connectionName, connConfig); err != nil {
+		return nil, err
+	}
+	if connConfig.ReadOnly {
+		return nil, fmt.Errorf("connection '%s' is read-only, write operations are not allowed", connectionName)
+	}
+
+	if table == "" {
+		return nil, fmt.Errorf("table is required")
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("at least one row is required")
+	}
+
+	columns := rowColumns(rows[0])
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("rows must contain at least one column")
+	}
+	for i, row := range rows {
+		if len(row) != len(columns) {
+			return nil, fmt.Errorf("row %d has %d columns, expected %d (all rows must share the columns of the first row)", i, len(row), len(columns))
+		}
+		for _, col := range columns {
+			if _, ok := row[col]; !ok {
+				return nil, fmt.Errorf("row %d is missing column '%s'", i, col)
+			}
+		}
+	}
+
+	target := QualifiedName(database, table)
+
+	quotedCols := make([]string, len(columns))
+	for i, col := range columns {
+		quotedCols[i] = QuoteIdentifier(col)
+	}
+	prefix := fmt.Sprintf("INSERT INTO %s (%s) VALUES ", target, strings.Join(quotedCols, ", "))
+	rowPlaceholder := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+
+	if isSensitiveQuery(prefix) {
+		return nil, fmt.Errorf("access to sensitive MySQL metadata is not allowed")
+	}
+
+	// VALUES() is deprecated on MySQL 8.0.20+ but, unlike the row alias that
+	// replaces it, also works on MariaDB and older MySQL
+	var suffix string
+	if opts.Upsert {
+		updateColumns := opts.UpdateColumns
+		if len(updateColumns) == 0 {
+			updateColumns = columns
+		}
+		assignments := make([]string, len(updateColumns))
+		for i, col := range updateColumns {
+			if _, ok := rows[0][col]; !ok {
+				return nil, fmt.Errorf("update column '%s' is not one of the inserted columns", col)
+			}
+			assignments[i] = fmt.Sprintf("%s = VALUES(%s)", QuoteIdentifier(col), QuoteIdentifier(col))
+		}
+		suffix = " ON DUPLICATE KEY UPDATE " + strings.Join(assignments, ", ")
+	}
+	if err := checkBlockedPatterns(connectionName, connConfig, prefix+rowPlaceholder+suffix); err != nil {
+		return nil, err
+	}
+	if err := checkQualifiedWrite(connectionName, connConfig, prefix+rowPlaceholder+suffix); err != nil {
+		return nil, err
+	}
+
+	maxPacket := m.MaxAllowedPacket(connectionName)
+	budget := int64(float64(maxPacket)*packetBudgetRatio) - int64(len(suffix))
+	maxRowsPerBatch := maxPlaceholders / len(columns)
+	if opts.BatchSize > 0 && opts.BatchSize < maxRowsPerBatch {
+		maxRowsPerBatch = opts.BatchSize
+	}
+
+	// Pin a single session so SHOW WARNINGS sees each batch's warnings
+	ctx := statementContext(opts.Context)
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Close()
+
+	defer m.invalidateCache(connectionName)
+
+	result := &BulkInsertResult{
+		MaxAllowedPacket: maxPacket,
+		BatchBytesBudget: budget,
+		Warning:          riskWarning(connectionName, connConfig),
+	}
+
+	start := 0
+	for start < len(rows) {
+		end := start
+		batchBytes := int64(len(prefix))
+		for end < len(rows) && end-start < maxRowsPerBatch {
+			width := estimateRowWidth(rows[end], columns) + int64(len(rowPlaceholder)) + 2
+			if end > start && batchBytes+width > budget {
+				break
+			}
+			if end == start && batchBytes+width > int64(maxPacket) {
+				return nil, fmt.Errorf("row %d is approximately %d bytes, which exceeds max_allowed_packet (%d)", end, width, maxPacket)
+			}
+			batchBytes += width
+			end++
+		}
+
+		batch := rows[start:end]
+		placeholders := make([]string, len(batch))
+		args := make([]interface{}, 0, len(batch)*len(columns))
+		for i, row := range batch {
+			placeholders[i] = rowPlaceholder
+			for _, col := range columns {
+				args = append(args, row[col])
+			}
+		}
+
+		result.Batches++
+		if len(batch) > result.LargestBatchRows {
+			result.LargestBatchRows = len(batch)
+		}
+
+		execResult, err := conn.ExecContext(ctx, prefix+strings.Join(placeholders, ", ")+suffix, args...)
+		if err != nil {
+			m.recordError(connectionName, prefix+"...", err)
+			if !opts.ContinueOnError {
+				return nil, fmt.Errorf("batch %d (rows %d-%d) failed after inserting %d rows: %w", result.Batches, start, end-1, result.RowsInserted, err)
+			}
+			result.FailedBatches = append(result.FailedBatches, BulkBatchError{Batch: result.Batches, FirstRow: start, LastRow: end - 1, Error: err.Error()})
+			result.RowsFailed += len(batch)
+		} else {
+			affected, _ := execResult.RowsAffected()
+			if opts.Upsert {
+				result.RowsInserted += int64(len(batch))
+				result.RowsAffected += affected
+			} else {
+				result.RowsInserted += affected
+			}
+			for _, w := range fetchWarnings(conn) {
+				if len(result.Warnings) == maxBulkWarnings {
+					result.WarningsOmitted++
+					continue
+				}
+				result.Warnings = append(result.Warnings, BulkWarning{Batch: result.Batches, FirstRow: start, Warning: w})
+			}
+		}
+
+		start = end
+		if opts.Progress != nil {
+			opts.Progress(start, len(rows))
+		}
+	}
+
+	return result, nil
+}
+
+// MaxAllowedPacket returns the max_allowed_packet value captured when the connection was opened
+func (m *Manager) MaxAllowedPacket(connectionName string) int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if v, ok := m.maxAllowedPacket[connectionName]; ok && v > 0 {
+		return v
+	}
+	return defaultMaxAllowedPacket
+}
+
+// rowColumns returns the column names of a row in a stable order
+func rowColumns(row map[string]interface{}) []string {
+	columns := make([]string, 0, len(row))
+	for col := range row {
+		columns = append(columns, col)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// estimateRowWidth approximates the number of bytes a row occupies on the wire
+func estimateRowWidth(row map[string]interface{}, columns []string) int64 {
+	var width int64
+	for _, col := range columns {
+		width += perValueOverhead
+		switch v := row[col].(type) {
+		case nil:
+		case string:
+			width += int64(len(v))
+		case []byte:
+			width += int64(len(v))
+		case time.Time:
+			width += 12
+		default:
+			width += int64(len(fmt.Sprint(v)))
+		}
+	}
+	return width
+}
+
+// QuoteIdentifier wraps a MySQL identifier in backticks, escaping embedded backticks
+func QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
@@ -0,0 +1,133 @@
+package db
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// cacheEntry is a stored SELECT result and when it stops being served
+type cacheEntry struct {
+	result  *QueryResult
+	expires time.Time
+}
+
+// cacheKey identifies a cacheable query: the normalized SQL, its bound
+// parameters, and every option that changes the result
+func cacheKey(query string, opts QueryOptions, args []interface{}) string {
+	params, err := json.Marshal(args)
+	if err != nil {
+		params = []byte(fmt.Sprintf("%#v", args))
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%d\x00%t\x00%t\x00%s",
+		normalizeSQL(query), opts.Database, opts.MaxRows, opts.ExcludeSoftDeleted, opts.Lint, params)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// normalizeSQL collapses whitespace outside quoted strings and identifiers
+// and drops trailing semicolons, so formatting differences share a cache entry
+func normalizeSQL(query string) string {
+	var b strings.Builder
+	var quote rune
+	space, escaped := false, false
+	for _, r := range strings.TrimSpace(query) {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			if r == '\\' && quote != '`' {
+				escaped = true
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	return strings.TrimRight(b.String(), "; ")
+}
+
+// cachedResult returns a copy of a live cache entry flagged as cached, or nil
+func (m *Manager) cachedResult(connectionName, key string) *QueryResult {
+	m.cacheMu.Lock()
+	defer m.cacheMu.Unlock()
+	entry, ok := m.cache[connectionName][key]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(entry.expires) {
+		delete(m.cache[connectionName], key)
+		return nil
+	}
+	result := entry.result.clone()
+	result.Cached = true
+	return result
+}
+
+// storeResult caches a copy of a result for ttl, evicting expired entries and
+// then the entries closest to expiry once the connection holds maxEntries
+func (m *Manager) storeResult(connectionName, key string, result *QueryResult, ttl time.Duration, maxEntries int) {
+	m.cacheMu.Lock()
+	defer m.cacheMu.Unlock()
+	entries, ok := m.cache[connectionName]
+	if !ok {
+		entries = make(map[string]*cacheEntry)
+		m.cache[connectionName] = entries
+	}
+
+	now := time.Now()
+	if _, exists := entries[key]; !exists && len(entries) >= maxEntries {
+		for k, entry := range entries {
+			if now.After(entry.expires) {
+				delete(entries, k)
+			}
+		}
+		for len(entries) >= maxEntries {
+			var oldest string
+			for k, entry := range entries {
+				if oldest == "" || entry.expires.Before(entries[oldest].expires) {
+					oldest = k
+				}
+			}
+			delete(entries, oldest)
+		}
+	}
+	entries[key] = &cacheEntry{result: result.clone(), expires: now.Add(ttl)}
+}
+
+// invalidateCache drops every cached result of a connection. Writes made
+// through this server call it so later reads see their changes.
+func (m *Manager) invalidateCache(connectionName string) {
+	m.cacheMu.Lock()
+	delete(m.cache, connectionName)
+	m.cacheMu.Unlock()
+}
+
+// clone copies a result deeply enough that shaping one copy (dropping
+// columns, truncating cells) leaves the other untouched
+func (r *QueryResult) clone() *QueryResult {
+	c := *r
+	c.Columns = append([]string(nil), r.Columns...)
+	c.ColumnTypes = append([]ColumnType(nil), r.ColumnTypes...)
+	c.Rows = make([]map[string]interface{}, len(r.Rows))
+	for i, row := range r.Rows {
+		copied := make(map[string]interface{}, len(row))
+		for k, v := range row {
+			copied[k] = v
+		}
+		c.Rows[i] = copied
+	}
+	return &c
+}
@@ -0,0 +1,179 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The catalog helpers below run fixed, parameterized information_schema
+// queries so common metadata questions never need free-form SQL. System
+// schemas are excluded from cross-database searches.
+
+// systemSchemas lists schemas that are never searched across databases
+const systemSchemas = "'mysql', 'information_schema', 'performance_schema', 'sys'"
+
+// TableSizes returns the largest tables in a database by data plus index size.
+// Row counts and sizes are InnoDB estimates from information_schema.TABLES.
+func (m *Manager) TableSizes(connectionName, database string, limit int) (*QueryResult, error) {
+	return m.ExecuteQuery(connectionName, fmt.Sprintf(`SELECT TABLE_NAME, ENGINE, TABLE_ROWS AS ESTIMATED_ROWS,
+		DATA_LENGTH AS DATA_BYTES, INDEX_LENGTH AS INDEX_BYTES,
+		DATA_LENGTH + INDEX_LENGTH AS TOTAL_BYTES,
+		ROUND((DATA_LENGTH + INDEX_LENGTH) / 1024 / 1024, 2) AS TOTAL_MB,
+		DATA_FREE AS FREE_BYTES
+		FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = COALESCE(?, DATABASE()) AND TABLE_TYPE = 'BASE TABLE'
+		ORDER BY TOTAL_BYTES DESC, TABLE_NAME
+		LIMIT %d`, limit), nullIfEmpty(database))
+}
+
+// SearchColumns finds the columns whose name contains the search term (or
+// equals it when exact is set), in one database or, when allDatabases is set,
+// in every non-system database
+func (m *Manager) SearchColumns(connectionName, database, name string, exact, allDatabases bool) (*QueryResult, error) {
+	pattern := escapeLike(name)
+	if !exact {
+		pattern = "%" + pattern + "%"
+	}
+
+	scope := "TABLE_SCHEMA = COALESCE(?, DATABASE())"
+	args := []interface{}{pattern, nullIfEmpty(database)}
+	if allDatabases {
+		scope = "TABLE_SCHEMA NOT IN (" + systemSchemas + ")"
+		args = args[:1]
+	}
+
+	return m.ExecuteQuery(connectionName, `SELECT TABLE_SCHEMA, TABLE_NAME, COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COLUMN_KEY
+		FROM information_schema.COLUMNS
+		WHERE COLUMN_NAME LIKE ? ESCAPE '!' AND `+scope+`
+		ORDER BY TABLE_SCHEMA, TABLE_NAME, ORDINAL_POSITION`, args...)
+}
+
+// escapeLike escapes LIKE wildcards (with ! as the escape character) so the
+// term matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
+
+// CharsetInfo returns the server and database character set defaults and the
+// collation of every table in the database. When table is set, the character
+// set and collation of that table's text columns are included too.
+func (m *Manager) CharsetInfo(connectionName, database, table string) (map[string]interface{}, error) {
+	server, err := m.ExecuteQuery(connectionName, `SELECT @@character_set_server AS character_set_server,
+		@@collation_server AS collation_server,
+		@@character_set_connection AS character_set_connection,
+		@@collation_connection AS collation_connection,
+		@@character_set_client AS character_set_client,
+		@@character_set_results AS character_set_results`)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := m.ExecuteQuery(connectionName, `SELECT SCHEMA_NAME, DEFAULT_CHARACTER_SET_NAME, DEFAULT_COLLATION_NAME
+		FROM information_schema.SCHEMATA
+		WHERE SCHEMA_NAME = COALESCE(?, DATABASE())`, nullIfEmpty(database))
+	if err != nil {
+		return nil, err
+	}
+	if len(schema.Rows) == 0 {
+		return nil, fmt.Errorf("database not found: %s", database)
+	}
+	defaultCollation := stringValue(schema.Rows[0]["DEFAULT_COLLATION_NAME"])
+
+	tables, err := m.ExecuteQuery(connectionName, `SELECT TABLE_NAME, TABLE_COLLATION
+		FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = COALESCE(?, DATABASE()) AND TABLE_TYPE = 'BASE TABLE'
+		ORDER BY TABLE_NAME`, nullIfEmpty(database))
+	if err != nil {
+		return nil, err
+	}
+
+	// Tables that differ from the database default are the usual source of
+	// "Illegal mix of collations" errors in joins
+	var mismatched []string
+	for _, row := range tables.Rows {
+		if collation := stringValue(row["TABLE_COLLATION"]); collation != "" && collation != defaultCollation {
+			mismatched = append(mismatched, stringValue(row["TABLE_NAME"]))
+		}
+	}
+
+	info := map[string]interface{}{
+		"server":                            server.Rows[0],
+		"database":                          schema.Rows[0],
+		"tables":                            tables.Rows,
+		"tables_with_non_default_collation": mismatched,
+	}
+
+	if table != "" {
+		columns, err := m.ExecuteQuery(connectionName, `SELECT COLUMN_NAME, COLUMN_TYPE, CHARACTER_SET_NAME, COLLATION_NAME
+			FROM information_schema.COLUMNS
+			WHERE TABLE_SCHEMA = COALESCE(?, DATABASE()) AND TABLE_NAME = ? AND CHARACTER_SET_NAME IS NOT NULL
+			ORDER BY ORDINAL_POSITION`, nullIfEmpty(database), table)
+		if err != nil {
+			return nil, err
+		}
+		info["columns"] = columns.Rows
+	}
+
+	return info, nil
+}
+
+// CheckCharsets reports everything in a database that does not use utf8mb4:
+// session character sets, the database default, table collations and text
+// columns. Anything else cannot store emoji or most non-Latin text, and a
+// latin1 session silently corrupts it on the way in.
+func (m *Manager) CheckCharsets(connectionName, database string) (map[string]interface{}, error) {
+	session, err := m.ExecuteQuery(connectionName, `SELECT @@character_set_client AS character_set_client,
+		@@character_set_connection AS character_set_connection,
+		@@character_set_results AS character_set_results,
+		@@collation_connection AS collation_connection`)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := m.ExecuteQuery(connectionName, `SELECT SCHEMA_NAME, DEFAULT_CHARACTER_SET_NAME, DEFAULT_COLLATION_NAME
+		FROM information_schema.SCHEMATA
+		WHERE SCHEMA_NAME = COALESCE(?, DATABASE())`, nullIfEmpty(database))
+	if err != nil {
+		return nil, err
+	}
+	if len(schema.Rows) == 0 {
+		return nil, fmt.Errorf("database not found: %s", database)
+	}
+
+	tables, err := m.ExecuteQuery(connectionName, `SELECT TABLE_NAME, TABLE_COLLATION
+		FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = COALESCE(?, DATABASE()) AND TABLE_TYPE = 'BASE TABLE'
+			AND TABLE_COLLATION NOT LIKE 'utf8mb4!_%' ESCAPE '!'
+		ORDER BY TABLE_NAME`, nullIfEmpty(database))
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := m.ExecuteQuery(connectionName, `SELECT c.TABLE_NAME, c.COLUMN_NAME, c.COLUMN_TYPE, c.CHARACTER_SET_NAME, c.COLLATION_NAME
+		FROM information_schema.COLUMNS c
+		JOIN information_schema.TABLES t ON t.TABLE_SCHEMA = c.TABLE_SCHEMA AND t.TABLE_NAME = c.TABLE_NAME
+		WHERE c.TABLE_SCHEMA = COALESCE(?, DATABASE()) AND t.TABLE_TYPE = 'BASE TABLE'
+			AND c.CHARACTER_SET_NAME IS NOT NULL AND c.CHARACTER_SET_NAME <> 'utf8mb4'
+		ORDER BY c.TABLE_NAME, c.ORDINAL_POSITION`, nullIfEmpty(database))
+	if err != nil {
+		return nil, err
+	}
+
+	var sessionIssues []string
+	for _, name := range []string{"character_set_client", "character_set_connection", "character_set_results"} {
+		if charset := stringValue(session.Rows[0][name]); charset != "utf8mb4" {
+			sessionIssues = append(sessionIssues, fmt.Sprintf("%s is %s", name, charset))
+		}
+	}
+	databaseCharset := stringValue(schema.Rows[0]["DEFAULT_CHARACTER_SET_NAME"])
+
+	return map[string]interface{}{
+		"ok":                  len(sessionIssues) == 0 && databaseCharset == "utf8mb4" && len(tables.Rows) == 0 && len(columns.Rows) == 0,
+		"session":             session.Rows[0],
+		"session_issues":      sessionIssues,
+		"database":            schema.Rows[0],
+		"database_is_utf8mb4": databaseCharset == "utf8mb4",
+		"non_utf8mb4_tables":  tables.Rows,
+		"non_utf8mb4_columns": columns.Rows,
+	}, nil
+}
@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// changesChunkRows is how many rows each re-read of updated rows matches by key
+const changesChunkRows = 500
+
+// UpdatedRow is a row an UPDATE changed, identified by its primary key
+type UpdatedRow struct {
+	Key     map[string]interface{}   `json:"key"`
+	Changes map[string]UpdatedColumn `json:"changes,omitempty"`
+
+	// Note explains a row whose values after the UPDATE could not be read
+	Note string `json:"note,omitempty"`
+}
+
+// UpdatedColumn holds a column's value before and after an UPDATE
+type UpdatedColumn struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// primaryKeyColumns looks up a table's primary key columns inside the
+// write's transaction, rather than on another pooled connection
+func primaryKeyColumns(ctx context.Context, tx *sql.Tx, database, table string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, primaryKeyColumnsSQL, nullIfEmpty(database), table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up the primary key: %w", err)
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var col string
+		if err := rows.Scan(&col); err != nil {
+			return nil, fmt.Errorf("failed to look up the primary key: %w", err)
+		}
+		columns = append(columns, col)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to look up the primary key: %w", err)
+	}
+	return columns, nil
+}
+
+// rowChanges reads the rows of before again by primary key, after the UPDATE
+// ran in the same transaction, and returns the columns that changed in each.
+// Rows the UPDATE matched without changing are left out, as are columns
+// outside allowed, the table's allowed_columns when set.
+func rowChanges(ctx context.Context, tx *sql.Tx, target *backupTarget, keyColumns, allowed []string, before *QueryResult) ([]UpdatedRow, error) {
+	quotedKeys := make([]string, len(keyColumns))
+	for i, col := range keyColumns {
+		quotedKeys[i] = QuoteIdentifier(col)
+	}
+	tuple := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(keyColumns)), ", ") + ")"
+
+	after := make(map[string]map[string]interface{}, len(before.Rows))
+	for start := 0; start < len(before.Rows); start += changesChunkRows {
+		chunk := before.Rows[start:min(start+changesChunkRows, len(before.Rows))]
+		args := make([]interface{}, 0, len(chunk)*len(keyColumns))
+		for _, row := range chunk {
+			for _, col := range keyColumns {
+				args = append(args, row[col])
+			}
+		}
+
+		query := fmt.Sprintf("SELECT * FROM %s WHERE (%s) IN (%s)",
+			QualifiedName(target.database, target.table),
+			strings.Join(quotedKeys, ", "),
+			strings.TrimSuffix(strings.Repeat(tuple+", ", len(chunk)), ", "))
+		rows, err := tx.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read updated rows: %w", err)
+		}
+		updated, err := scanRows(rows, len(chunk))
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range updated.Rows {
+			after[rowKey(row, keyColumns)] = row
+		}
+	}
+
+	var changed []UpdatedRow
+	for _, old := range before.Rows {
+		row := UpdatedRow{Key: make(map[string]interface{}, len(keyColumns))}
+		for _, col := range keyColumns {
+			row.Key[col] = old[col]
+		}
+
+		updated, ok := after[rowKey(old, keyColumns)]
+		if !ok {
+			row.Note = "row not found by its primary key after the UPDATE; the UPDATE may have changed the key"
+			changed = append(changed, row)
+			continue
+		}
+		changes := make(map[string]UpdatedColumn)
+		for _, col := range before.Columns {
+			if columnAllowed(allowed, col) && !sameValue(old[col], updated[col]) {
+				changes[col] = UpdatedColumn{Before: old[col], After: updated[col]}
+			}
+		}
+		if len(changes) > 0 {
+			row.Changes = changes
+			changed = append(changed, row)
+		}
+	}
+	return changed, nil
+}
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"strings"
+	"time"
+
+	"mysql-golang-mcp/config"
+)
+
+// sqlDatetimeLayout renders DATETIME values as MySQL writes them, with the
+// fractional seconds only when there are any
+const sqlDatetimeLayout = "2006-01-02 15:04:05.999999"
+
+// coercionRenderer returns how a column's values are rendered under a
+// connection's coercion settings, or nil when they are left as scanned
+func coercionRenderer(c *config.CoercionConfig, ct ColumnType) func(interface{}) interface{} {
+	dbType := strings.TrimPrefix(ct.DatabaseType, "UNSIGNED ")
+	switch {
+	case c.IsBooleanColumn(ct.Name) || (c.TinyintAsBool && dbType == "TINYINT"):
+		return coerceBool
+	case dbType == "DATE" && c.ZeroDatesAsNull:
+		return func(v interface{}) interface{} {
+			if v == "0001-01-01" {
+				return nil
+			}
+			return v
+		}
+	case dbType == "DATETIME" || dbType == "TIMESTAMP":
+		if !c.ZeroDatesAsNull && c.Location() == nil && c.DatetimeFormat == "rfc3339" {
+			return nil
+		}
+		return func(v interface{}) interface{} { return coerceDatetime(c, v) }
+	}
+	return nil
+}
+
+// coercionJSONType is the JSON type of a column rendered by coercionRenderer
+// when it differs from the scanned value's
+func coercionJSONType(c *config.CoercionConfig, ct ColumnType) string {
+	dbType := strings.TrimPrefix(ct.DatabaseType, "UNSIGNED ")
+	switch {
+	case c.IsBooleanColumn(ct.Name) || (c.TinyintAsBool && dbType == "TINYINT"):
+		return "boolean"
+	case (dbType == "DATETIME" || dbType == "TIMESTAMP") && c.DatetimeFormat == "unix":
+		return "number"
+	}
+	return ""
+}
+
+// coerceBool renders an integer or BIT(1) value as a boolean, leaving values
+// that are neither unchanged
+func coerceBool(v interface{}) interface{} {
+	switch b := v.(type) {
+	case int64:
+		return b != 0
+	case uint64:
+		return b != 0
+	case string:
+		// BIT values are scanned as their raw bytes
+		if len(b) == 1 {
+			return b[0] != 0
+		}
+	}
+	return v
+}
+
+// coerceDatetime renders a DATETIME or TIMESTAMP value, scanned as an RFC
+// 3339 string in UTC, in the configured zone and format. The zero date the
+// driver reads 0000-00-00 00:00:00 as becomes null with zero_dates_as_null.
+func coerceDatetime(c *config.CoercionConfig, v interface{}) interface{} {
+	s, ok := v.(string)
+	if !ok {
+		return v
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return v
+	}
+	if t.IsZero() {
+		if c.ZeroDatesAsNull {
+			return nil
+		}
+		return v
+	}
+	if location := c.Location(); location != nil {
+		t = t.In(location)
+	}
+
+	switch c.DatetimeFormat {
+	case "sql":
+		return t.Format(sqlDatetimeLayout)
+	case "unix":
+		if t.Nanosecond() == 0 {
+			return t.Unix()
+		}
+		return float64(t.UnixMicro()) / 1e6
+	default:
+		return t.Format(time.RFC3339Nano)
+	}
+}
@@ -0,0 +1,143 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// columnCommentPattern matches, leftmost first, string literals (skipped) and
+// the COMMENT clause of a column definition from SHOW CREATE TABLE
+var columnCommentPattern = regexp.MustCompile(`'(?:[^'\\]|\\.|'')*'|\sCOMMENT\s+'(?:[^'\\]|\\.|'')*'`)
+
+// CommentChange reports a table or column comment set by SetTableComment or
+// SetColumnComment, with the comment it replaced
+type CommentChange struct {
+	Table    string `json:"table"`
+	Column   string `json:"column,omitempty"`
+	Previous string `json:"previous_comment"`
+	Comment  string `json:"comment"`
+	SQL      string `json:"sql"`
+	*WriteResult
+}
+
+// SetTableComment replaces a table's comment. Requires allow_ddl on the
+// connection.
+func (m *Manager) SetTableComment(connectionName, database, table, comment string) (*CommentChange, error) {
+	current, err := m.ExecuteQuery(connectionName, `SELECT TABLE_COMMENT FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = COALESCE(?, DATABASE()) AND TABLE_NAME = ? AND TABLE_TYPE = 'BASE TABLE'`, nullIfEmpty(database), table)
+	if err != nil {
+		return nil, err
+	}
+	if len(current.Rows) == 0 {
+		return nil, fmt.Errorf("table not found: %s", table)
+	}
+
+	query := fmt.Sprintf("ALTER TABLE %s COMMENT = %s", QualifiedName(database, table), quoteStringLiteral(comment))
+	change := &CommentChange{Table: table, Previous: stringValue(current.Rows[0]["TABLE_COMMENT"]), Comment: comment, SQL: query}
+	return m.alterComment(connectionName, database, change)
+}
+
+// SetColumnComment replaces a column's comment. MySQL can only change a
+// column comment by restating the whole column, so the definition is taken
+// from SHOW CREATE TABLE and only its COMMENT clause is replaced. Requires
+// allow_ddl on the connection.
+func (m *Manager) SetColumnComment(connectionName, database, table, column, comment string) (*CommentChange, error) {
+	ddl, err := m.ShowCreateTable(connectionName, database, table)
+	if err != nil {
+		return nil, err
+	}
+	definition, err := columnDefinition(ddl, column)
+	if err != nil {
+		return nil, fmt.Errorf("%w in table %s", err, table)
+	}
+
+	var previous string
+	definition = columnCommentPattern.ReplaceAllStringFunc(definition, func(match string) string {
+		if match[0] == '\'' {
+			return match
+		}
+		literal := strings.TrimSpace(match[strings.IndexByte(match, '\''):])
+		previous = unquoteStringLiteral(literal)
+		return ""
+	})
+
+	query := fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s COMMENT %s", QualifiedName(database, table), definition, quoteStringLiteral(comment))
+	change := &CommentChange{Table: table, Column: column, Previous: previous, Comment: comment, SQL: query}
+	return m.alterComment(connectionName, database, change)
+}
+
+// alterComment runs the ALTER TABLE of a comment change, which waits for
+// approval on require_approval connections
+func (m *Manager) alterComment(connectionName, database string, change *CommentChange) (*CommentChange, error) {
+	result, err := m.execDDL(connectionName, "comment editing", database, change.SQL, WriteOptions{})
+	if err != nil {
+		return nil, err
+	}
+	change.WriteResult = result
+	return change, nil
+}
+
+// columnDefinition returns the definition of a column, name included, from a
+// CREATE TABLE statement. Column names match case-insensitively, as in MySQL.
+func columnDefinition(ddl, column string) (string, error) {
+	for _, line := range strings.Split(ddl, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "`") {
+			continue
+		}
+
+		// Read the quoted name, where a doubled backtick stands for one
+		var name strings.Builder
+		for i := 1; i < len(line); i++ {
+			if line[i] == '`' {
+				if i+1 < len(line) && line[i+1] == '`' {
+					name.WriteByte('`')
+					i++
+					continue
+				}
+				break
+			}
+			name.WriteByte(line[i])
+		}
+		if strings.EqualFold(name.String(), column) {
+			return strings.TrimSuffix(line, ","), nil
+		}
+	}
+	return "", fmt.Errorf("column not found: %s", column)
+}
+
+// unquoteStringLiteral reverses quoteStringLiteral, also accepting the
+// backslash escapes SHOW CREATE TABLE may use
+func unquoteStringLiteral(literal string) string {
+	if len(literal) < 2 {
+		return literal
+	}
+	inner := literal[1 : len(literal)-1]
+	var b strings.Builder
+	for i := 0; i < len(inner); i++ {
+		c := inner[i]
+		switch {
+		case c == '\'' && i+1 < len(inner) && inner[i+1] == '\'':
+			b.WriteByte('\'')
+			i++
+		case c == '\\' && i+1 < len(inner):
+			i++
+			switch inner[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case '0':
+				b.WriteByte(0)
+			default:
+				b.WriteByte(inner[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
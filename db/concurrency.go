@@ -0,0 +1,46 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// acquireSlot reserves one of the connection's max_concurrent_queries slots,
+// waiting up to queue_timeout_seconds for a slot to free up. The returned
+// function must be called to release the slot. A pending write batch on the
+// connection is committed first, so every other statement sees its rows.
+func (m *Manager) acquireSlot(name string) (func(), error) {
+	connConfig, exists := m.lookupConnection(name)
+	if !exists {
+		return nil, fmt.Errorf("unknown connection: %s", name)
+	}
+	m.commitPendingWrites(name)
+
+	m.mu.Lock()
+	sem, exists := m.semaphores[name]
+	if !exists {
+		sem = make(chan struct{}, connConfig.MaxConcurrentQueries)
+		m.semaphores[name] = sem
+	}
+	m.mu.Unlock()
+
+	release := func() { <-sem }
+
+	// Fast path: a slot is free
+	select {
+	case sem <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	timer := time.NewTimer(time.Duration(connConfig.QueueTimeoutSeconds) * time.Second)
+	defer timer.Stop()
+
+	select {
+	case sem <- struct{}{}:
+		return release, nil
+	case <-timer.C:
+		return nil, fmt.Errorf("connection '%s' is busy: %d queries already running (max_concurrent_queries=%d) and none finished within %ds; retry shortly or issue fewer parallel queries",
+			name, len(sem), connConfig.MaxConcurrentQueries, connConfig.QueueTimeoutSeconds)
+	}
+}
@@ -1,34 +1,116 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"log/slog"
+	"regexp"
 	"strings"
 	"sync"
+	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 
 	"mysql-golang-mcp/config"
 )
 
 // Manager handles multiple database connections
 type Manager struct {
-	config      *config.Config
-	connections map[string]*sql.DB
-	mu          sync.RWMutex
+	config           *config.Config
+	connections      map[string]*sql.DB
+	maxAllowedPacket map[string]int64
+	serverInfo       map[string]*ServerInfo
+	semaphores       map[string]chan struct{}
+	mu               sync.RWMutex
+
+	errorHistory []ErrorEntry
+	historyMu    sync.Mutex
+
+	cursors   map[string]*cursor
+	cursorsMu sync.Mutex
+
+	transactions   map[string]*transaction
+	transactionsMu sync.Mutex
+
+	batches       map[string]*writeBatch
+	batchFailures map[string][]WriteBatchOutcome
+	batchesMu     sync.Mutex
+
+	sessions   map[string]*session
+	sessionsMu sync.Mutex
+
+	backupTables map[string]bool
+	backupMu     sync.Mutex
+
+	approvals   map[string]*approval
+	approvalsMu sync.Mutex
+
+	cache   map[string]map[string]*cacheEntry
+	cacheMu sync.Mutex
+
+	digests        map[string]*QueryDigest
+	digestsSince   time.Time
+	digestsEvicted int64
+	digestsMu      sync.Mutex
+
+	learned       map[string]*LearnedQuery
+	learnedLoaded bool
+	learnedMu     sync.Mutex
+
+	usage       map[string]*poolUsage
+	usageMu     sync.Mutex
+	stopJanitor func()
+
+	userConnections   map[string]*config.ConnectionConfig
+	userConnectionsMu sync.Mutex
+
+	runtimeConnections   map[string]*config.ConnectionConfig
+	runtimeConnectionsMu sync.Mutex
+
+	removed   map[string]bool
+	removedMu sync.Mutex
 }
 
 // NewManager creates a new connection manager
 func NewManager(cfg *config.Config) *Manager {
-	return &Manager{
-		config:      cfg,
-		connections: make(map[string]*sql.DB),
+	m := &Manager{
+		config:           cfg,
+		connections:      make(map[string]*sql.DB),
+		maxAllowedPacket: make(map[string]int64),
+		serverInfo:       make(map[string]*ServerInfo),
+		semaphores:       make(map[string]chan struct{}),
+		cursors:          make(map[string]*cursor),
+		transactions:     make(map[string]*transaction),
+		batches:          make(map[string]*writeBatch),
+		batchFailures:    make(map[string][]WriteBatchOutcome),
+		sessions:         make(map[string]*session),
+		backupTables:     make(map[string]bool),
+		approvals:        make(map[string]*approval),
+		cache:            make(map[string]map[string]*cacheEntry),
+		digests:          make(map[string]*QueryDigest),
+		learned:          make(map[string]*LearnedQuery),
+		usage:            make(map[string]*poolUsage),
+		userConnections:  make(map[string]*config.ConnectionConfig),
+
+		runtimeConnections: make(map[string]*config.ConnectionConfig),
+		removed:            make(map[string]bool),
 	}
+
+	stop := make(chan struct{})
+	m.stopJanitor = sync.OnceFunc(func() { close(stop) })
+	go m.runJanitor(stop)
+	return m
 }
 
 // GetConnection returns a database connection by name, creating it if necessary
 func (m *Manager) GetConnection(name string) (*sql.DB, *config.ConnectionConfig, error) {
-	connConfig, exists := m.config.Connections[name]
+	if m.isRemoved(name) {
+		return nil, nil, fmt.Errorf("connection '%s' was removed with remove_connection; call reconnect_connection to restore it", name)
+	}
+	connConfig, exists := m.lookupConnection(name)
 	if !exists {
 		return nil, nil, fmt.Errorf("unknown connection: %s", name)
 	}
@@ -40,6 +122,7 @@ func (m *Manager) GetConnection(name string) (*sql.DB, *config.ConnectionConfig,
 	if exists {
 		// Check if connection is still alive
 		if err := db.Ping(); err == nil {
+			m.touchConnection(name)
 			return db, connConfig, nil
 		}
 		// Connection is dead, close it and reconnect
@@ -53,44 +136,450 @@ func (m *Manager) GetConnection(name string) (*sql.DB, *config.ConnectionConfig,
 	// Double-check after acquiring write lock
 	if db, exists := m.connections[name]; exists {
 		if err := db.Ping(); err == nil {
+			m.touchConnection(name)
 			return db, connConfig, nil
 		}
 		db.Close()
 	}
 
-	db, err := sql.Open("mysql", connConfig.DSN())
+	db, err := openPool(name, connConfig, m.config.StatementTag)
+	if err != nil && isAccessDenied(err) {
+		// Credentials may have been rotated; re-resolve secret references and retry once
+		if changed, resolveErr := connConfig.ResolveCredentials(); resolveErr == nil && changed {
+			db, err = openPool(name, connConfig, m.config.StatementTag)
+		}
+	}
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to open connection '%s': %w", name, err)
+		return nil, nil, err
+	}
+
+	// Capture max_allowed_packet so bulk operations can size their batches
+	var maxPacket int64
+	if err := db.QueryRow("SELECT @@max_allowed_packet").Scan(&maxPacket); err == nil {
+		m.maxAllowedPacket[name] = maxPacket
+	}
+
+	// Identify the server flavor so introspection can use dialect-specific sources
+	if info, err := detectServerInfo(db); err == nil {
+		m.serverInfo[name] = info
 	}
 
-	// Configure connection pool
-	db.SetMaxOpenConns(5)
+	m.connections[name] = db
+	m.touchConnection(name)
+	return db, connConfig, nil
+}
+
+// checkReplica refuses writes on connections tagged role: replica, before
+// and regardless of read_only
+func checkReplica(connectionName string, connConfig *config.ConnectionConfig) error {
+	if connConfig.IsReplica() {
+		return fmt.Errorf("connection '%s' is a replica (role: replica), writes are never allowed on it; write to the primary instead", connectionName)
+	}
+	return nil
+}
+
+// checkReadOnlyStatement refuses locking reads, SELECT ... INTO OUTFILE and
+// HANDLER statements on read-only connections. A read-only session still
+// allows them, and they pass the read-only prefix check.
+func checkReadOnlyStatement(connectionName string, connConfig *config.ConnectionConfig, query string) error {
+	if !connConfig.ReadOnly {
+		return nil
+	}
+	if violation := readOnlyViolation(query); violation != "" {
+		return fmt.Errorf("connection '%s' is read-only, %s are not allowed", connectionName, violation)
+	}
+	return nil
+}
+
+// readOnlyVariables are the session variables that make every transaction on
+// a session read-only, in the order they are tried. MySQL 5.7.20+ and MariaDB
+// 11.1+ know transaction_read_only; older MariaDB only knows tx_read_only.
+var readOnlyVariables = []string{"transaction_read_only", "tx_read_only"}
+
+// openPool opens and verifies a new connection pool for a connection config,
+// tagging its statements when statementTag is set. Sessions of read-only and
+// replica connections are put in read-only transaction mode, so MySQL itself
+// refuses writes the query classification lets through.
+func openPool(name string, connConfig *config.ConnectionConfig, statementTag string) (*sql.DB, error) {
+	var tagger *statementTagger
+	if statementTag != "" {
+		tagger = &statementTagger{template: statementTag, connection: name}
+	}
+	if !connConfig.ReadOnly && !connConfig.IsReplica() {
+		return openDSN(name, connConfig, connConfig.DSN(), tagger)
+	}
+
+	var err error
+	for _, variable := range readOnlyVariables {
+		var db *sql.DB
+		db, err = openDSN(name, connConfig, connConfig.DSN()+"&"+variable+"=1", tagger)
+		if err == nil {
+			return db, nil
+		}
+		if !isUnknownVariable(err) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("failed to make connection '%s' read-only at the session level: %w", name, err)
+}
+
+// openDSN opens and verifies a connection pool for a DSN, tagging its
+// statements when tagger is set
+func openDSN(name string, connConfig *config.ConnectionConfig, dsn string, tagger *statementTagger) (*sql.DB, error) {
+	var db *sql.DB
+	var err error
+	if tagger != nil {
+		db, err = openTaggedDSN(dsn, tagger)
+	} else {
+		db, err = sql.Open("mysql", dsn)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open connection '%s': %w", name, err)
+	}
+
+	// Configure connection pool, never smaller than the concurrency limit
+	db.SetMaxOpenConns(max(5, connConfig.MaxConcurrentQueries))
 	db.SetMaxIdleConns(2)
+	db.SetConnMaxIdleTime(time.Duration(connConfig.IdleTimeoutSeconds) * time.Second)
+	db.SetConnMaxLifetime(time.Duration(connConfig.MaxLifetimeSeconds) * time.Second)
 
 	// Test the connection
 	if err := db.Ping(); err != nil {
 		db.Close()
-		return nil, nil, fmt.Errorf("failed to connect to '%s': %w", name, err)
+		return nil, fmt.Errorf("failed to connect to '%s': %w", name, err)
 	}
 
-	m.connections[name] = db
-	return db, connConfig, nil
+	return db, nil
 }
 
-// ListConnections returns all configured connection names with their read-only status
+// isAccessDenied reports whether an error is a MySQL authentication failure
+func isAccessDenied(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1045
+}
+
+// isUnknownVariable reports whether an error is ER_UNKNOWN_SYSTEM_VARIABLE
+func isUnknownVariable(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1193
+}
+
+// RotateCredentials re-resolves the secret references for a connection and
+// rebuilds its pool so new credentials take effect without a restart
+func (m *Manager) RotateCredentials(name string) (map[string]interface{}, error) {
+	connConfig, exists := m.lookupConnection(name)
+	if !exists {
+		return nil, fmt.Errorf("unknown connection: %s", name)
+	}
+
+	m.mu.Lock()
+	changed, err := connConfig.ResolveCredentials()
+	m.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve credentials for '%s': %w", name, err)
+	}
+
+	resetResult, err := m.ResetConnection(name)
+	if err != nil {
+		return nil, err
+	}
+
+	resetResult["credentials_changed"] = changed
+	return resetResult, nil
+}
+
+// ListConnections returns all connection names, configured and added by
+// add_connection, with their read-only status, default flag, aliases, role,
+// environment, description, and risk tier
 func (m *Manager) ListConnections() []map[string]interface{} {
 	result := make([]map[string]interface{}, 0, len(m.config.Connections))
-	for name, conn := range m.config.Connections {
-		result = append(result, map[string]interface{}{
+	defaultName := m.config.DefaultConnection()
+	for _, name := range m.ConnectionNames() {
+		conn, _ := m.lookupConnection(name)
+		entry := map[string]interface{}{
 			"name":      name,
 			"read_only": conn.ReadOnly,
-		})
+			"risk_tier": conn.RiskTier,
+		}
+		if name == defaultName {
+			entry["default"] = true
+		}
+		if len(conn.Aliases) > 0 {
+			entry["aliases"] = conn.Aliases
+		}
+		if m.isRuntimeConnection(name) {
+			entry["runtime"] = true
+		}
+		if conn.Role != "" {
+			entry["role"] = conn.Role
+		}
+		if conn.Environment != "" {
+			entry["environment"] = conn.Environment
+		}
+		if conn.Description != "" {
+			entry["description"] = conn.Description
+		}
+		// Server flavor and version are known once the connection has been used
+		if info := m.cachedServerInfo(name); info != nil {
+			entry["server"] = info
+		}
+		result = append(result, entry)
 	}
 	return result
 }
 
+// ConnectionSummary returns a one-line description of every connection's
+// environment and risk tier, for embedding in tool descriptions
+func (m *Manager) ConnectionSummary() string {
+	parts := make([]string, 0, len(m.config.Connections))
+	defaultName := m.config.DefaultConnection()
+	for _, name := range m.ConnectionNames() {
+		conn, _ := m.lookupConnection(name)
+		attrs := []string{"risk: " + conn.RiskTier}
+		if conn.Environment != "" {
+			attrs = append([]string{conn.Environment}, attrs...)
+		}
+		if name == defaultName {
+			attrs = append([]string{"default"}, attrs...)
+		}
+		if len(conn.Aliases) > 0 {
+			attrs = append(attrs, "aliases: "+strings.Join(conn.Aliases, "/"))
+		}
+		if conn.IsReplica() {
+			attrs = append(attrs, "replica, no writes")
+		} else if conn.ReadOnly {
+			attrs = append(attrs, "read-only")
+		}
+		part := fmt.Sprintf("%s (%s)", name, strings.Join(attrs, ", "))
+		if conn.Description != "" {
+			part += " - " + conn.Description
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// DefaultConnection returns the connection tool calls use when they omit
+// one, or "" when there is none
+func (m *Manager) DefaultConnection() string {
+	return m.config.DefaultConnection()
+}
+
+// ResolveConnection returns the configured connection an alias stands for;
+// other names are returned unchanged
+func (m *Manager) ResolveConnection(name string) string {
+	return m.config.ResolveConnection(name)
+}
+
+// OutputFormat returns the configured default output format for tool results
+func (m *Manager) OutputFormat() string {
+	return m.config.OutputFormat
+}
+
+// protectedSchemas cannot be selected as a per-call database: unqualified
+// table names there would slip past the sensitive metadata checks
+var protectedSchemas = map[string]bool{"mysql": true, "performance_schema": true, "sys": true}
+
+// privilegeTables list the information_schema views that expose grants
+var privilegeTables = map[string]bool{"user_privileges": true, "schema_privileges": true, "table_privileges": true, "column_privileges": true}
+
+// checkProtectedTable refuses statements built from a table name that would
+// read a protected schema or a privilege table. Built statements quote their
+// identifiers, so isSensitiveQuery's substring checks cannot see them.
+func checkProtectedTable(database, table string) error {
+	if protectedSchemas[strings.ToLower(database)] {
+		return fmt.Errorf("tables in database '%s' cannot be accessed", database)
+	}
+	if privilegeTables[strings.ToLower(table)] {
+		return fmt.Errorf("table '%s' cannot be accessed", table)
+	}
+	return nil
+}
+
+// useDatabase switches a pinned session's default database and returns a
+// function that switches it back. A session that cannot be switched back is
+// discarded, so the pool never hands out a session scoped to the wrong database.
+func useDatabase(conn *sql.Conn, connConfig *config.ConnectionConfig, database string) (func(), error) {
+	if protectedSchemas[strings.ToLower(database)] {
+		return nil, fmt.Errorf("database '%s' cannot be used as the default database", database)
+	}
+
+	ctx := context.Background()
+	if _, err := conn.ExecContext(ctx, "USE "+QuoteIdentifier(database)); err != nil {
+		return nil, fmt.Errorf("failed to switch to database '%s': %w", database, err)
+	}
+
+	return func() {
+		if _, err := conn.ExecContext(ctx, "USE "+QuoteIdentifier(connConfig.Database)); err != nil {
+			conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		}
+	}, nil
+}
+
+// ddlConnection returns a connection and a held concurrency slot for a
+// guarded DDL tool, refusing read-only connections and those without
+// allow_ddl. purpose names the feature in the error, e.g. "view management".
+func (m *Manager) ddlConnection(connectionName, purpose string) (*sql.DB, *config.ConnectionConfig, func(), error) {
+	db, connConfig, err := m.GetConnection(connectionName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if err := checkReplica(connectionName, connConfig); err != nil {
+		return nil, nil, nil, err
+	}
+	if connConfig.ReadOnly {
+		return nil, nil, nil, fmt.Errorf("connection '%s' is read-only, DDL operations are not allowed", connectionName)
+	}
+	if !connConfig.AllowDDL {
+		return nil, nil, nil, fmt.Errorf("connection '%s' does not allow DDL; set allow_ddl: true in config to enable %s", connectionName, purpose)
+	}
+
+	release, err := m.acquireSlot(connectionName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return db, connConfig, release, nil
+}
+
+// execDDL runs a statement built by a guarded DDL tool, holding it for
+// approval on require_approval connections; the approved run executes the
+// same statement
+func (m *Manager) execDDL(connectionName, purpose, database, query string, opts WriteOptions) (*WriteResult, error) {
+	db, connConfig, release, err := m.ddlConnection(connectionName, purpose)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if err := checkBlockedPatterns(connectionName, connConfig, query); err != nil {
+		return nil, err
+	}
+
+	// Hold risky statements until a human approves them
+	queryType := DetectQueryType(query)
+	if !opts.approved && needsApproval(connConfig, queryType) {
+		pending, err := m.requestApproval(connectionName, connConfig, query, queryType, func() (interface{}, error) {
+			return m.execDDL(connectionName, purpose, database, query, WriteOptions{approved: true})
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &WriteResult{Approval: pending, Connection: connectionName, Database: database}, nil
+	}
+
+	start := time.Now()
+	if _, err := db.Exec(query); err != nil {
+		m.recordError(connectionName, query, err)
+		return nil, fmt.Errorf("query execution failed: %w", err)
+	}
+	m.invalidateCache(connectionName)
+
+	return &WriteResult{
+		Warning:     riskWarning(connectionName, connConfig),
+		ExecutionMs: time.Since(start).Milliseconds(),
+		Connection:  connectionName,
+		Database:    database,
+	}, nil
+}
+
+// checkBlockedPatterns refuses a statement matching one of the connection's
+// blocked_patterns
+func checkBlockedPatterns(connectionName string, connConfig *config.ConnectionConfig, query string) error {
+	if pattern := connConfig.BlockedPattern(query); pattern != "" {
+		return fmt.Errorf("statement blocked by connection '%s' policy: matches blocked pattern %q", connectionName, pattern)
+	}
+	return nil
+}
+
+// nonTableUpdatePattern matches UPDATE keywords that do not introduce a table
+var nonTableUpdatePattern = regexp.MustCompile(`(?i)\b(?:ON\s+DUPLICATE\s+KEY|FOR)\s+UPDATE\b`)
+
+// checkQualifiedWrite refuses an INSERT, UPDATE or DELETE that references a
+// table without its database when the connection has require_qualified_writes
+func checkQualifiedWrite(connectionName string, connConfig *config.ConnectionConfig, query string) error {
+	if !connConfig.RequireQualifiedWrites {
+		return nil
+	}
+	switch DetectQueryType(query) {
+	case QueryTypeInsert, QueryTypeUpdate, QueryTypeDelete:
+	default:
+		return nil
+	}
+
+	// UPDATE in ON DUPLICATE KEY UPDATE and FOR UPDATE is not followed by a table
+	masked := nonTableUpdatePattern.ReplaceAllString(maskLiterals(query), " ")
+
+	var unqualified []string
+	for _, ref := range ExtractTableRefs(masked) {
+		if ref.Database == "" {
+			unqualified = append(unqualified, ref.Table)
+		}
+	}
+	if len(unqualified) > 0 {
+		return fmt.Errorf("connection '%s' requires schema-qualified table names in writes (database.table); unqualified: %s", connectionName, strings.Join(unqualified, ", "))
+	}
+	return nil
+}
+
+// riskWarning returns a caution message for writes on high-risk connections
+func riskWarning(name string, connConfig *config.ConnectionConfig) string {
+	if connConfig.RiskTier != "high" {
+		return ""
+	}
+	env := connConfig.Environment
+	if env == "" {
+		env = "high-risk"
+	}
+	return fmt.Sprintf("connection '%s' is a %s connection (risk tier: high); double-check this write was intended", name, env)
+}
+
+// ResetConnection drains and rebuilds the pool for a single named connection.
+// In-flight queries on the old pool are allowed to finish before it is closed;
+// other connections are not affected.
+func (m *Manager) ResetConnection(name string) (map[string]interface{}, error) {
+	if _, exists := m.lookupConnection(name); !exists {
+		return nil, fmt.Errorf("unknown connection: %s", name)
+	}
+
+	// The write batch pins a connection of the pool, so it is committed first
+	m.commitPendingWrites(name)
+
+	m.mu.Lock()
+	old, hadPool := m.connections[name]
+	delete(m.connections, name)
+	delete(m.maxAllowedPacket, name)
+	delete(m.serverInfo, name)
+	m.mu.Unlock()
+
+	inUse := 0
+	if hadPool {
+		inUse = old.Stats().InUse
+		// Close blocks until queries already running on the pool have finished
+		old.Close()
+	}
+
+	if _, _, err := m.GetConnection(name); err != nil {
+		return nil, fmt.Errorf("pool for '%s' was drained but reconnect failed: %w", name, err)
+	}
+
+	return map[string]interface{}{
+		"name":               name,
+		"drained":            hadPool,
+		"drained_in_use":     inUse,
+		"reconnected":        true,
+		"max_allowed_packet": m.MaxAllowedPacket(name),
+	}, nil
+}
+
 // Close closes all open connections
 func (m *Manager) Close() {
+	m.stopJanitor()
+	m.closeAllCursors()
+	m.closeAllSessions()
+	m.commitAllWriteBatches()
+	m.rollbackAllTransactions()
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -102,15 +591,73 @@ func (m *Manager) Close() {
 
 // QueryResult holds the result of a query
 type QueryResult struct {
-	Columns []string                 `json:"columns"`
-	Rows    []map[string]interface{} `json:"rows"`
-	Count   int                      `json:"count"`
+	Columns     []string                 `json:"columns"`
+	ColumnTypes []ColumnType             `json:"column_types"`
+	Rows        []map[string]interface{} `json:"rows"`
+	Count       int                      `json:"count"`
+	Truncated   bool                     `json:"truncated"`
+	ExecutionMs int64                    `json:"execution_ms"`
+	Warnings    []Warning                `json:"warnings,omitempty"`
+	Connection  string                   `json:"connection,omitempty"`
+	Database    string                   `json:"database,omitempty"`
+
+	// SoftDeleteFiltered lists tables whose soft-deleted rows were excluded
+	SoftDeleteFiltered []string `json:"soft_delete_filtered,omitempty"`
+
+	// ProjectedTables lists tables read through their allowed_columns
+	ProjectedTables []string `json:"projected_tables,omitempty"`
+
+	// Lint holds anti-pattern findings when the connection has lint_selects enabled
+	Lint []LintFinding `json:"lint,omitempty"`
+
+	// Elided reports what was removed to fit the caller's token_budget
+	Elided *Elision `json:"elided,omitempty"`
+
+	// DuplicatesRemoved counts the rows dropped by distinct_rows
+	DuplicatesRemoved int `json:"duplicates_removed,omitempty"`
+
+	// Cached is set when the result was served from the connection's read cache
+	Cached bool `json:"cached,omitempty"`
+
+	// PrimaryKey describes the _pk column added by AnnotatePrimaryKeys
+	PrimaryKey *PrimaryKeyAnnotation `json:"primary_key,omitempty"`
+
+	// Page describes the page and the key of the next one when the query was
+	// paginated with paginate_by
+	Page *KeysetPage `json:"page,omitempty"`
 }
 
 // WriteResult holds the result of a write operation
 type WriteResult struct {
-	RowsAffected int64 `json:"rows_affected"`
-	LastInsertID int64 `json:"last_insert_id,omitempty"`
+	RowsAffected int64        `json:"rows_affected"`
+	LastInsertID int64        `json:"last_insert_id,omitempty"`
+	Warning      string       `json:"warning,omitempty"`
+	Returning    *QueryResult `json:"returning,omitempty"`
+	ExecutionMs  int64        `json:"execution_ms"`
+	Warnings     []Warning    `json:"warnings,omitempty"`
+	Connection   string       `json:"connection,omitempty"`
+	Database     string       `json:"database,omitempty"`
+
+	// RewrittenSQL is the statement actually executed when soft_delete_mode
+	// turned a DELETE into an UPDATE
+	RewrittenSQL string `json:"rewritten_sql,omitempty"`
+
+	// Backup references the snapshot of changed rows taken before the write
+	Backup *BackupRef `json:"backup,omitempty"`
+
+	// Changes lists the rows an UPDATE run with return_changes changed, with
+	// each changed column's value before and after
+	Changes []UpdatedRow `json:"changes,omitempty"`
+
+	// Batch is set when an INSERT joined the connection's write batch, whose
+	// rows are committed together later
+	Batch *WriteBatchStatus `json:"batch,omitempty"`
+
+	// Approval is set instead of a result when the statement was queued for approval
+	Approval *PendingApproval `json:"approval,omitempty"`
+
+	// Impact is the analysis of an ALTER TABLE made before running it
+	Impact *AlterImpact `json:"impact,omitempty"`
 }
 
 // UnsafeResult holds the result of an unsafe operation
@@ -119,16 +666,121 @@ type UnsafeResult struct {
 	WriteResult  *WriteResult `json:"write_result,omitempty"`
 	Warning      string       `json:"warning"`
 	SkippedCheck string       `json:"skipped_check"`
+
+	// Approval is set instead of a result when the statement was queued for approval
+	Approval *PendingApproval `json:"approval,omitempty"`
+}
+
+// QueryOptions holds per-call overrides for read queries
+type QueryOptions struct {
+	// MaxRows limits the rows returned; 0 uses the connection's max_rows and
+	// larger values are capped to it
+	MaxRows int
+
+	// ExcludeSoftDeleted filters soft-deleted rows from tables with a
+	// soft_delete_column when the connection has soft_delete_mode enabled
+	ExcludeSoftDeleted bool
+
+	// Lint runs lint_query on SELECTs when the connection has lint_selects enabled
+	Lint bool
+
+	// Database runs the query with this default database instead of the connection's
+	Database string
+
+	// Tenant runs the query in this tenant's schema on a connection with
+	// tenant_database_pattern, refusing queries that name other databases
+	Tenant string
+
+	// Progress is called periodically while a long query runs
+	Progress func(StatementProgress)
+
+	// Cache serves and stores SELECT results in the read cache when the
+	// connection has cache_ttl_seconds set
+	Cache bool
+
+	// Raw returns values exactly as the driver delivered them, as RawValue
+	// cells, instead of converting them by column type. Raw results are never cached.
+	Raw bool
+
+	// Context carries the caller's trace span, so the statement's span is
+	// recorded as its child; nil starts a new trace
+	Context context.Context
+
+	// Learn adds a SELECT that succeeds to the query templates suggest_queries returns
+	Learn bool
+}
+
+// ExecuteQuery executes a SQL query and returns the results.
+// Optional args are bound to ? placeholders in the query.
+func (m *Manager) ExecuteQuery(connectionName, query string, args ...interface{}) (*QueryResult, error) {
+	return m.ExecuteQueryWithOptions(connectionName, query, QueryOptions{}, args...)
+}
+
+// ExecuteQueryWithOptions executes a SQL query with per-call options and returns the results
+func (m *Manager) ExecuteQueryWithOptions(connectionName, query string, opts QueryOptions, args ...interface{}) (*QueryResult, error) {
+	run := m.startStatement(opts.Context, connectionName, query)
+	result, err := m.executeQuery(connectionName, query, opts, args...)
+	var rows int64
+	if result != nil {
+		rows = int64(result.Count)
+		run.cached = result.Cached
+	}
+	m.endStatement(run, rows, err)
+	if opts.Learn && err == nil {
+		m.learnQuery(connectionName, opts.Database, query, result.Count)
+	}
+	return result, err
 }
 
-// ExecuteQuery executes a SQL query and returns the results
-func (m *Manager) ExecuteQuery(connectionName, query string) (*QueryResult, error) {
+// executeQuery runs a query for ExecuteQueryWithOptions
+func (m *Manager) executeQuery(connectionName, query string, opts QueryOptions, args ...interface{}) (*QueryResult, error) {
 	db, connConfig, err := m.GetConnection(connectionName)
 	if err != nil {
 		return nil, err
 	}
+	if opts.Tenant != "" {
+		if opts.Database, err = m.tenantStatement(db, connectionName, connConfig, opts.Tenant, opts.Database, query); err != nil {
+			return nil, err
+		}
+	}
+
+	// Serve repeated identical SELECTs from the read cache. Entries are only
+	// stored after the checks below passed for the same SQL, so a hit needs no slot.
+	var cacheKeyValue string
+	if opts.Cache && !opts.Raw && connConfig.CacheTTLSeconds > 0 && DetectQueryType(query) == QueryTypeSelect {
+		cacheKeyValue = cacheKey(query, opts, args)
+		if cached := m.cachedResult(connectionName, cacheKeyValue); cached != nil {
+			slog.Debug("query served from cache", "connection", connectionName, "sql", query)
+			return cached, nil
+		}
+	}
+
+	// Lint before taking a slot; the column lookups run queries of their own.
+	// Linting is advisory, so a failure never blocks the query.
+	var lint []LintFinding
+	if opts.Lint && connConfig.LintSelects && DetectQueryType(query) == QueryTypeSelect {
+		if report, err := m.lintQuery(connectionName, opts.Database, query); err == nil {
+			lint = report.Findings
+		} else {
+			slog.Debug("lint failed", "connection", connectionName, "error", err)
+		}
+	}
+
+	release, err := m.acquireSlot(connectionName)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 
 	// Check read-only mode
+	if !isReadOnlyQuery(query) {
+		if err := checkReplica(connectionName, connConfig); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkReadOnlyStatement(connectionName, connConfig, query); err != nil {
+		return nil, err
+	}
 	if connConfig.ReadOnly && !isReadOnlyQuery(query) {
 		return nil, fmt.Errorf("connection '%s' is read-only, write operations are not allowed", connectionName)
 	}
@@ -142,55 +794,90 @@ func (m *Manager) ExecuteQuery(connectionName, query string) (*QueryResult, erro
 	if isSensitiveQuery(query) {
 		return nil, fmt.Errorf("access to sensitive MySQL metadata is not allowed")
 	}
-
-	rows, err := db.Query(query)
-	if err != nil {
-		return nil, fmt.Errorf("query execution failed: %w", err)
+	if err := checkBlockedPatterns(connectionName, connConfig, query); err != nil {
+		return nil, err
+	}
+	if err := checkQualifiedWrite(connectionName, connConfig, query); err != nil {
+		return nil, err
 	}
-	defer rows.Close()
 
-	columns, err := rows.Columns()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get columns: %w", err)
+	// Read tables with allowed_columns through a projection of those columns,
+	// before the soft-delete filter wraps the projected table
+	var projected []string
+	if DetectQueryType(query) == QueryTypeSelect {
+		if query, projected, err = applyColumnAllowlist(connConfig, query); err != nil {
+			return nil, err
+		}
 	}
 
-	result := &QueryResult{
-		Columns: columns,
-		Rows:    make([]map[string]interface{}, 0),
+	// Hide soft-deleted rows by reading soft-delete tables through a filtered derived table
+	var softDeleteFiltered []string
+	if opts.ExcludeSoftDeleted && connConfig.SoftDeleteMode && DetectQueryType(query) == QueryTypeSelect {
+		query, softDeleteFiltered = applySoftDeleteFilter(connConfig, query)
 	}
 
-	// Prepare value holders
-	values := make([]interface{}, len(columns))
-	valuePtrs := make([]interface{}, len(columns))
-	for i := range values {
-		valuePtrs[i] = &values[i]
+	// Pin a single session so SHOW WARNINGS sees this statement's warnings
+	ctx := statementContext(opts.Context)
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
 	}
+	defer conn.Close()
 
-	rowCount := 0
-	for rows.Next() {
-		if rowCount >= connConfig.MaxRows {
-			break
+	database := connConfig.Database
+	if opts.Database != "" {
+		restore, err := useDatabase(conn, connConfig, opts.Database)
+		if err != nil {
+			return nil, err
 		}
+		defer restore()
+		database = opts.Database
+	}
 
-		if err := rows.Scan(valuePtrs...); err != nil {
-			return nil, fmt.Errorf("failed to scan row: %w", err)
+	// Refuse SELECTs whose estimated cost exceeds the connection's budget
+	if connConfig.MaxEstimatedRowsExamined > 0 && DetectQueryType(query) == QueryTypeSelect {
+		if err := checkQueryCost(conn, connectionName, connConfig.MaxEstimatedRowsExamined, query, args...); err != nil {
+			return nil, err
 		}
+	}
 
-		row := make(map[string]interface{})
-		for i, col := range columns {
-			val := values[i]
-			// Convert []byte to string for JSON serialization
-			if b, ok := val.([]byte); ok {
-				row[col] = string(b)
-			} else {
-				row[col] = val
-			}
-		}
-		result.Rows = append(result.Rows, row)
-		rowCount++
+	maxRows := connConfig.MaxRows
+	if opts.MaxRows > 0 && opts.MaxRows < maxRows {
+		maxRows = opts.MaxRows
+	}
+
+	stopProgress := watchStatement(db, conn, connectionName, opts.Progress)
+	start := time.Now()
+	rows, err := conn.QueryContext(ctx, limitExecutionTime(connConfig, m.cachedServerInfo(connectionName), query), args...)
+	if err != nil {
+		stopProgress()
+		m.recordError(connectionName, query, err)
+		return nil, fmt.Errorf("query execution failed: %w", executionTimeError(connConfig, columnAllowlistError(connConfig, projected, err)))
+	}
+
+	var result *QueryResult
+	if opts.Raw {
+		result, err = scanRawRows(rows, maxRows)
+	} else {
+		result, err = scanRows(rows, maxRows)
+	}
+	rows.Close()
+	stopProgress()
+	if err != nil {
+		return nil, err
 	}
 
-	result.Count = rowCount
+	result.ExecutionMs = time.Since(start).Milliseconds()
+	result.Warnings = fetchWarnings(conn)
+	slog.Debug("query executed", "connection", connectionName, "sql", query, "rows", result.Count, "duration_ms", result.ExecutionMs)
+	result.Connection = connectionName
+	result.Database = database
+	result.SoftDeleteFiltered = softDeleteFiltered
+	result.ProjectedTables = projected
+	result.Lint = lint
+	if cacheKeyValue != "" {
+		m.storeResult(connectionName, cacheKeyValue, result, time.Duration(connConfig.CacheTTLSeconds)*time.Second, connConfig.CacheMaxEntries)
+	}
 	return result, nil
 }
 
@@ -220,7 +907,8 @@ func isDangerousQuery(query string) bool {
 
 // isSensitiveQuery checks for queries that could expose credentials or sensitive metadata
 func isSensitiveQuery(query string) bool {
-	q := strings.ToUpper(query)
+	// Backticks are dropped so `mysql`.`user` matches like mysql.user
+	q := strings.ToUpper(strings.ReplaceAll(query, "`", ""))
 
 	// Block SHOW GRANTS
 	if strings.Contains(q, "SHOW GRANTS") {
@@ -247,38 +935,149 @@ func isSensitiveQuery(query string) bool {
 
 // ExecuteWrite executes a write operation (INSERT, UPDATE, DELETE) and returns affected rows
 func (m *Manager) ExecuteWrite(connectionName, query string, allowedTypes ...QueryType) (*WriteResult, error) {
+	return m.ExecuteWriteArgs(connectionName, query, nil, allowedTypes...)
+}
+
+// ExecuteWriteArgs executes a parameterized write operation, binding args to ? placeholders
+func (m *Manager) ExecuteWriteArgs(connectionName, query string, args []interface{}, allowedTypes ...QueryType) (*WriteResult, error) {
+	return m.ExecuteWriteWithOptions(connectionName, query, args, WriteOptions{}, allowedTypes...)
+}
+
+// ExecuteWriteWithOptions executes a parameterized write operation with per-call options
+func (m *Manager) ExecuteWriteWithOptions(connectionName, query string, args []interface{}, opts WriteOptions, allowedTypes ...QueryType) (*WriteResult, error) {
+	run := m.startStatement(opts.Context, connectionName, query)
+	result, err := m.executeWrite(connectionName, query, args, opts, allowedTypes...)
+	var rows int64
+	if result != nil {
+		rows = result.RowsAffected
+	}
+	m.endStatement(run, rows, err)
+	return result, err
+}
+
+// executeWrite runs a write for ExecuteWriteWithOptions
+func (m *Manager) executeWrite(connectionName, query string, args []interface{}, opts WriteOptions, allowedTypes ...QueryType) (*WriteResult, error) {
 	db, connConfig, err := m.GetConnection(connectionName)
 	if err != nil {
 		return nil, err
 	}
+	if opts.Tenant != "" {
+		if opts.Database, err = m.tenantStatement(db, connectionName, connConfig, opts.Tenant, opts.Database, query); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := checkWriteStatement(connectionName, connConfig, query, allowedTypes); err != nil {
+		return nil, err
+	}
+	queryType := DetectQueryType(query)
+
+	// Coalesce INSERTs into the connection's write batch
+	if opts.Batch && !opts.ReturnRows && batchable(connConfig, query, queryType) {
+		return m.batchWrite(db, connectionName, connConfig, query, args, opts)
+	}
+
+	release, err := m.acquireSlot(connectionName)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Hold risky statements until a human approves them
+	if !opts.approved && needsApproval(connConfig, queryType) {
+		// The caller is gone by the time the statement is approved
+		approved := opts
+		approved.approved = true
+		approved.Progress = nil
+		pending, err := m.requestApproval(connectionName, connConfig, query, queryType, func() (interface{}, error) {
+			return m.ExecuteWriteWithOptions(connectionName, query, args, approved, allowedTypes...)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &WriteResult{Approval: pending, Connection: connectionName, Database: connConfig.Database}, nil
+	}
 
-	// Validate query type
-	if len(allowedTypes) > 0 {
-		if err := ValidateQueryType(query, allowedTypes...); err != nil {
+	defer m.invalidateCache(connectionName)
+
+	// Turn DELETEs on soft-delete tables into UPDATEs that stamp the soft-delete column
+	var rewrittenSQL string
+	if connConfig.SoftDeleteMode && queryType == QueryTypeDelete {
+		rewritten, ok, err := rewriteSoftDelete(connConfig, query)
+		if err != nil {
 			return nil, err
 		}
+		if ok {
+			if hasReturningClause(rewritten) {
+				return nil, fmt.Errorf("soft_delete_mode cannot rewrite DELETE ... RETURNING")
+			}
+			query, rewrittenSQL = rewritten, rewritten
+		}
 	}
 
-	// Check read-only mode
-	if connConfig.ReadOnly {
-		return nil, fmt.Errorf("connection '%s' is read-only, write operations are not allowed", connectionName)
+	// Pin a single session so SHOW WARNINGS sees this statement's warnings
+	ctx := statementContext(opts.Context)
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
 	}
+	defer conn.Close()
 
-	// Check for dangerous operations
-	queryType := DetectQueryType(query)
-	if IsDangerousQueryType(queryType) {
-		return nil, fmt.Errorf("dangerous operations (DROP, TRUNCATE, CREATE, GRANT, REVOKE) are not allowed. Use mysql_execute_unsafe if you need to bypass this check")
+	database := connConfig.Database
+	if opts.Database != "" {
+		restore, err := useDatabase(conn, connConfig, opts.Database)
+		if err != nil {
+			return nil, err
+		}
+		defer restore()
+		database = opts.Database
 	}
 
-	// Block sensitive metadata queries
-	if isSensitiveQuery(query) {
-		return nil, fmt.Errorf("access to sensitive MySQL metadata is not allowed")
+	stopProgress := watchStatement(db, conn, connectionName, opts.Progress)
+	defer stopProgress()
+
+	// Snapshot the rows an UPDATE or DELETE will change so they can be restored
+	backup := connConfig.BackupBeforeWrite
+	if opts.Backup != nil {
+		backup = *opts.Backup
+	}
+	if opts.ReturnChanges && queryType != QueryTypeUpdate {
+		return nil, fmt.Errorf("return_changes only supports UPDATE statements")
+	}
+	if (backup && (queryType == QueryTypeUpdate || queryType == QueryTypeDelete)) || opts.ReturnChanges {
+		result, err := m.executeWithSnapshot(conn, connectionName, connConfig, database, query, args, backup, opts.ReturnChanges)
+		if err != nil {
+			return nil, err
+		}
+		result.RewrittenSQL = rewrittenSQL
+		return result, nil
+	}
+
+	if opts.ReturnRows {
+		if queryType != QueryTypeInsert {
+			return nil, fmt.Errorf("return_rows only supports INSERT statements")
+		}
+		return m.executeInsertReturning(conn, connectionName, connConfig, database, query, args, opts.ReturnKeys)
+	}
+
+	// MariaDB returns the affected rows of INSERT/REPLACE/DELETE ... RETURNING as a result set
+	if info := m.cachedServerInfo(connectionName); info != nil && info.supportsReturning() && hasReturningClause(query) {
+		result, err := m.executeReturning(conn, connectionName, connConfig, query, args)
+		if err != nil {
+			return nil, err
+		}
+		result.Database = database
+		return result, nil
 	}
 
-	result, err := db.Exec(query)
+	start := time.Now()
+	result, err := conn.ExecContext(ctx, query, args...)
 	if err != nil {
+		m.recordError(connectionName, query, err)
 		return nil, fmt.Errorf("query execution failed: %w", err)
 	}
+	elapsed := time.Since(start)
+	slog.Debug("statement executed", "connection", connectionName, "sql", query, "duration_ms", elapsed.Milliseconds())
 
 	rowsAffected, _ := result.RowsAffected()
 	lastInsertID, _ := result.LastInsertId()
@@ -286,11 +1085,69 @@ func (m *Manager) ExecuteWrite(connectionName, query string, allowedTypes ...Que
 	return &WriteResult{
 		RowsAffected: rowsAffected,
 		LastInsertID: lastInsertID,
+		Warning:      riskWarning(connectionName, connConfig),
+		ExecutionMs:  elapsed.Milliseconds(),
+		Warnings:     fetchWarnings(conn),
+		Connection:   connectionName,
+		Database:     database,
+		RewrittenSQL: rewrittenSQL,
 	}, nil
 }
 
+// checkWriteStatement applies the checks every write goes through before it
+// runs: the allowed statement types, read-only mode, dangerous and sensitive
+// statements, blocked patterns and qualified writes
+func checkWriteStatement(connectionName string, connConfig *config.ConnectionConfig, query string, allowedTypes []QueryType) error {
+	// Validate query type
+	if len(allowedTypes) > 0 {
+		if err := ValidateQueryType(query, allowedTypes...); err != nil {
+			return err
+		}
+	}
+
+	// Check read-only mode
+	if err := checkReplica(connectionName, connConfig); err != nil {
+		return err
+	}
+	if connConfig.ReadOnly {
+		return fmt.Errorf("connection '%s' is read-only, write operations are not allowed", connectionName)
+	}
+
+	// Check for dangerous operations
+	if IsDangerousQueryType(DetectQueryType(query)) {
+		return fmt.Errorf("dangerous operations (DROP, TRUNCATE, CREATE, GRANT, REVOKE) are not allowed. Use mysql_execute_unsafe if you need to bypass this check")
+	}
+
+	// Block sensitive metadata queries
+	if isSensitiveQuery(query) {
+		return fmt.Errorf("access to sensitive MySQL metadata is not allowed")
+	}
+	if err := checkBlockedPatterns(connectionName, connConfig, query); err != nil {
+		return err
+	}
+	return checkQualifiedWrite(connectionName, connConfig, query)
+}
+
 // ExecuteAlter executes an ALTER TABLE statement
 func (m *Manager) ExecuteAlter(connectionName, query string) (*WriteResult, error) {
+	return m.ExecuteAlterWithOptions(connectionName, query, WriteOptions{})
+}
+
+// ExecuteAlterWithOptions executes an ALTER TABLE statement with per-call
+// options. Only Progress applies; the statement names its own table.
+func (m *Manager) ExecuteAlterWithOptions(connectionName, query string, opts WriteOptions) (*WriteResult, error) {
+	run := m.startStatement(opts.Context, connectionName, query)
+	result, err := m.executeAlter(connectionName, query, opts)
+	var rows int64
+	if result != nil {
+		rows = result.RowsAffected
+	}
+	m.endStatement(run, rows, err)
+	return result, err
+}
+
+// executeAlter runs an ALTER TABLE for ExecuteAlterWithOptions
+func (m *Manager) executeAlter(connectionName, query string, opts WriteOptions) (*WriteResult, error) {
 	db, connConfig, err := m.GetConnection(connectionName)
 	if err != nil {
 		return nil, err
@@ -302,6 +1159,9 @@ func (m *Manager) ExecuteAlter(connectionName, query string) (*WriteResult, erro
 	}
 
 	// Check read-only mode
+	if err := checkReplica(connectionName, connConfig); err != nil {
+		return nil, err
+	}
 	if connConfig.ReadOnly {
 		return nil, fmt.Errorf("connection '%s' is read-only, ALTER operations are not allowed", connectionName)
 	}
@@ -319,29 +1179,127 @@ func (m *Manager) ExecuteAlter(connectionName, query string) (*WriteResult, erro
 	if isSensitiveQuery(query) {
 		return nil, fmt.Errorf("access to sensitive MySQL metadata is not allowed")
 	}
+	if err := checkBlockedPatterns(connectionName, connConfig, query); err != nil {
+		return nil, err
+	}
+
+	// Analyze the impact first, refusing non-instant changes to large tables
+	// unless forced
+	impact, err := m.AnalyzeAlter(connectionName, query)
+	if err != nil {
+		return nil, err
+	}
+	if impact != nil && !opts.Force && impact.Algorithm != "instant" && impact.SizeMB != nil && *impact.SizeMB > float64(connConfig.AlterMaxTableMB) {
+		impact.Blocked = true
+		impact.BlockReason = fmt.Sprintf("the ALTER is estimated to run as %s on a %.2f MB table, above alter_max_table_mb (%d); review the impact and pass force: true to run it anyway",
+			impact.Algorithm, *impact.SizeMB, connConfig.AlterMaxTableMB)
+		if connConfig.OnlineSchemaChange != nil {
+			impact.BlockReason += ", or run it with online_alter"
+		}
+		return &WriteResult{Impact: impact, Connection: connectionName, Database: connConfig.Database}, nil
+	}
+
+	// Hold risky statements until a human approves them
+	if !opts.approved && needsApproval(connConfig, QueryTypeAlter) {
+		pending, err := m.requestApproval(connectionName, connConfig, query, QueryTypeAlter, func() (interface{}, error) {
+			return m.ExecuteAlterWithOptions(connectionName, query, WriteOptions{Force: opts.Force, approved: true})
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &WriteResult{Approval: pending, Connection: connectionName, Database: connConfig.Database, Impact: impact}, nil
+	}
+
+	// The analysis above runs its own queries, so the slot is taken only now
+	release, err := m.acquireSlot(connectionName)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Pin a single session so SHOW WARNINGS sees this statement's warnings
+	ctx := statementContext(opts.Context)
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Close()
 
-	result, err := db.Exec(query)
+	defer m.invalidateCache(connectionName)
+
+	stopProgress := watchStatement(db, conn, connectionName, opts.Progress)
+	start := time.Now()
+	result, err := conn.ExecContext(ctx, query)
+	stopProgress()
 	if err != nil {
+		m.recordError(connectionName, query, err)
 		return nil, fmt.Errorf("query execution failed: %w", err)
 	}
+	elapsed := time.Since(start)
+	slog.Debug("statement executed", "connection", connectionName, "sql", query, "duration_ms", elapsed.Milliseconds())
 
 	rowsAffected, _ := result.RowsAffected()
 
 	return &WriteResult{
 		RowsAffected: rowsAffected,
+		Warning:      riskWarning(connectionName, connConfig),
+		ExecutionMs:  elapsed.Milliseconds(),
+		Warnings:     fetchWarnings(conn),
+		Connection:   connectionName,
+		Database:     connConfig.Database,
+		Impact:       impact,
 	}, nil
 }
 
 // ExecuteUnsafe executes any query, bypassing dangerous and sensitive query checks
 // WARNING: This method should only be used when absolutely necessary
 func (m *Manager) ExecuteUnsafe(connectionName, query string) (*UnsafeResult, error) {
+	return m.ExecuteUnsafeContext(context.Background(), connectionName, query)
+}
+
+// ExecuteUnsafeContext is ExecuteUnsafe with the caller's context, whose
+// trace span becomes the parent of the statement's span
+func (m *Manager) ExecuteUnsafeContext(ctx context.Context, connectionName, query string) (*UnsafeResult, error) {
+	return m.executeUnsafe(ctx, connectionName, query, false)
+}
+
+// executeUnsafe traces an unsafe statement; approved skips the approval queue
+func (m *Manager) executeUnsafe(ctx context.Context, connectionName, query string, approved bool) (*UnsafeResult, error) {
+	run := m.startStatement(ctx, connectionName, query)
+	result, err := m.runUnsafe(statementContext(ctx), connectionName, query, approved)
+	var rows int64
+	if result != nil && result.QueryResult != nil {
+		rows = int64(result.QueryResult.Count)
+	} else if result != nil && result.WriteResult != nil {
+		rows = result.WriteResult.RowsAffected
+	}
+	m.endStatement(run, rows, err)
+	return result, err
+}
+
+// runUnsafe executes any query with ctx; approved skips the approval queue
+func (m *Manager) runUnsafe(ctx context.Context, connectionName, query string, approved bool) (*UnsafeResult, error) {
 	db, connConfig, err := m.GetConnection(connectionName)
 	if err != nil {
 		return nil, err
 	}
 
+	release, err := m.acquireSlot(connectionName)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	// Still respect read-only mode - that's a configuration choice
 	queryType := DetectQueryType(query)
+	if !IsReadOnlyQueryType(queryType) {
+		if err := checkReplica(connectionName, connConfig); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkReadOnlyStatement(connectionName, connConfig, query); err != nil {
+		return nil, err
+	}
 	if connConfig.ReadOnly && !IsReadOnlyQueryType(queryType) {
 		return nil, fmt.Errorf("connection '%s' is read-only, write operations are not allowed (even with unsafe mode)", connectionName)
 	}
@@ -355,71 +1313,79 @@ func (m *Manager) ExecuteUnsafe(connectionName, query string) (*UnsafeResult, er
 		skippedChecks = append(skippedChecks, "sensitive query blocking")
 	}
 
+	// Operator policy applies even in unsafe mode
+	if err := checkBlockedPatterns(connectionName, connConfig, query); err != nil {
+		return nil, err
+	}
+	if err := checkQualifiedWrite(connectionName, connConfig, query); err != nil {
+		return nil, err
+	}
+
 	skippedCheckMsg := "none"
 	if len(skippedChecks) > 0 {
 		skippedCheckMsg = strings.Join(skippedChecks, ", ")
 	}
 
+	// Hold risky statements until a human approves them
+	if !approved && needsApproval(connConfig, queryType) {
+		pending, err := m.requestApproval(connectionName, connConfig, query, queryType, func() (interface{}, error) {
+			return m.executeUnsafe(ctx, connectionName, query, true)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &UnsafeResult{Approval: pending, SkippedCheck: skippedCheckMsg}, nil
+	}
+
+	slog.Warn("unsafe execution", "connection", connectionName, "sql", query, "skipped_checks", skippedCheckMsg)
+
+	// allowed_columns is operator policy too; projected after approval so
+	// the approved run rewrites the original statement
+	var projected []string
+	if queryType == QueryTypeSelect {
+		if query, projected, err = applyColumnAllowlist(connConfig, query); err != nil {
+			return nil, err
+		}
+	}
+
 	result := &UnsafeResult{
 		Warning:      "UNSAFE EXECUTION: This query bypassed safety checks. Ensure you understand the implications.",
 		SkippedCheck: skippedCheckMsg,
 	}
 
+	// Pin a single session so SHOW WARNINGS sees this statement's warnings
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Close()
+
 	// Determine if this is a read or write query
 	if IsReadOnlyQueryType(queryType) {
 		// Use Query for SELECT-like operations
-		rows, err := db.Query(query)
+		rows, err := conn.QueryContext(ctx, query)
 		if err != nil {
-			return nil, fmt.Errorf("query execution failed: %w", err)
+			m.recordError(connectionName, query, err)
+			return nil, fmt.Errorf("query execution failed: %w", columnAllowlistError(connConfig, projected, err))
 		}
 		defer rows.Close()
 
-		columns, err := rows.Columns()
+		queryResult, err := scanRows(rows, connConfig.MaxRows)
+		rows.Close()
 		if err != nil {
-			return nil, fmt.Errorf("failed to get columns: %w", err)
-		}
-
-		queryResult := &QueryResult{
-			Columns: columns,
-			Rows:    make([]map[string]interface{}, 0),
-		}
-
-		// Prepare value holders
-		values := make([]interface{}, len(columns))
-		valuePtrs := make([]interface{}, len(columns))
-		for i := range values {
-			valuePtrs[i] = &values[i]
-		}
-
-		rowCount := 0
-		for rows.Next() {
-			if rowCount >= connConfig.MaxRows {
-				break
-			}
-
-			if err := rows.Scan(valuePtrs...); err != nil {
-				return nil, fmt.Errorf("failed to scan row: %w", err)
-			}
-
-			row := make(map[string]interface{})
-			for i, col := range columns {
-				val := values[i]
-				if b, ok := val.([]byte); ok {
-					row[col] = string(b)
-				} else {
-					row[col] = val
-				}
-			}
-			queryResult.Rows = append(queryResult.Rows, row)
-			rowCount++
+			return nil, err
 		}
+		queryResult.Warnings = fetchWarnings(conn)
+		queryResult.Connection = connectionName
+		queryResult.ProjectedTables = projected
 
-		queryResult.Count = rowCount
 		result.QueryResult = queryResult
 	} else {
 		// Use Exec for write operations
-		execResult, err := db.Exec(query)
+		defer m.invalidateCache(connectionName)
+		execResult, err := conn.ExecContext(ctx, query)
 		if err != nil {
+			m.recordError(connectionName, query, err)
 			return nil, fmt.Errorf("query execution failed: %w", err)
 		}
 
@@ -429,6 +1395,7 @@ func (m *Manager) ExecuteUnsafe(connectionName, query string) (*UnsafeResult, er
 		result.WriteResult = &WriteResult{
 			RowsAffected: rowsAffected,
 			LastInsertID: lastInsertID,
+			Warnings:     fetchWarnings(conn),
 		}
 	}
 
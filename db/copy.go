@@ -0,0 +1,503 @@
+package db
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+	"time"
+
+	"mysql-golang-mcp/config"
+)
+
+const (
+	// DefaultCopyBatchRows is how many rows copy_table_data reads per page by default
+	DefaultCopyBatchRows = 1000
+
+	// MaxCopyBatchRows caps the rows copy_table_data reads per page
+	MaxCopyBatchRows = 10000
+)
+
+// CopyTableOptions holds per-call settings for CopyTableData
+type CopyTableOptions struct {
+	// SourceDatabase and TargetDatabase default to each connection's database
+	SourceDatabase string
+	TargetDatabase string
+
+	// TargetTable defaults to the source table's name
+	TargetTable string
+
+	// Filters restrict the source rows copied
+	Filters []Filter
+
+	// Limit stops the copy after this many rows; 0 copies every row
+	Limit int64
+
+	// BatchSize is the rows read from the source per page
+	BatchSize int
+
+	// Upsert updates target rows that collide on a primary or unique key
+	Upsert bool
+
+	// Seed makes faker values repeatable; 0 picks a seed, which is returned
+	// in the result when a faker rule applies
+	Seed int64
+
+	// Progress is called after each page with the rows copied so far
+	Progress func(copied int64)
+
+	// Context carries the caller's trace span to the inserts
+	Context context.Context
+}
+
+// CopyTableResult reports a copy_table_data run
+type CopyTableResult struct {
+	Source      string `json:"source"`
+	Target      string `json:"target"`
+	Table       string `json:"table"`
+	TargetTable string `json:"target_table"`
+	RowsCopied  int64  `json:"rows_copied"`
+	Pages       int    `json:"pages"`
+
+	// Anonymized maps each anonymized column to its rule
+	Anonymized map[string]string `json:"anonymized,omitempty"`
+
+	// SkippedColumns are source columns not copied: generated columns,
+	// columns missing from the target, and columns outside allowed_columns
+	SkippedColumns []string `json:"skipped_columns,omitempty"`
+
+	Seed int64 `json:"seed,omitempty"`
+
+	// Warning flags a copy out of a prod connection without anonymization rules
+	Warning string `json:"warning,omitempty"`
+
+	Warnings        []BulkWarning `json:"warnings,omitempty"`
+	WarningsOmitted int           `json:"warnings_omitted,omitempty"`
+	ExecutionMs     int64         `json:"execution_ms"`
+}
+
+// anonymizer replaces a column value; n numbers the row within the copy
+type anonymizer func(value interface{}, n int64) (interface{}, error)
+
+// CopyTableData copies a table's rows from one connection to another, page
+// by page in primary key order, applying the source connection's
+// tables.<table>.anonymize rules to each row before it is written. Values
+// are copied as the driver reads them, so dates, binary data and decimals
+// arrive unchanged. The target must not be a prod connection.
+func (m *Manager) CopyTableData(source, target, table string, opts CopyTableOptions) (*CopyTableResult, error) {
+	start := time.Now()
+	_, sourceConfig, err := m.GetConnection(source)
+	if err != nil {
+		return nil, err
+	}
+	_, targetConfig, err := m.GetConnection(target)
+	if err != nil {
+		return nil, err
+	}
+	if targetConfig.Environment == "prod" {
+		return nil, fmt.Errorf("connection '%s' is a prod connection; copy_table_data does not write to prod connections", target)
+	}
+	if err := checkProtectedTable(databaseOrDefault(opts.SourceDatabase, sourceConfig), table); err != nil {
+		return nil, err
+	}
+	if opts.TargetTable == "" {
+		opts.TargetTable = table
+	}
+	if err := checkProtectedTable(databaseOrDefault(opts.TargetDatabase, targetConfig), opts.TargetTable); err != nil {
+		return nil, err
+	}
+	if source == target && opts.TargetTable == table && databaseOrDefault(opts.SourceDatabase, sourceConfig) == databaseOrDefault(opts.TargetDatabase, targetConfig) {
+		return nil, fmt.Errorf("source and target are the same table")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultCopyBatchRows
+	}
+	if opts.BatchSize > MaxCopyBatchRows {
+		return nil, fmt.Errorf("batch_size must be at most %d", MaxCopyBatchRows)
+	}
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano()
+	}
+
+	sourceColumns, err := m.TableColumns(source, opts.SourceDatabase, table)
+	if err != nil {
+		return nil, err
+	}
+	targetColumns, err := m.TableColumns(target, opts.TargetDatabase, opts.TargetTable)
+	if err != nil {
+		return nil, err
+	}
+	keyColumns, err := m.PrimaryKeyColumns(source, opts.SourceDatabase, table)
+	if err != nil {
+		return nil, err
+	}
+	if len(keyColumns) == 0 {
+		return nil, fmt.Errorf("table %s has no primary key; copy_table_data reads tables page by page in primary key order", table)
+	}
+
+	result := &CopyTableResult{Source: source, Target: target, Table: table, TargetTable: opts.TargetTable}
+
+	// A rule for a column the table does not have is most likely a typo that
+	// would let the real column through, so it stops the copy
+	sourceByName := make(map[string]ColumnInfo, len(sourceColumns))
+	for _, col := range sourceColumns {
+		sourceByName[strings.ToLower(col.Name)] = col
+	}
+	configured := anonymizeRules(sourceConfig, table)
+	rules := make(map[string]string, len(configured))
+	for column, rule := range configured {
+		col, ok := sourceByName[strings.ToLower(column)]
+		if !ok {
+			return nil, fmt.Errorf("tables.%s.anonymize names column %s, which %s does not have", table, column, table)
+		}
+		rules[col.Name] = rule
+	}
+	if len(rules) == 0 && sourceConfig.Environment == "prod" {
+		result.Warning = fmt.Sprintf("connection '%s' is a prod connection and %s has no anonymize rules, so its rows were copied unchanged", source, table)
+	}
+
+	allowed := allowedColumns(sourceConfig, table)
+	targetByName := make(map[string]ColumnInfo, len(targetColumns))
+	for _, col := range targetColumns {
+		if col.GenerationExpression == "" {
+			targetByName[strings.ToLower(col.Name)] = col
+		}
+	}
+	var columns []string
+	copied := make(map[string]ColumnInfo)
+	for _, col := range sourceColumns {
+		targetCol, onTarget := targetByName[strings.ToLower(col.Name)]
+		if col.GenerationExpression != "" || !onTarget || !columnAllowed(allowed, col.Name) {
+			result.SkippedColumns = append(result.SkippedColumns, col.Name)
+			continue
+		}
+		columns = append(columns, col.Name)
+		copied[col.Name] = targetCol
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("%s and %s have no columns in common", table, opts.TargetTable)
+	}
+	for _, col := range keyColumns {
+		if !columnAllowed(allowed, col) {
+			return nil, fmt.Errorf("primary key column %s of %s is not in allowed_columns, so the table cannot be paged through", col, table)
+		}
+	}
+	for _, f := range opts.Filters {
+		if !columnAllowed(allowed, f.Field) {
+			return nil, fmt.Errorf("filter column %s is not in the allowed_columns of %s", f.Field, table)
+		}
+	}
+
+	anonymizers, err := m.copyAnonymizers(target, opts, rules, copied, []byte(sourceConfig.AnonymizeSalt))
+	if err != nil {
+		return nil, err
+	}
+	if len(anonymizers) > 0 {
+		result.Anonymized = make(map[string]string, len(anonymizers))
+		for col := range anonymizers {
+			result.Anonymized[col] = rules[col]
+			if rules[col] == "faker" {
+				result.Seed = opts.Seed
+			}
+		}
+	}
+	// Key columns are read for paging even when the target lacks them
+	readColumns := append([]string(nil), columns...)
+	for _, col := range keyColumns {
+		if _, ok := copied[col]; !ok {
+			readColumns = append(readColumns, col)
+		}
+	}
+
+	var after []interface{}
+	for opts.Limit == 0 || result.RowsCopied < opts.Limit {
+		pageSize := int64(opts.BatchSize)
+		if opts.Limit > 0 {
+			pageSize = min(pageSize, opts.Limit-result.RowsCopied)
+		}
+		page, err := m.readCopyPage(source, sourceConfig, opts.SourceDatabase, table, readColumns, keyColumns, opts.Filters, after, pageSize)
+		if err != nil {
+			return nil, copyError(result, err)
+		}
+		if len(page) == 0 {
+			break
+		}
+
+		last := page[len(page)-1]
+		after = make([]interface{}, len(keyColumns))
+		for i, col := range keyColumns {
+			after[i] = last[col]
+		}
+
+		rows := make([]map[string]interface{}, len(page))
+		for i, sourceRow := range page {
+			row := make(map[string]interface{}, len(columns))
+			for _, col := range columns {
+				row[col] = sourceRow[col]
+				if anonymize, ok := anonymizers[col]; ok {
+					if row[col], err = anonymize(sourceRow[col], result.RowsCopied+int64(i)+1); err != nil {
+						return nil, copyError(result, fmt.Errorf("column %s: %w", col, err))
+					}
+				}
+			}
+			rows[i] = row
+		}
+
+		inserted, err := m.InsertRows(target, opts.TargetDatabase, opts.TargetTable, rows, InsertOptions{Upsert: opts.Upsert, Context: opts.Context})
+		if err != nil {
+			return nil, copyError(result, err)
+		}
+		result.RowsCopied += int64(len(rows))
+		result.Pages++
+		for _, w := range inserted.Warnings {
+			if len(result.Warnings) >= maxBulkWarnings {
+				result.WarningsOmitted++
+				continue
+			}
+			result.Warnings = append(result.Warnings, w)
+		}
+		result.WarningsOmitted += inserted.WarningsOmitted
+		if opts.Progress != nil {
+			opts.Progress(result.RowsCopied)
+		}
+		if int64(len(page)) < pageSize {
+			break
+		}
+	}
+
+	result.ExecutionMs = time.Since(start).Milliseconds()
+	return result, nil
+}
+
+// copyError reports a failed copy along with the rows already written,
+// which stay in the target
+func copyError(result *CopyTableResult, err error) error {
+	if result.RowsCopied == 0 {
+		return err
+	}
+	return fmt.Errorf("%w; the %d rows already copied to %s were kept", err, result.RowsCopied, result.TargetTable)
+}
+
+// readCopyPage reads the next page of source rows after the key values in
+// after, keeping values exactly as the driver returns them so they can be
+// written to the target unchanged
+func (m *Manager) readCopyPage(source string, sourceConfig *config.ConnectionConfig, database, table string, columns, keyColumns []string, filters []Filter, after []interface{}, limit int64) ([]map[string]interface{}, error) {
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = QuoteIdentifier(col)
+	}
+	quotedKeys := make([]string, len(keyColumns))
+	for i, col := range keyColumns {
+		quotedKeys[i] = QuoteIdentifier(col)
+	}
+
+	where, args, err := buildWhere(filters)
+	if err != nil {
+		return nil, err
+	}
+	if after != nil {
+		condition := fmt.Sprintf("(%s) > (%s)", strings.Join(quotedKeys, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(after)), ", "))
+		if where == "" {
+			where = " WHERE " + condition
+		} else {
+			where += " AND " + condition
+		}
+		args = append(args, after...)
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s%s ORDER BY %s LIMIT %d",
+		strings.Join(quoted, ", "), QualifiedName(database, table), where, strings.Join(quotedKeys, ", "), limit)
+
+	db, _, err := m.GetConnection(source)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkBlockedPatterns(source, sourceConfig, query); err != nil {
+		return nil, err
+	}
+	release, err := m.acquireSlot(source)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	rows, err := db.QueryContext(context.Background(), limitExecutionTime(sourceConfig, m.cachedServerInfo(source), query), args...)
+	if err != nil {
+		m.recordError(source, query, err)
+		return nil, fmt.Errorf("failed to read %s: %w", table, executionTimeError(sourceConfig, err))
+	}
+	defer rows.Close()
+
+	var page []map[string]interface{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		ptrs := make([]interface{}, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		row := make(map[string]interface{}, len(columns))
+		for i, col := range columns {
+			row[col] = values[i]
+		}
+		page = append(page, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", table, err)
+	}
+	return page, nil
+}
+
+// copyAnonymizers builds the anonymizer of each copied column with a rule,
+// checking that the values it writes fit the target column
+func (m *Manager) copyAnonymizers(target string, opts CopyTableOptions, rules map[string]string, copied map[string]ColumnInfo, salt []byte) (map[string]anonymizer, error) {
+	anonymizers := make(map[string]anonymizer)
+	var fake *fakeData
+	var unique map[string]bool
+	for column, rule := range rules {
+		col, ok := copied[column]
+		if !ok {
+			// A column that is not copied never reaches the target
+			continue
+		}
+		switch rule {
+		case "nullify":
+			if !col.Nullable {
+				return nil, fmt.Errorf("column %s is NOT NULL in %s, so it cannot be nullified", col.Name, opts.TargetTable)
+			}
+			anonymizers[column] = func(interface{}, int64) (interface{}, error) { return nil, nil }
+		case "hash":
+			hashed, err := hashAnonymizer(col, salt)
+			if err != nil {
+				return nil, err
+			}
+			anonymizers[column] = hashed
+		case "faker":
+			if fake == nil {
+				fake = newFakeData(opts.Seed)
+				indexes, err := m.GetIndexes(target, opts.TargetDatabase, opts.TargetTable)
+				if err != nil {
+					return nil, err
+				}
+				unique = make(map[string]bool)
+				for _, idx := range indexes {
+					if idx.Unique && len(idx.Columns) == 1 {
+						unique[strings.ToLower(idx.Columns[0])] = true
+					}
+				}
+			}
+			// Mixed into unique strings so separate runs do not produce the same values
+			token := fake.hex(4)
+			isUnique := unique[strings.ToLower(col.Name)] && isTextType(col.DataType) && !isUUIDColumn(col)
+			anonymizers[column] = func(value interface{}, n int64) (interface{}, error) {
+				if value == nil {
+					return nil, nil
+				}
+				generated, err := fake.value(col)
+				if err != nil {
+					return nil, err
+				}
+				if s, ok := generated.(string); ok && isUnique {
+					return uniqueText(s, fmt.Sprintf("%s%d", token, n), maxLength(col))
+				}
+				return generated, nil
+			}
+		}
+	}
+	return anonymizers, nil
+}
+
+// hashAnonymizer replaces values with a hash of them, HMAC-SHA256 keyed by
+// salt when one is set, rendered to fit the column: hex for text, a positive
+// number for integers, raw bytes for binary columns. Equal values hash
+// alike, so hashed keys still join across tables hashed with the same salt.
+func hashAnonymizer(col ColumnInfo, salt []byte) (anonymizer, error) {
+	digest := func(value interface{}) []byte {
+		var h hash.Hash
+		if len(salt) > 0 {
+			h = hmac.New(sha256.New, salt)
+		} else {
+			h = sha256.New()
+		}
+		if b, ok := value.([]byte); ok {
+			h.Write(b)
+		} else {
+			fmt.Fprint(h, value)
+		}
+		return h.Sum(nil)
+	}
+
+	switch {
+	case isTextType(col.DataType):
+		return func(value interface{}, _ int64) (interface{}, error) {
+			if value == nil {
+				return nil, nil
+			}
+			return truncateRunes(hex.EncodeToString(digest(value)), maxLength(col)), nil
+		}, nil
+	case isIntegerColumn(col.DataType) && !strings.HasPrefix(col.ColumnType, "tinyint(1)"):
+		_, hi := integerRange(col)
+		return func(value interface{}, _ int64) (interface{}, error) {
+			if value == nil {
+				return nil, nil
+			}
+			return int64(binary.BigEndian.Uint64(digest(value))%uint64(hi)) + 1, nil
+		}, nil
+	case strings.HasSuffix(col.DataType, "binary") || strings.HasSuffix(col.DataType, "blob"):
+		return func(value interface{}, _ int64) (interface{}, error) {
+			if value == nil {
+				return nil, nil
+			}
+			sum := digest(value)
+			if n := maxLength(col); n > 0 && n < len(sum) {
+				sum = sum[:n]
+			}
+			return sum, nil
+		}, nil
+	}
+	return nil, fmt.Errorf("the hash rule cannot be applied to column %s of type %s; use faker or nullify", col.Name, col.ColumnType)
+}
+
+// anonymizeRules returns the anonymize rules configured for a table
+func anonymizeRules(connConfig *config.ConnectionConfig, table string) map[string]string {
+	for name, t := range connConfig.Tables {
+		if t != nil && strings.EqualFold(name, table) {
+			return t.Anonymize
+		}
+	}
+	return nil
+}
+
+// columnAllowed reports whether allowed_columns, when set, includes column
+func columnAllowed(allowed []string, column string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, name := range allowed {
+		if strings.EqualFold(name, column) {
+			return true
+		}
+	}
+	return false
+}
+
+func isIntegerColumn(dataType string) bool {
+	switch dataType {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint":
+		return true
+	}
+	return false
+}
+
+// databaseOrDefault returns database, or the connection's default database
+func databaseOrDefault(database string, connConfig *config.ConnectionConfig) string {
+	if database != "" {
+		return database
+	}
+	return connConfig.Database
+}
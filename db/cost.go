@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// tableScanEstimate is a table access in an EXPLAIN plan with its estimated rows examined
+type tableScanEstimate struct {
+	Table        string
+	AccessType   string
+	Key          string
+	RowsExamined int64
+}
+
+// rowQueryer runs a single-row query on a pool, pinned session, or transaction
+type rowQueryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// checkQueryCost runs EXPLAIN FORMAT=JSON for a SELECT and refuses it if the
+// estimated rows examined exceed the connection's max_estimated_rows_examined
+func checkQueryCost(db rowQueryer, connectionName string, budget int64, query string, args ...interface{}) error {
+	var plan string
+	if err := db.QueryRowContext(context.Background(), "EXPLAIN FORMAT=JSON "+query, args...).Scan(&plan); err != nil {
+		return fmt.Errorf("failed to estimate query cost: %w", err)
+	}
+
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(plan), &parsed); err != nil {
+		return fmt.Errorf("failed to parse EXPLAIN output: %w", err)
+	}
+
+	var scans []tableScanEstimate
+	collectTableScans(parsed, &scans)
+
+	var total int64
+	for _, scan := range scans {
+		total += scan.RowsExamined
+	}
+	if total <= budget {
+		return nil
+	}
+
+	// Report the most expensive accesses first
+	sort.Slice(scans, func(i, j int) bool { return scans[i].RowsExamined > scans[j].RowsExamined })
+
+	details := make([]string, 0, len(scans))
+	for _, scan := range scans {
+		detail := fmt.Sprintf("%s: ~%d rows (access: %s", scan.Table, scan.RowsExamined, scan.AccessType)
+		if scan.Key != "" {
+			detail += ", key: " + scan.Key
+		}
+		if scan.AccessType == "ALL" {
+			detail += ", full table scan"
+		}
+		details = append(details, detail+")")
+	}
+
+	return fmt.Errorf("query refused: estimated rows examined (%d) exceeds max_estimated_rows_examined (%d) for connection '%s'. Breakdown: %s. Add a selective WHERE clause on an indexed column, narrow the range, or add an index (see get_indexes and the design_index_for_query prompt)",
+		total, budget, connectionName, strings.Join(details, "; "))
+}
+
+// collectTableScans walks an EXPLAIN FORMAT=JSON document and collects every table access
+func collectTableScans(node interface{}, scans *[]tableScanEstimate) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if name, ok := v["table_name"].(string); ok {
+			// MySQL reports rows_examined_per_scan; MariaDB reports rows
+			rows, ok := v["rows_examined_per_scan"].(float64)
+			if !ok {
+				rows, ok = v["rows"].(float64)
+			}
+			if ok {
+				scan := tableScanEstimate{Table: name, RowsExamined: int64(rows)}
+				scan.AccessType, _ = v["access_type"].(string)
+				scan.Key, _ = v["key"].(string)
+				*scans = append(*scans, scan)
+			}
+		}
+		for _, child := range v {
+			collectTableScans(child, scans)
+		}
+	case []interface{}:
+		for _, child := range v {
+			collectTableScans(child, scans)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package db
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DefaultCursorIdleTimeout is how long an unused cursor stays open before it is closed
+const DefaultCursorIdleTimeout = 5 * time.Minute
+
+// cursor is an open server-side result set that is read in batches across tool calls
+type cursor struct {
+	id         string
+	connection string
+	conn       *sql.Conn
+	rows       *sql.Rows
+	release    func()
+
+	columns     []string
+	columnTypes []ColumnType
+	dbTypes     []string
+
+	// pending is the row read ahead to report whether more rows remain
+	pending map[string]interface{}
+	fetched int
+
+	idleTimeout time.Duration
+	timer       *time.Timer
+	mu          sync.Mutex
+}
+
+// CursorInfo describes a newly opened cursor
+type CursorInfo struct {
+	CursorID           string       `json:"cursor_id"`
+	Connection         string       `json:"connection"`
+	Columns            []string     `json:"columns"`
+	ColumnTypes        []ColumnType `json:"column_types"`
+	IdleTimeoutSeconds int          `json:"idle_timeout_seconds"`
+}
+
+// CursorBatch holds one batch of rows fetched from a cursor
+type CursorBatch struct {
+	CursorID string                   `json:"cursor_id"`
+	Columns  []string                 `json:"columns"`
+	Rows     []map[string]interface{} `json:"rows"`
+	Count    int                      `json:"count"`
+	Fetched  int                      `json:"fetched_total"`
+	HasMore  bool                     `json:"has_more"`
+	Closed   bool                     `json:"closed"`
+
+	// columnTypes and connection let the rows be rendered for output
+	columnTypes []ColumnType
+	connection  string
+}
+
+// OpenCursor runs a SELECT and keeps its result set open so it can be read in
+// batches with FetchCursor. The cursor holds one of the connection's
+// max_concurrent_queries slots until it is closed, exhausted, or idle for idleTimeout.
+func (m *Manager) OpenCursor(connectionName, query string, idleTimeout time.Duration) (*CursorInfo, error) {
+	db, connConfig, err := m.GetConnection(connectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	if DetectQueryType(query) != QueryTypeSelect {
+		return nil, fmt.Errorf("cursors can only be opened for SELECT queries")
+	}
+	if err := checkReadOnlyStatement(connectionName, connConfig, query); err != nil {
+		return nil, err
+	}
+
+	// Block sensitive metadata queries
+	if isSensitiveQuery(query) {
+		return nil, fmt.Errorf("access to sensitive MySQL metadata is not allowed")
+	}
+	if err := checkBlockedPatterns(connectionName, connConfig, query); err != nil {
+		return nil, err
+	}
+	query, projected, err := applyColumnAllowlist(connConfig, query)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := m.acquireSlot(connectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refuse SELECTs whose estimated cost exceeds the connection's budget
+	if connConfig.MaxEstimatedRowsExamined > 0 {
+		if err := checkQueryCost(db, connectionName, connConfig.MaxEstimatedRowsExamined, query); err != nil {
+			release()
+			return nil, err
+		}
+	}
+
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		release()
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+
+	rows, err := conn.QueryContext(context.Background(), query)
+	if err != nil {
+		conn.Close()
+		release()
+		m.recordError(connectionName, query, err)
+		return nil, fmt.Errorf("query execution failed: %w", columnAllowlistError(connConfig, projected, err))
+	}
+
+	result, dbTypes, err := newResultSet(rows)
+	if err != nil {
+		rows.Close()
+		conn.Close()
+		release()
+		return nil, err
+	}
+
+	c := &cursor{
+		id:          newCursorID(),
+		connection:  connectionName,
+		conn:        conn,
+		rows:        rows,
+		release:     release,
+		columns:     result.Columns,
+		columnTypes: result.ColumnTypes,
+		dbTypes:     dbTypes,
+		idleTimeout: idleTimeout,
+	}
+	c.timer = time.AfterFunc(idleTimeout, func() {
+		slog.Info("cursor idle timeout", "cursor_id", c.id, "connection", connectionName)
+		m.CloseCursor(c.id)
+	})
+
+	m.cursorsMu.Lock()
+	m.cursors[c.id] = c
+	m.cursorsMu.Unlock()
+
+	return &CursorInfo{
+		CursorID:           c.id,
+		Connection:         connectionName,
+		Columns:            c.columns,
+		ColumnTypes:        c.columnTypes,
+		IdleTimeoutSeconds: int(idleTimeout.Seconds()),
+	}, nil
+}
+
+// FetchCursor reads up to batchSize rows from an open cursor, capped at the
+// connection's max_rows. The cursor is closed automatically once exhausted.
+func (m *Manager) FetchCursor(cursorID string, batchSize int) (*CursorBatch, error) {
+	m.cursorsMu.Lock()
+	c, exists := m.cursors[cursorID]
+	m.cursorsMu.Unlock()
+	if !exists {
+		return nil, fmt.Errorf("unknown or expired cursor: %s", cursorID)
+	}
+
+	connConfig, _ := m.lookupConnection(c.connection)
+	if maxRows := connConfig.MaxRows; batchSize <= 0 || batchSize > maxRows {
+		batchSize = maxRows
+	}
+
+	c.mu.Lock()
+	c.timer.Stop()
+
+	batch := &CursorBatch{CursorID: cursorID, Columns: c.columns, Rows: make([]map[string]interface{}, 0, batchSize), columnTypes: c.columnTypes, connection: c.connection}
+	if c.pending != nil {
+		batch.Rows = append(batch.Rows, c.pending)
+		c.pending = nil
+	}
+
+	var err error
+	for len(batch.Rows) < batchSize && c.rows.Next() {
+		var row map[string]interface{}
+		if row, err = scanRow(c.rows, c.columns, c.dbTypes); err != nil {
+			break
+		}
+		batch.Rows = append(batch.Rows, row)
+	}
+
+	// Read one row ahead so has_more is exact
+	if err == nil && len(batch.Rows) == batchSize && c.rows.Next() {
+		c.pending, err = scanRow(c.rows, c.columns, c.dbTypes)
+	}
+	if err == nil {
+		err = c.rows.Err()
+	}
+
+	c.fetched += len(batch.Rows)
+	batch.Count = len(batch.Rows)
+	batch.Fetched = c.fetched
+	batch.HasMore = c.pending != nil
+	if batch.HasMore && err == nil {
+		c.timer.Reset(c.idleTimeout)
+	}
+	c.mu.Unlock()
+
+	if err != nil || !batch.HasMore {
+		m.CloseCursor(cursorID)
+		batch.Closed = true
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cursor: %w", err)
+	}
+
+	return batch, nil
+}
+
+// Columnar converts the batch to the columnar layout, with rows as value arrays in column order
+func (b *CursorBatch) Columnar() map[string]interface{} {
+	values := make([][]interface{}, len(b.Rows))
+	for i, row := range b.Rows {
+		values[i] = make([]interface{}, len(b.Columns))
+		for j, col := range b.Columns {
+			values[i][j] = row[col]
+		}
+	}
+
+	return map[string]interface{}{
+		"cursor_id":     b.CursorID,
+		"columns":       b.Columns,
+		"values":        values,
+		"count":         b.Count,
+		"fetched_total": b.Fetched,
+		"has_more":      b.HasMore,
+		"closed":        b.Closed,
+	}
+}
+
+// CloseCursor closes a cursor and releases its session and concurrency slot
+func (m *Manager) CloseCursor(cursorID string) error {
+	m.cursorsMu.Lock()
+	c, exists := m.cursors[cursorID]
+	delete(m.cursors, cursorID)
+	m.cursorsMu.Unlock()
+	if !exists {
+		return fmt.Errorf("unknown or expired cursor: %s", cursorID)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.timer.Stop()
+	c.rows.Close()
+	c.conn.Close()
+	c.release()
+	return nil
+}
+
+// closeAllCursors closes every open cursor
+func (m *Manager) closeAllCursors() {
+	m.cursorsMu.Lock()
+	ids := make([]string, 0, len(m.cursors))
+	for id := range m.cursors {
+		ids = append(ids, id)
+	}
+	m.cursorsMu.Unlock()
+
+	for _, id := range ids {
+		m.CloseCursor(id)
+	}
+}
+
+// newCursorID returns a random cursor identifier
+func newCursorID() string {
+	return "cur_" + randomHex(8)
+}
+
+// randomHex returns n random bytes hex-encoded
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
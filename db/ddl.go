@@ -0,0 +1,127 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+)
+
+// CreateStatement holds the SHOW CREATE TABLE output for one table
+type CreateStatement struct {
+	Table     string `json:"table"`
+	Statement string `json:"statement"`
+}
+
+// ShowCreateTable returns the CREATE TABLE statement for a table
+func (m *Manager) ShowCreateTable(connectionName, database, table string) (string, error) {
+	queryResult, err := m.ExecuteQuery(connectionName, "SHOW CREATE TABLE "+QualifiedName(database, table))
+	if err != nil {
+		return "", err
+	}
+	if len(queryResult.Rows) == 0 {
+		return "", fmt.Errorf("table not found: %s", table)
+	}
+
+	if ddl, ok := queryResult.Rows[0]["Create Table"].(string); ok {
+		return ddl, nil
+	}
+	if ddl, ok := queryResult.Rows[0]["Create View"].(string); ok {
+		return ddl, nil
+	}
+	return "", fmt.Errorf("unexpected SHOW CREATE TABLE output for %s", table)
+}
+
+// BaseTables returns the names of all base tables in a database
+func (m *Manager) BaseTables(connectionName, database string) ([]string, error) {
+	queryResult, err := m.ExecuteQuery(connectionName, `SELECT TABLE_NAME FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = COALESCE(?, DATABASE()) AND TABLE_TYPE = 'BASE TABLE'
+		ORDER BY TABLE_NAME`, nullIfEmpty(database))
+	if err != nil {
+		return nil, err
+	}
+
+	tables := make([]string, 0, len(queryResult.Rows))
+	for _, row := range queryResult.Rows {
+		tables = append(tables, stringValue(row["TABLE_NAME"]))
+	}
+	return tables, nil
+}
+
+// ForeignKeyDependencies returns, for each table in a database, the tables it references
+func (m *Manager) ForeignKeyDependencies(connectionName, database string) (map[string][]string, error) {
+	queryResult, err := m.ExecuteQuery(connectionName, `SELECT DISTINCT TABLE_NAME, REFERENCED_TABLE_NAME
+		FROM information_schema.KEY_COLUMN_USAGE
+		WHERE TABLE_SCHEMA = COALESCE(?, DATABASE())
+		AND REFERENCED_TABLE_SCHEMA = TABLE_SCHEMA
+		AND REFERENCED_TABLE_NAME IS NOT NULL`, nullIfEmpty(database))
+	if err != nil {
+		return nil, err
+	}
+
+	deps := make(map[string][]string)
+	for _, row := range queryResult.Rows {
+		table := stringValue(row["TABLE_NAME"])
+		referenced := stringValue(row["REFERENCED_TABLE_NAME"])
+		if table != referenced {
+			deps[table] = append(deps[table], referenced)
+		}
+	}
+	return deps, nil
+}
+
+// SortByDependencies orders tables so that referenced tables come before the
+// tables that reference them. Tables involved in a dependency cycle are appended
+// at the end in name order and also returned separately.
+func SortByDependencies(tables []string, deps map[string][]string) (ordered []string, cyclic []string) {
+	inSet := make(map[string]bool, len(tables))
+	for _, t := range tables {
+		inSet[t] = true
+	}
+
+	// Count unresolved dependencies within the requested set
+	pending := make(map[string]int, len(tables))
+	dependents := make(map[string][]string)
+	for _, t := range tables {
+		for _, ref := range deps[t] {
+			if inSet[ref] {
+				pending[t]++
+				dependents[ref] = append(dependents[ref], t)
+			}
+		}
+	}
+
+	var ready []string
+	for _, t := range tables {
+		if pending[t] == 0 {
+			ready = append(ready, t)
+		}
+	}
+	sort.Strings(ready)
+
+	done := make(map[string]bool, len(tables))
+	for len(ready) > 0 {
+		t := ready[0]
+		ready = ready[1:]
+		ordered = append(ordered, t)
+		done[t] = true
+
+		var next []string
+		for _, d := range dependents[t] {
+			pending[d]--
+			if pending[d] == 0 {
+				next = append(next, d)
+			}
+		}
+		sort.Strings(next)
+		ready = append(ready, next...)
+	}
+
+	for _, t := range tables {
+		if !done[t] {
+			cyclic = append(cyclic, t)
+		}
+	}
+	sort.Strings(cyclic)
+	ordered = append(ordered, cyclic...)
+
+	return ordered, cyclic
+}
@@ -0,0 +1,239 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// dictionaryCommentLimit is the length comments are cut to when a data
+// dictionary exceeds its token budget
+const dictionaryCommentLimit = 80
+
+// DataDictionary is a compact summary of a whole database, sized to be given
+// to a model in one piece
+type DataDictionary struct {
+	Database      string             `json:"database"`
+	Tables        []DictionaryTable  `json:"tables"`
+	Relationships []string           `json:"relationships,omitempty"`
+	Elided        *DictionaryElision `json:"elided,omitempty"`
+}
+
+// DictionaryTable summarizes one table or view. Each column is rendered as
+// "name type [flags] [-- comment]".
+type DictionaryTable struct {
+	Name          string   `json:"name"`
+	Type          string   `json:"type,omitempty"`
+	EstimatedRows *int64   `json:"estimated_rows,omitempty"`
+	Comment       string   `json:"comment,omitempty"`
+	Columns       []string `json:"columns,omitempty"`
+
+	columns []ColumnInfo
+}
+
+// DictionaryElision reports what was removed from a data dictionary to fit a
+// token budget
+type DictionaryElision struct {
+	TokenBudget          int      `json:"token_budget"`
+	OriginalTokens       int      `json:"original_tokens"`
+	EstimatedTokens      int      `json:"estimated_tokens"`
+	TruncatedComments    int      `json:"truncated_comments,omitempty"`
+	TablesWithoutColumns []string `json:"tables_without_columns,omitempty"`
+}
+
+// DescribeDatabase summarizes every table and view in a database: estimated
+// row counts, table comments, columns with their types, keys and comments,
+// and foreign key relationships. It runs three information_schema queries
+// however many tables there are.
+func (m *Manager) DescribeDatabase(connectionName, database string) (*DataDictionary, error) {
+	tables, err := m.ExecuteQuery(connectionName, `SELECT TABLE_SCHEMA, TABLE_NAME, TABLE_TYPE, TABLE_ROWS, TABLE_COMMENT
+		FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = COALESCE(?, DATABASE())
+		ORDER BY TABLE_NAME`, nullIfEmpty(database))
+	if err != nil {
+		return nil, err
+	}
+	if len(tables.Rows) == 0 {
+		return m.emptyDictionary(connectionName, database)
+	}
+
+	dictionary := &DataDictionary{Database: stringValue(tables.Rows[0]["TABLE_SCHEMA"])}
+	byName := make(map[string]*DictionaryTable, len(tables.Rows))
+	dictionary.Tables = make([]DictionaryTable, len(tables.Rows))
+	for i, row := range tables.Rows {
+		t := &dictionary.Tables[i]
+		t.Name = stringValue(row["TABLE_NAME"])
+		if stringValue(row["TABLE_TYPE"]) == "VIEW" {
+			// Views report the comment "VIEW" and no row estimate
+			t.Type = "view"
+		} else {
+			t.EstimatedRows = int64Ptr(row["TABLE_ROWS"])
+			t.Comment = stringValue(row["TABLE_COMMENT"])
+		}
+		byName[t.Name] = t
+	}
+
+	columns, err := m.ExecuteQuery(connectionName, `SELECT TABLE_NAME, COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE,
+		COLUMN_KEY, EXTRA, COLUMN_COMMENT
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = ?
+		ORDER BY TABLE_NAME, ORDINAL_POSITION`, dictionary.Database)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range columns.Rows {
+		if t, ok := byName[stringValue(row["TABLE_NAME"])]; ok {
+			t.columns = append(t.columns, ColumnInfo{
+				Name:       stringValue(row["COLUMN_NAME"]),
+				ColumnType: stringValue(row["COLUMN_TYPE"]),
+				Nullable:   stringValue(row["IS_NULLABLE"]) == "YES",
+				Key:        stringValue(row["COLUMN_KEY"]),
+				Extra:      stringValue(row["EXTRA"]),
+				Comment:    stringValue(row["COLUMN_COMMENT"]),
+			})
+		}
+	}
+	for i := range dictionary.Tables {
+		dictionary.Tables[i].renderColumns(0)
+	}
+
+	if dictionary.Relationships, err = m.relationships(connectionName, dictionary.Database); err != nil {
+		return nil, err
+	}
+	return dictionary, nil
+}
+
+// emptyDictionary returns the dictionary of a database without tables, or an
+// error when the database does not exist
+func (m *Manager) emptyDictionary(connectionName, database string) (*DataDictionary, error) {
+	schema, err := m.ExecuteQuery(connectionName, `SELECT SCHEMA_NAME FROM information_schema.SCHEMATA
+		WHERE SCHEMA_NAME = COALESCE(?, DATABASE())`, nullIfEmpty(database))
+	if err != nil {
+		return nil, err
+	}
+	if len(schema.Rows) == 0 {
+		if database == "" {
+			return nil, fmt.Errorf("connection '%s' has no default database, pass database", connectionName)
+		}
+		return nil, fmt.Errorf("database not found: %s", database)
+	}
+	return &DataDictionary{Database: stringValue(schema.Rows[0]["SCHEMA_NAME"]), Tables: []DictionaryTable{}}, nil
+}
+
+// relationships lists the foreign keys of a database as
+// "table.column -> referenced_table.column", with multi-column keys as
+// "table.(a, b) -> referenced_table.(x, y)". Tables in other databases are
+// qualified with their database.
+func (m *Manager) relationships(connectionName, database string) ([]string, error) {
+	queryResult, err := m.ExecuteQuery(connectionName, `SELECT TABLE_NAME, CONSTRAINT_NAME, COLUMN_NAME,
+		REFERENCED_TABLE_SCHEMA, REFERENCED_TABLE_NAME, REFERENCED_COLUMN_NAME
+		FROM information_schema.KEY_COLUMN_USAGE
+		WHERE TABLE_SCHEMA = ? AND REFERENCED_TABLE_NAME IS NOT NULL
+		ORDER BY TABLE_NAME, CONSTRAINT_NAME, ORDINAL_POSITION`, database)
+	if err != nil {
+		return nil, err
+	}
+
+	type foreignKey struct {
+		table, referenced string
+		columns, targets  []string
+	}
+	var keys []*foreignKey
+	byConstraint := make(map[string]*foreignKey)
+	for _, row := range queryResult.Rows {
+		table := stringValue(row["TABLE_NAME"])
+		id := table + "\x00" + stringValue(row["CONSTRAINT_NAME"])
+		fk, ok := byConstraint[id]
+		if !ok {
+			referenced := stringValue(row["REFERENCED_TABLE_NAME"])
+			if schema := stringValue(row["REFERENCED_TABLE_SCHEMA"]); schema != database {
+				referenced = schema + "." + referenced
+			}
+			fk = &foreignKey{table: table, referenced: referenced}
+			byConstraint[id] = fk
+			keys = append(keys, fk)
+		}
+		fk.columns = append(fk.columns, stringValue(row["COLUMN_NAME"]))
+		fk.targets = append(fk.targets, stringValue(row["REFERENCED_COLUMN_NAME"]))
+	}
+
+	columnList := func(columns []string) string {
+		if len(columns) == 1 {
+			return columns[0]
+		}
+		return "(" + strings.Join(columns, ", ") + ")"
+	}
+	relationships := make([]string, len(keys))
+	for i, fk := range keys {
+		relationships[i] = fmt.Sprintf("%s.%s -> %s.%s", fk.table, columnList(fk.columns), fk.referenced, columnList(fk.targets))
+	}
+	return relationships, nil
+}
+
+// renderColumns renders the table's columns, cutting comments to
+// commentLimit characters when it is positive. It returns the number of
+// comments cut.
+func (t *DictionaryTable) renderColumns(commentLimit int) int {
+	truncated := 0
+	t.Columns = make([]string, len(t.columns))
+	for i, col := range t.columns {
+		parts := []string{col.Name, col.ColumnType}
+		switch col.Key {
+		case "PRI":
+			parts = append(parts, "PK")
+		case "UNI":
+			parts = append(parts, "UNIQUE")
+		}
+		if col.Nullable {
+			parts = append(parts, "NULL")
+		}
+		if strings.Contains(col.Extra, "auto_increment") {
+			parts = append(parts, "AUTO_INCREMENT")
+		}
+		if strings.Contains(col.Extra, "GENERATED") {
+			parts = append(parts, "GENERATED")
+		}
+
+		comment := col.Comment
+		if commentLimit > 0 && len([]rune(comment)) > commentLimit {
+			comment = string([]rune(comment)[:commentLimit]) + truncationMarker
+			truncated++
+		}
+		if comment != "" {
+			parts = append(parts, "--", comment)
+		}
+		t.Columns[i] = strings.Join(parts, " ")
+	}
+	return truncated
+}
+
+// FitTokenBudget shrinks the dictionary in place until estimate reports it
+// fits the budget: long comments are cut first, then the column lists of
+// tables are dropped starting from the last table. Every table stays listed by
+// name. It returns nil when the dictionary already fits.
+func (d *DataDictionary) FitTokenBudget(budget int, estimate func(*DataDictionary) int) *DictionaryElision {
+	original := estimate(d)
+	if original <= budget {
+		return nil
+	}
+	elision := &DictionaryElision{TokenBudget: budget, OriginalTokens: original}
+	d.Elided = elision
+
+	for i := range d.Tables {
+		elision.TruncatedComments += d.Tables[i].renderColumns(dictionaryCommentLimit)
+		if len([]rune(d.Tables[i].Comment)) > dictionaryCommentLimit {
+			d.Tables[i].Comment = string([]rune(d.Tables[i].Comment)[:dictionaryCommentLimit]) + truncationMarker
+			elision.TruncatedComments++
+		}
+	}
+
+	for i := len(d.Tables) - 1; i >= 0 && estimate(d) > budget; i-- {
+		if len(d.Tables[i].Columns) == 0 {
+			continue
+		}
+		d.Tables[i].Columns = nil
+		elision.TablesWithoutColumns = append([]string{d.Tables[i].Name}, elision.TablesWithoutColumns...)
+	}
+
+	elision.EstimatedTokens = estimate(d)
+	return elision
+}
@@ -0,0 +1,202 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DiffSide is one of the two queries compared by DiffQueries
+type DiffSide struct {
+	Connection string
+	Query      string
+}
+
+// ColumnChange holds the left and right values of a column that differs
+type ColumnChange struct {
+	Left  interface{} `json:"left"`
+	Right interface{} `json:"right"`
+}
+
+// ChangedRow is a row present on both sides whose non-key columns differ
+type ChangedRow struct {
+	Key     map[string]interface{}  `json:"key"`
+	Changes map[string]ColumnChange `json:"changes"`
+}
+
+// QueryDiff holds the row-level differences between two result sets
+type QueryDiff struct {
+	KeyColumns       []string                 `json:"key_columns"`
+	LeftCount        int                      `json:"left_count"`
+	RightCount       int                      `json:"right_count"`
+	Added            []map[string]interface{} `json:"added"`
+	Removed          []map[string]interface{} `json:"removed"`
+	Changed          []ChangedRow             `json:"changed"`
+	Unchanged        int                      `json:"unchanged"`
+	LeftOnlyColumns  []string                 `json:"left_only_columns,omitempty"`
+	RightOnlyColumns []string                 `json:"right_only_columns,omitempty"`
+	Truncated        bool                     `json:"truncated"`
+	Warning          string                   `json:"warning,omitempty"`
+}
+
+// DiffQueries runs two SELECTs (on the same or different connections) in
+// parallel, matches rows by the key columns, and reports rows added on the
+// right, removed from the left, and changed between them. Values are compared
+// by their rendered form, so a number scanned as int64 on one server and uint64
+// on another is not reported as a change.
+func (m *Manager) DiffQueries(left, right DiffSide, keyColumns []string) (*QueryDiff, error) {
+	if len(keyColumns) == 0 {
+		return nil, fmt.Errorf("at least one key column is required")
+	}
+
+	var leftResult, rightResult *QueryResult
+	var leftErr, rightErr error
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		leftResult, leftErr = m.ExecuteQuery(left.Connection, left.Query)
+	}()
+	go func() {
+		defer wg.Done()
+		rightResult, rightErr = m.ExecuteQuery(right.Connection, right.Query)
+	}()
+	wg.Wait()
+
+	if leftErr != nil {
+		return nil, fmt.Errorf("left query failed: %w", leftErr)
+	}
+	if rightErr != nil {
+		return nil, fmt.Errorf("right query failed: %w", rightErr)
+	}
+
+	for _, key := range keyColumns {
+		if !containsColumn(leftResult.Columns, key) {
+			return nil, fmt.Errorf("key column %s is not in the left result", key)
+		}
+		if !containsColumn(rightResult.Columns, key) {
+			return nil, fmt.Errorf("key column %s is not in the right result", key)
+		}
+	}
+
+	leftRows, err := indexRows(leftResult.Rows, keyColumns, "left")
+	if err != nil {
+		return nil, err
+	}
+	rightRows, err := indexRows(rightResult.Rows, keyColumns, "right")
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &QueryDiff{
+		KeyColumns: keyColumns,
+		LeftCount:  leftResult.Count,
+		RightCount: rightResult.Count,
+		Added:      []map[string]interface{}{},
+		Removed:    []map[string]interface{}{},
+		Changed:    []ChangedRow{},
+		Truncated:  leftResult.Truncated || rightResult.Truncated,
+	}
+	if diff.Truncated {
+		diff.Warning = "at least one result hit max_rows; rows beyond the limit were not compared, so added/removed rows may be spurious"
+	}
+
+	// Only columns present on both sides are compared
+	var shared []string
+	for _, col := range leftResult.Columns {
+		if containsColumn(rightResult.Columns, col) {
+			shared = append(shared, col)
+		} else {
+			diff.LeftOnlyColumns = append(diff.LeftOnlyColumns, col)
+		}
+	}
+	for _, col := range rightResult.Columns {
+		if !containsColumn(leftResult.Columns, col) {
+			diff.RightOnlyColumns = append(diff.RightOnlyColumns, col)
+		}
+	}
+
+	// Walk the left rows in result order so the output is stable
+	for _, row := range leftResult.Rows {
+		key := rowKey(row, keyColumns)
+		other, exists := rightRows[key]
+		if !exists {
+			diff.Removed = append(diff.Removed, row)
+			continue
+		}
+
+		changes := make(map[string]ColumnChange)
+		for _, col := range shared {
+			if !sameValue(row[col], other[col]) {
+				changes[col] = ColumnChange{Left: row[col], Right: other[col]}
+			}
+		}
+		if len(changes) == 0 {
+			diff.Unchanged++
+			continue
+		}
+		keyValues := make(map[string]interface{}, len(keyColumns))
+		for _, k := range keyColumns {
+			keyValues[k] = row[k]
+		}
+		diff.Changed = append(diff.Changed, ChangedRow{Key: keyValues, Changes: changes})
+	}
+
+	for _, row := range rightResult.Rows {
+		if _, exists := leftRows[rowKey(row, keyColumns)]; !exists {
+			diff.Added = append(diff.Added, row)
+		}
+	}
+
+	return diff, nil
+}
+
+// indexRows maps each row by its key, rejecting duplicate keys
+func indexRows(rows []map[string]interface{}, keyColumns []string, side string) (map[string]map[string]interface{}, error) {
+	index := make(map[string]map[string]interface{}, len(rows))
+	for _, row := range rows {
+		key := rowKey(row, keyColumns)
+		if _, exists := index[key]; exists {
+			return nil, fmt.Errorf("key (%s) is not unique in the %s result: duplicate value %s",
+				strings.Join(keyColumns, ", "), side, key)
+		}
+		index[key] = row
+	}
+	return index, nil
+}
+
+// rowKey renders a row's key column values as a single comparable string
+func rowKey(row map[string]interface{}, keyColumns []string) string {
+	parts := make([]string, len(keyColumns))
+	for i, col := range keyColumns {
+		parts[i] = renderValue(row[col])
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+// sameValue reports whether two scanned values render identically
+func sameValue(a, b interface{}) bool {
+	return renderValue(a) == renderValue(b)
+}
+
+// renderValue formats a scanned value for comparison, keeping NULL distinct
+// from the string "NULL"
+func renderValue(v interface{}) string {
+	if v == nil {
+		return "NULL"
+	}
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprint(v)
+}
+
+// containsColumn reports whether name is in columns
+func containsColumn(columns []string, name string) bool {
+	for _, col := range columns {
+		if col == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxDigests is the number of statement fingerprints tracked; when full, the
+// least recently seen fingerprint makes room for a new one
+const maxDigests = 500
+
+// TopQueryOrders lists the orderings TopQueries accepts
+var TopQueryOrders = []string{"total_time", "avg_time", "max_time", "calls", "errors", "rows"}
+
+// QueryDigest aggregates the statements sharing a fingerprint on a connection
+type QueryDigest struct {
+	Connection  string    `json:"connection"`
+	Fingerprint string    `json:"fingerprint"`
+	Calls       int64     `json:"calls"`
+	Errors      int64     `json:"errors,omitempty"`
+	CacheHits   int64     `json:"cache_hits,omitempty"`
+	TotalMs     float64   `json:"total_ms"`
+	AvgMs       float64   `json:"avg_ms"`
+	MaxMs       float64   `json:"max_ms"`
+	Rows        int64     `json:"rows"`
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+
+	total time.Duration
+	max   time.Duration
+}
+
+// TopQueriesReport is the response of TopQueries
+type TopQueriesReport struct {
+	Since        time.Time     `json:"since"`
+	OrderBy      string        `json:"order_by"`
+	Statements   int64         `json:"statements"`
+	Fingerprints int           `json:"fingerprints"`
+	Evicted      int64         `json:"evicted,omitempty"`
+	Queries      []QueryDigest `json:"queries"`
+	Reset        bool          `json:"reset,omitempty"`
+}
+
+// statementRun follows one statement for its trace span and query digest
+type statementRun struct {
+	span        trace.Span
+	connection  string
+	fingerprint string
+	start       time.Time
+	cached      bool
+}
+
+// startStatement starts tracing and timing a statement on a connection
+func (m *Manager) startStatement(ctx context.Context, connectionName, query string) *statementRun {
+	fingerprint := Fingerprint(query)
+	return &statementRun{
+		span:        startStatementSpan(ctx, connectionName, query, fingerprint),
+		connection:  connectionName,
+		fingerprint: fingerprint,
+		start:       time.Now(),
+	}
+}
+
+// endStatement ends a statement's span and adds it to its query digest
+func (m *Manager) endStatement(run *statementRun, rows int64, err error) {
+	endStatementSpan(run.span, rows, err)
+
+	elapsed := time.Since(run.start)
+	key := run.connection + "\x00" + run.fingerprint
+
+	m.digestsMu.Lock()
+	defer m.digestsMu.Unlock()
+
+	if m.digestsSince.IsZero() {
+		m.digestsSince = run.start
+	}
+	d, ok := m.digests[key]
+	if !ok {
+		if len(m.digests) >= maxDigests {
+			m.evictDigest()
+		}
+		d = &QueryDigest{Connection: run.connection, Fingerprint: run.fingerprint, FirstSeen: run.start}
+		m.digests[key] = d
+	}
+	d.Calls++
+	d.LastSeen = run.start
+	d.total += elapsed
+	if elapsed > d.max {
+		d.max = elapsed
+	}
+	if err != nil {
+		d.Errors++
+	} else {
+		d.Rows += rows
+	}
+	if run.cached {
+		d.CacheHits++
+	}
+}
+
+// evictDigest drops the least recently seen digest; digestsMu must be held
+func (m *Manager) evictDigest() {
+	var oldestKey string
+	var oldest time.Time
+	for key, d := range m.digests {
+		if oldestKey == "" || d.LastSeen.Before(oldest) {
+			oldestKey, oldest = key, d.LastSeen
+		}
+	}
+	delete(m.digests, oldestKey)
+	m.digestsEvicted++
+}
+
+// TopQueries reports the statement fingerprints run by this server, optionally
+// on one connection, ordered by one of TopQueryOrders (total_time when empty).
+// Cache hits count as calls. With reset, the digests are cleared after reading.
+func (m *Manager) TopQueries(connectionName, orderBy string, limit int, reset bool) (*TopQueriesReport, error) {
+	if connectionName != "" {
+		if _, ok := m.lookupConnection(connectionName); !ok {
+			return nil, fmt.Errorf("unknown connection: %s", connectionName)
+		}
+	}
+	if orderBy == "" {
+		orderBy = "total_time"
+	}
+	less, ok := map[string]func(a, b *QueryDigest) bool{
+		"total_time": func(a, b *QueryDigest) bool { return a.total > b.total },
+		"avg_time":   func(a, b *QueryDigest) bool { return a.AvgMs > b.AvgMs },
+		"max_time":   func(a, b *QueryDigest) bool { return a.max > b.max },
+		"calls":      func(a, b *QueryDigest) bool { return a.Calls > b.Calls },
+		"errors":     func(a, b *QueryDigest) bool { return a.Errors > b.Errors },
+		"rows":       func(a, b *QueryDigest) bool { return a.Rows > b.Rows },
+	}[orderBy]
+	if !ok {
+		return nil, fmt.Errorf("order_by must be one of total_time, avg_time, max_time, calls, errors, rows")
+	}
+
+	m.digestsMu.Lock()
+	report := &TopQueriesReport{Since: m.digestsSince, OrderBy: orderBy, Evicted: m.digestsEvicted, Reset: reset}
+	digests := make([]*QueryDigest, 0, len(m.digests))
+	for _, d := range m.digests {
+		if connectionName != "" && d.Connection != connectionName {
+			continue
+		}
+		copied := *d
+		copied.TotalMs = roundTo(float64(d.total)/float64(time.Millisecond), 2)
+		copied.AvgMs = roundTo(float64(d.total)/float64(d.Calls)/float64(time.Millisecond), 2)
+		copied.MaxMs = roundTo(float64(d.max)/float64(time.Millisecond), 2)
+		digests = append(digests, &copied)
+		report.Statements += d.Calls
+	}
+	if reset {
+		m.digests = make(map[string]*QueryDigest)
+		m.digestsSince = time.Time{}
+		m.digestsEvicted = 0
+	}
+	m.digestsMu.Unlock()
+
+	report.Fingerprints = len(digests)
+	sort.SliceStable(digests, func(i, j int) bool {
+		if less(digests[i], digests[j]) {
+			return true
+		}
+		if less(digests[j], digests[i]) {
+			return false
+		}
+		return digests[i].Fingerprint < digests[j].Fingerprint
+	})
+	if limit > 0 && len(digests) > limit {
+		digests = digests[:limit]
+	}
+	report.Queries = make([]QueryDigest, len(digests))
+	for i, d := range digests {
+		report.Queries[i] = *d
+	}
+	return report, nil
+}
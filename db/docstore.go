@@ -0,0 +1,220 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Document Store collections created through the X DevAPI are InnoDB tables
+// holding each document in a JSON doc column, with its _id extracted into a
+// generated primary key column. They are read here over the classic protocol
+// with JSON functions, so no X Protocol (port 33060) connection is needed.
+
+// DefaultDocumentLimit is the number of documents FindDocuments returns by default
+const DefaultDocumentLimit = 100
+
+// DocumentQuery describes a search of a Document Store collection. Filter
+// fields, order_by columns and projected fields are JSON paths into the
+// document, such as "address.city" or "$.tags[0]".
+type DocumentQuery struct {
+	Database   string
+	Collection string
+	Filters    []Filter
+	OrderBy    []OrderBy
+	Fields     []string
+	Limit      int
+	Offset     int
+}
+
+// DocumentResult holds the documents found in a collection, parsed as JSON
+type DocumentResult struct {
+	Collection  string        `json:"collection"`
+	Documents   []interface{} `json:"documents"`
+	Count       int           `json:"count"`
+	Truncated   bool          `json:"truncated"`
+	ExecutionMs int64         `json:"execution_ms"`
+	SQL         string        `json:"sql"`
+}
+
+// ListCollections returns the Document Store collections in a database with
+// their estimated document counts
+func (m *Manager) ListCollections(connectionName, database string) (*QueryResult, error) {
+	return m.ExecuteQuery(connectionName, `SELECT t.TABLE_NAME AS COLLECTION, t.TABLE_ROWS AS ESTIMATED_DOCUMENTS,
+		EXISTS (SELECT 1 FROM information_schema.COLUMNS s
+			WHERE s.TABLE_SCHEMA = t.TABLE_SCHEMA AND s.TABLE_NAME = t.TABLE_NAME
+			AND s.COLUMN_NAME = '_json_schema') AS HAS_SCHEMA
+		FROM information_schema.TABLES t
+		JOIN information_schema.COLUMNS d ON d.TABLE_SCHEMA = t.TABLE_SCHEMA AND d.TABLE_NAME = t.TABLE_NAME
+			AND d.COLUMN_NAME = 'doc' AND d.DATA_TYPE = 'json'
+		JOIN information_schema.COLUMNS i ON i.TABLE_SCHEMA = t.TABLE_SCHEMA AND i.TABLE_NAME = t.TABLE_NAME
+			AND i.COLUMN_NAME = '_id' AND i.GENERATION_EXPRESSION LIKE '%$._id%'
+		WHERE t.TABLE_SCHEMA = COALESCE(?, DATABASE()) AND t.TABLE_TYPE = 'BASE TABLE'
+		ORDER BY t.TABLE_NAME`, nullIfEmpty(database))
+}
+
+// BuildFindDocuments builds a parameterized SELECT of a collection's
+// documents. Comparisons are made between JSON values, so the string "1" does
+// not match the number 1 and true matches only a JSON boolean; LIKE compares
+// the unquoted text. IS NULL matches documents where the field is missing or
+// JSON null. With fields, each document is reduced to its _id and those paths.
+func BuildFindDocuments(q DocumentQuery) (string, []interface{}, error) {
+	if q.Collection == "" {
+		return "", nil, fmt.Errorf("collection is required")
+	}
+
+	var args []interface{}
+	projection := "doc"
+	if len(q.Fields) > 0 {
+		pairs := []string{"'_id', JSON_EXTRACT(doc, '$._id')"}
+		for _, field := range q.Fields {
+			path := NormalizeJSONPath(field)
+			if path == "" {
+				return "", nil, fmt.Errorf("fields must be non-empty JSON paths")
+			}
+			pairs = append(pairs, "?, JSON_EXTRACT(doc, ?)")
+			args = append(args, strings.TrimPrefix(strings.TrimPrefix(path, "$"), "."), path)
+		}
+		projection = "JSON_OBJECT(" + strings.Join(pairs, ", ") + ")"
+	}
+	query := fmt.Sprintf("SELECT %s AS doc FROM %s", projection, QualifiedName(q.Database, q.Collection))
+
+	conditions := make([]string, 0, len(q.Filters))
+	for _, f := range q.Filters {
+		path := NormalizeJSONPath(f.Field)
+		if path == "" {
+			return "", nil, fmt.Errorf("filter field is required")
+		}
+		op := strings.ToUpper(strings.TrimSpace(f.Op))
+		extract := "JSON_EXTRACT(doc, ?)"
+
+		switch op {
+		case "=", "!=", "<>", "<", "<=", ">", ">=":
+			if f.Value == nil {
+				return "", nil, fmt.Errorf("filter on '%s' with op %s requires a value (use IS NULL for nulls)", f.Field, op)
+			}
+			value, err := jsonArg(f.Value)
+			if err != nil {
+				return "", nil, err
+			}
+			conditions = append(conditions, fmt.Sprintf("%s %s CAST(? AS JSON)", extract, op))
+			args = append(args, path, value)
+		case "LIKE", "NOT LIKE":
+			if f.Value == nil {
+				return "", nil, fmt.Errorf("filter on '%s' with op %s requires a value", f.Field, op)
+			}
+			conditions = append(conditions, fmt.Sprintf("JSON_UNQUOTE(%s) %s ?", extract, op))
+			args = append(args, path, f.Value)
+		case "IN", "NOT IN":
+			values, ok := f.Value.([]interface{})
+			if !ok || len(values) == 0 {
+				return "", nil, fmt.Errorf("filter on '%s' with op %s requires a non-empty list value", f.Field, op)
+			}
+			// IN does not compare JSON values, so it is spelled out as ORed equalities
+			matches := make([]string, len(values))
+			for i, v := range values {
+				value, err := jsonArg(v)
+				if err != nil {
+					return "", nil, err
+				}
+				matches[i] = extract + " = CAST(? AS JSON)"
+				args = append(args, path, value)
+			}
+			condition := "(" + strings.Join(matches, " OR ") + ")"
+			if op == "NOT IN" {
+				condition = "NOT " + condition
+			}
+			conditions = append(conditions, condition)
+		case "IS NULL", "IS NOT NULL":
+			cmp := "="
+			if op == "IS NOT NULL" {
+				cmp = "<>"
+			}
+			conditions = append(conditions, fmt.Sprintf("COALESCE(JSON_TYPE(%s), 'NULL') %s 'NULL'", extract, cmp))
+			args = append(args, path)
+		case "BETWEEN":
+			values, ok := f.Value.([]interface{})
+			if !ok || len(values) != 2 {
+				return "", nil, fmt.Errorf("filter on '%s' with op BETWEEN requires a two-element list value", f.Field)
+			}
+			low, err := jsonArg(values[0])
+			if err != nil {
+				return "", nil, err
+			}
+			high, err := jsonArg(values[1])
+			if err != nil {
+				return "", nil, err
+			}
+			conditions = append(conditions, fmt.Sprintf("%s BETWEEN CAST(? AS JSON) AND CAST(? AS JSON)", extract))
+			args = append(args, path, low, high)
+		default:
+			return "", nil, fmt.Errorf("unsupported filter op '%s'; supported ops: %s", f.Op, strings.Join(FilterOps, ", "))
+		}
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	if len(q.OrderBy) > 0 {
+		terms := make([]string, len(q.OrderBy))
+		for i, o := range q.OrderBy {
+			terms[i] = "JSON_EXTRACT(doc, ?)"
+			if o.Desc {
+				terms[i] += " DESC"
+			}
+			args = append(args, NormalizeJSONPath(o.Column))
+		}
+		query += " ORDER BY " + strings.Join(terms, ", ")
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultDocumentLimit
+	}
+	query += fmt.Sprintf(" LIMIT %d", limit)
+	if q.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET %d", q.Offset)
+	}
+
+	return query, args, nil
+}
+
+// jsonArg renders a filter value as JSON text for CAST(? AS JSON)
+func jsonArg(v interface{}) (string, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("invalid filter value %v: %w", v, err)
+	}
+	return string(encoded), nil
+}
+
+// FindDocuments returns the documents of a collection matching a query,
+// parsed as JSON. The number of documents is capped by the connection's max_rows.
+func (m *Manager) FindDocuments(connectionName string, q DocumentQuery, opts QueryOptions) (*DocumentResult, error) {
+	query, args, err := BuildFindDocuments(q)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.MaxRows == 0 {
+		opts.MaxRows = q.Limit
+	}
+	queryResult, err := m.ExecuteQueryWithOptions(connectionName, query, opts, args...)
+	if err != nil {
+		return nil, err
+	}
+	queryResult.ParseJSONColumns()
+
+	result := &DocumentResult{
+		Collection:  q.Collection,
+		Documents:   make([]interface{}, len(queryResult.Rows)),
+		Count:       queryResult.Count,
+		Truncated:   queryResult.Truncated,
+		ExecutionMs: queryResult.ExecutionMs,
+		SQL:         query,
+	}
+	for i, row := range queryResult.Rows {
+		result.Documents[i] = row["doc"]
+	}
+	return result, nil
+}
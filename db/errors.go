@@ -0,0 +1,234 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// maxErrorHistory is the number of failed statements kept for explain_error
+const maxErrorHistory = 50
+
+// ErrorEntry records a statement that failed during execution
+type ErrorEntry struct {
+	Connection  string    `json:"connection"`
+	Query       string    `json:"query"`
+	ErrorNumber uint16    `json:"error_number,omitempty"`
+	Message     string    `json:"message"`
+	Time        time.Time `json:"time"`
+}
+
+// ErrorInfo describes a MySQL error and how to resolve it
+type ErrorInfo struct {
+	Number         uint16   `json:"number,omitempty"`
+	Name           string   `json:"name"`
+	Causes         []string `json:"causes"`
+	Variables      []string `json:"-"`
+	SuggestedTools []string `json:"suggested_next_steps"`
+}
+
+// ErrorExplanation is the structured response of ExplainError
+type ErrorExplanation struct {
+	ErrorInfo
+	Input             string                 `json:"input"`
+	AffectedStatement *ErrorEntry            `json:"affected_statement,omitempty"`
+	ServerVariables   map[string]interface{} `json:"server_variables,omitempty"`
+	VariablesError    string                 `json:"server_variables_error,omitempty"`
+}
+
+// errorKnowledge maps MySQL error numbers to causes, relevant variables, and next steps
+var errorKnowledge = map[uint16]ErrorInfo{
+	1040: {Name: "ER_CON_COUNT_ERROR (Too many connections)",
+		Causes:         []string{"The server reached max_connections", "Connection leaks in other clients holding idle connections"},
+		Variables:      []string{"max_connections", "wait_timeout"},
+		SuggestedTools: []string{"Retry after a short delay", "Ask a DBA to review max_connections and idle clients"}},
+	1044: {Name: "ER_DBACCESS_DENIED_ERROR (Access denied to database)",
+		Causes:         []string{"The configured user has no privileges on the requested database"},
+		SuggestedTools: []string{"list_databases to see which databases are accessible", "Use a connection configured for that database"}},
+	1045: {Name: "ER_ACCESS_DENIED_ERROR (Access denied for user)",
+		Causes:         []string{"Wrong password or user in the connection config", "The password was rotated and the server still holds the old one", "The user is not allowed to connect from this host"},
+		SuggestedTools: []string{"rotate_credentials to re-read secret references", "Verify the credentials in config.json"}},
+	1046: {Name: "ER_NO_DB_ERROR (No database selected)",
+		Causes:         []string{"The connection has no default database and the table was not schema-qualified"},
+		SuggestedTools: []string{"Qualify tables as database.table", "list_databases"}},
+	1048: {Name: "ER_BAD_NULL_ERROR (Column cannot be null)",
+		Causes:         []string{"A NULL value was supplied for a NOT NULL column"},
+		SuggestedTools: []string{"describe_table to check nullability and defaults"}},
+	1054: {Name: "ER_BAD_FIELD_ERROR (Unknown column)",
+		Causes:         []string{"Misspelled column name", "Column belongs to a different table or alias", "A string literal was written with double quotes under ANSI_QUOTES"},
+		SuggestedTools: []string{"describe_table to list the actual columns"}},
+	1055: {Name: "ER_WRONG_FIELD_WITH_GROUP (ONLY_FULL_GROUP_BY violation)",
+		Causes:         []string{"A selected column is neither aggregated nor in GROUP BY while ONLY_FULL_GROUP_BY is enabled"},
+		Variables:      []string{"sql_mode"},
+		SuggestedTools: []string{"Add the column to GROUP BY or wrap it in ANY_VALUE()/an aggregate"}},
+	1062: {Name: "ER_DUP_ENTRY (Duplicate entry)",
+		Causes:         []string{"The inserted or updated value violates a PRIMARY KEY or UNIQUE index"},
+		SuggestedTools: []string{"get_indexes to see unique constraints", "mysql_select to find the existing row", "Use INSERT ... ON DUPLICATE KEY UPDATE if an upsert was intended"}},
+	1064: {Name: "ER_PARSE_ERROR (SQL syntax error)",
+		Causes:         []string{"Invalid SQL syntax near the quoted position", "A reserved word used as an identifier without backticks", "Syntax not supported by this server version"},
+		SuggestedTools: []string{"Check the text right after 'near' in the message", "Quote identifiers with backticks"}},
+	1142: {Name: "ER_TABLEACCESS_DENIED_ERROR (Command denied)",
+		Causes:         []string{"The configured user lacks the privilege for this statement on the table"},
+		SuggestedTools: []string{"Use a connection with the required privileges"}},
+	1146: {Name: "ER_NO_SUCH_TABLE (Table doesn't exist)",
+		Causes:         []string{"Misspelled table name", "Wrong default database", "Case sensitivity (lower_case_table_names)"},
+		Variables:      []string{"lower_case_table_names"},
+		SuggestedTools: []string{"list_tables to see the actual table names", "Qualify the table as database.table"}},
+	1153: {Name: "ER_NET_PACKET_TOO_LARGE (Packet too large)",
+		Causes:         []string{"The statement or a single row exceeds max_allowed_packet"},
+		Variables:      []string{"max_allowed_packet"},
+		SuggestedTools: []string{"mysql_insert_rows, which sizes batches to max_allowed_packet", "Split the statement into smaller parts"}},
+	1205: {Name: "ER_LOCK_WAIT_TIMEOUT (Lock wait timeout exceeded)",
+		Causes:         []string{"Another transaction held a row lock longer than innodb_lock_wait_timeout", "A long-running or abandoned transaction"},
+		Variables:      []string{"innodb_lock_wait_timeout", "transaction_isolation"},
+		SuggestedTools: []string{"Retry the statement", "Narrow the WHERE clause so fewer rows are locked"}},
+	1213: {Name: "ER_LOCK_DEADLOCK (Deadlock found)",
+		Causes:         []string{"Two transactions acquired locks in opposite order", "Gap locks from range scans under REPEATABLE READ"},
+		Variables:      []string{"transaction_isolation", "innodb_deadlock_detect"},
+		SuggestedTools: []string{"Retry the statement; InnoDB rolled back this transaction", "Access rows in a consistent order"}},
+	1264: {Name: "ER_WARN_DATA_OUT_OF_RANGE (Out of range value)",
+		Causes:         []string{"A numeric value exceeds the column type's range"},
+		Variables:      []string{"sql_mode"},
+		SuggestedTools: []string{"describe_table to check the column type"}},
+	1265: {Name: "WARN_DATA_TRUNCATED (Data truncated)",
+		Causes:         []string{"A value does not fit the column type or is not a valid ENUM/SET member"},
+		Variables:      []string{"sql_mode"},
+		SuggestedTools: []string{"describe_table to check the column type and allowed values"}},
+	1364: {Name: "ER_NO_DEFAULT_FOR_FIELD (Field doesn't have a default value)",
+		Causes:         []string{"A NOT NULL column without a default was omitted from the INSERT under strict mode"},
+		Variables:      []string{"sql_mode"},
+		SuggestedTools: []string{"describe_table to find required columns"}},
+	1366: {Name: "ER_TRUNCATED_WRONG_VALUE_FOR_FIELD (Incorrect value)",
+		Causes:         []string{"A value has the wrong type for the column", "Characters not representable in the column's character set (e.g. emoji in utf8mb3)"},
+		Variables:      []string{"sql_mode", "character_set_connection"},
+		SuggestedTools: []string{"describe_table to check the column type and charset"}},
+	1406: {Name: "ER_DATA_TOO_LONG (Data too long for column)",
+		Causes:         []string{"A string exceeds the column's declared length"},
+		Variables:      []string{"sql_mode"},
+		SuggestedTools: []string{"describe_table to check the column length"}},
+	1451: {Name: "ER_ROW_IS_REFERENCED_2 (Cannot delete or update a parent row)",
+		Causes:         []string{"Child rows reference this row through a foreign key"},
+		SuggestedTools: []string{"mysql_select on the child table to find referencing rows"}},
+	1452: {Name: "ER_NO_REFERENCED_ROW_2 (Cannot add or update a child row)",
+		Causes:         []string{"The referenced parent row does not exist"},
+		SuggestedTools: []string{"mysql_select on the parent table to verify the key exists"}},
+	1792: {Name: "ER_CANT_EXECUTE_IN_READ_ONLY_TRANSACTION (Cannot execute statement in a READ ONLY transaction)",
+		Causes:         []string{"The connection is read_only, so its sessions run every transaction read-only", "The statement writes to a table even though it was classified as a read"},
+		SuggestedTools: []string{"list_connections to find a connection that allows writes"}},
+	2006: {Name: "CR_SERVER_GONE_ERROR (MySQL server has gone away)",
+		Causes:         []string{"The connection was idle longer than wait_timeout", "The server restarted or failed over", "A packet exceeded max_allowed_packet"},
+		Variables:      []string{"wait_timeout", "interactive_timeout", "max_allowed_packet"},
+		SuggestedTools: []string{"reset_connection to rebuild the pool", "Retry the statement"}},
+	2013: {Name: "CR_SERVER_LOST (Lost connection during query)",
+		Causes:         []string{"The query ran longer than net_read_timeout or the client read timeout", "The server was restarted or killed the connection"},
+		Variables:      []string{"net_read_timeout", "net_write_timeout", "wait_timeout"},
+		SuggestedTools: []string{"reset_connection to rebuild the pool", "Add a LIMIT or narrower filter to shorten the query"}},
+	3024: {Name: "ER_QUERY_TIMEOUT (Maximum statement execution time exceeded)",
+		Causes:         []string{"The SELECT ran longer than max_execution_time"},
+		Variables:      []string{"max_execution_time"},
+		SuggestedTools: []string{"Add indexes or narrower filters", "Use the analyze_slow_query prompt"}},
+}
+
+// errorNumberPattern extracts the error number from a driver error message
+var errorNumberPattern = regexp.MustCompile(`(?i)Error\s+(\d{4})`)
+
+// recordError stores a failed statement in the bounded error history
+func (m *Manager) recordError(connectionName, query string, err error) {
+	entry := ErrorEntry{
+		Connection: connectionName,
+		Query:      query,
+		Message:    err.Error(),
+		Time:       time.Now(),
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		entry.ErrorNumber = mysqlErr.Number
+	} else if errors.Is(err, mysql.ErrInvalidConn) {
+		entry.ErrorNumber = 2006
+	}
+
+	slog.Warn("query failed", "connection", connectionName, "error_number", entry.ErrorNumber, "error", entry.Message, "sql", query)
+
+	m.historyMu.Lock()
+	defer m.historyMu.Unlock()
+
+	m.errorHistory = append(m.errorHistory, entry)
+	if len(m.errorHistory) > maxErrorHistory {
+		m.errorHistory = m.errorHistory[len(m.errorHistory)-maxErrorHistory:]
+	}
+}
+
+// ExplainError returns structured causes, the affected statement from history,
+// relevant server variables, and suggested next steps for a MySQL error.
+// The input may be an error number or a full error message.
+func (m *Manager) ExplainError(connectionName, input string) (*ErrorExplanation, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, fmt.Errorf("error is required")
+	}
+
+	var number uint16
+	if n, err := strconv.ParseUint(input, 10, 16); err == nil {
+		number = uint16(n)
+	} else if match := errorNumberPattern.FindStringSubmatch(input); match != nil {
+		n, _ := strconv.ParseUint(match[1], 10, 16)
+		number = uint16(n)
+	} else if strings.Contains(input, "invalid connection") || strings.Contains(input, "bad connection") {
+		number = 2006
+	}
+
+	explanation := &ErrorExplanation{Input: input}
+	if info, ok := errorKnowledge[number]; ok {
+		explanation.ErrorInfo = info
+		explanation.Number = number
+	} else {
+		explanation.ErrorInfo = ErrorInfo{
+			Number:         number,
+			Name:           "Unrecognized error",
+			Causes:         []string{"This error is not in the server's knowledge base"},
+			SuggestedTools: []string{"Check the MySQL error reference for this error number"},
+		}
+	}
+
+	// Find the most recent matching statement in history
+	m.historyMu.Lock()
+	for i := len(m.errorHistory) - 1; i >= 0; i-- {
+		entry := m.errorHistory[i]
+		if connectionName != "" && entry.Connection != connectionName {
+			continue
+		}
+		if (number != 0 && entry.ErrorNumber == number) || strings.Contains(entry.Message, input) {
+			explanation.AffectedStatement = &entry
+			break
+		}
+	}
+	m.historyMu.Unlock()
+
+	if connectionName == "" && explanation.AffectedStatement != nil {
+		connectionName = explanation.AffectedStatement.Connection
+	}
+
+	// Fetch the server variables relevant to this error
+	if connectionName != "" && len(explanation.Variables) > 0 {
+		selects := make([]string, len(explanation.Variables))
+		for i, v := range explanation.Variables {
+			selects[i] = fmt.Sprintf("@@%s AS %s", v, v)
+		}
+
+		vars, err := m.ExecuteQuery(connectionName, "SELECT "+strings.Join(selects, ", "))
+		if err != nil {
+			explanation.VariablesError = err.Error()
+		} else if len(vars.Rows) > 0 {
+			explanation.ServerVariables = vars.Rows[0]
+		}
+	}
+
+	return explanation, nil
+}
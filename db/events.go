@@ -0,0 +1,74 @@
+package db
+
+import (
+	"fmt"
+)
+
+// ListEvents returns the scheduled events in a database and whether the
+// event scheduler is running; events only fire while it is ON
+func (m *Manager) ListEvents(connectionName, database string) (map[string]interface{}, error) {
+	events, err := m.ExecuteQuery(connectionName, `SELECT EVENT_NAME, STATUS, EVENT_TYPE, EXECUTE_AT,
+		INTERVAL_VALUE, INTERVAL_FIELD, STARTS, ENDS, LAST_EXECUTED, ON_COMPLETION, DEFINER
+		FROM information_schema.EVENTS
+		WHERE EVENT_SCHEMA = COALESCE(?, DATABASE())
+		ORDER BY EVENT_NAME`, nullIfEmpty(database))
+	if err != nil {
+		return nil, err
+	}
+
+	scheduler, err := m.eventSchedulerState(connectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"event_scheduler": scheduler,
+		"events":          events.Rows,
+	}, nil
+}
+
+// DescribeEvent returns an event's schedule, body, and SHOW CREATE EVENT statement
+func (m *Manager) DescribeEvent(connectionName, database, name string) (map[string]interface{}, error) {
+	event, err := m.ExecuteQuery(connectionName, `SELECT EVENT_NAME, STATUS, EVENT_TYPE, EXECUTE_AT,
+		INTERVAL_VALUE, INTERVAL_FIELD, STARTS, ENDS, LAST_EXECUTED, ON_COMPLETION,
+		EVENT_DEFINITION, DEFINER, TIME_ZONE, SQL_MODE, CREATED, LAST_ALTERED, EVENT_COMMENT
+		FROM information_schema.EVENTS
+		WHERE EVENT_SCHEMA = COALESCE(?, DATABASE()) AND EVENT_NAME = ?`, nullIfEmpty(database), name)
+	if err != nil {
+		return nil, err
+	}
+	if len(event.Rows) == 0 {
+		return nil, fmt.Errorf("event not found: %s", name)
+	}
+
+	create, err := m.ExecuteQuery(connectionName, "SHOW CREATE EVENT "+QualifiedName(database, name))
+	if err != nil {
+		return nil, err
+	}
+	if len(create.Rows) == 0 {
+		return nil, fmt.Errorf("event not found: %s", name)
+	}
+
+	scheduler, err := m.eventSchedulerState(connectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"event":            event.Rows[0],
+		"create_statement": create.Rows[0]["Create Event"],
+		"event_scheduler":  scheduler,
+	}, nil
+}
+
+// eventSchedulerState returns @@event_scheduler: ON, OFF, or DISABLED
+func (m *Manager) eventSchedulerState(connectionName string) (string, error) {
+	queryResult, err := m.ExecuteQuery(connectionName, "SELECT @@GLOBAL.event_scheduler AS event_scheduler")
+	if err != nil {
+		return "", err
+	}
+	if len(queryResult.Rows) == 0 {
+		return "", nil
+	}
+	return stringValue(queryResult.Rows[0]["event_scheduler"]), nil
+}
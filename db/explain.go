@@ -0,0 +1,266 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ExplainFormats lists the renderings of mysql_explain plans
+var ExplainFormats = []string{"json", "tree", "mermaid"}
+
+// ExplainPlan holds the EXPLAIN FORMAT=JSON plan of a SELECT
+type ExplainPlan struct {
+	Connection  string      `json:"connection"`
+	Database    string      `json:"database,omitempty"`
+	Plan        interface{} `json:"plan"`
+	ExecutionMs int64       `json:"execution_ms"`
+}
+
+// planNode is one step of a plan as the tree and Mermaid renderings show it
+type planNode struct {
+	label    string
+	details  []string
+	fullScan bool
+	children []*planNode
+}
+
+// operationLabels name the plan's operation nodes, from MySQL and MariaDB
+var operationLabels = map[string]string{
+	"ordering_operation":         "ORDER BY",
+	"grouping_operation":         "GROUP BY",
+	"duplicates_removal":         "DISTINCT",
+	"windowing":                  "WINDOW",
+	"union_result":               "UNION",
+	"nested_loop":                "nested loop",
+	"filesort":                   "filesort",
+	"temporary_table":            "temporary table",
+	"read_sorted_file":           "read sorted file",
+	"having_condition":           "HAVING",
+	"materialized_from_subquery": "materialized subquery",
+	"attached_subqueries":        "subqueries",
+}
+
+// planDetailKeys are plan fields that describe their node rather than hold steps
+var planDetailKeys = map[string]bool{
+	"cost_info": true, "used_columns": true, "possible_keys": true, "used_key_parts": true,
+	"ref": true, "r_loops": true, "message": true,
+}
+
+// mermaidEscaper escapes the characters Mermaid labels cannot hold as is,
+// such as the angle brackets of <derived2>
+var mermaidEscaper = strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;")
+
+// planConditionLimit caps the length of conditions shown in rendered plans
+const planConditionLimit = 80
+
+// Explain returns the EXPLAIN FORMAT=JSON plan of a SELECT without running it.
+// Unqualified tables are resolved in database, or the connection's default
+// database when it is empty.
+func (m *Manager) Explain(connectionName, database, query string) (*ExplainPlan, error) {
+	if err := ValidateQueryType(query, QueryTypeSelect); err != nil {
+		return nil, err
+	}
+	result, err := m.ExecuteQueryWithOptions(connectionName, "EXPLAIN FORMAT=JSON "+query, QueryOptions{Database: database})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Rows) == 0 || len(result.Columns) == 0 {
+		return nil, fmt.Errorf("EXPLAIN returned no plan")
+	}
+	text, ok := result.Rows[0][result.Columns[0]].(string)
+	if !ok {
+		return nil, fmt.Errorf("EXPLAIN returned no plan")
+	}
+	var plan interface{}
+	if err := json.Unmarshal([]byte(text), &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse EXPLAIN output: %w", err)
+	}
+	return &ExplainPlan{Connection: connectionName, Database: result.Database, Plan: plan, ExecutionMs: result.ExecutionMs}, nil
+}
+
+// Tree renders the plan as an indented ASCII tree, one step per line
+func (p *ExplainPlan) Tree() string {
+	var b strings.Builder
+	for _, root := range planNodes("", p.Plan) {
+		writeTree(&b, root, "", "")
+	}
+	return b.String()
+}
+
+func writeTree(b *strings.Builder, node *planNode, prefix, childPrefix string) {
+	b.WriteString(prefix + node.label)
+	if len(node.details) > 0 {
+		b.WriteString(" (" + strings.Join(node.details, ", ") + ")")
+	}
+	b.WriteString("\n")
+	for i, child := range node.children {
+		if i == len(node.children)-1 {
+			writeTree(b, child, childPrefix+"`- ", childPrefix+"   ")
+		} else {
+			writeTree(b, child, childPrefix+"|- ", childPrefix+"|  ")
+		}
+	}
+}
+
+// Mermaid renders the plan as a Mermaid flowchart, with full table scans
+// highlighted
+func (p *ExplainPlan) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+	var scans []string
+	next := 0
+	var walk func(node *planNode) string
+	walk = func(node *planNode) string {
+		id := "n" + strconv.Itoa(next)
+		next++
+		lines := append([]string{node.label}, node.details...)
+		for i, line := range lines {
+			lines[i] = mermaidEscaper.Replace(line)
+		}
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", id, strings.Join(lines, "<br/>"))
+		if node.fullScan {
+			scans = append(scans, id)
+		}
+		for _, child := range node.children {
+			fmt.Fprintf(&b, "  %s --> %s\n", id, walk(child))
+		}
+		return id
+	}
+	for _, root := range planNodes("", p.Plan) {
+		walk(root)
+	}
+	if len(scans) > 0 {
+		b.WriteString("  classDef fullScan stroke:#d33,stroke-width:2px\n")
+		fmt.Fprintf(&b, "  class %s fullScan\n", strings.Join(scans, ","))
+	}
+	return b.String()
+}
+
+// planNodes converts the plan value found under key into the steps it holds
+func planNodes(key string, value interface{}) []*planNode {
+	switch v := value.(type) {
+	case []interface{}:
+		// Array items wrap their step, as in {"table": {...}}
+		var nodes []*planNode
+		for _, item := range v {
+			if m, ok := item.(map[string]interface{}); ok {
+				nodes = append(nodes, childNodes(m)...)
+			}
+		}
+		if label := operationLabels[key]; label != "" && len(nodes) > 1 {
+			return []*planNode{{label: label, children: nodes}}
+		}
+		return nodes
+	case map[string]interface{}:
+		node := &planNode{}
+		switch {
+		case v["table_name"] != nil && key != "union_result":
+			node.label = "table " + fmt.Sprint(v["table_name"])
+			node.details, node.fullScan = tableDetails(v)
+		case v["select_id"] != nil:
+			node.label = "select #" + fmt.Sprint(v["select_id"])
+			if cost, ok := v["cost_info"].(map[string]interface{}); ok && cost["query_cost"] != nil {
+				node.details = append(node.details, "cost "+fmt.Sprint(cost["query_cost"]))
+			}
+		case key == "" || key == "query_block":
+			// The document root, and a UNION's query block, only wrap steps
+			return childNodes(v)
+		default:
+			if node.label = operationLabels[key]; node.label == "" {
+				node.label = strings.ReplaceAll(key, "_", " ")
+			}
+			node.details = operationDetails(v)
+		}
+		node.children = childNodes(v)
+		return []*planNode{node}
+	}
+	return nil
+}
+
+// childNodes returns the steps nested in a plan object, in field order
+func childNodes(v map[string]interface{}) []*planNode {
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var nodes []*planNode
+	for _, k := range keys {
+		if planDetailKeys[k] {
+			continue
+		}
+		switch child := v[k].(type) {
+		case map[string]interface{}:
+			nodes = append(nodes, planNodes(k, child)...)
+		case []interface{}:
+			if len(child) > 0 {
+				if _, ok := child[0].(map[string]interface{}); ok {
+					nodes = append(nodes, planNodes(k, child)...)
+				}
+			}
+		}
+	}
+	return nodes
+}
+
+// tableDetails describes a table access: how it is read, with which key,
+// the estimated rows, and its flags and condition
+func tableDetails(v map[string]interface{}) ([]string, bool) {
+	var details []string
+	access, _ := v["access_type"].(string)
+	if access != "" {
+		if key, ok := v["key"].(string); ok && key != "" {
+			details = append(details, access+" on "+key)
+		} else {
+			details = append(details, access)
+		}
+	}
+	fullScan := access == "ALL"
+	if fullScan {
+		details = append(details, "full table scan")
+	}
+	// MySQL reports rows_examined_per_scan; MariaDB reports rows
+	rows := v["rows_examined_per_scan"]
+	if rows == nil {
+		rows = v["rows"]
+	}
+	if rows != nil {
+		details = append(details, "~"+fmt.Sprint(rows)+" rows")
+	}
+	if filtered := v["filtered"]; filtered != nil && fmt.Sprint(filtered) != "100" && fmt.Sprint(filtered) != "100.00" {
+		details = append(details, "filtered "+fmt.Sprint(filtered)+"%")
+	}
+	details = append(details, operationDetails(v)...)
+	if condition, ok := v["attached_condition"].(string); ok {
+		details = append(details, "where "+truncateCondition(condition))
+	}
+	return details, fullScan
+}
+
+// operationDetails lists the flags set on a plan node
+func operationDetails(v map[string]interface{}) []string {
+	var details []string
+	for _, flag := range []string{"using_index", "using_index_for_group_by", "using_temporary_table", "using_filesort", "using_join_buffer"} {
+		switch value := v[flag].(type) {
+		case bool:
+			if value {
+				details = append(details, strings.ReplaceAll(flag, "_", " "))
+			}
+		case string:
+			details = append(details, strings.ReplaceAll(flag, "_", " ")+" "+value)
+		}
+	}
+	return details
+}
+
+// truncateCondition shortens a condition to planConditionLimit characters
+func truncateCondition(condition string) string {
+	if runes := []rune(condition); len(runes) > planConditionLimit {
+		return string(runes[:planConditionLimit]) + "…"
+	}
+	return condition
+}
@@ -0,0 +1,382 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	fakeFirstNames = []string{"Olivia", "Liam", "Emma", "Noah", "Ava", "Oliver", "Sophia", "Elijah", "Mia", "James",
+		"Amelia", "Lucas", "Harper", "Mateo", "Aria", "Arjun", "Yuki", "Chen", "Fatima", "Kwame"}
+	fakeLastNames = []string{"Smith", "Johnson", "Williams", "Brown", "Garcia", "Miller", "Davis", "Martinez", "Lopez", "Wilson",
+		"Anderson", "Taylor", "Thomas", "Moore", "Patel", "Kim", "Nguyen", "Okafor", "Schmidt", "Rossi"}
+	fakeCompanies = []string{"Acme", "Globex", "Initech", "Umbrella", "Stark Industries", "Wayne Enterprises", "Hooli",
+		"Vandelay Industries", "Soylent", "Wonka", "Cyberdyne", "Tyrell"}
+	fakeCompanySuffixes = []string{"Inc", "LLC", "Ltd", "Group", "Co"}
+	fakeStreets         = []string{"Main St", "Oak Ave", "Maple Dr", "Cedar Ln", "Park Rd", "Elm St", "Lakeview Blvd", "Hill St", "River Rd", "Station Rd"}
+	fakeCities          = []string{"Springfield", "Riverside", "Franklin", "Greenville", "Bristol", "Clinton", "Fairview", "Salem", "Madison", "Georgetown",
+		"Ashland", "Oxford", "Arlington", "Burlington", "Manchester"}
+	fakeStates    = []string{"California", "Texas", "New York", "Ontario", "Bavaria", "Queensland", "Lombardy", "Catalonia", "Scotland", "Ohio"}
+	fakeCountries = []string{"United States", "Canada", "United Kingdom", "Germany", "France", "Australia", "Japan", "India", "Brazil", "Spain"}
+	// fakeCountryCodes are the ISO 3166-1 alpha-2 codes of fakeCountries
+	fakeCountryCodes = []string{"US", "CA", "GB", "DE", "FR", "AU", "JP", "IN", "BR", "ES"}
+	fakeCurrencies   = []string{"USD", "EUR", "GBP", "JPY", "CAD", "AUD"}
+	fakeStatuses     = []string{"active", "inactive", "pending", "archived"}
+	// fakeDomains are reserved for documentation (RFC 2606), so generated
+	// addresses can never reach anyone
+	fakeDomains = []string{"example.com", "example.org", "example.net"}
+	fakeWords   = []string{"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "do",
+		"eiusmod", "tempor", "incididunt", "ut", "labore", "et", "dolore", "magna", "aliqua", "enim",
+		"minim", "veniam", "quis", "nostrud", "exercitation", "ullamco", "laboris", "nisi", "aliquip", "commodo"}
+)
+
+// fakeTextRule generates a string for columns named like one of names
+type fakeTextRule struct {
+	names []string
+	value func(f *fakeData, col ColumnInfo) string
+}
+
+// fakeTextRules are tried in order, so more specific names come first
+var fakeTextRules = []fakeTextRule{
+	{[]string{"email", "email_address", "mail"}, func(f *fakeData, col ColumnInfo) string {
+		return strings.ToLower(f.pick(fakeFirstNames)+"."+f.pick(fakeLastNames)) + "@" + f.pick(fakeDomains)
+	}},
+	{[]string{"first_name", "firstname", "given_name", "forename"}, func(f *fakeData, col ColumnInfo) string { return f.pick(fakeFirstNames) }},
+	{[]string{"last_name", "lastname", "surname", "family_name"}, func(f *fakeData, col ColumnInfo) string { return f.pick(fakeLastNames) }},
+	{[]string{"username", "user_name", "login", "handle", "nickname"}, func(f *fakeData, col ColumnInfo) string {
+		return strings.ToLower(f.pick(fakeFirstNames)) + strconv.Itoa(f.rng.Intn(1000))
+	}},
+	{[]string{"company", "company_name", "organization", "organisation", "employer"}, func(f *fakeData, col ColumnInfo) string {
+		return f.pick(fakeCompanies) + " " + f.pick(fakeCompanySuffixes)
+	}},
+	{[]string{"name", "full_name", "fullname", "display_name"}, func(f *fakeData, col ColumnInfo) string {
+		return f.pick(fakeFirstNames) + " " + f.pick(fakeLastNames)
+	}},
+	{[]string{"phone", "mobile", "telephone", "phone_number", "fax"}, func(f *fakeData, col ColumnInfo) string {
+		return fmt.Sprintf("+1-555-%03d-%04d", f.rng.Intn(1000), f.rng.Intn(10000))
+	}},
+	{[]string{"url", "website", "homepage", "link"}, func(f *fakeData, col ColumnInfo) string {
+		return "https://www." + f.pick(fakeDomains) + "/" + f.pick(fakeWords)
+	}},
+	{[]string{"ip", "ip_address", "ipaddress"}, func(f *fakeData, col ColumnInfo) string {
+		// 192.0.2.0/24 is reserved for documentation (RFC 5737)
+		return fmt.Sprintf("192.0.2.%d", 1+f.rng.Intn(254))
+	}},
+	{[]string{"address", "street", "street_address", "address1", "address_line1", "line1"}, func(f *fakeData, col ColumnInfo) string {
+		return strconv.Itoa(1+f.rng.Intn(9999)) + " " + f.pick(fakeStreets)
+	}},
+	{[]string{"city", "town"}, func(f *fakeData, col ColumnInfo) string { return f.pick(fakeCities) }},
+	{[]string{"state", "province", "region"}, func(f *fakeData, col ColumnInfo) string { return f.pick(fakeStates) }},
+	{[]string{"zip", "zipcode", "zip_code", "postal_code", "postcode"}, func(f *fakeData, col ColumnInfo) string {
+		return fmt.Sprintf("%05d", f.rng.Intn(100000))
+	}},
+	{[]string{"country", "country_code"}, func(f *fakeData, col ColumnInfo) string {
+		if col.MaxLength != nil && *col.MaxLength <= 3 {
+			return f.pick(fakeCountryCodes)
+		}
+		return f.pick(fakeCountries)
+	}},
+	{[]string{"currency", "currency_code"}, func(f *fakeData, col ColumnInfo) string { return f.pick(fakeCurrencies) }},
+	{[]string{"status"}, func(f *fakeData, col ColumnInfo) string { return f.pick(fakeStatuses) }},
+	{[]string{"slug"}, func(f *fakeData, col ColumnInfo) string {
+		return strings.ReplaceAll(f.words(2+f.rng.Intn(3)), " ", "-")
+	}},
+	{[]string{"password", "password_hash", "token", "secret", "api_key"}, func(f *fakeData, col ColumnInfo) string { return f.hex(32) }},
+	{[]string{"sku", "code"}, func(f *fakeData, col ColumnInfo) string { return fmt.Sprintf("SKU-%06d", f.rng.Intn(1000000)) }},
+	{[]string{"title", "subject", "headline", "label"}, func(f *fakeData, col ColumnInfo) string { return capitalize(f.words(3 + f.rng.Intn(3))) }},
+	{[]string{"description", "comment", "comments", "body", "notes", "note", "content", "message", "bio", "summary", "text"}, func(f *fakeData, col ColumnInfo) string {
+		return capitalize(f.words(8+f.rng.Intn(8))) + "."
+	}},
+}
+
+// fakeNumberRule bounds the numbers generated for columns named like one of names
+type fakeNumberRule struct {
+	names  []string
+	lo, hi float64
+}
+
+var fakeNumberRules = []fakeNumberRule{
+	{[]string{"age"}, 18, 90},
+	{[]string{"price", "amount", "total", "subtotal", "cost", "balance", "salary", "fee"}, 1, 1000},
+	{[]string{"quantity", "qty", "count", "stock", "inventory"}, 1, 100},
+	{[]string{"rating", "score", "stars"}, 1, 5},
+	{[]string{"percent", "percentage", "discount"}, 0, 100},
+	{[]string{"lat", "latitude"}, -90, 90},
+	{[]string{"lng", "lon", "longitude"}, -180, 180},
+	{[]string{"year"}, 1990, 2030},
+}
+
+// birthDateNames are column names given dates of birth rather than recent dates
+var birthDateNames = []string{"birth", "birthday", "birthdate", "dob", "date_of_birth"}
+
+// fakeData generates column values for generate_test_data from a seeded
+// source, so a seed reproduces the same values
+type fakeData struct {
+	rng *rand.Rand
+	now time.Time
+}
+
+func newFakeData(seed int64) *fakeData {
+	return &fakeData{rng: rand.New(rand.NewSource(seed)), now: time.Now()}
+}
+
+// value generates a value for a column that fits its type, shaped by its
+// name where the name says what it holds
+func (f *fakeData) value(col ColumnInfo) (interface{}, error) {
+	switch col.DataType {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint":
+		if strings.HasPrefix(col.ColumnType, "tinyint(1)") {
+			return f.rng.Intn(2), nil
+		}
+		lo, hi := integerRange(col)
+		if rule, ok := numberRule(col.Name); ok && int64(rule.lo) >= lo && int64(rule.hi) <= hi {
+			lo, hi = int64(rule.lo), int64(rule.hi)
+		} else {
+			lo, hi = max(lo, 1), min(hi, 10000)
+		}
+		return lo + f.rng.Int63n(hi-lo+1), nil
+	case "decimal", "numeric":
+		scale := 0
+		if col.NumericScale != nil {
+			scale = int(*col.NumericScale)
+		}
+		limit := 1000.0
+		if col.NumericPrecision != nil {
+			limit = math.Pow10(int(*col.NumericPrecision)-scale) - math.Pow10(-scale)
+		}
+		return strconv.FormatFloat(f.number(col, limit), 'f', scale, 64), nil
+	case "float", "double", "real":
+		return roundTo(f.number(col, math.MaxFloat64), 2), nil
+	case "date":
+		return f.date(col).Format("2006-01-02"), nil
+	case "datetime", "timestamp":
+		return f.date(col).Format("2006-01-02 15:04:05"), nil
+	case "time":
+		return fmt.Sprintf("%02d:%02d:%02d", f.rng.Intn(24), f.rng.Intn(60), f.rng.Intn(60)), nil
+	case "year":
+		return 1990 + f.rng.Intn(41), nil
+	case "char", "varchar", "tinytext", "text", "mediumtext", "longtext":
+		return truncateRunes(f.text(col), maxLength(col)), nil
+	case "enum", "set":
+		values := enumValues(col.ColumnType)
+		if len(values) == 0 {
+			return nil, fmt.Errorf("column %s has no values in %s", col.Name, col.ColumnType)
+		}
+		return f.pick(values), nil
+	case "json":
+		doc, _ := json.Marshal(map[string]interface{}{"note": f.words(3), "score": f.rng.Intn(100)})
+		return string(doc), nil
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob":
+		size := 16
+		if n := maxLength(col); n > 0 {
+			size = min(size, n)
+		}
+		b := make([]byte, size)
+		f.rng.Read(b)
+		return b, nil
+	case "bit":
+		bits := 1
+		if col.NumericPrecision != nil {
+			bits = int(*col.NumericPrecision)
+		}
+		return f.rng.Uint64() >> (64 - min(bits, 64)), nil
+	}
+	return nil, fmt.Errorf("cannot generate values for column %s of type %s", col.Name, col.ColumnType)
+}
+
+// text generates a string for a character column
+func (f *fakeData) text(col ColumnInfo) string {
+	if isUUIDColumn(col) {
+		return f.uuid()
+	}
+	for _, rule := range fakeTextRules {
+		if matchesColumnName(col.Name, rule.names) {
+			return rule.value(f, col)
+		}
+	}
+	if n := maxLength(col); n > 0 && n <= 3 {
+		letters := make([]byte, n)
+		for i := range letters {
+			letters[i] = byte('A' + f.rng.Intn(26))
+		}
+		return string(letters)
+	}
+	if strings.HasSuffix(col.DataType, "text") {
+		return capitalize(f.words(10+f.rng.Intn(20))) + "."
+	}
+	return f.words(1 + f.rng.Intn(4))
+}
+
+// number generates a number for a column up to limit: within the bounds of
+// a rule for the column's name when the column can hold them, otherwise
+// between 0 and 1000
+func (f *fakeData) number(col ColumnInfo, limit float64) float64 {
+	lo, hi := 0.0, min(limit, 1000)
+	floor := -limit
+	if strings.Contains(col.ColumnType, "unsigned") {
+		floor = 0
+	}
+	if rule, ok := numberRule(col.Name); ok && rule.lo >= floor && rule.hi <= limit {
+		lo, hi = rule.lo, rule.hi
+	}
+	return lo + f.rng.Float64()*(hi-lo)
+}
+
+// date generates a date within the last three years, or a date of birth
+func (f *fakeData) date(col ColumnInfo) time.Time {
+	if col.DataType != "timestamp" && matchesColumnName(col.Name, birthDateNames) {
+		return time.Date(1950+f.rng.Intn(56), time.Month(1+f.rng.Intn(12)), 1+f.rng.Intn(28), 0, 0, 0, 0, time.UTC)
+	}
+	return f.now.Add(-time.Duration(f.rng.Int63n(int64(3 * 365 * 24 * time.Hour)))).Truncate(time.Second)
+}
+
+func (f *fakeData) pick(values []string) string {
+	return values[f.rng.Intn(len(values))]
+}
+
+func (f *fakeData) words(n int) string {
+	words := make([]string, n)
+	for i := range words {
+		words[i] = f.pick(fakeWords)
+	}
+	return strings.Join(words, " ")
+}
+
+func (f *fakeData) hex(n int) string {
+	b := make([]byte, (n+1)/2)
+	f.rng.Read(b)
+	return fmt.Sprintf("%x", b)[:n]
+}
+
+// uuid generates a version 4 UUID
+func (f *fakeData) uuid() string {
+	b := make([]byte, 16)
+	f.rng.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// isUUIDColumn reports whether a character column holds UUIDs, by name or
+// by its CHAR(36) type
+func isUUIDColumn(col ColumnInfo) bool {
+	return col.ColumnType == "char(36)" || matchesColumnName(col.Name, []string{"uuid", "guid"})
+}
+
+// matchesColumnName reports whether a column name contains one of names as
+// whole words, so customer_email and customerEmail match email but
+// description does not match ip. Words are split at camelCase humps and any
+// character other than a letter or digit, and compared in lower case.
+func matchesColumnName(column string, names []string) bool {
+	var split strings.Builder
+	var prev rune
+	for _, r := range column {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			r = '_'
+		case unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+			split.WriteByte('_')
+		}
+		split.WriteRune(unicode.ToLower(r))
+		prev = r
+	}
+	words := strings.FieldsFunc(split.String(), func(r rune) bool { return r == '_' })
+	normalized := "_" + strings.Join(words, "_") + "_"
+	for _, name := range names {
+		if strings.Contains(normalized, "_"+name+"_") {
+			return true
+		}
+	}
+	return false
+}
+
+func numberRule(name string) (fakeNumberRule, bool) {
+	for _, rule := range fakeNumberRules {
+		if matchesColumnName(name, rule.names) {
+			return rule, true
+		}
+	}
+	return fakeNumberRule{}, false
+}
+
+// integerRange returns the values an integer column can hold; BIGINT
+// UNSIGNED is capped at the largest signed value
+func integerRange(col ColumnInfo) (int64, int64) {
+	bits := map[string]uint{"tinyint": 8, "smallint": 16, "mediumint": 24, "int": 32, "integer": 32, "bigint": 64}[col.DataType]
+	if strings.Contains(col.ColumnType, "unsigned") {
+		if bits == 64 {
+			return 0, math.MaxInt64
+		}
+		return 0, 1<<bits - 1
+	}
+	if bits == 64 {
+		return math.MinInt64, math.MaxInt64
+	}
+	return -(1 << (bits - 1)), 1<<(bits-1) - 1
+}
+
+// enumValues parses the values of an ENUM or SET column type, such as
+// enum('a','it”s'), where quotes inside a value are doubled
+func enumValues(columnType string) []string {
+	open, end := strings.Index(columnType, "("), strings.LastIndex(columnType, ")")
+	if open < 0 || end < open {
+		return nil
+	}
+	inner := columnType[open+1 : end]
+
+	var values []string
+	var current strings.Builder
+	quoted := false
+	for i := 0; i < len(inner); i++ {
+		c := inner[i]
+		if c != '\'' {
+			if quoted {
+				current.WriteByte(c)
+			}
+			continue
+		}
+		if quoted && i+1 < len(inner) && inner[i+1] == '\'' {
+			current.WriteByte('\'')
+			i++
+			continue
+		}
+		if quoted {
+			values = append(values, current.String())
+			current.Reset()
+		}
+		quoted = !quoted
+	}
+	return values
+}
+
+// maxLength returns a character or binary column's length, or 0 when unknown
+func maxLength(col ColumnInfo) int {
+	if col.MaxLength == nil || *col.MaxLength > math.MaxInt32 {
+		return 0
+	}
+	return int(*col.MaxLength)
+}
+
+// truncateRunes cuts s to at most n characters; n of 0 leaves s whole
+func truncateRunes(s string, n int) string {
+	if n <= 0 {
+		return s
+	}
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n])
+	}
+	return s
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// FederatedResult holds the outcome of a query on one connection
+type FederatedResult struct {
+	Result *QueryResult `json:"result,omitempty"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// ConnectionNames returns all configured connection names, and those added
+// by add_connection, in sorted order, leaving out removed connections
+func (m *Manager) ConnectionNames() []string {
+	names := make([]string, 0, len(m.config.Connections))
+	for name := range m.config.Connections {
+		if !m.isRemoved(name) {
+			names = append(names, name)
+		}
+	}
+	m.runtimeConnectionsMu.Lock()
+	for name := range m.runtimeConnections {
+		names = append(names, name)
+	}
+	m.runtimeConnectionsMu.Unlock()
+	sort.Strings(names)
+	return names
+}
+
+// ExecuteQueryMulti runs the same query against several connections in parallel
+// and returns the results keyed by connection name. An empty list targets every
+// configured connection. A failure on one connection does not affect the others.
+func (m *Manager) ExecuteQueryMulti(connectionNames []string, query string) (map[string]*FederatedResult, error) {
+	if len(connectionNames) == 0 {
+		connectionNames = m.ConnectionNames()
+	}
+
+	for _, name := range connectionNames {
+		if _, exists := m.lookupConnection(name); !exists {
+			return nil, fmt.Errorf("unknown connection: %s", name)
+		}
+	}
+
+	results := make(map[string]*FederatedResult, len(connectionNames))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, name := range connectionNames {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+
+			fr := &FederatedResult{}
+			queryResult, err := m.ExecuteQuery(name, query)
+			if err != nil {
+				fr.Error = err.Error()
+			} else {
+				fr.Result = queryResult
+			}
+
+			mu.Lock()
+			results[name] = fr
+			mu.Unlock()
+		}(name)
+	}
+
+	wg.Wait()
+	return results, nil
+}
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"regexp"
+	"strings"
+)
+
+// fingerprintTokenPattern matches, leftmost first, the parts of a statement
+// that a fingerprint keeps (quoted identifiers), replaces (string, hex and
+// numeric literals) or drops (comments)
+var fingerprintTokenPattern = regexp.MustCompile("(?is)`[^`]*`" +
+	`|'(?:[^'\\]|\\.|'')*'|"(?:[^"\\]|\\.|"")*"` +
+	`|/\*.*?\*/|--\s[^\n]*|#[^\n]*` +
+	`|\b0x[0-9a-f]+\b|\bx'[0-9a-f]*'` +
+	`|\b\d+(?:\.\d+)?(?:e[+-]?\d+)?\b|\.\d+\b`)
+
+var (
+	whitespacePattern  = regexp.MustCompile(`\s+`)
+	inListPattern      = regexp.MustCompile(`\bin \( ?\?(?: ?, ?\?)* ?\)`)
+	valuesListPattern  = regexp.MustCompile(`\bvalues ?\([?, ]*\)(?: ?, ?\([?, ]*\))*`)
+	negativeLitPattern = regexp.MustCompile(`([=<>(,] ?)-\?`)
+)
+
+// Fingerprint normalizes a statement into its shape, in the style of
+// pt-fingerprint: literals become ?, comments and extra whitespace are
+// removed, IN lists and multi-row VALUES collapse to (?+), and everything is
+// lowercased. Statements that differ only in their values share a fingerprint.
+func Fingerprint(query string) string {
+	normalized := fingerprintTokenPattern.ReplaceAllStringFunc(query, func(token string) string {
+		switch {
+		case token[0] == '`':
+			return token
+		case strings.HasPrefix(token, "/*"), strings.HasPrefix(token, "--"), token[0] == '#':
+			return " "
+		default:
+			return "?"
+		}
+	})
+
+	normalized = strings.ToLower(whitespacePattern.ReplaceAllString(normalized, " "))
+	normalized = strings.TrimRight(strings.TrimSpace(normalized), "; ")
+	normalized = negativeLitPattern.ReplaceAllString(normalized, "$1?")
+	normalized = inListPattern.ReplaceAllString(normalized, "in (?+)")
+	normalized = valuesListPattern.ReplaceAllString(normalized, "values (?+)")
+	return normalized
+}
@@ -0,0 +1,146 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"mysql-golang-mcp/config"
+)
+
+// ServerInfo identifies the server flavor (mysql, mariadb, or percona) and version
+type ServerInfo struct {
+	Flavor         string `json:"flavor"`
+	Version        string `json:"version"`
+	VersionComment string `json:"version_comment,omitempty"`
+	major, minor   int
+	patch          int
+}
+
+// detectServerInfo reads VERSION() and @@version_comment to identify the server
+func detectServerInfo(db *sql.DB) (*ServerInfo, error) {
+	info := &ServerInfo{}
+	if err := db.QueryRow("SELECT VERSION(), @@version_comment").Scan(&info.Version, &info.VersionComment); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case strings.Contains(strings.ToLower(info.Version), "mariadb"):
+		info.Flavor = "mariadb"
+	case strings.Contains(strings.ToLower(info.VersionComment), "percona"):
+		info.Flavor = "percona"
+	default:
+		info.Flavor = "mysql"
+	}
+	fmt.Sscanf(info.Version, "%d.%d.%d", &info.major, &info.minor, &info.patch)
+
+	return info, nil
+}
+
+// atLeast reports whether the server version is at least major.minor
+func (s *ServerInfo) atLeast(major, minor int) bool {
+	return s.major > major || (s.major == major && s.minor >= minor)
+}
+
+// hasDataLockViews reports whether performance_schema.data_lock_waits exists
+// (MySQL and Percona 8.0+; MariaDB only has information_schema.innodb_lock_waits)
+func (s *ServerInfo) hasDataLockViews() bool {
+	return s.Flavor != "mariadb" && s.atLeast(8, 0)
+}
+
+// hasPerformanceSchemaProcesslist reports whether performance_schema.processlist exists
+func (s *ServerInfo) hasPerformanceSchemaProcesslist() bool {
+	return s.Flavor != "mariadb" && s.atLeast(8, 0)
+}
+
+// supportsReturning reports whether INSERT/REPLACE/DELETE ... RETURNING is available
+// (MariaDB 10.5+)
+func (s *ServerInfo) supportsReturning() bool {
+	return s.Flavor == "mariadb" && s.atLeast(10, 5)
+}
+
+// hasBinaryLogStatus reports whether SHOW BINARY LOG STATUS replaces SHOW
+// MASTER STATUS (MySQL and Percona 8.2+, where the old form was removed in 8.4)
+func (s *ServerInfo) hasBinaryLogStatus() bool {
+	return s.Flavor != "mariadb" && s.atLeast(8, 2)
+}
+
+// supportsDualPasswords reports whether ALTER USER ... RETAIN CURRENT
+// PASSWORD is available (MySQL and Percona 8.0.14+)
+func (s *ServerInfo) supportsDualPasswords() bool {
+	return s.Flavor != "mariadb" && (s.atLeast(8, 1) || (s.major == 8 && s.minor == 0 && s.patch >= 14))
+}
+
+// ServerInfo returns the flavor and version of a connection's server, connecting if necessary
+func (m *Manager) ServerInfo(connectionName string) (*ServerInfo, error) {
+	if _, _, err := m.GetConnection(connectionName); err != nil {
+		return nil, err
+	}
+
+	info := m.cachedServerInfo(connectionName)
+	if info == nil {
+		return nil, fmt.Errorf("server version unavailable for connection '%s'", connectionName)
+	}
+	return info, nil
+}
+
+// cachedServerInfo returns the server info captured when the pool was opened, or nil
+func (m *Manager) cachedServerInfo(connectionName string) *ServerInfo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.serverInfo[connectionName]
+}
+
+// returningPattern matches a RETURNING clause outside of string literals
+var returningPattern = regexp.MustCompile(`(?i)\bRETURNING\b`)
+
+// stringLiteralPattern matches single- and double-quoted string literals
+var stringLiteralPattern = regexp.MustCompile(`'(?:[^'\\]|\\.|'')*'|"(?:[^"\\]|\\.|"")*"`)
+
+// hasReturningClause reports whether a write statement has a RETURNING clause
+func hasReturningClause(query string) bool {
+	return returningPattern.MatchString(stringLiteralPattern.ReplaceAllString(query, "''"))
+}
+
+// executeReturning runs a write with a RETURNING clause on a pinned session. The
+// returned rows are capped at max_rows, but rows_affected counts every row.
+func (m *Manager) executeReturning(conn *sql.Conn, connectionName string, connConfig *config.ConnectionConfig, query string, args []interface{}) (*WriteResult, error) {
+	start := time.Now()
+	rows, err := conn.QueryContext(context.Background(), query, args...)
+	if err != nil {
+		m.recordError(connectionName, query, err)
+		return nil, fmt.Errorf("query execution failed: %w", err)
+	}
+
+	returning, err := scanRows(rows, connConfig.MaxRows)
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+	affected := int64(returning.Count)
+	if returning.Truncated {
+		// scanRows stopped on a row beyond max_rows; count it and the rest
+		affected++
+		for rows.Next() {
+			affected++
+		}
+	}
+	rows.Close()
+	returning.Connection = connectionName
+	elapsed := time.Since(start)
+	slog.Debug("statement executed", "connection", connectionName, "sql", query, "duration_ms", elapsed.Milliseconds())
+
+	return &WriteResult{
+		RowsAffected: affected,
+		Returning:    returning,
+		Warning:      riskWarning(connectionName, connConfig),
+		ExecutionMs:  elapsed.Milliseconds(),
+		Warnings:     fetchWarnings(conn),
+		Connection:   connectionName,
+		Database:     connConfig.Database,
+	}, nil
+}
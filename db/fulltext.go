@@ -0,0 +1,195 @@
+package db
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// TextScoreColumn is the result column holding each row's search_text relevance
+const TextScoreColumn = "_score"
+
+// DefaultTextSearchLimit is the number of rows search_text returns by default
+const DefaultTextSearchLimit = 20
+
+// textSearchModes maps each search_text mode to its AGAINST modifier
+var textSearchModes = map[string]string{
+	"natural":         "IN NATURAL LANGUAGE MODE",
+	"boolean":         "IN BOOLEAN MODE",
+	"query_expansion": "WITH QUERY EXPANSION",
+}
+
+// TextSearchModes lists the modes search_text accepts
+var TextSearchModes = []string{"natural", "boolean", "query_expansion"}
+
+// FulltextIndex is a FULLTEXT index with its columns in index order
+type FulltextIndex struct {
+	Database string   `json:"database"`
+	Table    string   `json:"table"`
+	Name     string   `json:"name"`
+	Columns  []string `json:"columns"`
+}
+
+// TextSearch describes a search_text query: rows of a table matching text
+// against the columns of one FULLTEXT index, most relevant first
+type TextSearch struct {
+	StructuredQuery
+
+	Text string
+
+	// Mode is natural, boolean or query_expansion; natural when empty
+	Mode string
+
+	// MatchColumns are the columns of the FULLTEXT index to search; when
+	// empty, the table's only FULLTEXT index is used
+	MatchColumns []string
+
+	// IncludeDeleted keeps soft-deleted rows on connections with soft_delete_mode
+	IncludeDeleted bool
+}
+
+// FulltextIndexes lists the FULLTEXT indexes of a database, or of one table
+// when table is set, in the connection's default database when database is empty
+func (m *Manager) FulltextIndexes(connectionName, database, table string) ([]FulltextIndex, error) {
+	query := `SELECT TABLE_SCHEMA, TABLE_NAME, INDEX_NAME, COLUMN_NAME FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = COALESCE(?, DATABASE()) AND INDEX_TYPE = 'FULLTEXT'`
+	args := []interface{}{nullIfEmpty(database)}
+	if table != "" {
+		query += " AND TABLE_NAME = ?"
+		args = append(args, table)
+	}
+	query += " ORDER BY TABLE_NAME, INDEX_NAME, SEQ_IN_INDEX"
+
+	result, err := m.ExecuteQuery(connectionName, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// One row per index column, in index order
+	indexes := []FulltextIndex{}
+	for _, row := range result.Rows {
+		tableName, name := stringValue(row["TABLE_NAME"]), stringValue(row["INDEX_NAME"])
+		if n := len(indexes); n == 0 || indexes[n-1].Table != tableName || indexes[n-1].Name != name {
+			indexes = append(indexes, FulltextIndex{Database: stringValue(row["TABLE_SCHEMA"]), Table: tableName, Name: name, Columns: []string{}})
+		}
+		indexes[len(indexes)-1].Columns = append(indexes[len(indexes)-1].Columns, stringValue(row["COLUMN_NAME"]))
+	}
+	return indexes, nil
+}
+
+// SearchText runs a MATCH ... AGAINST query, returning matching rows with
+// their relevance in the _score column, highest first. MATCH must name
+// exactly the columns of one FULLTEXT index, so the columns are checked
+// against the table's indexes first. The query goes through the same checks
+// as any SELECT.
+func (m *Manager) SearchText(connectionName string, search TextSearch, opts QueryOptions) (*QueryResult, error) {
+	if strings.TrimSpace(search.Text) == "" {
+		return nil, fmt.Errorf("search text is required")
+	}
+	if search.Mode == "" {
+		search.Mode = "natural"
+	}
+	modifier, ok := textSearchModes[search.Mode]
+	if !ok {
+		return nil, fmt.Errorf("mode must be one of %s", strings.Join(TextSearchModes, ", "))
+	}
+
+	indexes, err := m.FulltextIndexes(connectionName, search.Database, search.Table)
+	if err != nil {
+		return nil, err
+	}
+	index, err := matchFulltextIndex(search.Table, indexes, search.MatchColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	// The soft-delete filter is applied as a condition: MATCH needs the base
+	// table, not the derived table applySoftDeleteFilter would read through
+	if connConfig, exists := m.lookupConnection(connectionName); exists && connConfig.SoftDeleteMode && !search.IncludeDeleted {
+		if col := softDeleteColumn(connConfig, search.Table); col != "" {
+			search.Filters = append(search.Filters, Filter{Field: col, Op: "IS NULL"})
+		}
+	}
+
+	query, args, err := buildTextSearch(search, index.Columns, modifier)
+	if err != nil {
+		return nil, err
+	}
+	opts.ExcludeSoftDeleted = false
+	return m.ExecuteQueryWithOptions(connectionName, query, opts, args...)
+}
+
+// matchFulltextIndex picks the FULLTEXT index whose columns are columns, in
+// any order, or the table's only FULLTEXT index when columns is empty
+func matchFulltextIndex(table string, indexes []FulltextIndex, columns []string) (*FulltextIndex, error) {
+	if len(indexes) == 0 {
+		return nil, fmt.Errorf("table %s has no FULLTEXT index; add one with ALTER TABLE %s ADD FULLTEXT (column, ...) to search it", table, QuoteIdentifier(table))
+	}
+
+	described := make([]string, len(indexes))
+	for i, idx := range indexes {
+		described[i] = fmt.Sprintf("%s (%s)", idx.Name, strings.Join(idx.Columns, ", "))
+	}
+	if len(columns) == 0 {
+		if len(indexes) > 1 {
+			return nil, fmt.Errorf("table %s has several FULLTEXT indexes; pass match_columns with the columns of one: %s", table, strings.Join(described, "; "))
+		}
+		return &indexes[0], nil
+	}
+
+	want := make([]string, len(columns))
+	for i, col := range columns {
+		want[i] = strings.ToLower(col)
+	}
+	slices.Sort(want)
+	for i, idx := range indexes {
+		have := make([]string, len(idx.Columns))
+		for j, col := range idx.Columns {
+			have[j] = strings.ToLower(col)
+		}
+		slices.Sort(have)
+		if slices.Equal(want, have) {
+			return &indexes[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no FULLTEXT index of %s covers exactly the columns %s; MATCH must name all the columns of one index: %s", table, strings.Join(columns, ", "), strings.Join(described, "; "))
+}
+
+// buildTextSearch builds the parameterized SELECT of a text search, with the
+// relevance as _score and extra filters combined with the MATCH by AND
+func buildTextSearch(search TextSearch, matchColumns []string, modifier string) (string, []interface{}, error) {
+	projection := "*"
+	if len(search.Columns) > 0 {
+		quoted := make([]string, len(search.Columns))
+		for i, col := range search.Columns {
+			quoted[i] = QuoteIdentifier(col)
+		}
+		projection = strings.Join(quoted, ", ")
+	}
+
+	quotedMatch := make([]string, len(matchColumns))
+	for i, col := range matchColumns {
+		quotedMatch[i] = QuoteIdentifier(col)
+	}
+	match := fmt.Sprintf("MATCH (%s) AGAINST (? %s)", strings.Join(quotedMatch, ", "), modifier)
+
+	where := " WHERE " + match
+	filters, filterArgs, err := buildWhere(search.Filters)
+	if err != nil {
+		return "", nil, err
+	}
+	if filters != "" {
+		where += " AND " + strings.TrimPrefix(filters, " WHERE ")
+	}
+
+	limit := search.Limit
+	if limit <= 0 {
+		limit = DefaultTextSearchLimit
+	}
+	query := fmt.Sprintf("SELECT %s, %s AS %s FROM %s%s ORDER BY %s DESC%s",
+		projection, match, QuoteIdentifier(TextScoreColumn), QualifiedName(search.Database, search.Table),
+		where, QuoteIdentifier(TextScoreColumn), buildLimit(limit))
+
+	args := append([]interface{}{search.Text, search.Text}, filterArgs...)
+	return query, args, nil
+}
@@ -0,0 +1,346 @@
+package db
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// wkbTypes names the WKB geometry type codes MySQL stores
+var wkbTypes = map[uint32]string{
+	1: "Point", 2: "LineString", 3: "Polygon",
+	4: "MultiPoint", 5: "MultiLineString", 6: "MultiPolygon", 7: "GeometryCollection",
+}
+
+// Geometry is a spatial value decoded from MySQL's internal format: a 4-byte
+// little-endian SRID followed by the value as WKB. Coordinates are nested
+// like GeoJSON's: []float64 for a Point, [][]float64 for a LineString or
+// MultiPoint, [][][]float64 for a Polygon or MultiLineString, and
+// [][][][]float64 for a MultiPolygon.
+type Geometry struct {
+	SRID        uint32
+	Type        string
+	Coordinates interface{}
+	Geometries  []*Geometry
+	wkb         []byte
+}
+
+// DecodeGeometry decodes a spatial value as MySQL returns it
+func DecodeGeometry(b []byte) (*Geometry, error) {
+	if len(b) < 4 {
+		return nil, fmt.Errorf("geometry value too short")
+	}
+	r := &wkbReader{b: b[4:]}
+	g, err := r.geometry()
+	if err != nil {
+		return nil, err
+	}
+	if r.pos != len(r.b) {
+		return nil, fmt.Errorf("geometry value has %d trailing bytes", len(r.b)-r.pos)
+	}
+	g.SRID = binary.LittleEndian.Uint32(b)
+	g.wkb = b[4:]
+	return g, nil
+}
+
+// wkbReader reads WKB geometries, each with its own byte order
+type wkbReader struct {
+	b     []byte
+	pos   int
+	order binary.ByteOrder
+}
+
+func (r *wkbReader) uint32() (uint32, error) {
+	if r.pos+4 > len(r.b) {
+		return 0, fmt.Errorf("geometry value truncated")
+	}
+	v := r.order.Uint32(r.b[r.pos:])
+	r.pos += 4
+	return v, nil
+}
+
+func (r *wkbReader) point() ([]float64, error) {
+	if r.pos+16 > len(r.b) {
+		return nil, fmt.Errorf("geometry value truncated")
+	}
+	x := math.Float64frombits(r.order.Uint64(r.b[r.pos:]))
+	y := math.Float64frombits(r.order.Uint64(r.b[r.pos+8:]))
+	r.pos += 16
+	return []float64{x, y}, nil
+}
+
+func (r *wkbReader) points() ([][]float64, error) {
+	n, err := r.uint32()
+	if err != nil {
+		return nil, err
+	}
+	if int(n) > (len(r.b)-r.pos)/16 {
+		return nil, fmt.Errorf("geometry value truncated")
+	}
+	points := make([][]float64, n)
+	for i := range points {
+		if points[i], err = r.point(); err != nil {
+			return nil, err
+		}
+	}
+	return points, nil
+}
+
+func (r *wkbReader) rings() ([][][]float64, error) {
+	n, err := r.uint32()
+	if err != nil {
+		return nil, err
+	}
+	if int(n) > (len(r.b)-r.pos)/4 {
+		return nil, fmt.Errorf("geometry value truncated")
+	}
+	rings := make([][][]float64, n)
+	for i := range rings {
+		if rings[i], err = r.points(); err != nil {
+			return nil, err
+		}
+	}
+	return rings, nil
+}
+
+// geometry reads one WKB geometry: its byte order, type and body
+func (r *wkbReader) geometry() (*Geometry, error) {
+	if r.pos >= len(r.b) {
+		return nil, fmt.Errorf("geometry value truncated")
+	}
+	switch r.b[r.pos] {
+	case 0:
+		r.order = binary.BigEndian
+	case 1:
+		r.order = binary.LittleEndian
+	default:
+		return nil, fmt.Errorf("invalid WKB byte order %d", r.b[r.pos])
+	}
+	r.pos++
+
+	code, err := r.uint32()
+	if err != nil {
+		return nil, err
+	}
+	g := &Geometry{Type: wkbTypes[code]}
+	switch code {
+	case 1:
+		g.Coordinates, err = r.point()
+	case 2:
+		g.Coordinates, err = r.points()
+	case 3:
+		g.Coordinates, err = r.rings()
+	case 4, 5, 6, 7:
+		var n uint32
+		if n, err = r.uint32(); err != nil {
+			return nil, err
+		}
+		if int(n) > (len(r.b)-r.pos)/5 {
+			return nil, fmt.Errorf("geometry value truncated")
+		}
+		members := make([]*Geometry, n)
+		for i := range members {
+			if members[i], err = r.geometry(); err != nil {
+				return nil, err
+			}
+		}
+		g.setMembers(members)
+	default:
+		return nil, fmt.Errorf("unsupported WKB geometry type %d", code)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// setMembers stores the members of a multi-geometry as nested coordinates,
+// or of a collection as geometries
+func (g *Geometry) setMembers(members []*Geometry) {
+	switch g.Type {
+	case "MultiPoint":
+		coords := make([][]float64, len(members))
+		for i, m := range members {
+			coords[i], _ = m.Coordinates.([]float64)
+		}
+		g.Coordinates = coords
+	case "MultiLineString":
+		coords := make([][][]float64, len(members))
+		for i, m := range members {
+			coords[i], _ = m.Coordinates.([][]float64)
+		}
+		g.Coordinates = coords
+	case "MultiPolygon":
+		coords := make([][][][]float64, len(members))
+		for i, m := range members {
+			coords[i], _ = m.Coordinates.([][][]float64)
+		}
+		g.Coordinates = coords
+	default:
+		g.Geometries = members
+	}
+}
+
+// GeoJSON returns the geometry as a GeoJSON geometry object
+func (g *Geometry) GeoJSON() map[string]interface{} {
+	if g.Type == "GeometryCollection" {
+		geometries := make([]interface{}, len(g.Geometries))
+		for i, member := range g.Geometries {
+			geometries[i] = member.GeoJSON()
+		}
+		return map[string]interface{}{"type": g.Type, "geometries": geometries}
+	}
+	return map[string]interface{}{"type": g.Type, "coordinates": g.Coordinates}
+}
+
+// WKT returns the geometry as well-known text, as MySQL's ST_AsText writes
+// it but always with the stored axis order
+func (g *Geometry) WKT() string {
+	name := strings.ToUpper(g.Type)
+	switch coords := g.Coordinates.(type) {
+	case []float64:
+		return name + "(" + wktPoint(coords) + ")"
+	case [][]float64:
+		if g.Type == "MultiPoint" {
+			if len(coords) == 0 {
+				return name + " EMPTY"
+			}
+			points := make([]string, len(coords))
+			for i, p := range coords {
+				points[i] = "(" + wktPoint(p) + ")"
+			}
+			return name + "(" + strings.Join(points, ",") + ")"
+		}
+		return name + wktPoints(coords)
+	case [][][]float64:
+		if len(coords) == 0 {
+			return name + " EMPTY"
+		}
+		return name + wktRings(coords)
+	case [][][][]float64:
+		if len(coords) == 0 {
+			return name + " EMPTY"
+		}
+		polygons := make([]string, len(coords))
+		for i, p := range coords {
+			polygons[i] = wktRings(p)
+		}
+		return name + "(" + strings.Join(polygons, ",") + ")"
+	}
+
+	if len(g.Geometries) == 0 {
+		return name + " EMPTY"
+	}
+	members := make([]string, len(g.Geometries))
+	for i, member := range g.Geometries {
+		members[i] = member.WKT()
+	}
+	return name + "(" + strings.Join(members, ",") + ")"
+}
+
+func wktPoint(p []float64) string {
+	if len(p) != 2 {
+		return ""
+	}
+	return strconv.FormatFloat(p[0], 'f', -1, 64) + " " + strconv.FormatFloat(p[1], 'f', -1, 64)
+}
+
+func wktPoints(points [][]float64) string {
+	rendered := make([]string, len(points))
+	for i, p := range points {
+		rendered[i] = wktPoint(p)
+	}
+	return "(" + strings.Join(rendered, ",") + ")"
+}
+
+func wktRings(rings [][][]float64) string {
+	rendered := make([]string, len(rings))
+	for i, ring := range rings {
+		rendered[i] = wktPoints(ring)
+	}
+	return "(" + strings.Join(rendered, ",") + ")"
+}
+
+// formatGeometry renders a scanned spatial value in the given
+// geometry_format, leaving values that do not decode unchanged
+func formatGeometry(v interface{}, format string) interface{} {
+	s, ok := v.(string)
+	if !ok {
+		return v
+	}
+	g, err := DecodeGeometry([]byte(s))
+	if err != nil {
+		return v
+	}
+	switch format {
+	case "geojson":
+		return g.GeoJSON()
+	case "wkb":
+		return hex.EncodeToString(g.wkb)
+	default:
+		return g.WKT()
+	}
+}
+
+// spatialFunctions maps the spatial filter ops to the MySQL functions
+// comparing a column with the filter's geometry
+var spatialFunctions = map[string]string{"INTERSECTS": "ST_Intersects", "CONTAINS": "ST_Contains", "WITHIN": "ST_Within"}
+
+// spatialCondition builds the condition of a spatial filter on a geometry
+// column. The value is the geometry to compare with: WKT text (SRID 0), or an
+// object holding "wkt" or "geojson" with an optional "srid", which must match
+// the column's. WKT with an SRID is read longitude first, as GeoJSON is.
+// WITHIN DISTANCE also takes a "distance" in the SRID's unit (meters for
+// geographic SRIDs) and matches rows whose ST_Distance is at most that.
+func spatialCondition(col, op string, value interface{}) (string, []interface{}, error) {
+	spec, ok := value.(map[string]interface{})
+	if wkt, isText := value.(string); isText {
+		spec, ok = map[string]interface{}{"wkt": wkt}, true
+	}
+	if !ok {
+		return "", nil, fmt.Errorf("op %s requires a WKT string or an object with wkt or geojson", op)
+	}
+
+	var geometry string
+	var args []interface{}
+	srid, hasSRID := spec["srid"].(float64)
+	switch {
+	case spec["wkt"] != nil:
+		wkt, ok := spec["wkt"].(string)
+		if !ok || wkt == "" {
+			return "", nil, fmt.Errorf("wkt must be a non-empty string")
+		}
+		if hasSRID && srid != 0 {
+			geometry = "ST_GeomFromText(?, ?, 'axis-order=long-lat')"
+			args = append(args, wkt, int64(srid))
+		} else {
+			geometry = "ST_GeomFromText(?)"
+			args = append(args, wkt)
+		}
+	case spec["geojson"] != nil:
+		encoded, err := json.Marshal(spec["geojson"])
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid geojson: %w", err)
+		}
+		if !hasSRID {
+			srid = 4326
+		}
+		geometry = "ST_GeomFromGeoJSON(?, 1, ?)"
+		args = append(args, string(encoded), int64(srid))
+	default:
+		return "", nil, fmt.Errorf("op %s requires a WKT string or an object with wkt or geojson", op)
+	}
+
+	if op != "WITHIN DISTANCE" {
+		return fmt.Sprintf("%s(%s, %s)", spatialFunctions[op], col, geometry), args, nil
+	}
+	distance, ok := spec["distance"].(float64)
+	if !ok || distance < 0 {
+		return "", nil, fmt.Errorf("op WITHIN DISTANCE requires a non-negative distance")
+	}
+	return fmt.Sprintf("ST_Distance(%s, %s) <= ?", col, geometry), append(args, distance), nil
+}
@@ -0,0 +1,293 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"mysql-golang-mcp/config"
+)
+
+// DefaultRowHistoryLimit is the number of versions RowHistory returns by default
+const DefaultRowHistoryLimit = 50
+
+// historyTimeLayouts are the accepted formats of a row_history point in time
+var historyTimeLayouts = []string{"2006-01-02 15:04:05.999999", "2006-01-02T15:04:05.999999", "2006-01-02"}
+
+// RowHistory holds the versions of one row found in its table's history,
+// oldest first, and optionally the version current at a point in time
+type RowHistory struct {
+	Table        string                   `json:"table"`
+	Key          map[string]interface{}   `json:"key"`
+	Source       string                   `json:"source"`
+	HistoryTable string                   `json:"history_table,omitempty"`
+	Image        string                   `json:"image,omitempty"`
+	Versions     []map[string]interface{} `json:"versions"`
+	Truncated    bool                     `json:"truncated,omitempty"`
+
+	// At is the requested point in time; AsOf is the row as it was then, or
+	// nil with AsOfNote explaining why
+	At       string                 `json:"at,omitempty"`
+	AsOf     map[string]interface{} `json:"as_of,omitempty"`
+	AsOfNote string                 `json:"as_of_note,omitempty"`
+
+	// Current is the row as it is now, or nil when it no longer exists
+	Current map[string]interface{} `json:"current"`
+}
+
+// ParseHistoryTime normalizes a point in time given as "2006-01-02 15:04:05"
+// (optionally with fractional seconds or a T separator) or "2006-01-02"
+func ParseHistoryTime(at string) (string, error) {
+	for _, layout := range historyTimeLayouts {
+		if t, err := time.Parse(layout, strings.TrimSpace(at)); err == nil {
+			return t.Format("2006-01-02 15:04:05.999999"), nil
+		}
+	}
+	return "", fmt.Errorf("at must be a time like 2024-06-01 14:30:00 or 2024-06-01, got %q", at)
+}
+
+// RowHistory returns the prior versions of the row of table identified by
+// key (primary key column to value), from the table's history table under the
+// connection's row_history convention, or from MariaDB system versioning when
+// the table is system-versioned. With at, the version current at that time is
+// picked out as well. At most limit versions, the most recent, are returned.
+func (m *Manager) RowHistory(connectionName, database, table string, key map[string]interface{}, at string, limit int) (*RowHistory, error) {
+	_, connConfig, err := m.GetConnection(connectionName)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = DefaultRowHistoryLimit
+	}
+	if at != "" {
+		if at, err = ParseHistoryTime(at); err != nil {
+			return nil, err
+		}
+	}
+
+	keyColumns, err := m.PrimaryKeyColumns(connectionName, database, table)
+	if err != nil {
+		return nil, err
+	}
+	if len(keyColumns) == 0 {
+		return nil, fmt.Errorf("table %s has no primary key", table)
+	}
+	keyArgs, err := primaryKeyArgs(keyColumns, []map[string]interface{}{key})
+	if err != nil {
+		return nil, err
+	}
+	args := keyArgs[0]
+	where := strings.Join(conditionsFor(keyColumns), " AND ")
+
+	history := &RowHistory{Table: table, Key: key, At: at}
+	current, err := m.ExecuteQuery(connectionName, fmt.Sprintf("SELECT * FROM %s WHERE %s LIMIT 1", QualifiedName(database, table), where), args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(current.Rows) > 0 {
+		history.Current = current.Rows[0]
+	}
+
+	historyTable, convention, err := m.historyTable(connectionName, connConfig, database, table)
+	if err != nil {
+		return nil, err
+	}
+	if historyTable != "" {
+		history.Source, history.HistoryTable, history.Image = "history_table", historyTable, convention.Image
+		if err := m.readHistoryTable(connectionName, database, history, convention, where, args, limit); err != nil {
+			return nil, err
+		}
+		return history, nil
+	}
+
+	versioned, err := m.isSystemVersioned(connectionName, database, table)
+	if err != nil {
+		return nil, err
+	}
+	if !versioned {
+		return nil, fmt.Errorf("no history is available for %s: configure row_history (or a history_table for it) on connection '%s', or make it a MariaDB system-versioned table", table, connectionName)
+	}
+	history.Source = "system_versioning"
+	if err := m.readSystemVersions(connectionName, database, history, where, args, limit); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// historyTable returns the existing history table of a table and the
+// convention it follows, or "" when the table has none
+func (m *Manager) historyTable(connectionName string, connConfig *config.ConnectionConfig, database, table string) (string, *config.RowHistoryConfig, error) {
+	convention := connConfig.RowHistory
+	var name string
+	for configured, t := range connConfig.Tables {
+		if t != nil && t.HistoryTable != "" && strings.EqualFold(configured, table) {
+			name = t.HistoryTable
+		}
+	}
+	if name == "" && convention == nil {
+		return "", nil, nil
+	}
+	if convention == nil {
+		// A history_table alone follows the default convention
+		convention = &config.RowHistoryConfig{TimestampColumn: "changed_at", Image: "after"}
+	}
+	if name == "" {
+		name = table + convention.TableSuffix
+	}
+
+	exists, err := m.ExecuteQuery(connectionName, `SELECT 1 FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = COALESCE(?, DATABASE()) AND TABLE_NAME = ?`, nullIfEmpty(database), name)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(exists.Rows) == 0 {
+		return "", nil, nil
+	}
+	return name, convention, nil
+}
+
+// readHistoryTable fills in the versions of a row from its history table
+func (m *Manager) readHistoryTable(connectionName, database string, history *RowHistory, convention *config.RowHistoryConfig, where string, args []interface{}, limit int) error {
+	target := QualifiedName(database, history.HistoryTable)
+	timestamp := QuoteIdentifier(convention.TimestampColumn)
+
+	versions, err := m.ExecuteQuery(connectionName, fmt.Sprintf("SELECT * FROM %s WHERE %s ORDER BY %s DESC LIMIT %d", target, where, timestamp, limit+1), args...)
+	if err != nil {
+		return err
+	}
+	history.Versions, history.Truncated = oldestFirst(versions.Rows, limit)
+
+	if history.At == "" {
+		return nil
+	}
+	atArgs := append(append([]interface{}(nil), args...), history.At)
+
+	// An after image is the row from its change until the next one; a before
+	// image is the row from the previous change until its own
+	if convention.Image == "after" {
+		asOf, err := m.ExecuteQuery(connectionName, fmt.Sprintf("SELECT * FROM %s WHERE %s AND %s <= ? ORDER BY %s DESC LIMIT 1", target, where, timestamp, timestamp), atArgs...)
+		if err != nil {
+			return err
+		}
+		switch {
+		case len(asOf.Rows) == 0:
+			history.AsOfNote = "no version at or before this time: the row did not exist yet, or its history starts later"
+		case isDeleteOperation(asOf.Rows[0], convention.OperationColumn):
+			history.AsOfNote = "the row had been deleted by this time"
+		default:
+			history.AsOf = asOf.Rows[0]
+		}
+		return nil
+	}
+
+	asOf, err := m.ExecuteQuery(connectionName, fmt.Sprintf("SELECT * FROM %s WHERE %s AND %s > ? ORDER BY %s LIMIT 1", target, where, timestamp, timestamp), atArgs...)
+	if err != nil {
+		return err
+	}
+	switch {
+	case len(asOf.Rows) > 0 && isInsertOperation(asOf.Rows[0], convention.OperationColumn):
+		history.AsOfNote = "the row was inserted after this time"
+	case len(asOf.Rows) > 0:
+		history.AsOf = asOf.Rows[0]
+	case history.Current != nil:
+		// Unchanged since then
+		history.AsOf = history.Current
+	default:
+		history.AsOfNote = "the row does not exist and no change to it is recorded after this time"
+	}
+	return nil
+}
+
+// isSystemVersioned reports whether a table is a MariaDB system-versioned table
+func (m *Manager) isSystemVersioned(connectionName, database, table string) (bool, error) {
+	queryResult, err := m.ExecuteQuery(connectionName, `SELECT TABLE_TYPE FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = COALESCE(?, DATABASE()) AND TABLE_NAME = ?`, nullIfEmpty(database), table)
+	if err != nil {
+		return false, err
+	}
+	return len(queryResult.Rows) > 0 && stringValue(queryResult.Rows[0]["TABLE_TYPE"]) == "SYSTEM VERSIONED", nil
+}
+
+// readSystemVersions fills in the versions of a row of a MariaDB
+// system-versioned table, each with the _row_start and _row_end of its period
+func (m *Manager) readSystemVersions(connectionName, database string, history *RowHistory, where string, args []interface{}, limit int) error {
+	// Periods use implicit ROW_START and ROW_END columns unless the table
+	// names its own, which MariaDB marks in EXTRA
+	start, end := "ROW_START", "ROW_END"
+	columns, err := m.ExecuteQuery(connectionName, `SELECT COLUMN_NAME, EXTRA FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = COALESCE(?, DATABASE()) AND TABLE_NAME = ?
+		AND (EXTRA LIKE '%ROW START%' OR EXTRA LIKE '%ROW END%')`, nullIfEmpty(database), history.Table)
+	if err != nil {
+		return err
+	}
+	for _, row := range columns.Rows {
+		if strings.Contains(stringValue(row["EXTRA"]), "ROW START") {
+			start = QuoteIdentifier(stringValue(row["COLUMN_NAME"]))
+		} else {
+			end = QuoteIdentifier(stringValue(row["COLUMN_NAME"]))
+		}
+	}
+
+	target := QualifiedName(database, history.Table)
+	versions, err := m.ExecuteQuery(connectionName, fmt.Sprintf("SELECT *, %s AS _row_start, %s AS _row_end FROM %s FOR SYSTEM_TIME ALL WHERE %s ORDER BY %s DESC LIMIT %d",
+		start, end, target, where, start, limit+1), args...)
+	if err != nil {
+		return err
+	}
+	history.Versions, history.Truncated = oldestFirst(versions.Rows, limit)
+
+	if history.At == "" {
+		return nil
+	}
+	atArgs := append(append([]interface{}(nil), args...), history.At, history.At)
+	asOf, err := m.ExecuteQuery(connectionName, fmt.Sprintf("SELECT *, %s AS _row_start, %s AS _row_end FROM %s FOR SYSTEM_TIME ALL WHERE %s AND %s <= ? AND %s > ? LIMIT 1",
+		start, end, target, where, start, end), atArgs...)
+	if err != nil {
+		return err
+	}
+	if len(asOf.Rows) > 0 {
+		history.AsOf = asOf.Rows[0]
+	} else {
+		history.AsOfNote = "the row did not exist at this time"
+	}
+	return nil
+}
+
+// oldestFirst reverses newest-first rows read with one extra row beyond
+// limit, reporting whether older versions were left out
+func oldestFirst(rows []map[string]interface{}, limit int) ([]map[string]interface{}, bool) {
+	truncated := len(rows) > limit
+	if truncated {
+		rows = rows[:limit]
+	}
+	versions := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		versions[len(rows)-1-i] = row
+	}
+	return versions, truncated
+}
+
+// isDeleteOperation reports whether a history row records a delete, such as
+// "DELETE", "delete" or "D" in the operation column
+func isDeleteOperation(row map[string]interface{}, column string) bool {
+	return operationIs(row, column, "D")
+}
+
+// isInsertOperation reports whether a history row records an insert
+func isInsertOperation(row map[string]interface{}, column string) bool {
+	return operationIs(row, column, "I")
+}
+
+// operationIs reports whether the operation column of a history row starts
+// with the given letter, case-insensitively
+func operationIs(row map[string]interface{}, column, letter string) bool {
+	if column == "" {
+		return false
+	}
+	for name, v := range row {
+		if strings.EqualFold(name, column) {
+			return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(stringValue(v))), letter)
+		}
+	}
+	return false
+}
@@ -0,0 +1,534 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// indexNameLimit is the longest index name MySQL accepts
+const indexNameLimit = 64
+
+var (
+	// comparisonPattern matches a column compared with a value, a placeholder
+	// or, for joins, another table's column
+	comparisonPattern = regexp.MustCompile(`(?i)(?:(` + identifierPart + `)\.)?(` + identifierPart + `)\s*(<=>|<=|>=|<>|!=|=|<|>|\bBETWEEN\b|\bLIKE\b|\bIN\s*\()\s*(?:(` + identifierPart + `)\.(` + identifierPart + `))?`)
+	orPattern         = regexp.MustCompile(`(?i)\bOR\b|\|\|`)
+	orderEndPattern   = regexp.MustCompile(`(?i)\b(?:LIMIT|FOR|LOCK)\b`)
+	orderItemPattern  = regexp.MustCompile(`(?i)^\s*(?:(` + identifierPart + `)\.)?(` + identifierPart + `)(?:\s+(ASC|DESC))?\s*$`)
+)
+
+// IndexRecommendation is an index proposed for the tables of analyzed queries
+type IndexRecommendation struct {
+	Database  string   `json:"database,omitempty"`
+	Table     string   `json:"table"`
+	Columns   []string `json:"columns"`
+	Statement string   `json:"statement"`
+	Rationale string   `json:"rationale"`
+
+	// Queries are the 1-based positions of the queries the index serves
+	Queries []int `json:"queries"`
+
+	// CurrentAccess is how the plan reads the table now, e.g. ALL for a full
+	// scan, and the row counts are the optimizer's estimates per scan
+	CurrentAccess      string `json:"current_access"`
+	RowsExaminedNow    int64  `json:"rows_examined_now"`
+	EstimatedRowsAfter int64  `json:"estimated_rows_after"`
+	EstimatedBenefit   string `json:"estimated_benefit"`
+}
+
+// IndexAdvice holds the indexes recommended for a set of queries, with notes
+// on tables whose access could not be improved by a new index
+type IndexAdvice struct {
+	Connection      string                `json:"connection"`
+	Recommendations []IndexRecommendation `json:"recommendations"`
+	Notes           []string              `json:"notes,omitempty"`
+	Count           int                   `json:"count"`
+}
+
+// tableAccess is one table read of a plan
+type tableAccess struct {
+	alias      string
+	accessType string
+	key        string
+	rows       int64
+	filtered   float64
+}
+
+// indexPredicate is a condition an index on column could serve. A join
+// condition records the table the column is compared with.
+type indexPredicate struct {
+	column   string
+	rangeOp  bool
+	joinedTo string
+}
+
+// RecommendIndexes proposes CREATE INDEX statements for SELECT queries. Each
+// query is explained, and every table it reads by a full scan, a full index
+// scan or a partial index gets an index on its equality and join columns,
+// then one range column or, when the plan sorts, the ORDER BY columns. Tables
+// an existing index already serves this way are left out. Conditions are read
+// lexically, so ones under OR, wrapped in functions or comparing expressions
+// are ignored. Row estimates come from the optimizer's rows and filtered
+// figures. Unqualified tables are resolved in database, or the connection's
+// default database when it is empty.
+func (m *Manager) RecommendIndexes(connectionName, database string, queries []string) (*IndexAdvice, error) {
+	advice := &IndexAdvice{Connection: connectionName, Recommendations: []IndexRecommendation{}}
+	existing := make(map[string][]Index)
+
+	for i, query := range queries {
+		plan, err := m.Explain(connectionName, database, query)
+		if err != nil {
+			return nil, fmt.Errorf("query %d: %w", i+1, err)
+		}
+		var accesses []tableAccess
+		filesort := false
+		collectTableAccesses(plan.Plan, &accesses, &filesort)
+
+		refs := queryTableRefs(query, database)
+		columns := m.lintColumns(connectionName, database, query)
+		predicates := indexPredicates(query, refs, columns)
+		orderBy := orderByColumns(query, refs, columns)
+
+		for position, access := range accesses {
+			ref, ok := refs[strings.ToLower(access.alias)]
+			if !ok {
+				// Derived tables and CTEs cannot be indexed
+				continue
+			}
+			switch access.accessType {
+			case "system", "const", "eq_ref":
+				continue
+			}
+
+			// Join columns only help a table read after the one it is joined to
+			earlier := make(map[string]bool)
+			for _, a := range accesses[:position] {
+				earlier[strings.ToLower(a.alias)] = true
+			}
+			var equality, ranges, joins []string
+			for _, p := range predicates[strings.ToLower(access.alias)] {
+				switch {
+				case p.joinedTo != "" && !earlier[p.joinedTo]:
+				case p.rangeOp:
+					ranges = appendColumn(ranges, p.column)
+				default:
+					equality = appendColumn(equality, p.column)
+					if p.joinedTo != "" {
+						joins = appendColumn(joins, p.column)
+					}
+				}
+			}
+
+			candidate := append([]string{}, equality...)
+			var reasons []string
+			if len(equality) > 0 {
+				reasons = append(reasons, "equality on "+strings.Join(equality, ", ")+" comes first")
+			}
+			if len(joins) > 0 {
+				reasons = append(reasons, strings.Join(joins, ", ")+" joins a table read earlier")
+			}
+			filtering, sorted := len(equality) > 0, false
+			for _, column := range ranges {
+				if !hasColumn(candidate, column) {
+					candidate = append(candidate, column)
+					filtering = true
+					reasons = append(reasons, "range condition on "+column+" follows the equality columns")
+					break
+				}
+			}
+			if len(candidate) == len(equality) && filesort && orderBy.alias == strings.ToLower(access.alias) {
+				for _, column := range orderBy.columns {
+					candidate = appendColumn(candidate, column)
+				}
+				if len(candidate) > len(equality) {
+					sorted = true
+					reasons = append(reasons, "ORDER BY "+strings.Join(orderBy.columns, ", ")+" is read in index order instead of a filesort")
+				}
+			}
+			if len(candidate) == 0 {
+				if access.accessType == "ALL" {
+					advice.Notes = append(advice.Notes, fmt.Sprintf("Query %d scans %s fully but has no condition on it an index could serve", i+1, ref.Table))
+				}
+				continue
+			}
+
+			key := strings.ToLower(ref.Database + "." + ref.Table)
+			if _, ok := existing[key]; !ok {
+				indexes, err := m.GetIndexes(connectionName, ref.Database, ref.Table)
+				if err != nil {
+					return nil, fmt.Errorf("query %d: %w", i+1, err)
+				}
+				existing[key] = indexes
+			}
+			if index := servingIndex(existing[key], candidate, len(equality)); index != "" {
+				if access.accessType == "ALL" || access.accessType == "index" {
+					advice.Notes = append(advice.Notes, fmt.Sprintf("Query %d: index %s on %s already covers (%s) but the optimizer reads the table with access type %s; run ANALYZE TABLE or check the selectivity of its columns", i+1, index, ref.Table, strings.Join(candidate, ", "), access.accessType))
+				}
+				continue
+			}
+
+			after := access.rows
+			if filtering {
+				after = int64(float64(access.rows) * access.filtered / 100)
+				if after < 1 && access.rows > 0 {
+					after = 1
+				}
+			}
+			var benefits []string
+			if after < access.rows {
+				benefits = append(benefits, fmt.Sprintf("examines ~%d rows per scan instead of ~%d (%.0f%% fewer)", after, access.rows, 100-float64(after)*100/float64(access.rows)))
+			}
+			if sorted {
+				benefits = append(benefits, "avoids the filesort")
+			}
+			if len(benefits) == 0 {
+				benefits = append(benefits, "lets MySQL seek instead of reading every row")
+			}
+
+			advice.addRecommendation(IndexRecommendation{
+				Database:           ref.Database,
+				Table:              ref.Table,
+				Columns:            candidate,
+				Statement:          createIndexStatement(ref, candidate),
+				Rationale:          fmt.Sprintf("%s is read with access type %s; %s", ref.Table, accessDescription(access), strings.Join(reasons, "; ")),
+				Queries:            []int{i + 1},
+				CurrentAccess:      access.accessType,
+				RowsExaminedNow:    access.rows,
+				EstimatedRowsAfter: after,
+				EstimatedBenefit:   strings.Join(benefits, " and "),
+			})
+		}
+	}
+
+	advice.Count = len(advice.Recommendations)
+	return advice, nil
+}
+
+// addRecommendation adds an index, merging it with one on the same table
+// whose columns it starts with or that starts with its columns
+func (a *IndexAdvice) addRecommendation(r IndexRecommendation) {
+	for i, existing := range a.Recommendations {
+		if !strings.EqualFold(existing.Database, r.Database) || !strings.EqualFold(existing.Table, r.Table) {
+			continue
+		}
+		switch {
+		case columnsPrefix(r.Columns, existing.Columns):
+			a.Recommendations[i].Queries = append(existing.Queries, r.Queries...)
+			return
+		case columnsPrefix(existing.Columns, r.Columns):
+			r.Queries = append(existing.Queries, r.Queries...)
+			a.Recommendations[i] = r
+			return
+		}
+	}
+	a.Recommendations = append(a.Recommendations, r)
+}
+
+// collectTableAccesses gathers the table reads of a plan in plan order, and
+// whether any step sorts with a filesort
+func collectTableAccesses(value interface{}, accesses *[]tableAccess, filesort *bool) {
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			collectTableAccesses(item, accesses, filesort)
+		}
+	case map[string]interface{}:
+		if sort, ok := v["using_filesort"].(bool); ok && sort {
+			*filesort = true
+		}
+		if name, ok := v["table_name"].(string); ok && v["access_type"] != nil {
+			access := tableAccess{alias: name, accessType: stringValue(v["access_type"]), filtered: 100}
+			access.key, _ = v["key"].(string)
+			// MySQL reports rows_examined_per_scan; MariaDB reports rows
+			if rows := v["rows_examined_per_scan"]; rows != nil {
+				access.rows = int64Value(rows)
+			} else {
+				access.rows = int64Value(v["rows"])
+			}
+			if filtered, err := strconv.ParseFloat(stringValue(v["filtered"]), 64); err == nil && filtered > 0 {
+				access.filtered = filtered
+			}
+			*accesses = append(*accesses, access)
+		}
+		for _, child := range childKeys(v) {
+			collectTableAccesses(v[child], accesses, filesort)
+		}
+	}
+}
+
+// childKeys returns the keys of a plan object in the order childNodes visits them
+func childKeys(v map[string]interface{}) []string {
+	keys := make([]string, 0, len(v))
+	for k, child := range v {
+		switch child.(type) {
+		case map[string]interface{}, []interface{}:
+			if !planDetailKeys[k] {
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// queryTableRefs maps the alias, or name, of each table a query reads to the table
+func queryTableRefs(query, defaultDatabase string) map[string]TableRef {
+	refs := make(map[string]TableRef)
+	literals := stringLiteralPattern.FindAllStringIndex(query, -1)
+	for _, match := range readTableRefPattern.FindAllStringSubmatchIndex(query, -1) {
+		if insideLiteral(literals, match[0]) {
+			continue
+		}
+		text := query[match[2]:match[3]]
+		ref := TableRef{Database: defaultDatabase, Table: tableName(text)}
+		if parts := strings.SplitN(text, ".", 2); len(parts) == 2 {
+			ref.Database = unquoteIdentifier(parts[0])
+		}
+		key := strings.ToLower(ref.Table)
+		if alias := aliasPattern.FindStringSubmatch(query[match[3]:]); alias != nil && (alias[1] != "" || !notAliases[strings.ToUpper(alias[2])]) {
+			key = strings.ToLower(unquoteIdentifier(alias[2]))
+		}
+		refs[key] = ref
+	}
+	return refs
+}
+
+// resolveAlias returns the alias of the table a column belongs to, or "" when
+// it is not a column of exactly one referenced table
+func resolveAlias(refs map[string]TableRef, columns map[string]map[string]lintColumn, qualifier, name string) string {
+	name = strings.ToLower(unquoteIdentifier(name))
+	if qualifier != "" {
+		alias := strings.ToLower(unquoteIdentifier(qualifier))
+		if _, ok := refs[alias]; !ok {
+			return ""
+		}
+		if _, ok := columns[alias][name]; !ok {
+			return ""
+		}
+		return alias
+	}
+	found := ""
+	for alias := range refs {
+		if _, ok := columns[alias][name]; ok {
+			if found != "" {
+				return ""
+			}
+			found = alias
+		}
+	}
+	return found
+}
+
+// indexPredicates finds the equality, IN, range and prefix LIKE conditions on
+// columns, by table alias. Conditions in a group combined with OR are skipped,
+// since one index cannot serve both sides.
+func indexPredicates(query string, refs map[string]TableRef, columns map[string]map[string]lintColumn) map[string][]indexPredicate {
+	predicates := make(map[string][]indexPredicate)
+	masked := maskLiterals(query)
+	for _, loc := range comparisonPattern.FindAllStringSubmatchIndex(masked, -1) {
+		if groupHasOr(masked, loc[0]) {
+			continue
+		}
+		group := func(n int) string {
+			if loc[2*n] < 0 {
+				return ""
+			}
+			return masked[loc[2*n]:loc[2*n+1]]
+		}
+		alias := resolveAlias(refs, columns, group(1), group(2))
+		if alias == "" {
+			continue
+		}
+		column := unquoteIdentifier(group(2))
+
+		op := strings.ToUpper(strings.Join(strings.Fields(group(3)), ""))
+		switch op {
+		case "<>", "!=":
+			continue
+		case "<", "<=", ">", ">=", "BETWEEN":
+			predicates[alias] = append(predicates[alias], indexPredicate{column: column, rangeOp: true})
+		case "LIKE":
+			// Only a pattern with a fixed prefix can seek an index
+			rest := strings.TrimSpace(query[loc[7]:])
+			if len(rest) < 2 || rest[0] != '\'' || rest[1] == '%' || rest[1] == '_' {
+				continue
+			}
+			predicates[alias] = append(predicates[alias], indexPredicate{column: column, rangeOp: true})
+		default:
+			other := ""
+			if group(4) != "" && op != "IN(" {
+				other = resolveAlias(refs, columns, group(4), group(5))
+				if other == "" || other == alias {
+					continue
+				}
+				predicates[other] = append(predicates[other], indexPredicate{column: unquoteIdentifier(group(5)), joinedTo: alias})
+			}
+			predicates[alias] = append(predicates[alias], indexPredicate{column: column, joinedTo: other})
+		}
+	}
+	return predicates
+}
+
+// groupHasOr reports whether the parenthesized group holding pos, or the
+// statement when there is none, combines conditions with OR
+func groupHasOr(masked string, pos int) bool {
+	start, depth := 0, 0
+	for i := pos - 1; i >= 0; i-- {
+		if masked[i] == ')' {
+			depth++
+		} else if masked[i] == '(' {
+			if depth == 0 {
+				start = i + 1
+				break
+			}
+			depth--
+		}
+	}
+	end := len(masked)
+	depth = 0
+	for i := pos; i < len(masked); i++ {
+		if masked[i] == '(' {
+			depth++
+		} else if masked[i] == ')' {
+			if depth == 0 {
+				end = i
+				break
+			}
+			depth--
+		}
+	}
+	return orPattern.MatchString(blankNested(masked[start:end]))
+}
+
+// queryOrder is the top-level ORDER BY of a query when it sorts by columns
+// of one table in one direction
+type queryOrder struct {
+	alias   string
+	columns []string
+}
+
+// orderByColumns returns the top-level ORDER BY of a query, or an empty order
+// when it sorts by expressions, several tables or mixed directions
+func orderByColumns(query string, refs map[string]TableRef, columns map[string]map[string]lintColumn) queryOrder {
+	masked := maskLiterals(strings.TrimRight(strings.TrimSpace(query), ";"))
+	topLevel := blankNested(masked)
+	orders := orderByPattern.FindAllStringIndex(topLevel, -1)
+	if orders == nil {
+		return queryOrder{}
+	}
+	clause := topLevel[orders[len(orders)-1][1]:]
+	if end := orderEndPattern.FindStringIndex(clause); end != nil {
+		clause = clause[:end[0]]
+	}
+
+	var order queryOrder
+	direction := ""
+	for _, item := range strings.Split(clause, ",") {
+		m := orderItemPattern.FindStringSubmatch(item)
+		if m == nil {
+			return queryOrder{}
+		}
+		alias := resolveAlias(refs, columns, m[1], m[2])
+		dir := strings.ToUpper(m[3])
+		if dir == "" {
+			dir = "ASC"
+		}
+		if alias == "" || (order.alias != "" && alias != order.alias) || (direction != "" && dir != direction) {
+			return queryOrder{}
+		}
+		order.alias, direction = alias, dir
+		order.columns = append(order.columns, unquoteIdentifier(m[2]))
+	}
+	return order
+}
+
+// servingIndex returns the name of an index starting with the candidate's
+// columns, its first equalityCount columns in any order, or ""
+func servingIndex(indexes []Index, candidate []string, equalityCount int) string {
+	for _, index := range indexes {
+		if len(index.Columns) < len(candidate) {
+			continue
+		}
+		serves := true
+		for i, column := range candidate {
+			if i < equalityCount {
+				serves = hasColumn(index.Columns[:equalityCount], column)
+			} else {
+				serves = strings.EqualFold(index.Columns[i], column)
+			}
+			if !serves {
+				break
+			}
+		}
+		if serves {
+			return index.Name
+		}
+	}
+	return ""
+}
+
+// createIndexStatement builds the CREATE INDEX statement for columns of a table,
+// naming the index idx_<table>_<columns> within MySQL's name length limit
+func createIndexStatement(ref TableRef, columns []string) string {
+	name := "idx_" + ref.Table + "_" + strings.Join(columns, "_")
+	if runes := []rune(name); len(runes) > indexNameLimit {
+		name = string(runes[:indexNameLimit])
+	}
+	table := QuoteIdentifier(ref.Table)
+	if ref.Database != "" {
+		table = QuoteIdentifier(ref.Database) + "." + table
+	}
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = QuoteIdentifier(column)
+	}
+	return fmt.Sprintf("CREATE INDEX %s ON %s (%s)", QuoteIdentifier(name), table, strings.Join(quoted, ", "))
+}
+
+// accessDescription describes a table access type for a rationale
+func accessDescription(access tableAccess) string {
+	switch access.accessType {
+	case "ALL":
+		return "ALL (a full table scan)"
+	case "index":
+		return "index (a full scan of " + access.key + ")"
+	}
+	if access.key != "" {
+		return access.accessType + " on " + access.key
+	}
+	return access.accessType
+}
+
+// appendColumn appends a column unless it is already listed
+func appendColumn(columns []string, column string) []string {
+	if hasColumn(columns, column) {
+		return columns
+	}
+	return append(columns, column)
+}
+
+// hasColumn reports whether columns holds column, ignoring case as MySQL does
+func hasColumn(columns []string, column string) bool {
+	for _, c := range columns {
+		if strings.EqualFold(c, column) {
+			return true
+		}
+	}
+	return false
+}
+
+// columnsPrefix reports whether prefix lists the first columns of columns
+func columnsPrefix(prefix, columns []string) bool {
+	if len(prefix) > len(columns) {
+		return false
+	}
+	for i, column := range prefix {
+		if !strings.EqualFold(column, columns[i]) {
+			return false
+		}
+	}
+	return true
+}
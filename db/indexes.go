@@ -0,0 +1,42 @@
+package db
+
+// Index is one index of a table with its columns in index order
+type Index struct {
+	Name    string   `json:"name"`
+	Unique  bool     `json:"unique"`
+	Columns []string `json:"columns"`
+}
+
+// GetIndexes returns the indexes of a table in database, or in the
+// connection's default database when database is empty
+func (m *Manager) GetIndexes(connectionName, database, table string) ([]Index, error) {
+	query := "SHOW INDEX FROM " + QuoteIdentifier(table)
+	if database != "" {
+		query = "SHOW INDEX FROM " + QuoteIdentifier(database) + "." + QuoteIdentifier(table)
+	}
+
+	result, err := m.ExecuteQuery(connectionName, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// SHOW INDEX lists each index's columns in order, one row per column
+	indexes := []Index{}
+	positions := make(map[string]int)
+	for _, row := range result.Rows {
+		name := stringValue(row["Key_name"])
+		if name == "" {
+			continue
+		}
+		i, exists := positions[name]
+		if !exists {
+			i = len(indexes)
+			positions[name] = i
+			indexes = append(indexes, Index{Name: name, Unique: stringValue(row["Non_unique"]) == "0", Columns: []string{}})
+		}
+		if column := stringValue(row["Column_name"]); column != "" {
+			indexes[i].Columns = append(indexes[i].Columns, column)
+		}
+	}
+	return indexes, nil
+}
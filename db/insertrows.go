@@ -0,0 +1,188 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"mysql-golang-mcp/config"
+)
+
+// insertTargetPattern matches the head of INSERT [INTO] <table>
+var insertTargetPattern = regexp.MustCompile(`(?is)^\s*INSERT\s+(?:(?:LOW_PRIORITY|DELAYED|HIGH_PRIORITY|IGNORE)\s+)*(?:INTO\s+)?(` +
+	identifierPart + `(?:\.` + identifierPart + `)?)`)
+
+// onDuplicateKeyPattern matches an INSERT's ON DUPLICATE KEY UPDATE clause
+var onDuplicateKeyPattern = regexp.MustCompile(`(?i)\bON\s+DUPLICATE\s+KEY\s+UPDATE\b`)
+
+// executeInsertReturning runs an INSERT and reads the rows it added back in
+// the same transaction, so generated defaults, timestamps and AUTO_INCREMENT
+// values are returned without a follow-up SELECT. Rows are found by keys when
+// given, otherwise by the AUTO_INCREMENT values the INSERT generated. Keys
+// may only find rows the INSERT added, or changed through ON DUPLICATE KEY
+// UPDATE, and rows are read through the table's allowed_columns.
+func (m *Manager) executeInsertReturning(conn *sql.Conn, connectionName string, connConfig *config.ConnectionConfig, database, query string, args []interface{}, keys []map[string]interface{}) (*WriteResult, error) {
+	masked := maskLiterals(query)
+	if hasReturningClause(query) {
+		return nil, fmt.Errorf("return_rows cannot be combined with RETURNING")
+	}
+	loc := insertTargetPattern.FindStringSubmatchIndex(masked)
+	if loc == nil {
+		return nil, fmt.Errorf("return_rows only supports INSERT [INTO] <table> statements")
+	}
+	ref := strings.TrimSpace(query[loc[2]:loc[3]])
+	targetDatabase, table := database, tableName(ref)
+	if parts := strings.SplitN(ref, ".", 2); len(parts) == 2 {
+		targetDatabase = unquoteIdentifier(parts[0])
+	}
+	upsert := onDuplicateKeyPattern.MatchString(masked)
+	if len(keys) == 0 && upsert {
+		return nil, fmt.Errorf("return_rows cannot find the rows of INSERT ... ON DUPLICATE KEY UPDATE by AUTO_INCREMENT value; pass return_keys")
+	}
+
+	projection := "*"
+	allowed := allowedColumns(connConfig, table)
+	if len(allowed) > 0 {
+		quoted := make([]string, len(allowed))
+		for i, col := range allowed {
+			quoted[i] = QuoteIdentifier(col)
+		}
+		projection = strings.Join(quoted, ", ")
+		for i, key := range keys {
+			for col := range key {
+				if !columnAllowed(allowed, col) {
+					return nil, fmt.Errorf("return_keys entry %d names column %s, which is not in the allowed_columns of %s", i+1, col, table)
+				}
+			}
+		}
+	}
+
+	ctx := context.Background()
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Rows the keys find before the INSERT were not added by it; they are
+	// only returned when ON DUPLICATE KEY UPDATE changed them
+	var selectSQL string
+	var selectArgs []interface{}
+	existing := map[string]bool{}
+	if len(keys) > 0 {
+		selectSQL, selectArgs, err = keyedSelect(targetDatabase, table, projection, keys)
+		if err != nil {
+			return nil, err
+		}
+		rows, err := tx.QueryContext(ctx, selectSQL, selectArgs...)
+		if err != nil {
+			m.recordError(connectionName, selectSQL, err)
+			return nil, fmt.Errorf("failed to read the rows matching return_keys: %w", err)
+		}
+		before, err := scanRows(rows, connConfig.MaxRows)
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+		if before.Truncated || len(before.Rows) > 0 && !upsert {
+			return nil, fmt.Errorf("return_keys match rows that exist before the INSERT; keys must identify the rows it inserts")
+		}
+		for _, row := range before.Rows {
+			existing[rowKey(row, before.Columns)] = true
+		}
+	}
+
+	start := time.Now()
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		m.recordError(connectionName, query, err)
+		return nil, fmt.Errorf("query execution failed: %w", err)
+	}
+	elapsed := time.Since(start)
+	warnings := fetchWarnings(tx)
+	rowsAffected, _ := result.RowsAffected()
+	lastInsertID, _ := result.LastInsertId()
+
+	if len(keys) == 0 {
+		if lastInsertID == 0 {
+			return nil, fmt.Errorf("the INSERT generated no AUTO_INCREMENT value to find its rows by, so it was rolled back; pass return_keys")
+		}
+		var column string
+		err := tx.QueryRowContext(ctx, `SELECT COLUMN_NAME FROM information_schema.COLUMNS
+			WHERE TABLE_SCHEMA = COALESCE(?, DATABASE()) AND TABLE_NAME = ? AND EXTRA LIKE '%auto_increment%'`,
+			nullIfEmpty(targetDatabase), table).Scan(&column)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the AUTO_INCREMENT column of %s, so the INSERT was rolled back: %w", table, err)
+		}
+		// A multi-row INSERT's generated values run on from LAST_INSERT_ID()
+		selectSQL = fmt.Sprintf("SELECT %s FROM %s WHERE %s BETWEEN ? AND ?", projection, QualifiedName(targetDatabase, table), QuoteIdentifier(column))
+		selectArgs = []interface{}{lastInsertID, lastInsertID + rowsAffected - 1}
+	}
+
+	rows, err := tx.QueryContext(ctx, selectSQL, selectArgs...)
+	if err != nil {
+		m.recordError(connectionName, selectSQL, err)
+		return nil, fmt.Errorf("failed to read inserted rows, so the INSERT was rolled back: %w", err)
+	}
+	returning, err := scanRows(rows, connConfig.MaxRows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(returning.Rows)) > rowsAffected {
+		return nil, fmt.Errorf("return_keys match %d rows but the INSERT affected %d, so it was rolled back; keys must identify the rows it inserts", len(returning.Rows), rowsAffected)
+	}
+	for _, row := range returning.Rows {
+		if existing[rowKey(row, returning.Columns)] {
+			return nil, fmt.Errorf("return_keys match a row the INSERT did not add or change, so it was rolled back; keys must identify the rows it inserts")
+		}
+	}
+	returning.Connection = connectionName
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+	slog.Debug("statement executed", "connection", connectionName, "sql", query, "duration_ms", elapsed.Milliseconds())
+
+	return &WriteResult{
+		RowsAffected: rowsAffected,
+		LastInsertID: lastInsertID,
+		Returning:    returning,
+		Warning:      riskWarning(connectionName, connConfig),
+		ExecutionMs:  elapsed.Milliseconds(),
+		Warnings:     warnings,
+		Connection:   connectionName,
+		Database:     database,
+	}, nil
+}
+
+// keyedSelect builds the SELECT reading projection from the rows matching any
+// of keys, each an object of column values such as a unique key of an
+// inserted row
+func keyedSelect(database, table, projection string, keys []map[string]interface{}) (string, []interface{}, error) {
+	var conditions []string
+	var args []interface{}
+	for i, key := range keys {
+		if len(key) == 0 {
+			return "", nil, fmt.Errorf("return_keys entry %d has no columns", i+1)
+		}
+		columns := make([]string, 0, len(key))
+		for col := range key {
+			columns = append(columns, col)
+		}
+		sort.Strings(columns)
+
+		terms := make([]string, len(columns))
+		for j, col := range columns {
+			terms[j] = QuoteIdentifier(col) + " <=> ?"
+			args = append(args, key[col])
+		}
+		conditions = append(conditions, "("+strings.Join(terms, " AND ")+")")
+	}
+	return fmt.Sprintf("SELECT %s FROM %s WHERE %s", projection, QualifiedName(database, table), strings.Join(conditions, " OR ")), args, nil
+}
@@ -0,0 +1,156 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// janitorInterval is how often idle connection pools are looked for
+const janitorInterval = 30 * time.Second
+
+// PoolHealth describes a connection's pool: whether it is open, when a tool
+// last used it, and the database/sql pool statistics of the open pool
+type PoolHealth struct {
+	Connection         string `json:"connection"`
+	Open               bool   `json:"open"`
+	IdleTimeoutSeconds int    `json:"idle_timeout_seconds"`
+	MaxLifetimeSeconds int    `json:"max_lifetime_seconds"`
+
+	// LastUsed is when a tool last used the connection, and IdleSeconds how
+	// long ago that was; both are omitted before its first use
+	LastUsed    string `json:"last_used,omitempty"`
+	IdleSeconds int64  `json:"idle_seconds,omitempty"`
+
+	// IdleEvictions counts the times the pool was closed for being unused
+	// longer than idle_timeout_seconds
+	IdleEvictions int64  `json:"idle_evictions"`
+	LastEvicted   string `json:"last_evicted,omitempty"`
+
+	OpenConnections int   `json:"open_connections"`
+	InUse           int   `json:"in_use"`
+	Idle            int   `json:"idle"`
+	WaitCount       int64 `json:"wait_count"`
+	WaitMs          int64 `json:"wait_ms"`
+
+	// Connections the open pool closed for exceeding the idle limit, being
+	// idle longer than idle_timeout_seconds, or reaching max_lifetime_seconds
+	ClosedMaxIdle     int64 `json:"closed_max_idle"`
+	ClosedIdleTime    int64 `json:"closed_idle_time"`
+	ClosedMaxLifetime int64 `json:"closed_max_lifetime"`
+}
+
+// poolUsage is when a connection was last used and how often its pool was evicted
+type poolUsage struct {
+	lastUsed    time.Time
+	evictions   int64
+	lastEvicted time.Time
+}
+
+// touchConnection records that a tool is using the connection now
+func (m *Manager) touchConnection(name string) {
+	m.usageMu.Lock()
+	defer m.usageMu.Unlock()
+	usage, ok := m.usage[name]
+	if !ok {
+		usage = &poolUsage{}
+		m.usage[name] = usage
+	}
+	usage.lastUsed = time.Now()
+}
+
+// runJanitor closes idle connection pools every janitorInterval until stop is closed
+func (m *Manager) runJanitor(stop <-chan struct{}) {
+	ticker := time.NewTicker(janitorInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			m.evictIdlePools(now)
+		}
+	}
+}
+
+// evictIdlePools closes the pools of connections no tool has used for their
+// idle_timeout_seconds, so no connection to the server stays open while the
+// client is idle. Pools with a connection in use, such as one pinned by a
+// cursor, session or transaction, are kept.
+func (m *Manager) evictIdlePools(now time.Time) {
+	var evicted []*sql.DB
+	m.mu.Lock()
+	m.usageMu.Lock()
+	for name, db := range m.connections {
+		connConfig, exists := m.lookupConnection(name)
+		usage := m.usage[name]
+		if !exists || usage == nil {
+			continue
+		}
+		if now.Sub(usage.lastUsed) < time.Duration(connConfig.IdleTimeoutSeconds)*time.Second || db.Stats().InUse > 0 {
+			continue
+		}
+		delete(m.connections, name)
+		usage.evictions++
+		usage.lastEvicted = now
+		evicted = append(evicted, db)
+		slog.Info("closing idle connection pool", "connection", name, "idle_seconds", int64(now.Sub(usage.lastUsed).Seconds()))
+	}
+	m.usageMu.Unlock()
+	m.mu.Unlock()
+
+	for _, db := range evicted {
+		db.Close()
+	}
+}
+
+// PoolHealth reports the pool of the named connection, or of every
+// connection when name is empty
+func (m *Manager) PoolHealth(name string) ([]PoolHealth, error) {
+	names := m.ConnectionNames()
+	if name != "" {
+		if _, exists := m.lookupConnection(name); !exists {
+			return nil, fmt.Errorf("unknown connection: %s", name)
+		}
+		names = []string{name}
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	m.usageMu.Lock()
+	defer m.usageMu.Unlock()
+
+	now := time.Now()
+	result := make([]PoolHealth, 0, len(names))
+	for _, name := range names {
+		connConfig, _ := m.lookupConnection(name)
+		health := PoolHealth{
+			Connection:         name,
+			IdleTimeoutSeconds: connConfig.IdleTimeoutSeconds,
+			MaxLifetimeSeconds: connConfig.MaxLifetimeSeconds,
+		}
+		if usage := m.usage[name]; usage != nil {
+			health.LastUsed = usage.lastUsed.UTC().Format(time.RFC3339)
+			health.IdleSeconds = int64(now.Sub(usage.lastUsed).Seconds())
+			health.IdleEvictions = usage.evictions
+			if !usage.lastEvicted.IsZero() {
+				health.LastEvicted = usage.lastEvicted.UTC().Format(time.RFC3339)
+			}
+		}
+		if db, open := m.connections[name]; open {
+			stats := db.Stats()
+			health.Open = true
+			health.OpenConnections = stats.OpenConnections
+			health.InUse = stats.InUse
+			health.Idle = stats.Idle
+			health.WaitCount = stats.WaitCount
+			health.WaitMs = stats.WaitDuration.Milliseconds()
+			health.ClosedMaxIdle = stats.MaxIdleClosed
+			health.ClosedIdleTime = stats.MaxIdleTimeClosed
+			health.ClosedMaxLifetime = stats.MaxLifetimeClosed
+		}
+		result = append(result, health)
+	}
+	return result, nil
+}
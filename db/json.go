@@ -0,0 +1,118 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// JSONExtract describes a structured query that extracts a path from a JSON column
+type JSONExtract struct {
+	StructuredQuery
+
+	// Column is the JSON column and Path the JSON path to extract from it;
+	// a path without a leading $ is taken relative to the document root
+	Column string
+	Path   string
+
+	// Unquote returns scalar values as plain strings (->>) rather than JSON (->)
+	Unquote bool
+
+	// Alias names the extracted column; it defaults to the path
+	Alias string
+}
+
+// NormalizeJSONPath prefixes a path with $ when it is given relative to the
+// document root, so "address.city" and "[0]" become "$.address.city" and "$[0]"
+func NormalizeJSONPath(path string) string {
+	path = strings.TrimSpace(path)
+	switch {
+	case path == "" || strings.HasPrefix(path, "$"):
+		return path
+	case strings.HasPrefix(path, "["):
+		return "$" + path
+	default:
+		return "$." + strings.TrimPrefix(path, ".")
+	}
+}
+
+// Expression returns the MySQL shorthand for the extraction, such as
+// `doc`->>'$.address.city', for use in hand-written queries. The -> and ->>
+// operators are not available on MariaDB.
+func (q JSONExtract) Expression() string {
+	op := "->"
+	if q.Unquote {
+		op = "->>"
+	}
+	return QuoteIdentifier(q.Column) + op + quoteStringLiteral(NormalizeJSONPath(q.Path))
+}
+
+// BuildJSONExtract builds a parameterized SELECT of the requested columns plus
+// the extracted path. It uses JSON_EXTRACT and JSON_UNQUOTE rather than the
+// -> operators so it also runs on MariaDB, and binds the path as a parameter.
+func BuildJSONExtract(q JSONExtract) (string, []interface{}, error) {
+	if q.Table == "" {
+		return "", nil, fmt.Errorf("table is required")
+	}
+	if q.Column == "" {
+		return "", nil, fmt.Errorf("column is required")
+	}
+	path := NormalizeJSONPath(q.Path)
+	if path == "" {
+		return "", nil, fmt.Errorf("path is required")
+	}
+
+	alias := q.Alias
+	if alias == "" {
+		alias = path
+	}
+	expr := fmt.Sprintf("JSON_EXTRACT(%s, ?)", QuoteIdentifier(q.Column))
+	if q.Unquote {
+		expr = "JSON_UNQUOTE(" + expr + ")"
+	}
+
+	projection := make([]string, 0, len(q.Columns)+1)
+	for _, col := range q.Columns {
+		projection = append(projection, QuoteIdentifier(col))
+	}
+	projection = append(projection, expr+" AS "+QuoteIdentifier(alias))
+
+	where, whereArgs, err := buildWhere(q.Filters)
+	if err != nil {
+		return "", nil, err
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(projection, ", "), QualifiedName(q.Database, q.Table))
+	query += where + buildOrderBy(q.OrderBy) + buildLimit(q.Limit)
+
+	return query, append([]interface{}{path}, whereArgs...), nil
+}
+
+// ParseJSONColumns replaces the text of JSON-typed cells with the parsed
+// document, so they appear as nested JSON rather than escaped strings.
+// Numbers keep their exact text. Cells that fail to parse are left as strings.
+func (r *QueryResult) ParseJSONColumns() {
+	for _, ct := range r.ColumnTypes {
+		if ct.DatabaseType != "JSON" {
+			continue
+		}
+		for _, row := range r.Rows {
+			s, ok := row[ct.Name].(string)
+			if !ok {
+				continue
+			}
+			dec := json.NewDecoder(strings.NewReader(s))
+			dec.UseNumber()
+			var v interface{}
+			if err := dec.Decode(&v); err == nil {
+				row[ct.Name] = v
+			}
+		}
+	}
+}
+
+// quoteStringLiteral renders a string as a single-quoted SQL literal
+func quoteStringLiteral(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,227 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// keysetAlias names the derived table a paginated query is wrapped in
+const keysetAlias = "_keyset"
+
+var (
+	// trailingLimitPattern matches a LIMIT clause ending the statement:
+	// LIMIT n, LIMIT offset, n or LIMIT n OFFSET offset
+	trailingLimitPattern = regexp.MustCompile(`(?is)\bLIMIT\s+(\d+)(?:\s*,\s*(\d+)|\s+OFFSET\s+(\d+))?\s*$`)
+	orderByPattern       = regexp.MustCompile(`(?i)\bORDER\s+BY\b`)
+	paginateByPattern    = regexp.MustCompile(`(?i)^\s*(.+?)(?:\s+(ASC|DESC))?\s*$`)
+)
+
+// KeysetPage describes a page read with paginate_by: rows ordered by one
+// column and starting after a key instead of skipping an OFFSET
+type KeysetPage struct {
+	Column     string      `json:"column"`
+	Descending bool        `json:"descending,omitempty"`
+	After      interface{} `json:"after,omitempty"`
+
+	// ReplacedOffset is the query's OFFSET when it was converted to the key
+	// of the row before the page
+	ReplacedOffset int64 `json:"replaced_offset,omitempty"`
+
+	// NextKey is the after value for the next page; nil on the last page
+	NextKey interface{} `json:"next_key"`
+	HasMore bool        `json:"has_more"`
+
+	RewrittenSQL string `json:"rewritten_sql"`
+
+	// keys holds each row's key, so the next key survives token_budget
+	// truncating or dropping the column
+	keys []interface{}
+}
+
+// keysetQuery is a SELECT split for keyset pagination
+type keysetQuery struct {
+	base   string
+	limit  int
+	offset int64
+}
+
+// parseKeysetQuery removes the top-level ORDER BY and LIMIT of a SELECT,
+// returning the rest with the LIMIT's row count (0 when absent) and offset
+func parseKeysetQuery(query string) (*keysetQuery, error) {
+	trimmed := strings.TrimRight(strings.TrimSpace(query), ";")
+	topLevel := blankNested(maskLiterals(trimmed))
+
+	kq := &keysetQuery{}
+	cut := len(trimmed)
+	if m := trailingLimitPattern.FindStringSubmatchIndex(topLevel); m != nil {
+		cut = m[0]
+		count, offset := topLevel[m[2]:m[3]], ""
+		switch {
+		case m[4] >= 0:
+			count, offset = topLevel[m[4]:m[5]], topLevel[m[2]:m[3]]
+		case m[6] >= 0:
+			offset = topLevel[m[6]:m[7]]
+		}
+		limit, err := strconv.Atoi(count)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LIMIT %s", count)
+		}
+		kq.limit = limit
+		if offset != "" {
+			if kq.offset, err = strconv.ParseInt(offset, 10, 64); err != nil {
+				return nil, fmt.Errorf("invalid OFFSET %s", offset)
+			}
+		}
+	} else if limitPattern.MatchString(topLevel) {
+		return nil, fmt.Errorf("paginate_by needs the query's LIMIT clause at its end")
+	}
+	if orders := orderByPattern.FindAllStringIndex(topLevel[:cut], -1); orders != nil {
+		cut = orders[len(orders)-1][0]
+	}
+
+	kq.base = strings.TrimSpace(trimmed[:cut])
+	return kq, nil
+}
+
+// SelectPage runs a SELECT one page at a time by keyset pagination. The
+// query's ORDER BY is replaced by paginateBy, a column of its select list
+// optionally followed by ASC or DESC, and the page holds the rows whose key
+// comes after the given key. Without after, a LIMIT's OFFSET is converted to
+// the key of the row before the page, so later pages can pass the returned
+// next_key instead of growing the OFFSET. The query's LIMIT row count (or
+// opts.MaxRows) is the page size. The column should be unique and non-NULL,
+// or rows sharing a key at a page boundary are skipped.
+func (m *Manager) SelectPage(connectionName, query, paginateBy string, after interface{}, opts QueryOptions) (*QueryResult, error) {
+	if err := ValidateQueryType(query, QueryTypeSelect); err != nil {
+		return nil, err
+	}
+	_, connConfig, err := m.GetConnection(connectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := paginateByPattern.FindStringSubmatch(paginateBy)
+	if spec == nil {
+		return nil, fmt.Errorf("paginate_by must name a column, optionally followed by ASC or DESC")
+	}
+	// A qualified column is named by its last part in the wrapped result
+	parts := strings.Split(spec[1], ".")
+	column := unquoteIdentifier(parts[len(parts)-1])
+	if column == "" {
+		return nil, fmt.Errorf("paginate_by must name a column, optionally followed by ASC or DESC")
+	}
+	page := &KeysetPage{Column: column, Descending: strings.EqualFold(spec[2], "DESC"), After: after}
+
+	kq, err := parseKeysetQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	pageSize := kq.limit
+	if pageSize == 0 {
+		pageSize = opts.MaxRows
+	}
+	if pageSize == 0 || pageSize > connConfig.MaxRows {
+		pageSize = connConfig.MaxRows
+	}
+
+	wrapped := "SELECT * FROM (" + kq.base + ") AS " + keysetAlias
+	order := " ORDER BY " + QuoteIdentifier(column)
+	comparison := " > ?"
+	if page.Descending {
+		order += " DESC"
+		comparison = " < ?"
+	}
+
+	// Find the key of the last row the OFFSET skips, reading only that column
+	exhausted := false
+	if after == nil && kq.offset > 0 {
+		page.ReplacedOffset = kq.offset
+		boundaryQuery := "SELECT " + QuoteIdentifier(column) + " FROM (" + kq.base + ") AS " + keysetAlias + order + fmt.Sprintf(" LIMIT 1 OFFSET %d", kq.offset-1)
+		boundary, err := m.ExecuteQueryWithOptions(connectionName, boundaryQuery, QueryOptions{
+			MaxRows:            1,
+			ExcludeSoftDeleted: opts.ExcludeSoftDeleted,
+			Database:           opts.Database,
+			Context:            opts.Context,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(boundary.Rows) == 0 {
+			// The OFFSET is past the last row, so the page is empty
+			exhausted = true
+		} else {
+			after = columnValue(boundary.Rows[0], column)
+			page.After = after
+		}
+	}
+
+	rewritten := wrapped
+	var args []interface{}
+	if exhausted {
+		rewritten += " LIMIT 0"
+	} else {
+		if after != nil {
+			rewritten += " WHERE " + QuoteIdentifier(column) + comparison
+			args = append(args, after)
+		}
+		// Read one row past the page to learn whether another page follows
+		rewritten += order + fmt.Sprintf(" LIMIT %d", pageSize+1)
+	}
+	page.RewrittenSQL = rewritten
+
+	// Lint the query as written rather than its rewrite
+	var lint []LintFinding
+	if opts.Lint && connConfig.LintSelects {
+		if report, err := m.lintQuery(connectionName, opts.Database, query); err == nil {
+			lint = report.Findings
+		}
+	}
+	opts.Lint = false
+	opts.MaxRows = pageSize
+
+	result, err := m.ExecuteQueryWithOptions(connectionName, rewritten, opts, args...)
+	if err != nil {
+		return nil, err
+	}
+	result.Lint = lint
+
+	page.keys = make([]interface{}, len(result.Rows))
+	for i, row := range result.Rows {
+		page.keys[i] = columnValue(row, column)
+	}
+	page.setNextKey(len(result.Rows), result.Truncated)
+	result.Page = page
+	return result, nil
+}
+
+// setNextKey sets the key the next page starts after, when more rows follow
+// the first kept rows of the page
+func (p *KeysetPage) setNextKey(kept int, hasMore bool) {
+	p.HasMore = hasMore
+	p.NextKey = nil
+	if !hasMore {
+		return
+	}
+	if kept == 0 {
+		// Nothing was returned, so the next page starts where this one did
+		p.NextKey = p.After
+		return
+	}
+	p.NextKey = p.keys[kept-1]
+}
+
+// columnValue returns a row's value for column, matching its name case-insensitively
+// as MySQL does
+func columnValue(row map[string]interface{}, column string) interface{} {
+	if v, ok := row[column]; ok {
+		return v
+	}
+	for name, v := range row {
+		if strings.EqualFold(name, column) {
+			return v
+		}
+	}
+	return nil
+}
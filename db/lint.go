@@ -0,0 +1,331 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// LintFinding is one anti-pattern detected in a query
+type LintFinding struct {
+	Rule       string `json:"rule"`
+	Severity   string `json:"severity"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// LintReport holds the findings for a linted query
+type LintReport struct {
+	Findings []LintFinding `json:"findings"`
+	Count    int           `json:"count"`
+}
+
+// lintColumn is what the linter needs to know about a column
+type lintColumn struct {
+	dataType     string
+	leadsAnIndex bool
+}
+
+var (
+	selectStarPattern    = regexp.MustCompile(`(?i)\bSELECT\s+(?:DISTINCT\s+)?(?:\w+\.)?\*`)
+	existsOpenPattern    = regexp.MustCompile(`(?i)\bEXISTS\s*\(\s*$`)
+	aggregatePattern     = regexp.MustCompile(`(?i)\b(?:COUNT|SUM|AVG|MIN|MAX)\s*\(`)
+	fromClausePattern    = regexp.MustCompile(`(?is)\bFROM\b(.*?)(?:\bWHERE\b|\bGROUP\s+BY\b|\bHAVING\b|\bORDER\s+BY\b|\bLIMIT\b|\bWINDOW\b|\bUNION\b|\bFOR\b|\bLOCK\b|$)`)
+	whereClausePattern   = regexp.MustCompile(`(?is)\bWHERE\b(.*?)(?:\bGROUP\s+BY\b|\bHAVING\b|\bORDER\s+BY\b|\bLIMIT\b|\bWINDOW\b|\bUNION\b|\bFOR\b|\bLOCK\b|$)`)
+	joinPredicatePattern = regexp.MustCompile(`(\w+)\.\w+\s*(?:=|<=>)\s*(\w+)\.\w+`)
+	joinPattern          = regexp.MustCompile(`(?i)\b(CROSS\s+|NATURAL\s+(?:LEFT\s+|RIGHT\s+)?(?:OUTER\s+)?)?JOIN\b`)
+	joinConditionPattern = regexp.MustCompile(`(?i)\b(?:ON|USING)\b`)
+	fromPattern          = regexp.MustCompile(`(?i)\bFROM\b`)
+	wherePattern         = regexp.MustCompile(`(?i)\bWHERE\b`)
+	limitPattern         = regexp.MustCompile(`(?i)\bLIMIT\b`)
+	groupByPattern       = regexp.MustCompile(`(?i)\bGROUP\s+BY\b`)
+
+	numericComparisonPattern = regexp.MustCompile(`(?i)(?:\b(\w+)\.)?\b(\w+)\s*(?:=|<>|!=|<=>|<=|>=|<|>|\bIN\s*\()\s*(-?\d+(?:\.\d+)?)\b`)
+	functionOnColumnPattern  = regexp.MustCompile(`(?i)\b(\w+)\s*\(\s*(?:(\w+)\.)?(\w+)\b[^()]*\)\s*(?:=|<>|!=|<=>|<=|>=|<|>|\bLIKE\b|\bIN\b|\bBETWEEN\b)`)
+	leadingWildcardPattern   = regexp.MustCompile(`(?i)(?:\b(\w+)\.)?\b(\w+)\s+LIKE\s+'%`)
+)
+
+// notFunctions are keywords that can precede a parenthesis without being a function call
+var notFunctions = map[string]bool{
+	"IN": true, "EXISTS": true, "AND": true, "OR": true, "NOT": true, "ON": true, "WHERE": true,
+	"SELECT": true, "FROM": true, "JOIN": true, "USING": true, "VALUES": true, "AS": true, "ANY": true, "ALL": true,
+}
+
+// stringTypes are column data types that hold text
+var stringTypes = map[string]bool{
+	"char": true, "varchar": true, "tinytext": true, "text": true, "mediumtext": true, "longtext": true, "enum": true, "set": true,
+}
+
+// LintQuery checks a SELECT for common anti-patterns without running it.
+// Lexical rules (SELECT *, missing LIMIT, cartesian joins) need no database
+// access; the implicit conversion and non-sargable rules look up the
+// referenced tables' column types and indexes.
+func (m *Manager) LintQuery(connectionName, query string) (*LintReport, error) {
+	return m.lintQuery(connectionName, "", query)
+}
+
+// lintQuery lints a query whose unqualified tables live in database, or in the
+// connection's default database when database is empty
+func (m *Manager) lintQuery(connectionName, database, query string) (*LintReport, error) {
+	if err := ValidateQueryType(query, QueryTypeSelect); err != nil {
+		return nil, err
+	}
+	if _, _, err := m.GetConnection(connectionName); err != nil {
+		return nil, err
+	}
+
+	masked := maskLiterals(query)
+	topLevel := blankNested(masked)
+
+	var findings []LintFinding
+	findings = append(findings, lintSelectStar(masked)...)
+	findings = append(findings, lintMissingLimit(topLevel)...)
+	findings = append(findings, lintCartesianJoins(topLevel)...)
+
+	columns := m.lintColumns(connectionName, database, query)
+	findings = append(findings, lintImplicitConversions(masked, columns)...)
+	findings = append(findings, lintNonSargable(query, masked, columns)...)
+
+	if findings == nil {
+		findings = []LintFinding{}
+	}
+	return &LintReport{Findings: findings, Count: len(findings)}, nil
+}
+
+// blankNested replaces everything inside parentheses with spaces, leaving the
+// top-level statement with its offsets intact
+func blankNested(s string) string {
+	b := []byte(s)
+	depth := 0
+	for i, c := range b {
+		switch {
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth > 0 {
+				depth--
+			}
+		case depth > 0:
+			b[i] = ' '
+		}
+	}
+	return string(b)
+}
+
+// lintSelectStar flags SELECT * outside of EXISTS subqueries
+func lintSelectStar(masked string) []LintFinding {
+	for _, loc := range selectStarPattern.FindAllStringIndex(masked, -1) {
+		if existsOpenPattern.MatchString(masked[:loc[0]]) {
+			continue
+		}
+		return []LintFinding{{
+			Rule:       "select_star",
+			Severity:   "warning",
+			Message:    "SELECT * returns every column, including ones the caller may not need",
+			Suggestion: "List only the columns you need; it reduces I/O and response size and can let an index cover the query",
+		}}
+	}
+	return nil
+}
+
+// lintMissingLimit flags a top-level SELECT from a table without LIMIT, unless
+// it is an aggregate without GROUP BY (which returns a single row)
+func lintMissingLimit(topLevel string) []LintFinding {
+	if !fromPattern.MatchString(topLevel) || limitPattern.MatchString(topLevel) {
+		return nil
+	}
+	if aggregatePattern.MatchString(topLevel) && !groupByPattern.MatchString(topLevel) {
+		return nil
+	}
+	return []LintFinding{{
+		Rule:       "missing_limit",
+		Severity:   "info",
+		Message:    "The query has no LIMIT, so it returns every matching row (truncated at max_rows)",
+		Suggestion: "Add a LIMIT, with an ORDER BY if you need a deterministic subset",
+	}}
+}
+
+// lintCartesianJoins flags comma joins without a join predicate in WHERE and
+// JOINs without ON or USING
+func lintCartesianJoins(topLevel string) []LintFinding {
+	var findings []LintFinding
+
+	if from := fromClausePattern.FindStringSubmatch(topLevel); from != nil && strings.Contains(from[1], ",") {
+		joined := false
+		if where := whereClausePattern.FindStringSubmatch(topLevel); where != nil {
+			for _, m := range joinPredicatePattern.FindAllStringSubmatch(where[1], -1) {
+				if !strings.EqualFold(m[1], m[2]) {
+					joined = true
+					break
+				}
+			}
+		}
+		if !joined {
+			findings = append(findings, LintFinding{
+				Rule:       "cartesian_join",
+				Severity:   "warning",
+				Message:    "Tables are listed with commas in FROM but WHERE has no condition relating them, so every row is paired with every other row",
+				Suggestion: "Use explicit JOIN ... ON with the join condition",
+			})
+		}
+	}
+
+	joins := joinPattern.FindAllStringSubmatchIndex(topLevel, -1)
+	for i, loc := range joins {
+		if loc[2] != -1 {
+			// CROSS JOIN is explicit; NATURAL JOIN has an implied condition
+			continue
+		}
+		end := len(topLevel)
+		if i+1 < len(joins) {
+			end = joins[i+1][0]
+		}
+		segment := topLevel[loc[1]:end]
+		if where := wherePattern.FindStringIndex(segment); where != nil {
+			segment = segment[:where[0]]
+		}
+		if !joinConditionPattern.MatchString(segment) {
+			findings = append(findings, LintFinding{
+				Rule:       "cartesian_join",
+				Severity:   "warning",
+				Message:    "A JOIN has no ON or USING clause, which MySQL treats as a cross join",
+				Suggestion: "Add the join condition with ON, or write CROSS JOIN if the cross product is intended",
+			})
+			break
+		}
+	}
+
+	return findings
+}
+
+// lintColumns looks up the data type and index use of every column in the
+// tables a query references, keyed by table alias and by table name. Tables
+// that cannot be described (derived tables, CTEs) are skipped.
+func (m *Manager) lintColumns(connectionName, defaultDatabase, query string) map[string]map[string]lintColumn {
+	tables := make(map[string]map[string]lintColumn)
+	literals := stringLiteralPattern.FindAllStringIndex(query, -1)
+
+	for _, match := range readTableRefPattern.FindAllStringSubmatchIndex(query, -1) {
+		if insideLiteral(literals, match[0]) {
+			continue
+		}
+		ref := query[match[2]:match[3]]
+		database := defaultDatabase
+		if parts := strings.SplitN(ref, ".", 2); len(parts) == 2 {
+			database = unquoteIdentifier(parts[0])
+		}
+		table := tableName(ref)
+
+		columns, ok := tables[strings.ToLower(table)]
+		if !ok {
+			result, err := m.ExecuteQuery(connectionName, `SELECT c.COLUMN_NAME, c.DATA_TYPE, MAX(s.SEQ_IN_INDEX = 1) AS LEADS_INDEX
+				FROM information_schema.COLUMNS c
+				LEFT JOIN information_schema.STATISTICS s
+					ON s.TABLE_SCHEMA = c.TABLE_SCHEMA AND s.TABLE_NAME = c.TABLE_NAME AND s.COLUMN_NAME = c.COLUMN_NAME
+				WHERE c.TABLE_SCHEMA = COALESCE(?, DATABASE()) AND c.TABLE_NAME = ?
+				GROUP BY c.COLUMN_NAME, c.DATA_TYPE`, nullIfEmpty(database), table)
+			if err != nil || len(result.Rows) == 0 {
+				continue
+			}
+			columns = make(map[string]lintColumn, len(result.Rows))
+			for _, row := range result.Rows {
+				columns[strings.ToLower(stringValue(row["COLUMN_NAME"]))] = lintColumn{
+					dataType:     strings.ToLower(stringValue(row["DATA_TYPE"])),
+					leadsAnIndex: int64Value(row["LEADS_INDEX"]) == 1,
+				}
+			}
+			tables[strings.ToLower(table)] = columns
+		}
+
+		if alias := aliasPattern.FindStringSubmatch(query[match[3]:]); alias != nil && (alias[1] != "" || !notAliases[strings.ToUpper(alias[2])]) {
+			tables[strings.ToLower(unquoteIdentifier(alias[2]))] = columns
+		}
+	}
+	return tables
+}
+
+// resolveColumn finds a column by optional qualifier, or in any referenced table
+func resolveColumn(tables map[string]map[string]lintColumn, qualifier, name string) (lintColumn, bool) {
+	name = strings.ToLower(name)
+	if qualifier != "" {
+		col, ok := tables[strings.ToLower(qualifier)][name]
+		return col, ok
+	}
+	for _, columns := range tables {
+		if col, ok := columns[name]; ok {
+			return col, true
+		}
+	}
+	return lintColumn{}, false
+}
+
+// lintImplicitConversions flags string columns compared with numeric literals,
+// which makes MySQL convert every row's value and rules out the index
+func lintImplicitConversions(masked string, tables map[string]map[string]lintColumn) []LintFinding {
+	var findings []LintFinding
+	seen := make(map[string]bool)
+	for _, m := range numericComparisonPattern.FindAllStringSubmatch(masked, -1) {
+		col, ok := resolveColumn(tables, m[1], m[2])
+		if !ok || !stringTypes[col.dataType] || seen[m[2]] {
+			continue
+		}
+		seen[m[2]] = true
+		findings = append(findings, LintFinding{
+			Rule:       "implicit_conversion",
+			Severity:   "warning",
+			Message:    fmt.Sprintf("Column %s is %s but is compared with the number %s; MySQL converts every row's value to a number, so no index on %s can be used and values like '%s abc' also match", m[2], strings.ToUpper(col.dataType), m[3], m[2], m[3]),
+			Suggestion: fmt.Sprintf("Quote the literal: %s = '%s'", m[2], m[3]),
+		})
+	}
+	return findings
+}
+
+// lintNonSargable flags functions wrapped around indexed columns and leading
+// LIKE wildcards on indexed columns, both of which prevent index seeks
+func lintNonSargable(query, masked string, tables map[string]map[string]lintColumn) []LintFinding {
+	var findings []LintFinding
+	seen := make(map[string]bool)
+
+	for _, m := range functionOnColumnPattern.FindAllStringSubmatch(masked, -1) {
+		fn := strings.ToUpper(m[1])
+		if notFunctions[fn] {
+			continue
+		}
+		col, ok := resolveColumn(tables, m[2], m[3])
+		if !ok || !col.leadsAnIndex || seen[m[3]] {
+			continue
+		}
+		seen[m[3]] = true
+		findings = append(findings, LintFinding{
+			Rule:       "non_sargable",
+			Severity:   "warning",
+			Message:    fmt.Sprintf("%s() is applied to indexed column %s in a condition, so the index cannot be used to find matching rows", fn, m[3]),
+			Suggestion: fmt.Sprintf("Rewrite the condition on the bare column, e.g. YEAR(%s) = 2024 as %s >= '2024-01-01' AND %s < '2025-01-01', or index the expression", m[3], m[3], m[3]),
+		})
+	}
+
+	literals := stringLiteralPattern.FindAllStringIndex(query, -1)
+	for _, loc := range leadingWildcardPattern.FindAllStringSubmatchIndex(query, -1) {
+		if insideLiteral(literals, loc[0]) {
+			continue
+		}
+		qualifier := ""
+		if loc[2] != -1 {
+			qualifier = query[loc[2]:loc[3]]
+		}
+		name := query[loc[4]:loc[5]]
+		col, ok := resolveColumn(tables, qualifier, name)
+		if !ok || !col.leadsAnIndex || seen[name] {
+			continue
+		}
+		seen[name] = true
+		findings = append(findings, LintFinding{
+			Rule:       "non_sargable",
+			Severity:   "warning",
+			Message:    fmt.Sprintf("LIKE with a leading %% on indexed column %s cannot use the index and scans every row", name),
+			Suggestion: "Use a prefix match (LIKE 'abc%') or a FULLTEXT index for substring search",
+		})
+	}
+
+	return findings
+}
@@ -0,0 +1,162 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+)
+
+// lockWaitsQuery reads lock waits from performance_schema (MySQL 8.0+)
+const lockWaitsQuery = `SELECT
+	w.REQUESTING_ENGINE_TRANSACTION_ID AS waiting_trx_id,
+	rt.trx_mysql_thread_id AS waiting_thread_id,
+	rt.trx_query AS waiting_query,
+	TIMESTAMPDIFF(SECOND, rt.trx_wait_started, NOW()) AS wait_seconds,
+	w.BLOCKING_ENGINE_TRANSACTION_ID AS blocking_trx_id,
+	bt.trx_mysql_thread_id AS blocking_thread_id,
+	bt.trx_query AS blocking_query,
+	bt.trx_state AS blocking_state,
+	TIMESTAMPDIFF(SECOND, bt.trx_started, NOW()) AS blocking_trx_age_seconds,
+	l.OBJECT_SCHEMA AS locked_schema,
+	l.OBJECT_NAME AS locked_table,
+	l.INDEX_NAME AS locked_index,
+	l.LOCK_TYPE AS lock_type,
+	l.LOCK_MODE AS lock_mode
+FROM performance_schema.data_lock_waits w
+JOIN information_schema.innodb_trx rt ON rt.trx_id = w.REQUESTING_ENGINE_TRANSACTION_ID
+JOIN information_schema.innodb_trx bt ON bt.trx_id = w.BLOCKING_ENGINE_TRANSACTION_ID
+LEFT JOIN performance_schema.data_locks l ON l.ENGINE_LOCK_ID = w.REQUESTING_ENGINE_LOCK_ID
+ORDER BY wait_seconds DESC`
+
+// legacyLockWaitsQuery reads lock waits from information_schema (MySQL 5.7)
+const legacyLockWaitsQuery = `SELECT
+	r.trx_id AS waiting_trx_id,
+	r.trx_mysql_thread_id AS waiting_thread_id,
+	r.trx_query AS waiting_query,
+	TIMESTAMPDIFF(SECOND, r.trx_wait_started, NOW()) AS wait_seconds,
+	b.trx_id AS blocking_trx_id,
+	b.trx_mysql_thread_id AS blocking_thread_id,
+	b.trx_query AS blocking_query,
+	b.trx_state AS blocking_state,
+	TIMESTAMPDIFF(SECOND, b.trx_started, NOW()) AS blocking_trx_age_seconds,
+	l.lock_table AS locked_table,
+	l.lock_index AS locked_index,
+	l.lock_type AS lock_type,
+	l.lock_mode AS lock_mode
+FROM information_schema.innodb_lock_waits w
+JOIN information_schema.innodb_trx b ON b.trx_id = w.blocking_trx_id
+JOIN information_schema.innodb_trx r ON r.trx_id = w.requesting_trx_id
+LEFT JOIN information_schema.innodb_locks l ON l.lock_id = w.requested_lock_id
+ORDER BY wait_seconds DESC`
+
+// LockDiagnosis describes current InnoDB lock contention
+type LockDiagnosis struct {
+	LockWaits               []map[string]interface{}   `json:"lock_waits"`
+	BlockingChains          [][]map[string]interface{} `json:"blocking_chains"`
+	RootBlockers            []map[string]interface{}   `json:"root_blockers"`
+	LongRunningTransactions []map[string]interface{}   `json:"long_running_transactions"`
+	Source                  string                     `json:"source"`
+}
+
+// DiagnoseLocks returns lock waits, blocking chains from each blocked transaction
+// to the transaction at the root of the chain, and the oldest open transactions
+func (m *Manager) DiagnoseLocks(connectionName string) (*LockDiagnosis, error) {
+	diagnosis := &LockDiagnosis{Source: "performance_schema.data_lock_waits"}
+
+	// MariaDB and MySQL 5.7 only have the information_schema lock tables
+	var waits *QueryResult
+	var err error
+	if info, infoErr := m.ServerInfo(connectionName); infoErr != nil || info.hasDataLockViews() {
+		waits, err = m.ExecuteQuery(connectionName, lockWaitsQuery)
+	} else {
+		err = fmt.Errorf("performance_schema.data_lock_waits is not available on %s %s", info.Flavor, info.Version)
+	}
+	if err != nil {
+		legacy, legacyErr := m.ExecuteQuery(connectionName, legacyLockWaitsQuery)
+		if legacyErr != nil {
+			return nil, fmt.Errorf("failed to read lock waits: %w", err)
+		}
+		waits = legacy
+		diagnosis.Source = "information_schema.innodb_lock_waits"
+	}
+	diagnosis.LockWaits = waits.Rows
+
+	trx, err := m.ExecuteQuery(connectionName, `SELECT trx_id, trx_mysql_thread_id AS thread_id, trx_state,
+		trx_started, TIMESTAMPDIFF(SECOND, trx_started, NOW()) AS age_seconds,
+		trx_rows_locked, trx_rows_modified, trx_query
+		FROM information_schema.innodb_trx
+		ORDER BY trx_started
+		LIMIT 20`)
+	if err != nil {
+		return nil, err
+	}
+	diagnosis.LongRunningTransactions = trx.Rows
+
+	// Build the waits-for graph: waiting transaction -> blocking transactions
+	blockedBy := make(map[string][]string)
+	info := make(map[string]map[string]interface{})
+	isBlocker := make(map[string]bool)
+	for _, w := range waits.Rows {
+		waiting := stringValue(w["waiting_trx_id"])
+		blocking := stringValue(w["blocking_trx_id"])
+		blockedBy[waiting] = append(blockedBy[waiting], blocking)
+		isBlocker[blocking] = true
+
+		info[waiting] = map[string]interface{}{
+			"trx_id":       waiting,
+			"thread_id":    w["waiting_thread_id"],
+			"query":        w["waiting_query"],
+			"wait_seconds": w["wait_seconds"],
+		}
+		if _, exists := info[blocking]; !exists {
+			info[blocking] = map[string]interface{}{
+				"trx_id":      blocking,
+				"thread_id":   w["blocking_thread_id"],
+				"query":       w["blocking_query"],
+				"state":       w["blocking_state"],
+				"age_seconds": w["blocking_trx_age_seconds"],
+			}
+		}
+	}
+
+	waitingIDs := make([]string, 0, len(blockedBy))
+	for waiting := range blockedBy {
+		waitingIDs = append(waitingIDs, waiting)
+	}
+	sort.Strings(waitingIDs)
+
+	blockerIDs := make([]string, 0, len(isBlocker))
+	for blocking := range isBlocker {
+		blockerIDs = append(blockerIDs, blocking)
+	}
+	sort.Strings(blockerIDs)
+
+	// Walk from every transaction that blocks nobody up to its root blocker
+	for _, waiting := range waitingIDs {
+		if isBlocker[waiting] {
+			continue
+		}
+		chain := []map[string]interface{}{info[waiting]}
+		visited := map[string]bool{waiting: true}
+		current := waiting
+		for len(blockedBy[current]) > 0 {
+			next := blockedBy[current][0]
+			if visited[next] {
+				// Cycle: InnoDB deadlock detection will resolve it
+				chain = append(chain, map[string]interface{}{"trx_id": next, "cycle": true})
+				break
+			}
+			visited[next] = true
+			chain = append(chain, info[next])
+			current = next
+		}
+		diagnosis.BlockingChains = append(diagnosis.BlockingChains, chain)
+	}
+
+	for _, trxID := range blockerIDs {
+		if len(blockedBy[trxID]) == 0 {
+			diagnosis.RootBlockers = append(diagnosis.RootBlockers, info[trxID])
+		}
+	}
+
+	return diagnosis, nil
+}
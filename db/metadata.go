@@ -0,0 +1,37 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Warning is a single row of SHOW WARNINGS output
+type Warning struct {
+	Level   string `json:"level"`
+	Code    int64  `json:"code"`
+	Message string `json:"message"`
+}
+
+// sessionQueryer is a pinned session: a *sql.Conn or a *sql.Tx
+type sessionQueryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// fetchWarnings reads SHOW WARNINGS for the previous statement on a pinned session.
+// Warnings are best-effort metadata, so failures yield no warnings rather than an error.
+func fetchWarnings(conn sessionQueryer) []Warning {
+	rows, err := conn.QueryContext(context.Background(), "SHOW WARNINGS")
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var warnings []Warning
+	for rows.Next() {
+		var w Warning
+		if err := rows.Scan(&w.Level, &w.Code, &w.Message); err == nil {
+			warnings = append(warnings, w)
+		}
+	}
+	return warnings
+}
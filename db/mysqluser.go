@@ -0,0 +1,80 @@
+package db
+
+import (
+	"fmt"
+	"log/slog"
+
+	"mysql-golang-mcp/config"
+)
+
+// lookupConnection returns the config of a configured connection, of one
+// added by add_connection, or of a connection derived by UserConnection.
+// Connections taken out of service by RemoveConnection are not found.
+func (m *Manager) lookupConnection(name string) (*config.ConnectionConfig, bool) {
+	if m.isRemoved(name) {
+		return nil, false
+	}
+	if connConfig, exists := m.config.Connections[name]; exists {
+		return connConfig, true
+	}
+	m.runtimeConnectionsMu.Lock()
+	connConfig, exists := m.runtimeConnections[name]
+	m.runtimeConnectionsMu.Unlock()
+	if exists {
+		return connConfig, true
+	}
+	m.userConnectionsMu.Lock()
+	defer m.userConnectionsMu.Unlock()
+	connConfig, exists = m.userConnections[name]
+	return connConfig, exists
+}
+
+// UserConnection returns the name of a connection that is the named one
+// logged in as user with password, so an HTTP client's statements run as
+// its own MySQL account and the server's audit log attributes them to it.
+// The derived connection, named "<connection>@<user>/<client>" so no other
+// client can reach or replace it, has its own pool and concurrency slots and
+// keeps every other setting. When the password changes, as short-lived
+// credentials do, the new one is verified by opening a pool with it first;
+// only then is the pool opened with the old one closed, once its running
+// statements finish.
+func (m *Manager) UserConnection(connectionName, client, user, password string) (string, error) {
+	base, exists := m.config.Connections[connectionName]
+	if !exists || m.isRemoved(connectionName) {
+		return "", fmt.Errorf("unknown connection: %s", connectionName)
+	}
+	name := connectionName + "@" + user + "/" + client
+	candidate := base.WithCredentials(user, password)
+
+	m.userConnectionsMu.Lock()
+	current, exists := m.userConnections[name]
+	if !exists || current.Password == password {
+		if !exists {
+			m.userConnections[name] = candidate
+		}
+		m.userConnectionsMu.Unlock()
+		return name, nil
+	}
+	m.userConnectionsMu.Unlock()
+
+	// A password that fails to log in leaves the working pool in place
+	verified, err := openPool(name, candidate, m.config.StatementTag)
+	if err != nil {
+		return "", err
+	}
+	verified.Close()
+
+	m.userConnectionsMu.Lock()
+	m.userConnections[name] = candidate
+	m.userConnectionsMu.Unlock()
+
+	m.mu.Lock()
+	old, hadPool := m.connections[name]
+	delete(m.connections, name)
+	m.mu.Unlock()
+	if hadPool {
+		slog.Info("credentials changed, replacing pool", "connection", name)
+		go old.Close()
+	}
+	return name, nil
+}
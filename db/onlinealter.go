@@ -0,0 +1,257 @@
+package db
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"mysql-golang-mcp/config"
+)
+
+// onlineAlterOutputLines is the number of trailing output lines kept from a
+// gh-ost or pt-online-schema-change run
+const onlineAlterOutputLines = 40
+
+// OnlineAlterResult holds the outcome of an ALTER TABLE run through the
+// connection's online schema change tool
+type OnlineAlterResult struct {
+	Tool        string   `json:"tool"`
+	Connection  string   `json:"connection"`
+	Database    string   `json:"database"`
+	Table       string   `json:"table"`
+	Alter       string   `json:"alter"`
+	DryRun      bool     `json:"dry_run"`
+	Command     []string `json:"command"`
+	ExecutionMs int64    `json:"execution_ms"`
+
+	// Output is the tail of the tool's combined stdout and stderr
+	Output []string `json:"output"`
+
+	// Impact is the analysis of the ALTER made before running it
+	Impact *AlterImpact `json:"impact,omitempty"`
+
+	// Approval is set instead of a result when the run was queued for approval
+	Approval *PendingApproval `json:"approval,omitempty"`
+}
+
+// Progress lines of the online schema change tools: gh-ost reports
+// "Copy: 1200/48000 2.5%; ..." and pt-online-schema-change
+// "Copying `shop`.`orders`:  45% 01:23 remain"
+var (
+	ghostProgressPattern = regexp.MustCompile(`^Copy: (\d+)/(\d+) `)
+	ptoscProgressPattern = regexp.MustCompile(`^Copying .*:\s+(\d+)% `)
+)
+
+// OnlineAlter runs an ALTER TABLE through the connection's online schema
+// change tool, which copies the table in the background and swaps it in
+// instead of locking it. With dryRun the tool only validates the change
+// (gh-ost without --execute, pt-online-schema-change --dry-run). Progress
+// receives the tool's copy progress as it runs.
+func (m *Manager) OnlineAlter(connectionName, query string, dryRun bool, opts WriteOptions) (*OnlineAlterResult, error) {
+	_, connConfig, err := m.GetConnection(connectionName)
+	if err != nil {
+		return nil, err
+	}
+	osc := connConfig.OnlineSchemaChange
+	if osc == nil {
+		return nil, fmt.Errorf("online_alter is not configured for connection '%s': set online_schema_change to gh-ost or pt-online-schema-change", connectionName)
+	}
+
+	if err := ValidateQueryType(query, QueryTypeAlter); err != nil {
+		return nil, err
+	}
+	trimmed := strings.TrimRight(strings.TrimSpace(query), ";")
+	loc := alterTablePattern.FindStringSubmatchIndex(trimmed)
+	if loc == nil {
+		return nil, fmt.Errorf("online_alter only runs ALTER TABLE statements")
+	}
+	ref, alter := trimmed[loc[2]:loc[3]], strings.TrimSpace(trimmed[loc[1]:])
+	if alter == "" {
+		return nil, fmt.Errorf("ALTER TABLE has no changes to make")
+	}
+
+	if err := checkReplica(connectionName, connConfig); err != nil {
+		return nil, err
+	}
+	if connConfig.ReadOnly {
+		return nil, fmt.Errorf("connection '%s' is read-only, ALTER operations are not allowed", connectionName)
+	}
+	if isSensitiveQuery(query) {
+		return nil, fmt.Errorf("access to sensitive MySQL metadata is not allowed")
+	}
+	if err := checkBlockedPatterns(connectionName, connConfig, query); err != nil {
+		return nil, err
+	}
+
+	result := &OnlineAlterResult{
+		Tool:       osc.Tool,
+		Connection: connectionName,
+		Database:   connConfig.Database,
+		Table:      tableName(ref),
+		Alter:      alter,
+		DryRun:     dryRun,
+	}
+	if parts := strings.SplitN(ref, ".", 2); len(parts) == 2 {
+		result.Database = unquoteIdentifier(parts[0])
+	}
+	if result.Database == "" {
+		return nil, fmt.Errorf("online_alter needs a database: qualify the table or set the connection's database")
+	}
+	// pt-online-schema-change takes them in a DSN, where these would add keys
+	if osc.Tool != "gh-ost" && strings.ContainsAny(result.Database+result.Table, ",=") {
+		return nil, fmt.Errorf("%s cannot alter %s.%s: database and table names containing ',' or '=' cannot be passed in its DSN", osc.Tool, result.Database, result.Table)
+	}
+
+	path, err := exec.LookPath(osc.Path)
+	if err != nil {
+		return nil, fmt.Errorf("%s binary not found: %w", osc.Tool, err)
+	}
+
+	if result.Impact, err = m.AnalyzeAlter(connectionName, query); err != nil {
+		return nil, err
+	}
+
+	// Hold risky statements until a human approves them
+	if !dryRun && !opts.approved && needsApproval(connConfig, QueryTypeAlter) {
+		pending, err := m.requestApproval(connectionName, connConfig, query, QueryTypeAlter, func() (interface{}, error) {
+			return m.OnlineAlter(connectionName, query, false, WriteOptions{approved: true})
+		})
+		if err != nil {
+			return nil, err
+		}
+		result.Approval = pending
+		return result, nil
+	}
+
+	// Credentials go in a private option file rather than on the command
+	// line, where other processes could read them
+	defaults, err := writeClientDefaults(connConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(defaults)
+
+	args := onlineAlterArgs(osc, connConfig, defaults, result.Database, result.Table, alter, dryRun)
+	result.Command = append([]string{path}, args...)
+
+	start := time.Now()
+	output, err := runOnlineAlter(path, args, osc.Tool, opts.Progress)
+	result.ExecutionMs = time.Since(start).Milliseconds()
+	result.Output = output
+	if err != nil {
+		m.recordError(connectionName, query, err)
+		return nil, fmt.Errorf("%s failed: %w\n%s", osc.Tool, err, strings.Join(output, "\n"))
+	}
+	if !dryRun {
+		m.invalidateCache(connectionName)
+	}
+	slog.Debug("online alter finished", "connection", connectionName, "tool", osc.Tool, "table", result.Table, "dry_run", dryRun, "duration_ms", result.ExecutionMs)
+	return result, nil
+}
+
+// writeClientDefaults writes the connection's user and password to a
+// temporary [client] option file readable only by this process's user
+func writeClientDefaults(connConfig *config.ConnectionConfig) (string, error) {
+	f, err := os.CreateTemp("", "mysql-mcp-osc-*.cnf")
+	if err != nil {
+		return "", fmt.Errorf("failed to write credentials file: %w", err)
+	}
+	quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	_, err = fmt.Fprintf(f, "[client]\nuser=\"%s\"\npassword=\"%s\"\n", quote.Replace(connConfig.User), quote.Replace(connConfig.Password))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write credentials file: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// onlineAlterArgs builds the command line of a gh-ost or
+// pt-online-schema-change run
+func onlineAlterArgs(osc *config.OnlineSchemaChangeConfig, connConfig *config.ConnectionConfig, defaults, database, table, alter string, dryRun bool) []string {
+	if osc.Tool == "gh-ost" {
+		args := []string{
+			"--conf=" + defaults,
+			"--host=" + connConfig.Host,
+			"--port=" + strconv.Itoa(connConfig.Port),
+			"--database=" + database,
+			"--table=" + table,
+			"--alter=" + alter,
+		}
+		args = append(args, osc.Args...)
+		if !dryRun {
+			args = append(args, "--execute")
+		}
+		return args
+	}
+
+	// pt-online-schema-change requires --defaults-file to come first
+	args := []string{"--defaults-file=" + defaults, "--alter=" + alter}
+	args = append(args, osc.Args...)
+	if dryRun {
+		args = append(args, "--dry-run")
+	} else {
+		args = append(args, "--execute")
+	}
+	return append(args, fmt.Sprintf("h=%s,P=%d,D=%s,t=%s", connConfig.Host, connConfig.Port, database, table))
+}
+
+// runOnlineAlter runs the tool, reporting its copy progress at most every
+// progressInterval, and returns the tail of its output
+func runOnlineAlter(path string, args []string, tool string, report func(StatementProgress)) ([]string, error) {
+	cmd := exec.Command(path, args...)
+	reader, writer := io.Pipe()
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	waited := make(chan error, 1)
+	go func() {
+		waited <- cmd.Wait()
+		writer.Close()
+	}()
+
+	start := time.Now()
+	var lastReport time.Time
+	var tail []string
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if tail = append(tail, line); len(tail) > onlineAlterOutputLines {
+			tail = tail[1:]
+		}
+
+		if report == nil || time.Since(lastReport) < progressInterval {
+			continue
+		}
+		progress := StatementProgress{ElapsedMs: time.Since(start).Milliseconds(), Stage: tool}
+		if m := ghostProgressPattern.FindStringSubmatch(line); m != nil {
+			progress.WorkCompleted, _ = strconv.ParseInt(m[1], 10, 64)
+			progress.WorkEstimated, _ = strconv.ParseInt(m[2], 10, 64)
+		} else if m := ptoscProgressPattern.FindStringSubmatch(line); m != nil {
+			progress.WorkCompleted, _ = strconv.ParseInt(m[1], 10, 64)
+			progress.WorkEstimated = 100
+		} else {
+			continue
+		}
+		report(progress)
+		lastReport = time.Now()
+	}
+	// Drain anything left after an oversized line so the tool never blocks
+	io.Copy(io.Discard, reader)
+	return tail, <-waited
+}
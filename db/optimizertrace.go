@@ -0,0 +1,317 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// optimizerTraceMemSize is the optimizer_trace_max_mem_size set for a trace;
+// the rest of a larger trace is cut off and counted in missing_bytes
+const optimizerTraceMemSize = 4 << 20
+
+// OptimizerTrace is the condensed optimizer trace of a SELECT
+type OptimizerTrace struct {
+	Connection string `json:"connection"`
+	Database   string `json:"database,omitempty"`
+
+	// Executed reports whether the SELECT itself ran, rather than EXPLAIN
+	Executed bool `json:"executed"`
+
+	Summary *OptimizerTraceSummary `json:"summary"`
+
+	// Trace is the full trace document, returned with include_trace
+	Trace interface{} `json:"trace,omitempty"`
+
+	// MissingBytes is how much of the trace was cut off by the memory limit
+	MissingBytes int64 `json:"missing_bytes,omitempty"`
+
+	ExecutionMs int64 `json:"execution_ms"`
+}
+
+// OptimizerTraceSummary holds the join order and index decisions from a trace
+type OptimizerTraceSummary struct {
+	// JoinOrder is the final table order of the chosen plan
+	JoinOrder []string `json:"join_order"`
+
+	// PlansConsidered and PlansPruned count the join orders the optimizer
+	// evaluated and the ones it abandoned early
+	PlansConsidered int `json:"plans_considered"`
+	PlansPruned     int `json:"plans_pruned"`
+
+	Tables []TraceTable `json:"tables"`
+}
+
+// TraceTable holds the optimizer's decisions for one table
+type TraceTable struct {
+	Table string `json:"table"`
+
+	// UnusableIndexes lists indexes ruled out for range access, with why
+	UnusableIndexes []TraceAccessPath `json:"unusable_indexes,omitempty"`
+
+	// RangeAlternatives are the range scans the optimizer costed
+	RangeAlternatives []TraceAccessPath `json:"range_alternatives,omitempty"`
+
+	// AccessPaths are the ways to read the table considered while joining
+	AccessPaths []TraceAccessPath `json:"access_paths,omitempty"`
+}
+
+// TraceAccessPath is one way of reading a table the optimizer considered
+type TraceAccessPath struct {
+	AccessType string   `json:"access_type,omitempty"`
+	Index      string   `json:"index,omitempty"`
+	Rows       *float64 `json:"rows,omitempty"`
+	Cost       *float64 `json:"cost,omitempty"`
+	Chosen     *bool    `json:"chosen,omitempty"`
+	Cause      string   `json:"cause,omitempty"`
+}
+
+// OptimizerTrace enables optimizer_trace on a pinned session, runs EXPLAIN
+// for a SELECT (or the SELECT itself with execute, discarding its rows),
+// and condenses information_schema.OPTIMIZER_TRACE into the join order and
+// index choices. The session's optimizer_trace is turned off afterwards.
+func (m *Manager) OptimizerTrace(connectionName, database, query string, execute, includeTrace bool) (*OptimizerTrace, error) {
+	if err := ValidateQueryType(query, QueryTypeSelect); err != nil {
+		return nil, err
+	}
+
+	db, connConfig, err := m.GetConnection(connectionName)
+	if err != nil {
+		return nil, err
+	}
+	if isSensitiveQuery(query) {
+		return nil, fmt.Errorf("access to sensitive MySQL metadata is not allowed")
+	}
+	if err := checkBlockedPatterns(connectionName, connConfig, query); err != nil {
+		return nil, err
+	}
+
+	release, err := m.acquireSlot(connectionName)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Close()
+
+	trace := &OptimizerTrace{Connection: connectionName, Database: connConfig.Database, Executed: execute}
+	if database != "" {
+		restore, err := useDatabase(conn, connConfig, database)
+		if err != nil {
+			return nil, err
+		}
+		defer restore()
+		trace.Database = database
+	}
+
+	if execute && connConfig.MaxEstimatedRowsExamined > 0 {
+		if err := checkQueryCost(conn, connectionName, connConfig.MaxEstimatedRowsExamined, query); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("SET SESSION optimizer_trace = 'enabled=on', optimizer_trace_max_mem_size = %d", optimizerTraceMemSize)); err != nil {
+		return nil, fmt.Errorf("failed to enable optimizer_trace: %w", err)
+	}
+	// The session returns to the pool, so tracing must not stay on
+	defer func() {
+		if _, err := conn.ExecContext(ctx, "SET SESSION optimizer_trace = 'enabled=off'"); err != nil {
+			slog.Warn("failed to disable optimizer_trace", "connection", connectionName, "error", err)
+		}
+	}()
+
+	statement := "EXPLAIN " + query
+	if execute {
+		statement = limitExecutionTime(connConfig, m.cachedServerInfo(connectionName), query)
+	}
+	start := time.Now()
+	if err := drainQuery(ctx, conn, statement); err != nil {
+		m.recordError(connectionName, query, err)
+		return nil, fmt.Errorf("query execution failed: %w", executionTimeError(connConfig, err))
+	}
+	trace.ExecutionMs = time.Since(start).Milliseconds()
+
+	var text string
+	var insufficientPrivileges bool
+	err = conn.QueryRowContext(ctx, "SELECT TRACE, MISSING_BYTES_BEYOND_MAX_MEM_SIZE, INSUFFICIENT_PRIVILEGES FROM information_schema.OPTIMIZER_TRACE").
+		Scan(&text, &trace.MissingBytes, &insufficientPrivileges)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("the server recorded no optimizer trace for the query")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read optimizer trace: %w", err)
+	}
+	if insufficientPrivileges {
+		return nil, fmt.Errorf("the optimizer trace is hidden: the account lacks privileges on a view or routine the query uses")
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal([]byte(text), &doc); err != nil {
+		if trace.MissingBytes > 0 {
+			return nil, fmt.Errorf("the optimizer trace exceeded %d bytes and was cut off", optimizerTraceMemSize)
+		}
+		return nil, fmt.Errorf("failed to parse optimizer trace: %w", err)
+	}
+	trace.Summary = summarizeOptimizerTrace(doc)
+	if includeTrace {
+		trace.Trace = doc
+	}
+	return trace, nil
+}
+
+// drainQuery runs a statement and reads its result set to the end
+func drainQuery(ctx context.Context, conn *sql.Conn, statement string) error {
+	rows, err := conn.QueryContext(ctx, statement)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+	}
+	return rows.Err()
+}
+
+// summarizeOptimizerTrace walks a trace document collecting the decisions
+// that explain a plan: range analysis per table, the access paths costed
+// while choosing the join order, and the final join order
+func summarizeOptimizerTrace(doc interface{}) *OptimizerTraceSummary {
+	summary := &OptimizerTraceSummary{JoinOrder: []string{}, Tables: []TraceTable{}}
+	tables := make(map[string]*TraceTable)
+	var tableNames []string
+	table := func(name string) *TraceTable {
+		if t, ok := tables[name]; ok {
+			return t
+		}
+		t := &TraceTable{Table: name}
+		tables[name] = t
+		tableNames = append(tableNames, name)
+		return t
+	}
+
+	var order []string
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		switch v := node.(type) {
+		case map[string]interface{}:
+			name, _ := v["table"].(string)
+			if name != "" {
+				if _, ok := v["plan_prefix"]; ok {
+					summary.PlansConsidered++
+					if pruned(v) {
+						summary.PlansPruned++
+					}
+				}
+				if best, ok := v["best_access_path"].(map[string]interface{}); ok {
+					t := table(name)
+					for _, p := range traceAccessPaths(best["considered_access_paths"]) {
+						t.AccessPaths = mergeAccessPath(t.AccessPaths, p)
+					}
+				}
+				if analysis, ok := v["range_analysis"].(map[string]interface{}); ok {
+					t := table(name)
+					for _, p := range traceAccessPaths(analysis["potential_range_indexes"]) {
+						if p.Cause != "" {
+							t.UnusableIndexes = append(t.UnusableIndexes, p)
+						}
+					}
+					if alternatives, ok := analysis["analyzing_range_alternatives"].(map[string]interface{}); ok {
+						t.RangeAlternatives = append(t.RangeAlternatives, traceAccessPaths(alternatives["range_scan_alternatives"])...)
+					}
+				}
+			}
+			if refine, ok := v["refine_plan"].([]interface{}); ok {
+				order = order[:0]
+				for _, step := range refine {
+					if s, ok := step.(map[string]interface{}); ok {
+						if name, ok := s["table"].(string); ok {
+							order = append(order, name)
+						}
+					}
+				}
+			}
+			// Keys are walked in order so repeated runs summarize alike
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				walk(v[key])
+			}
+		case []interface{}:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(doc)
+
+	if len(order) > 0 {
+		summary.JoinOrder = order
+	}
+	for _, name := range tableNames {
+		summary.Tables = append(summary.Tables, *tables[name])
+	}
+	return summary
+}
+
+// pruned reports whether a considered plan was abandoned before completion
+func pruned(plan map[string]interface{}) bool {
+	for _, key := range []string{"pruned_by_cost", "pruned_by_heuristic"} {
+		if b, _ := plan[key].(bool); b {
+			return true
+		}
+	}
+	return false
+}
+
+// traceAccessPaths converts a trace list of index or access-path entries
+func traceAccessPaths(node interface{}) []TraceAccessPath {
+	list, _ := node.([]interface{})
+	paths := make([]TraceAccessPath, 0, len(list))
+	for _, item := range list {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		p := TraceAccessPath{}
+		p.AccessType, _ = entry["access_type"].(string)
+		p.Index, _ = entry["index"].(string)
+		p.Cause, _ = entry["cause"].(string)
+		if rows, ok := entry["rows"].(float64); ok {
+			p.Rows = &rows
+		} else if rows, ok := entry["rows_to_scan"].(float64); ok {
+			p.Rows = &rows
+		}
+		if cost, ok := entry["cost"].(float64); ok {
+			p.Cost = &cost
+		}
+		if chosen, ok := entry["chosen"].(bool); ok {
+			p.Chosen = &chosen
+		}
+		paths = append(paths, p)
+	}
+	return paths
+}
+
+// mergeAccessPath adds an access path, replacing an earlier entry for the
+// same access type and index: the optimizer costs a table again for each
+// join prefix it considers, and only the last costing is kept
+func mergeAccessPath(paths []TraceAccessPath, p TraceAccessPath) []TraceAccessPath {
+	for i, existing := range paths {
+		if existing.AccessType == p.AccessType && existing.Index == p.Index {
+			paths[i] = p
+			return paths
+		}
+	}
+	return append(paths, p)
+}
@@ -0,0 +1,188 @@
+package db
+
+import (
+	"strconv"
+	"strings"
+
+	"mysql-golang-mcp/config"
+)
+
+// maxSafeInteger is the largest integer a double holds exactly (2^53 - 1),
+// beyond which clients that parse JSON numbers as doubles lose precision
+const maxSafeInteger = 1<<53 - 1
+
+// OutputOptions control how row values are rendered in tool results. The
+// values kept in results stay as scanned, since backups and undo write them
+// back to the database.
+type OutputOptions struct {
+	// SafeNumbers renders integers beyond 2^53 as decimal strings
+	SafeNumbers bool
+
+	// GeometryFormat renders spatial values as wkt, geojson or wkb (hex)
+	GeometryFormat string
+
+	// coercion returns the coercion settings of the connection a result
+	// came from, or nil
+	coercion func(connection string) *config.CoercionConfig
+}
+
+// OutputOptions returns the configured rendering of row values
+func (m *Manager) OutputOptions() OutputOptions {
+	return OutputOptions{
+		SafeNumbers:    m.config.SafeNumbers,
+		GeometryFormat: m.config.GeometryFormat,
+		coercion: func(connection string) *config.CoercionConfig {
+			if connConfig, exists := m.lookupConnection(connection); exists {
+				return connConfig.Coercion
+			}
+			return nil
+		},
+	}
+}
+
+// coercionFor returns the coercion settings of a connection, or nil
+func (opts OutputOptions) coercionFor(connection string) *config.CoercionConfig {
+	if opts.coercion == nil || connection == "" {
+		return nil
+	}
+	return opts.coercion(connection)
+}
+
+// ForOutput returns a copy of the result with its values rendered per opts
+// and its column types annotated with their JSON type. Rows without values to
+// render are shared with r rather than copied.
+func (r *QueryResult) ForOutput(opts OutputOptions) *QueryResult {
+	if r == nil {
+		return nil
+	}
+	c := *r
+	coercion := opts.coercionFor(r.Connection)
+	c.ColumnTypes = opts.columnTypes(r.ColumnTypes, coercion)
+	c.Rows = opts.rows(r.Rows, r.ColumnTypes, coercion)
+	return &c
+}
+
+// ForOutput returns a copy of the cursor description with its column types
+// annotated with their JSON type
+func (c *CursorInfo) ForOutput(opts OutputOptions) *CursorInfo {
+	if c == nil {
+		return nil
+	}
+	info := *c
+	info.ColumnTypes = opts.columnTypes(c.ColumnTypes, opts.coercionFor(c.Connection))
+	return &info
+}
+
+// ForOutput returns a copy of the batch with its values rendered per opts
+func (b *CursorBatch) ForOutput(opts OutputOptions) *CursorBatch {
+	if b == nil {
+		return nil
+	}
+	batch := *b
+	batch.Rows = opts.rows(b.Rows, b.columnTypes, opts.coercionFor(b.connection))
+	return &batch
+}
+
+// columnTypes annotates the column types whose values are not plain JSON
+// numbers: "string" for DECIMAL, "number|string" for BIGINT with
+// SafeNumbers, the geometry format for spatial columns, and the JSON type of
+// columns the connection's coercion renders differently
+func (opts OutputOptions) columnTypes(columnTypes []ColumnType, coercion *config.CoercionConfig) []ColumnType {
+	annotated := make([]ColumnType, len(columnTypes))
+	for i, ct := range columnTypes {
+		annotated[i] = ct
+		switch strings.TrimPrefix(ct.DatabaseType, "UNSIGNED ") {
+		case "DECIMAL":
+			if opts.SafeNumbers {
+				annotated[i].JSONType = "string"
+			}
+		case "BIGINT":
+			if opts.SafeNumbers {
+				annotated[i].JSONType = "number|string"
+			}
+		case "GEOMETRY":
+			if opts.GeometryFormat != "" {
+				annotated[i].JSONType = opts.GeometryFormat
+			}
+		}
+		if coercion != nil {
+			if jsonType := coercionJSONType(coercion, ct); jsonType != "" {
+				annotated[i].JSONType = jsonType
+			}
+		}
+	}
+	return annotated
+}
+
+// columnRenderer renders the values of one column
+type columnRenderer struct {
+	column string
+	render func(interface{}) interface{}
+}
+
+// rows returns rows with their values rendered, copying only the rows that
+// hold a value to render
+func (opts OutputOptions) rows(rows []map[string]interface{}, columnTypes []ColumnType, coercion *config.CoercionConfig) []map[string]interface{} {
+	if rows == nil {
+		return nil
+	}
+	var renderers []columnRenderer
+	for _, ct := range columnTypes {
+		if opts.GeometryFormat != "" && ct.DatabaseType == "GEOMETRY" {
+			renderers = append(renderers, columnRenderer{ct.Name, func(v interface{}) interface{} { return formatGeometry(v, opts.GeometryFormat) }})
+		} else if coercion != nil {
+			if render := coercionRenderer(coercion, ct); render != nil {
+				renderers = append(renderers, columnRenderer{ct.Name, render})
+			}
+		}
+	}
+	if !opts.SafeNumbers && len(renderers) == 0 {
+		return rows
+	}
+
+	rendered := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		rendered[i] = row
+		copied := false
+		set := func(col string, v interface{}) {
+			if !copied {
+				rendered[i] = make(map[string]interface{}, len(row))
+				for k, v := range row {
+					rendered[i][k] = v
+				}
+				copied = true
+			}
+			rendered[i][col] = v
+		}
+
+		for _, r := range renderers {
+			if v, ok := row[r.column]; ok && v != nil {
+				set(r.column, r.render(v))
+			}
+		}
+		if opts.SafeNumbers {
+			for col, v := range row {
+				if s, ok := unsafeInteger(v); ok {
+					set(col, s)
+				}
+			}
+		}
+	}
+	return rendered
+}
+
+// unsafeInteger returns an integer a double cannot hold exactly as a
+// decimal string
+func unsafeInteger(v interface{}) (string, bool) {
+	switch n := v.(type) {
+	case int64:
+		if n > maxSafeInteger || n < -maxSafeInteger {
+			return strconv.FormatInt(n, 10), true
+		}
+	case uint64:
+		if n > maxSafeInteger {
+			return strconv.FormatUint(n, 10), true
+		}
+	}
+	return "", false
+}
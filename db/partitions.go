@@ -0,0 +1,292 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// numericLiteral matches the plain decimal numbers written unquoted in partition bounds
+var numericLiteral = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// PartitionInfo is one partition (or subpartition) of a table
+type PartitionInfo struct {
+	Name             string `json:"name"`
+	SubpartitionName string `json:"subpartition_name,omitempty"`
+	Ordinal          int64  `json:"ordinal"`
+
+	// Description is the partition bound: the LESS THAN value for RANGE
+	// partitioning or the value list for LIST
+	Description string `json:"description,omitempty"`
+
+	EstimatedRows int64  `json:"estimated_rows"`
+	DataBytes     int64  `json:"data_bytes"`
+	IndexBytes    int64  `json:"index_bytes"`
+	Comment       string `json:"comment,omitempty"`
+}
+
+// TablePartitions describes how a table is partitioned
+type TablePartitions struct {
+	Database    string `json:"database,omitempty"`
+	Table       string `json:"table"`
+	Partitioned bool   `json:"partitioned"`
+
+	// Method is RANGE, RANGE COLUMNS, LIST, LIST COLUMNS, HASH, LINEAR HASH,
+	// KEY, or LINEAR KEY; Expression is the partitioning expression or columns
+	Method             string `json:"method,omitempty"`
+	Expression         string `json:"expression,omitempty"`
+	SubpartitionMethod string `json:"subpartition_method,omitempty"`
+
+	Partitions  []PartitionInfo       `json:"partitions,omitempty"`
+	Suggestions []PartitionSuggestion `json:"suggestions,omitempty"`
+}
+
+// PartitionSuggestion is a generated partition maintenance statement, with
+// the alter_partitions arguments that run it
+type PartitionSuggestion struct {
+	Action    string                 `json:"action"`
+	SQL       string                 `json:"sql"`
+	Arguments map[string]interface{} `json:"arguments"`
+	Note      string                 `json:"note,omitempty"`
+}
+
+// PartitionChange is a structured partition change for AlterPartitions
+type PartitionChange struct {
+	// Action is "drop" or "add"
+	Action string
+
+	// Partitions lists the partitions to drop
+	Partitions []string
+
+	// Partition names the partition to add, bounded by Values: the LESS THAN
+	// value(s) for RANGE partitioning or the value list for LIST.
+	// Numbers, NULL, and MAXVALUE are written as is; other strings are quoted.
+	Partition string
+	Values    []interface{}
+}
+
+// Partitions returns the partitions of a table from information_schema.PARTITIONS
+// along with suggested maintenance statements. Row counts and sizes are the
+// same estimates information_schema.TABLES reports.
+func (m *Manager) Partitions(connectionName, database, table string) (*TablePartitions, error) {
+	queryResult, err := m.ExecuteQuery(connectionName, `SELECT PARTITION_NAME, SUBPARTITION_NAME,
+		PARTITION_ORDINAL_POSITION, PARTITION_METHOD, SUBPARTITION_METHOD, PARTITION_EXPRESSION,
+		PARTITION_DESCRIPTION, TABLE_ROWS, DATA_LENGTH, INDEX_LENGTH, PARTITION_COMMENT
+		FROM information_schema.PARTITIONS
+		WHERE TABLE_SCHEMA = COALESCE(?, DATABASE()) AND TABLE_NAME = ?
+		ORDER BY PARTITION_ORDINAL_POSITION, SUBPARTITION_ORDINAL_POSITION`, nullIfEmpty(database), table)
+	if err != nil {
+		return nil, err
+	}
+	if len(queryResult.Rows) == 0 {
+		return nil, fmt.Errorf("table not found: %s", table)
+	}
+
+	result := &TablePartitions{Database: database, Table: table}
+	for _, row := range queryResult.Rows {
+		// An unpartitioned table has a single row with a NULL partition name
+		if row["PARTITION_NAME"] == nil {
+			return result, nil
+		}
+		result.Partitioned = true
+		result.Method = stringValue(row["PARTITION_METHOD"])
+		result.Expression = stringValue(row["PARTITION_EXPRESSION"])
+		result.SubpartitionMethod = stringValue(row["SUBPARTITION_METHOD"])
+		result.Partitions = append(result.Partitions, PartitionInfo{
+			Name:             stringValue(row["PARTITION_NAME"]),
+			SubpartitionName: stringValue(row["SUBPARTITION_NAME"]),
+			Ordinal:          int64Value(row["PARTITION_ORDINAL_POSITION"]),
+			Description:      stringValue(row["PARTITION_DESCRIPTION"]),
+			EstimatedRows:    int64Value(row["TABLE_ROWS"]),
+			DataBytes:        int64Value(row["DATA_LENGTH"]),
+			IndexBytes:       int64Value(row["INDEX_LENGTH"]),
+			Comment:          stringValue(row["PARTITION_COMMENT"]),
+		})
+	}
+
+	result.Suggestions = suggestPartitionChanges(result)
+	return result, nil
+}
+
+// AlterPartitions drops or adds partitions of a RANGE or LIST partitioned
+// table. Dropping a partition deletes its rows. Adding a RANGE partition to a
+// table whose last partition is bounded by MAXVALUE splits that partition.
+// Requires allow_ddl on the connection, and approval on require_approval
+// connections.
+func (m *Manager) AlterPartitions(connectionName, database, table string, change PartitionChange) (*WriteResult, error) {
+	partitions, err := m.Partitions(connectionName, database, table)
+	if err != nil {
+		return nil, err
+	}
+	query, err := BuildPartitionAlter(partitions, change)
+	if err != nil {
+		return nil, err
+	}
+	return m.execDDL(connectionName, "partition management", database, query, WriteOptions{})
+}
+
+// BuildPartitionAlter builds the ALTER TABLE statement for a partition change
+// against a table's current partitioning
+func BuildPartitionAlter(t *TablePartitions, change PartitionChange) (string, error) {
+	if !t.Partitioned {
+		return "", fmt.Errorf("table %s is not partitioned", t.Table)
+	}
+	ranged := strings.HasPrefix(t.Method, "RANGE")
+	if !ranged && !strings.HasPrefix(t.Method, "LIST") {
+		return "", fmt.Errorf("partition changes are only supported for RANGE and LIST partitioning, %s uses %s", t.Table, t.Method)
+	}
+	target := "ALTER TABLE " + QualifiedName(t.Database, t.Table)
+
+	switch change.Action {
+	case "drop":
+		if len(change.Partitions) == 0 {
+			return "", fmt.Errorf("partitions to drop are required")
+		}
+		if len(change.Partitions) >= len(partitionNames(t)) {
+			return "", fmt.Errorf("cannot drop every partition of %s", t.Table)
+		}
+		names := make([]string, len(change.Partitions))
+		for i, name := range change.Partitions {
+			if !t.hasPartition(name) {
+				return "", fmt.Errorf("partition not found: %s", name)
+			}
+			names[i] = QuoteIdentifier(name)
+		}
+		return target + " DROP PARTITION " + strings.Join(names, ", "), nil
+
+	case "add":
+		if change.Partition == "" {
+			return "", fmt.Errorf("partition name is required")
+		}
+		if t.hasPartition(change.Partition) {
+			return "", fmt.Errorf("partition already exists: %s", change.Partition)
+		}
+		if len(change.Values) == 0 {
+			return "", fmt.Errorf("values are required")
+		}
+		literals := make([]string, len(change.Values))
+		for i, v := range change.Values {
+			literals[i] = partitionLiteral(v)
+		}
+		bound := "VALUES IN (" + strings.Join(literals, ", ") + ")"
+		if ranged {
+			bound = "VALUES LESS THAN (" + strings.Join(literals, ", ") + ")"
+		}
+		definition := fmt.Sprintf("PARTITION %s %s", QuoteIdentifier(change.Partition), bound)
+
+		// A RANGE partition can only be added after the last one, so a
+		// MAXVALUE catch-all is split instead
+		if last := t.Partitions[len(t.Partitions)-1]; ranged && strings.Contains(last.Description, "MAXVALUE") {
+			return fmt.Sprintf("%s REORGANIZE PARTITION %s INTO (%s, PARTITION %s VALUES LESS THAN (%s))",
+				target, QuoteIdentifier(last.Name), definition, QuoteIdentifier(last.Name), last.Description), nil
+		}
+		return target + " ADD PARTITION (" + definition + ")", nil
+
+	default:
+		return "", fmt.Errorf("unknown action %q; use drop or add", change.Action)
+	}
+}
+
+// suggestPartitionChanges proposes routine RANGE maintenance: dropping the
+// oldest partition and adding the next one, continuing the spacing of the
+// last two integer bounds
+func suggestPartitionChanges(t *TablePartitions) []PartitionSuggestion {
+	if !strings.HasPrefix(t.Method, "RANGE") {
+		return nil
+	}
+	names := partitionNames(t)
+	var suggestions []PartitionSuggestion
+
+	if len(names) > 1 {
+		oldest := names[0]
+		var rows, bytes int64
+		for _, p := range t.Partitions {
+			if p.Name == oldest {
+				rows += p.EstimatedRows
+				bytes += p.DataBytes + p.IndexBytes
+			}
+		}
+		change := PartitionChange{Action: "drop", Partitions: []string{oldest}}
+		if sql, err := BuildPartitionAlter(t, change); err == nil {
+			suggestions = append(suggestions, PartitionSuggestion{
+				Action:    "drop",
+				SQL:       sql,
+				Arguments: map[string]interface{}{"table": t.Table, "action": "drop", "partitions": []string{oldest}},
+				Note:      fmt.Sprintf("deletes about %d rows (%d bytes) in the oldest partition", rows, bytes),
+			})
+		}
+	}
+
+	var bounds []int64
+	for i, p := range t.Partitions {
+		if i > 0 && t.Partitions[i-1].Name == p.Name {
+			continue
+		}
+		if n, err := strconv.ParseInt(p.Description, 10, 64); err == nil {
+			bounds = append(bounds, n)
+		}
+	}
+	if len(bounds) >= 2 {
+		last, prev := bounds[len(bounds)-1], bounds[len(bounds)-2]
+		if step := last - prev; step > 0 {
+			next := last + step
+			change := PartitionChange{Action: "add", Partition: fmt.Sprintf("p%d", next), Values: []interface{}{next}}
+			if sql, err := BuildPartitionAlter(t, change); err == nil {
+				suggestions = append(suggestions, PartitionSuggestion{
+					Action:    "add",
+					SQL:       sql,
+					Arguments: map[string]interface{}{"table": t.Table, "action": "add", "partition": change.Partition, "values": change.Values},
+					Note:      fmt.Sprintf("continues the spacing of the last two bounds (%d); rename the partition to match your naming scheme", step),
+				})
+			}
+		}
+	}
+	return suggestions
+}
+
+// partitionNames returns the distinct partition names in order
+func partitionNames(t *TablePartitions) []string {
+	var names []string
+	for _, p := range t.Partitions {
+		if len(names) == 0 || names[len(names)-1] != p.Name {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
+
+// hasPartition reports whether the table has a partition with this name
+func (t *TablePartitions) hasPartition(name string) bool {
+	for _, p := range t.Partitions {
+		if strings.EqualFold(p.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// partitionLiteral renders a partition bound value: numbers, NULL, and
+// MAXVALUE as is, and anything else as a quoted string
+func partitionLiteral(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "NULL"
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case int:
+		return strconv.Itoa(val)
+	case string:
+		if strings.EqualFold(val, "MAXVALUE") {
+			return "MAXVALUE"
+		}
+		if numericLiteral.MatchString(val) {
+			return val
+		}
+		return quoteStringLiteral(val)
+	default:
+		return quoteStringLiteral(fmt.Sprint(val))
+	}
+}
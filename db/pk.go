@@ -0,0 +1,96 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PrimaryKeyColumn is the synthetic result column holding each row's primary key
+const PrimaryKeyColumn = "_pk"
+
+// PrimaryKeyAnnotation describes the primary key added to result rows as _pk
+type PrimaryKeyAnnotation struct {
+	Database string   `json:"database,omitempty"`
+	Table    string   `json:"table,omitempty"`
+	Columns  []string `json:"columns,omitempty"`
+
+	// Note explains why rows were not annotated
+	Note string `json:"note,omitempty"`
+}
+
+// primaryKeyColumnsSQL lists a table's primary key columns in key order,
+// taking the database and table as arguments; a NULL database means the
+// session's default database
+const primaryKeyColumnsSQL = `SELECT COLUMN_NAME FROM information_schema.KEY_COLUMN_USAGE
+		WHERE TABLE_SCHEMA = COALESCE(?, DATABASE()) AND TABLE_NAME = ? AND CONSTRAINT_NAME = 'PRIMARY'
+		ORDER BY ORDINAL_POSITION`
+
+// PrimaryKeyColumns returns a table's primary key columns in key order, or
+// none when the table has no primary key
+func (m *Manager) PrimaryKeyColumns(connectionName, database, table string) ([]string, error) {
+	queryResult, err := m.ExecuteQuery(connectionName, primaryKeyColumnsSQL, nullIfEmpty(database), table)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]string, 0, len(queryResult.Rows))
+	for _, row := range queryResult.Rows {
+		columns = append(columns, stringValue(row["COLUMN_NAME"]))
+	}
+	return columns, nil
+}
+
+// AnnotatePrimaryKeys adds a _pk column to the result of a single-table
+// SELECT, holding each row's primary key values as an object keyed by column,
+// so a follow-up write can target exactly those rows. database is the default
+// database the query ran with. When rows cannot be annotated, the reason is
+// recorded in the result's primary_key note instead.
+func (m *Manager) AnnotatePrimaryKeys(connectionName, database, query string, r *QueryResult) {
+	refs := ExtractTableRefs(maskLiterals(query))
+	if len(refs) != 1 {
+		r.PrimaryKey = &PrimaryKeyAnnotation{Note: "primary keys are only added for SELECTs reading a single table"}
+		return
+	}
+	if refs[0].Database != "" {
+		database = refs[0].Database
+	}
+	annotation := &PrimaryKeyAnnotation{Database: database, Table: refs[0].Table}
+	r.PrimaryKey = annotation
+
+	keyColumns, err := m.PrimaryKeyColumns(connectionName, database, refs[0].Table)
+	if err != nil {
+		annotation.Note = fmt.Sprintf("could not look up the primary key: %v", err)
+		return
+	}
+	if len(keyColumns) == 0 {
+		annotation.Note = "table has no primary key"
+		return
+	}
+	annotation.Columns = keyColumns
+
+	// Match key columns to result columns case-insensitively, as MySQL does
+	resultColumns := make(map[string]string, len(r.Columns))
+	for _, col := range r.Columns {
+		resultColumns[strings.ToLower(col)] = col
+	}
+	var missing []string
+	for _, key := range keyColumns {
+		if _, ok := resultColumns[strings.ToLower(key)]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		annotation.Note = fmt.Sprintf("select the primary key column(s) %s, unaliased, to get _pk", strings.Join(missing, ", "))
+		return
+	}
+
+	for _, row := range r.Rows {
+		pk := make(map[string]interface{}, len(keyColumns))
+		for _, key := range keyColumns {
+			pk[key] = row[resultColumns[strings.ToLower(key)]]
+		}
+		row[PrimaryKeyColumn] = pk
+	}
+	r.Columns = append(r.Columns, PrimaryKeyColumn)
+	r.ColumnTypes = append(r.ColumnTypes, ColumnType{Name: PrimaryKeyColumn, DatabaseType: "PRIMARY KEY"})
+}
@@ -0,0 +1,296 @@
+package db
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxPrimaryKeyWriteRows caps the keys accepted by one WriteByPrimaryKey call
+const maxPrimaryKeyWriteRows = 1000
+
+// PrimaryKeyWriteResult reports a write targeted by primary key. SQL is the
+// statement run once per key, with the key values as its last parameters.
+type PrimaryKeyWriteResult struct {
+	Table     string `json:"table,omitempty"`
+	Operation string `json:"operation,omitempty"`
+	SQL       string `json:"sql,omitempty"`
+	Keys      int    `json:"keys,omitempty"`
+	*WriteResult
+}
+
+// WriteByPrimaryKey updates or deletes exactly the rows identified by keys,
+// each an object of primary key column to value (such as the _pk field added
+// by AnnotatePrimaryKeys). It runs one parameterized statement per key in a
+// single transaction, and rolls everything back if any key matches no row.
+// set holds the new column values for UPDATE and must be empty for DELETE.
+func (m *Manager) WriteByPrimaryKey(connectionName, database, table string, queryType QueryType, keys []map[string]interface{}, set map[string]interface{}, opts WriteOptions) (*PrimaryKeyWriteResult, error) {
+	run := m.startStatement(opts.Context, connectionName, GetQueryTypeLabel(queryType)+" "+QualifiedName(database, table)+" BY PRIMARY KEY")
+	result, err := m.writeByPrimaryKey(connectionName, database, table, queryType, keys, set, opts)
+	var rows int64
+	if result != nil && result.WriteResult != nil {
+		rows = result.RowsAffected
+	}
+	m.endStatement(run, rows, err)
+	return result, err
+}
+
+// writeByPrimaryKey runs a write for WriteByPrimaryKey
+func (m *Manager) writeByPrimaryKey(connectionName, database, table string, queryType QueryType, keys []map[string]interface{}, set map[string]interface{}, opts WriteOptions) (*PrimaryKeyWriteResult, error) {
+	db, connConfig, err := m.GetConnection(connectionName)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkReplica(connectionName, connConfig); err != nil {
+		return nil, err
+	}
+	if connConfig.ReadOnly {
+		return nil, fmt.Errorf("connection '%s' is read-only, write operations are not allowed", connectionName)
+	}
+
+	switch queryType {
+	case QueryTypeUpdate:
+		if len(set) == 0 {
+			return nil, fmt.Errorf("set is required for UPDATE")
+		}
+	case QueryTypeDelete:
+		if len(set) > 0 {
+			return nil, fmt.Errorf("set is only valid for UPDATE")
+		}
+	default:
+		return nil, fmt.Errorf("operation must be UPDATE or DELETE")
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("at least one key is required")
+	}
+	if len(keys) > maxPrimaryKeyWriteRows {
+		return nil, fmt.Errorf("at most %d keys can be written at once, got %d", maxPrimaryKeyWriteRows, len(keys))
+	}
+	if database == "" {
+		database = connConfig.Database
+	}
+
+	keyColumns, err := m.PrimaryKeyColumns(connectionName, database, table)
+	if err != nil {
+		return nil, err
+	}
+	if len(keyColumns) == 0 {
+		return nil, fmt.Errorf("table %s has no primary key", table)
+	}
+
+	keyArgs, err := primaryKeyArgs(keyColumns, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build the statement once; only the key values change per row
+	target := QualifiedName(database, table)
+	where := strings.Join(conditionsFor(keyColumns), " AND ")
+	var query string
+	var setArgs []interface{}
+	if queryType == QueryTypeUpdate {
+		columns := make([]string, 0, len(set))
+		for col := range set {
+			columns = append(columns, col)
+		}
+		sort.Strings(columns)
+		assignments := make([]string, len(columns))
+		for i, col := range columns {
+			assignments[i] = QuoteIdentifier(col) + " = ?"
+			setArgs = append(setArgs, set[col])
+		}
+		query = fmt.Sprintf("UPDATE %s SET %s WHERE %s", target, strings.Join(assignments, ", "), where)
+	} else {
+		query = fmt.Sprintf("DELETE FROM %s WHERE %s", target, where)
+	}
+
+	// Block sensitive metadata tables
+	if isSensitiveQuery(query) {
+		return nil, fmt.Errorf("access to sensitive MySQL metadata is not allowed")
+	}
+	if err := checkBlockedPatterns(connectionName, connConfig, query); err != nil {
+		return nil, err
+	}
+	if err := checkQualifiedWrite(connectionName, connConfig, query); err != nil {
+		return nil, err
+	}
+
+	result := &PrimaryKeyWriteResult{Table: table, Operation: GetQueryTypeLabel(queryType), SQL: query, Keys: len(keys)}
+
+	// Hold risky statements until a human approves them
+	if !opts.approved && needsApproval(connConfig, queryType) {
+		approved := opts
+		approved.approved = true
+		pending, err := m.requestApproval(connectionName, connConfig, query, queryType, func() (interface{}, error) {
+			return m.WriteByPrimaryKey(connectionName, database, table, queryType, keys, set, approved)
+		})
+		if err != nil {
+			return nil, err
+		}
+		result.WriteResult = &WriteResult{Approval: pending, Connection: connectionName, Database: database}
+		return result, nil
+	}
+
+	// Turn the DELETE into an UPDATE that stamps the soft-delete column
+	var rewrittenSQL string
+	if connConfig.SoftDeleteMode && queryType == QueryTypeDelete {
+		rewritten, ok, err := rewriteSoftDelete(connConfig, query)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			query, rewrittenSQL = rewritten, rewritten
+		}
+	}
+
+	release, err := m.acquireSlot(connectionName)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	ctx := statementContext(opts.Context)
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Close()
+
+	backup := connConfig.BackupBeforeWrite
+	if opts.Backup != nil {
+		backup = *opts.Backup
+	}
+
+	// CREATE TABLE would implicitly commit, so prepare the backup table first
+	if backup && connConfig.BackupTable != "" {
+		if err := m.ensureBackupTable(conn, connectionName, connConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	defer m.invalidateCache(connectionName)
+
+	start := time.Now()
+
+	// Lock and snapshot the target rows so the backup matches what is overwritten
+	var snapshot *QueryResult
+	if backup {
+		matches := make([]string, len(keys))
+		var args []interface{}
+		for i, values := range keyArgs {
+			matches[i] = "(" + where + ")"
+			args = append(args, values...)
+		}
+		selectSQL := fmt.Sprintf("SELECT * FROM %s WHERE %s FOR UPDATE", target, strings.Join(matches, " OR "))
+		rows, err := tx.QueryContext(ctx, selectSQL, args...)
+		if err != nil {
+			m.recordError(connectionName, selectSQL, err)
+			return nil, fmt.Errorf("backup query failed: %w", err)
+		}
+		snapshot, err = scanRows(rows, 0)
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	writeResult := &WriteResult{
+		Warning:      riskWarning(connectionName, connConfig),
+		Connection:   connectionName,
+		Database:     database,
+		RewrittenSQL: rewrittenSQL,
+	}
+	var missing []string
+	for i, values := range keyArgs {
+		execResult, err := tx.ExecContext(ctx, query, append(append([]interface{}(nil), setArgs...), values...)...)
+		if err != nil {
+			m.recordError(connectionName, query, err)
+			return nil, fmt.Errorf("write failed for key %d, nothing was changed: %w", i+1, err)
+		}
+		writeResult.Warnings = append(writeResult.Warnings, fetchWarnings(tx)...)
+
+		affected, _ := execResult.RowsAffected()
+		writeResult.RowsAffected += affected
+		if affected > 0 {
+			continue
+		}
+
+		// MySQL reports 0 affected rows both when the row already holds the
+		// new values and when no row matched
+		var matches int
+		if err := tx.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", target, where), values...).Scan(&matches); err != nil {
+			return nil, fmt.Errorf("write failed, nothing was changed: %w", err)
+		}
+		if matches == 0 {
+			missing = append(missing, fmt.Sprint(keys[i]))
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%d of %d keys matched no row, so nothing was changed: %s", len(missing), len(keys), strings.Join(missing, ", "))
+	}
+
+	if backup {
+		// A soft delete changed the rows rather than removing them
+		operation := GetQueryTypeLabel(queryType)
+		if rewrittenSQL != "" {
+			operation = "UPDATE"
+		}
+		ref, err := m.storeBackup(tx, connConfig, newBackup(connectionName, query, &backupTarget{database: database, table: table, operation: operation}, snapshot))
+		if err != nil {
+			return nil, fmt.Errorf("failed to store backup, write rolled back: %w", err)
+		}
+		writeResult.Backup = ref
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+	writeResult.ExecutionMs = time.Since(start).Milliseconds()
+	result.WriteResult = writeResult
+
+	slog.Info("write by primary key", "connection", connectionName, "table", table, "operation", queryType, "keys", len(keys), "rows_affected", writeResult.RowsAffected)
+	return result, nil
+}
+
+// primaryKeyArgs orders each key's values by the primary key columns,
+// rejecting keys with missing, extra, or repeated values
+func primaryKeyArgs(keyColumns []string, keys []map[string]interface{}) ([][]interface{}, error) {
+	seen := make(map[string]bool, len(keys))
+	args := make([][]interface{}, len(keys))
+	for i, key := range keys {
+		byName := make(map[string]interface{}, len(key))
+		for col, v := range key {
+			byName[strings.ToLower(col)] = v
+		}
+		if len(byName) != len(keyColumns) {
+			return nil, fmt.Errorf("key %d must have exactly the primary key columns %s", i+1, strings.Join(keyColumns, ", "))
+		}
+
+		values := make([]interface{}, len(keyColumns))
+		for j, col := range keyColumns {
+			v, ok := byName[strings.ToLower(col)]
+			if !ok {
+				return nil, fmt.Errorf("key %d is missing primary key column %s", i+1, col)
+			}
+			if v == nil {
+				return nil, fmt.Errorf("key %d has a null value for primary key column %s", i+1, col)
+			}
+			values[j] = v
+		}
+
+		id := fmt.Sprintf("%#v", values)
+		if seen[id] {
+			return nil, fmt.Errorf("key %d repeats an earlier key", i+1)
+		}
+		seen[id] = true
+		args[i] = values
+	}
+	return args, nil
+}
@@ -0,0 +1,202 @@
+package db
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultProfileSampleSize is the number of rows profile_table reads by default
+const DefaultProfileSampleSize = 10000
+
+// MaxProfileSampleSize caps the rows profile_table may read
+const MaxProfileSampleSize = 100000
+
+// profileValueWidth is the number of characters kept for string min/max/top values
+const profileValueWidth = 100
+
+// ValueCount is a value and how often it occurs in the sample
+type ValueCount struct {
+	Value interface{} `json:"value"`
+	Count int64       `json:"count"`
+}
+
+// ColumnProfile holds summary statistics for one column
+type ColumnProfile struct {
+	Name          string       `json:"name"`
+	DataType      string       `json:"data_type"`
+	NullPercent   float64      `json:"null_percent"`
+	DistinctCount *int64       `json:"distinct_count,omitempty"`
+	Min           interface{}  `json:"min,omitempty"`
+	Max           interface{}  `json:"max,omitempty"`
+	AvgLength     *float64     `json:"avg_length,omitempty"`
+	TopValues     []ValueCount `json:"top_values,omitempty"`
+}
+
+// TableProfile holds per-column statistics computed over a bounded sample
+type TableProfile struct {
+	Table       string          `json:"table"`
+	SampleSize  int             `json:"sample_size"`
+	SampledRows int64           `json:"sampled_rows"`
+	Columns     []ColumnProfile `json:"columns"`
+}
+
+// ProfileTable computes per-column statistics (null %, distinct count, min/max,
+// average length, top 5 values) over the first sampleSize rows of a table.
+// Binary, JSON, and spatial columns only report null % and average byte length.
+func (m *Manager) ProfileTable(connectionName, database, table string, columnNames []string, sampleSize int) (*TableProfile, error) {
+	if sampleSize <= 0 {
+		sampleSize = DefaultProfileSampleSize
+	}
+	sampleSize = min(sampleSize, MaxProfileSampleSize)
+
+	_, connConfig, err := m.GetConnection(connectionName)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkProtectedTable(databaseOrDefault(database, connConfig), table); err != nil {
+		return nil, err
+	}
+
+	columns, err := m.TableColumns(connectionName, database, table)
+	if err != nil {
+		return nil, err
+	}
+	if len(columnNames) > 0 {
+		if columns, err = selectColumns(columns, columnNames); err != nil {
+			return nil, err
+		}
+	}
+
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = QuoteIdentifier(col.Name)
+	}
+	sample := fmt.Sprintf("(SELECT %s FROM %s LIMIT %d) AS sample",
+		strings.Join(quoted, ", "), QualifiedName(database, table), sampleSize)
+
+	// One aggregate pass computes every column's scalar statistics
+	aggregates := []string{"COUNT(*) AS sampled_rows"}
+	for i, col := range columns {
+		q := quoted[i]
+		aggregates = append(aggregates, fmt.Sprintf("CAST(SUM(%s IS NULL) AS SIGNED) AS nulls_%d", q, i))
+		switch profileKind(col.DataType) {
+		case "string":
+			aggregates = append(aggregates,
+				fmt.Sprintf("COUNT(DISTINCT %s) AS distinct_%d", q, i),
+				fmt.Sprintf("LEFT(MIN(%s), %d) AS min_%d", q, profileValueWidth, i),
+				fmt.Sprintf("LEFT(MAX(%s), %d) AS max_%d", q, profileValueWidth, i),
+				fmt.Sprintf("AVG(CHAR_LENGTH(%s)) AS len_%d", q, i))
+		case "ordered":
+			aggregates = append(aggregates,
+				fmt.Sprintf("COUNT(DISTINCT %s) AS distinct_%d", q, i),
+				fmt.Sprintf("MIN(%s) AS min_%d", q, i),
+				fmt.Sprintf("MAX(%s) AS max_%d", q, i))
+		default:
+			aggregates = append(aggregates, fmt.Sprintf("AVG(LENGTH(%s)) AS len_%d", q, i))
+		}
+	}
+
+	stats, err := m.ExecuteQuery(connectionName, fmt.Sprintf("SELECT %s FROM %s", strings.Join(aggregates, ", "), sample))
+	if err != nil {
+		return nil, err
+	}
+	if len(stats.Rows) == 0 {
+		return nil, fmt.Errorf("failed to profile table: %s", table)
+	}
+	row := stats.Rows[0]
+
+	profile := &TableProfile{
+		Table:       table,
+		SampleSize:  sampleSize,
+		SampledRows: int64Value(row["sampled_rows"]),
+		Columns:     make([]ColumnProfile, len(columns)),
+	}
+
+	for i, col := range columns {
+		p := ColumnProfile{Name: col.Name, DataType: col.DataType}
+		if profile.SampledRows > 0 {
+			p.NullPercent = roundTo(float64(int64Value(row[fmt.Sprintf("nulls_%d", i)]))*100/float64(profile.SampledRows), 2)
+		}
+		if v, ok := row[fmt.Sprintf("distinct_%d", i)]; ok {
+			p.DistinctCount = int64Ptr(v)
+		}
+		p.Min = row[fmt.Sprintf("min_%d", i)]
+		p.Max = row[fmt.Sprintf("max_%d", i)]
+		if v := row[fmt.Sprintf("len_%d", i)]; v != nil {
+			if avg, err := strconv.ParseFloat(stringValue(v), 64); err == nil {
+				avg = roundTo(avg, 2)
+				p.AvgLength = &avg
+			}
+		}
+
+		if profileKind(col.DataType) != "other" {
+			if p.TopValues, err = m.topValues(connectionName, quoted[i], col.DataType, sample); err != nil {
+				return nil, err
+			}
+		}
+		profile.Columns[i] = p
+	}
+
+	return profile, nil
+}
+
+// topValues returns the five most frequent values of a column in the sample
+func (m *Manager) topValues(connectionName, quotedColumn, dataType, sample string) ([]ValueCount, error) {
+	value := quotedColumn
+	if profileKind(dataType) == "string" {
+		value = fmt.Sprintf("LEFT(%s, %d)", quotedColumn, profileValueWidth)
+	}
+
+	queryResult, err := m.ExecuteQuery(connectionName, fmt.Sprintf(
+		"SELECT %s AS value, COUNT(*) AS count FROM %s GROUP BY value ORDER BY count DESC, value LIMIT 5", value, sample))
+	if err != nil {
+		return nil, err
+	}
+
+	top := make([]ValueCount, 0, len(queryResult.Rows))
+	for _, row := range queryResult.Rows {
+		top = append(top, ValueCount{Value: row["value"], Count: int64Value(row["count"])})
+	}
+	return top, nil
+}
+
+// selectColumns returns the named columns in the order requested
+func selectColumns(columns []ColumnInfo, names []string) ([]ColumnInfo, error) {
+	byName := make(map[string]ColumnInfo, len(columns))
+	for _, col := range columns {
+		byName[strings.ToLower(col.Name)] = col
+	}
+
+	selected := make([]ColumnInfo, 0, len(names))
+	for _, name := range names {
+		col, ok := byName[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown column: %s", name)
+		}
+		selected = append(selected, col)
+	}
+	return selected, nil
+}
+
+// profileKind groups MySQL data types by the statistics that make sense for them:
+// "string" (text with character lengths), "ordered" (numbers and temporals), or
+// "other" (binary, JSON, spatial: null % and byte length only)
+func profileKind(dataType string) string {
+	switch dataType {
+	case "char", "varchar", "tinytext", "text", "mediumtext", "longtext", "enum", "set":
+		return "string"
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint",
+		"decimal", "numeric", "float", "double", "real", "year",
+		"date", "datetime", "timestamp", "time":
+		return "ordered"
+	default:
+		return "other"
+	}
+}
+
+// roundTo rounds f to the given number of decimal places
+func roundTo(f float64, places int) float64 {
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(f, 'f', places, 64), 64)
+	return rounded
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Progress reports for statements start once a statement has run for
+// progressDelay and repeat every progressInterval until it finishes
+const (
+	progressDelay    = 3 * time.Second
+	progressInterval = 2 * time.Second
+
+	// stageQueryTimeout bounds each stage lookup so a busy pool never holds up a report
+	stageQueryTimeout = time.Second
+)
+
+// StatementProgress describes a statement that is still running
+type StatementProgress struct {
+	ElapsedMs int64 `json:"elapsed_ms"`
+
+	// Stage is the statement's current stage from
+	// performance_schema.events_stages_current, or its processlist state
+	// when stage instrumentation is off
+	Stage string `json:"stage,omitempty"`
+
+	// WorkCompleted and WorkEstimated are reported by stages that track
+	// progress, such as InnoDB ALTER TABLE stages
+	WorkCompleted int64 `json:"work_completed,omitempty"`
+	WorkEstimated int64 `json:"work_estimated,omitempty"`
+}
+
+// stageQuery reads the current stage of a session from performance_schema.
+// Stages are only recorded when the stage/% instruments and the
+// events_stages_current consumer are enabled.
+const stageQuery = `SELECT s.EVENT_NAME, COALESCE(s.WORK_COMPLETED, 0), COALESCE(s.WORK_ESTIMATED, 0)
+	FROM performance_schema.events_stages_current s
+	JOIN performance_schema.threads t ON t.THREAD_ID = s.THREAD_ID
+	WHERE t.PROCESSLIST_ID = ?`
+
+// stateQuery reads the processlist state of a session
+const stateQuery = `SELECT COALESCE(STATE, '') FROM information_schema.PROCESSLIST WHERE ID = ?`
+
+// watchStatement calls report periodically while a statement runs on conn,
+// starting once it has run for progressDelay. Stages are looked up from a
+// separate pooled session. The returned function stops the reports and must
+// be called when the statement finishes; it is a no-op when report is nil.
+func watchStatement(db *sql.DB, conn *sql.Conn, connectionName string, report func(StatementProgress)) func() {
+	if report == nil {
+		return func() {}
+	}
+
+	var sessionID int64
+	if err := conn.QueryRowContext(context.Background(), "SELECT CONNECTION_ID()").Scan(&sessionID); err != nil {
+		slog.Debug("stage lookup disabled, failed to read connection id", "connection", connectionName, "error", err)
+	}
+
+	start := time.Now()
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		timer := time.NewTimer(progressDelay)
+		defer timer.Stop()
+		stages := sessionID != 0
+		for {
+			select {
+			case <-done:
+				return
+			case <-timer.C:
+			}
+
+			progress := StatementProgress{ElapsedMs: time.Since(start).Milliseconds()}
+			if stages {
+				if err := readStage(db, sessionID, &progress); err != nil {
+					slog.Debug("stage lookup failed, reporting elapsed time only", "connection", connectionName, "error", err)
+					stages = false
+				}
+			}
+			report(progress)
+			timer.Reset(progressInterval)
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
+// readStage fills in the current stage of a session, falling back to its
+// processlist state when performance_schema has no stage for it
+func readStage(db *sql.DB, sessionID int64, progress *StatementProgress) error {
+	ctx, cancel := context.WithTimeout(context.Background(), stageQueryTimeout)
+	defer cancel()
+
+	var event string
+	err := db.QueryRowContext(ctx, stageQuery, sessionID).Scan(&event, &progress.WorkCompleted, &progress.WorkEstimated)
+	if err == nil {
+		progress.Stage = strings.TrimPrefix(event, "stage/")
+		return nil
+	}
+	if err != sql.ErrNoRows {
+		slog.Debug("performance_schema stage lookup failed", "error", err)
+	}
+
+	return db.QueryRowContext(ctx, stateQuery, sessionID).Scan(&progress.Stage)
+}
@@ -0,0 +1,122 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+
+	"mysql-golang-mcp/config"
+)
+
+// allowedColumns returns the allowed_columns configured for a table, or nil
+// when all its columns may be read
+func allowedColumns(connConfig *config.ConnectionConfig, table string) []string {
+	for name, t := range connConfig.Tables {
+		if t != nil && strings.EqualFold(name, table) {
+			return t.AllowedColumns
+		}
+	}
+	return nil
+}
+
+// applyColumnAllowlist replaces each FROM/JOIN reference to a table with
+// allowed_columns by a derived table selecting only those columns, keeping
+// the original alias (or the table name). SELECT * then returns the allowed
+// columns, and naming any other column fails as an unknown column. Like the
+// soft-delete filter, MySQL merges the derived tables into the outer query.
+// A restricted table mentioned anywhere else (e.g. a comma join) is refused,
+// since it would be read unprojected. It returns the rewritten query and the
+// tables that were projected.
+func applyColumnAllowlist(connConfig *config.ConnectionConfig, query string) (string, []string, error) {
+	restricted := false
+	for _, t := range connConfig.Tables {
+		if t != nil && len(t.AllowedColumns) > 0 {
+			restricted = true
+			break
+		}
+	}
+	if !restricted {
+		return query, nil, nil
+	}
+
+	literals := stringLiteralPattern.FindAllStringIndex(query, -1)
+	var b strings.Builder
+	var projected []string
+	var spans [][]int
+	last := 0
+
+	for _, match := range readTableRefPattern.FindAllStringSubmatchIndex(query, -1) {
+		refStart, refEnd := match[2], match[3]
+		if insideLiteral(literals, match[0]) {
+			continue
+		}
+		ref := query[refStart:refEnd]
+		table := tableName(ref)
+		columns := allowedColumns(connConfig, table)
+		if len(columns) == 0 {
+			continue
+		}
+
+		quoted := make([]string, len(columns))
+		for i, column := range columns {
+			quoted[i] = QuoteIdentifier(column)
+		}
+		b.WriteString(query[last:refStart])
+		fmt.Fprintf(&b, "(SELECT %s FROM %s)", strings.Join(quoted, ", "), ref)
+
+		// A derived table needs an alias; reuse the table name when none is given
+		alias := aliasPattern.FindStringSubmatch(query[refEnd:])
+		if alias == nil || (alias[1] == "" && notAliases[strings.ToUpper(alias[2])]) {
+			b.WriteString(" AS " + QuoteIdentifier(table))
+		}
+		last = refEnd
+		spans = append(spans, []int{refStart, refEnd})
+		projected = append(projected, table)
+	}
+
+	// Every other mention of a restricted table, except as a column
+	// qualifier, reads it in a way the rewrite does not cover
+	masked := maskLiterals(query)
+	for name, t := range connConfig.Tables {
+		if t == nil || len(t.AllowedColumns) == 0 {
+			continue
+		}
+		pattern := regexp.MustCompile("(?i)(?:^|[^\\w$])`?(" + regexp.QuoteMeta(name) + ")`?(?:$|[^\\w$.`])")
+		for _, loc := range pattern.FindAllStringSubmatchIndex(masked, -1) {
+			if !insideLiteral(spans, loc[2]) {
+				return "", nil, fmt.Errorf("table '%s' has allowed_columns and can only be read through FROM or JOIN %s; rewrite the query to reference it there", name, name)
+			}
+		}
+	}
+
+	if len(projected) == 0 {
+		return query, nil, nil
+	}
+	b.WriteString(query[last:])
+	return b.String(), projected, nil
+}
+
+// columnAllowlistError explains an unknown column error from a query whose
+// restricted tables were projected to their allowed_columns
+func columnAllowlistError(connConfig *config.ConnectionConfig, projected []string, err error) error {
+	var mysqlErr *mysql.MySQLError
+	if len(projected) == 0 || !errors.As(err, &mysqlErr) || mysqlErr.Number != 1054 {
+		return err
+	}
+
+	tables := make(map[string]bool, len(projected))
+	var allowed []string
+	for _, table := range projected {
+		if tables[table] {
+			continue
+		}
+		tables[table] = true
+		allowed = append(allowed, fmt.Sprintf("%s (%s)", table, strings.Join(allowedColumns(connConfig, table), ", ")))
+	}
+	sort.Strings(allowed)
+	return fmt.Errorf("%w; only allowed_columns can be read from %s", err, strings.Join(allowed, ", "))
+}
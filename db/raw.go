@@ -0,0 +1,99 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"unicode/utf8"
+)
+
+// RawValue describes a value exactly as the driver delivered it, before any
+// of the conversions scanRows applies
+type RawValue struct {
+	// GoType is the Go type the driver produced, such as []uint8, int64, or time.Time
+	GoType string `json:"go_type"`
+	Null   bool   `json:"null"`
+
+	// Length, Hex, and ValidUTF8 describe byte slices; Text is set when the
+	// bytes are valid UTF-8
+	Length    *int   `json:"length,omitempty"`
+	Hex       string `json:"hex,omitempty"`
+	ValidUTF8 *bool  `json:"valid_utf8,omitempty"`
+	Text      string `json:"text,omitempty"`
+
+	// Value holds non-byte values as the driver returned them
+	Value interface{} `json:"value,omitempty"`
+}
+
+// scanRawRows reads the current result set like scanRows but keeps every
+// value as a RawValue, and reports each column's driver scan type and length.
+// Useful for diagnosing charset, collation, and driver conversion issues.
+func scanRawRows(rows *sql.Rows, maxRows int) (*QueryResult, error) {
+	result, _, err := newResultSet(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get column types: %w", err)
+	}
+	for i, ct := range colTypes {
+		if ct.ScanType() != nil {
+			result.ColumnTypes[i].ScanType = ct.ScanType().String()
+		}
+		if length, ok := ct.Length(); ok {
+			result.ColumnTypes[i].Length = &length
+		}
+	}
+
+	values := make([]interface{}, len(result.Columns))
+	valuePtrs := make([]interface{}, len(result.Columns))
+	for i := range values {
+		valuePtrs[i] = &values[i]
+	}
+
+	rowCount := 0
+	for rows.Next() {
+		if rowCount >= maxRows {
+			result.Truncated = true
+			break
+		}
+
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		row := make(map[string]interface{}, len(result.Columns))
+		for i, col := range result.Columns {
+			row[col] = rawValue(values[i])
+		}
+		result.Rows = append(result.Rows, row)
+		rowCount++
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	result.Count = rowCount
+	return result, nil
+}
+
+// rawValue describes a scanned driver value without converting it
+func rawValue(val interface{}) RawValue {
+	switch v := val.(type) {
+	case nil:
+		return RawValue{GoType: "nil", Null: true}
+	case []byte:
+		length := len(v)
+		valid := utf8.Valid(v)
+		raw := RawValue{GoType: fmt.Sprintf("%T", v), Length: &length, Hex: hex.EncodeToString(v), ValidUTF8: &valid}
+		if valid {
+			raw.Text = string(v)
+		}
+		return raw
+	default:
+		return RawValue{GoType: fmt.Sprintf("%T", v), Value: v}
+	}
+}
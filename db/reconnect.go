@@ -0,0 +1,217 @@
+package db
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ConnectionTeardown reports what tearing down a connection's pool ended
+type ConnectionTeardown struct {
+	Connection string `json:"connection"`
+
+	// Removed is set by RemoveConnection, Reconnected by ReconnectConnection
+	Removed     bool `json:"removed,omitempty"`
+	Reconnected bool `json:"reconnected,omitempty"`
+
+	// HadPool reports whether the pool was open, and InUse how many of its
+	// connections were running statements when it was abandoned
+	HadPool bool `json:"had_pool"`
+	InUse   int  `json:"in_use"`
+
+	// WriteBatch is the connection's pending write batch, committed first
+	WriteBatch *WriteBatchOutcome `json:"write_batch,omitempty"`
+
+	RolledBackTransactions []string `json:"rolled_back_transactions,omitempty"`
+	ClosedCursors          []string `json:"closed_cursors,omitempty"`
+	ClosedSessions         []string `json:"closed_sessions,omitempty"`
+
+	// ClosedUserConnections lists the "<connection>@<user>/<client>" connections
+	// derived from a removed connection, each torn down with it
+	ClosedUserConnections []string `json:"closed_user_connections,omitempty"`
+
+	// Unpersisted is set when the connection was deleted from
+	// runtime_connections_file
+	Unpersisted bool `json:"unpersisted,omitempty"`
+
+	// Server and LatencyMs describe the new pool after a reconnect
+	Server    *ServerInfo `json:"server,omitempty"`
+	LatencyMs int64       `json:"latency_ms,omitempty"`
+}
+
+// RemoveConnection tears down a connection's pool and takes the connection
+// out of service: tools refuse it until ReconnectConnection restores it. A
+// connection added by add_connection is forgotten instead, and can only be
+// added again; it is also deleted from runtime_connections_file. Connections
+// derived from it for clients' MySQL accounts are torn down too.
+func (m *Manager) RemoveConnection(name string) (*ConnectionTeardown, error) {
+	if _, exists := m.lookupConnection(name); !exists {
+		return nil, fmt.Errorf("unknown connection: %s", name)
+	}
+	if strings.Contains(name, "@") {
+		return nil, fmt.Errorf("connection '%s' belongs to a client's MySQL account and cannot be removed", name)
+	}
+
+	// Deleted from the file first, so a failure leaves the connection as it was
+	unpersisted := false
+	if m.config.AllowRuntimeConnections {
+		var err error
+		if unpersisted, err = m.config.DeleteRuntimeConnection(name); err != nil {
+			return nil, fmt.Errorf("connection '%s' was not removed: %w", name, err)
+		}
+	}
+
+	// Taken out of service first, so no new statement starts on the pool
+	if m.isRuntimeConnection(name) {
+		m.runtimeConnectionsMu.Lock()
+		delete(m.runtimeConnections, name)
+		m.runtimeConnectionsMu.Unlock()
+	} else {
+		m.removedMu.Lock()
+		m.removed[name] = true
+		m.removedMu.Unlock()
+	}
+
+	teardown := m.teardownConnection(name)
+	teardown.Removed = true
+	teardown.Unpersisted = unpersisted
+
+	m.userConnectionsMu.Lock()
+	var derived []string
+	for userName := range m.userConnections {
+		if strings.HasPrefix(userName, name+"@") {
+			derived = append(derived, userName)
+			delete(m.userConnections, userName)
+		}
+	}
+	m.userConnectionsMu.Unlock()
+	sort.Strings(derived)
+	for _, userName := range derived {
+		m.teardownConnection(userName)
+		teardown.ClosedUserConnections = append(teardown.ClosedUserConnections, userName)
+	}
+
+	slog.Info("connection removed", "connection", name)
+	return teardown, nil
+}
+
+// ReconnectConnection tears down a connection's pool, abandoning whatever
+// was running on it, and opens a new one. It restores a connection taken
+// out of service by RemoveConnection.
+func (m *Manager) ReconnectConnection(name string) (*ConnectionTeardown, error) {
+	m.removedMu.Lock()
+	delete(m.removed, name)
+	m.removedMu.Unlock()
+
+	if _, exists := m.lookupConnection(name); !exists {
+		return nil, fmt.Errorf("unknown connection: %s", name)
+	}
+
+	teardown := m.teardownConnection(name)
+	start := time.Now()
+	if _, _, err := m.GetConnection(name); err != nil {
+		return nil, fmt.Errorf("pool for '%s' was torn down but reconnect failed: %w", name, err)
+	}
+	teardown.Reconnected = true
+	teardown.Server = m.cachedServerInfo(name)
+	teardown.LatencyMs = time.Since(start).Milliseconds()
+	slog.Info("connection reconnected", "connection", name, "in_use", teardown.InUse)
+	return teardown, nil
+}
+
+// isRemoved reports whether a connection, or the connection a client's
+// MySQL account connection was derived from, was removed
+func (m *Manager) isRemoved(name string) bool {
+	base, _, _ := strings.Cut(name, "@")
+	m.removedMu.Lock()
+	defer m.removedMu.Unlock()
+	return m.removed[base]
+}
+
+// teardownConnection commits the connection's write batch, ends its
+// transactions, cursors and sessions, and drops its pool. Unlike
+// ResetConnection, it does not wait for statements running on the pool, so
+// a hung one cannot block it; the old pool is closed once they finish.
+func (m *Manager) teardownConnection(name string) *ConnectionTeardown {
+	teardown := &ConnectionTeardown{Connection: name}
+
+	m.batchesMu.Lock()
+	b := m.batches[name]
+	m.batchesMu.Unlock()
+	if b != nil {
+		b.mu.Lock()
+		if !b.done {
+			outcome := m.endWriteBatch(b, true)
+			teardown.WriteBatch = &outcome
+		}
+		b.mu.Unlock()
+	}
+
+	m.transactionsMu.Lock()
+	var transactions []*transaction
+	for id, t := range m.transactions {
+		if t.connection == name {
+			transactions = append(transactions, t)
+			delete(m.transactions, id)
+		}
+	}
+	m.transactionsMu.Unlock()
+	for _, t := range transactions {
+		t.mu.Lock()
+		if !t.done {
+			t.timer.Stop()
+			t.tx.Rollback()
+			t.release()
+			t.done = true
+			teardown.RolledBackTransactions = append(teardown.RolledBackTransactions, t.id)
+		}
+		t.mu.Unlock()
+	}
+
+	m.cursorsMu.Lock()
+	var cursorIDs []string
+	for id, c := range m.cursors {
+		if c.connection == name {
+			cursorIDs = append(cursorIDs, id)
+		}
+	}
+	m.cursorsMu.Unlock()
+	for _, id := range cursorIDs {
+		if m.CloseCursor(id) == nil {
+			teardown.ClosedCursors = append(teardown.ClosedCursors, id)
+		}
+	}
+
+	m.sessionsMu.Lock()
+	var sessionIDs []string
+	for id, s := range m.sessions {
+		if s.connection == name {
+			sessionIDs = append(sessionIDs, id)
+		}
+	}
+	m.sessionsMu.Unlock()
+	for _, id := range sessionIDs {
+		if _, err := m.CloseSession(id); err == nil {
+			teardown.ClosedSessions = append(teardown.ClosedSessions, id)
+		}
+	}
+
+	// Statements still holding slots release them into the old semaphore
+	m.mu.Lock()
+	old, hadPool := m.connections[name]
+	delete(m.connections, name)
+	delete(m.maxAllowedPacket, name)
+	delete(m.serverInfo, name)
+	delete(m.semaphores, name)
+	m.mu.Unlock()
+	m.invalidateCache(name)
+
+	if hadPool {
+		teardown.HadPool = true
+		teardown.InUse = old.Stats().InUse
+		go old.Close()
+	}
+	return teardown
+}
@@ -0,0 +1,223 @@
+package db
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// passwordStoreTimeout bounds the password_rotation command
+const passwordStoreTimeout = time.Minute
+
+// Generated passwords draw from these classes, with at least one character
+// of each so they pass validate_password's MEDIUM policy. The symbols are
+// ones the DSN and shell quoting leave alone.
+var passwordClasses = []string{
+	"abcdefghijklmnopqrstuvwxyz",
+	"ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	"0123456789",
+	"-_.!#^*+=",
+}
+
+// PasswordRotation is the result of rotate_password. The password itself is
+// never returned.
+type PasswordRotation struct {
+	Connection string `json:"connection"`
+	User       string `json:"user"`
+	Statement  string `json:"statement"`
+
+	// StoredIn lists where the new password was stored: the
+	// password_rotation command and/or the password_file
+	StoredIn []string `json:"stored_in"`
+
+	// OldPasswordRetained is set when the previous password still works as
+	// the account's secondary password, for other clients still using it
+	OldPasswordRetained bool   `json:"old_password_retained"`
+	Reconnected         bool   `json:"reconnected"`
+	Note                string `json:"note,omitempty"`
+	Warning             string `json:"warning,omitempty"`
+}
+
+// RotatePassword changes the connection's own MySQL password to a generated
+// one, stores it with the password_rotation command and in password_file, and
+// rebuilds the pool with it. On MySQL 8.0.14+ the old password is retained as
+// the secondary password until the next rotation, unless discardOld is set.
+// If the new password cannot be stored the change is reverted. Requires
+// allow_admin and password_rotation on the connection.
+func (m *Manager) RotatePassword(connectionName string, discardOld bool) (*PasswordRotation, error) {
+	connConfig, exists := m.config.Connections[connectionName]
+	if !exists {
+		return nil, fmt.Errorf("unknown connection: %s", connectionName)
+	}
+	rotation := connConfig.PasswordRotation
+	if rotation == nil {
+		return nil, fmt.Errorf("connection '%s' has no password_rotation configured; set password_rotation in config to enable rotate_password", connectionName)
+	}
+	info, err := m.ServerInfo(connectionName)
+	if err != nil {
+		return nil, err
+	}
+	dual := info.supportsDualPasswords()
+
+	password, err := generatePassword(rotation.Length)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate password: %w", err)
+	}
+
+	db, _, release, err := m.adminConnection(connectionName, true)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	user, oldPassword := connConfig.User, connConfig.Password
+	m.mu.RUnlock()
+
+	query, redacted := alterOwnPassword(password, oldPassword, dual, dual)
+	if err := checkBlockedPatterns(connectionName, connConfig, redacted); err != nil {
+		release()
+		return nil, err
+	}
+	if _, err := db.Exec(query); err != nil {
+		release()
+		m.recordError(connectionName, redacted, err)
+		return nil, fmt.Errorf("query execution failed: %w", err)
+	}
+
+	result := &PasswordRotation{Connection: connectionName, User: user, Statement: redacted, StoredIn: []string{}}
+	if err := m.storePassword(connectionName, password, result); err != nil {
+		// The account's old password is restored so the stored one still works
+		revert, revertRedacted := alterOwnPassword(oldPassword, password, dual, false)
+		_, revertErr := db.Exec(revert)
+		release()
+		if revertErr != nil {
+			m.recordError(connectionName, revertRedacted, revertErr)
+			if dual {
+				return nil, fmt.Errorf("failed to store the new password (%v) and to restore the old one (%v); the old password still works as the account's secondary password", err, revertErr)
+			}
+			return nil, fmt.Errorf("failed to store the new password (%v) and to restore the old one (%v); the account's password must be reset by an administrator", err, revertErr)
+		}
+		return nil, fmt.Errorf("failed to store the new password, the old one was restored: %w", err)
+	}
+	release()
+
+	m.mu.Lock()
+	connConfig.SetPassword(password)
+	m.mu.Unlock()
+	if _, err := m.ResetConnection(connectionName); err != nil {
+		return nil, err
+	}
+	result.Reconnected = true
+	result.Warning = riskWarning(connectionName, connConfig)
+
+	switch {
+	case !dual:
+		result.Note = "the server does not support dual passwords (MySQL 8.0.14+), so other clients using the old password can no longer connect"
+	case discardOld:
+		if err := m.discardOldPassword(connectionName); err != nil {
+			result.OldPasswordRetained = true
+			result.Note = "the old password could not be discarded: " + err.Error()
+		}
+	default:
+		result.OldPasswordRetained = true
+		result.Note = "the old password keeps working as the secondary password until the next rotation or ALTER USER ... DISCARD OLD PASSWORD"
+	}
+	return result, nil
+}
+
+// alterOwnPassword returns the statement changing the connected account's
+// password, and its redacted form. With dual passwords the current password
+// is named in REPLACE, so accounts with password_require_current can change
+// it, and with retain it is kept as the secondary password.
+func alterOwnPassword(password, current string, dual, retain bool) (string, string) {
+	query := "ALTER USER CURRENT_USER() IDENTIFIED BY " + quoteStringLiteral(password)
+	redacted := "ALTER USER CURRENT_USER() IDENTIFIED BY '[redacted]'"
+	if dual {
+		query += " REPLACE " + quoteStringLiteral(current)
+		redacted += " REPLACE '[redacted]'"
+	}
+	if retain {
+		query += " RETAIN CURRENT PASSWORD"
+		redacted += " RETAIN CURRENT PASSWORD"
+	}
+	return query, redacted
+}
+
+// storePassword hands the new password to the password_rotation command and
+// writes it to password_file, recording each in the result
+func (m *Manager) storePassword(connectionName, password string, result *PasswordRotation) error {
+	connConfig := m.config.Connections[connectionName]
+	rotation := connConfig.PasswordRotation
+
+	if rotation.Command != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), passwordStoreTimeout)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, rotation.Command, rotation.Args...)
+		cmd.Stdin = strings.NewReader(password)
+		cmd.Env = append(os.Environ(), "MYSQL_MCP_CONNECTION="+connectionName, "MYSQL_MCP_USER="+result.User)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			message := strings.TrimSpace(string(output))
+			if len(message) > 500 {
+				message = message[len(message)-500:]
+			}
+			return fmt.Errorf("password_rotation command failed: %w: %s", err, message)
+		}
+		result.StoredIn = append(result.StoredIn, "command")
+	}
+
+	if connConfig.PasswordFile != "" {
+		if err := connConfig.WritePasswordFile(password); err != nil {
+			return err
+		}
+		result.StoredIn = append(result.StoredIn, "password_file")
+	}
+	return nil
+}
+
+// discardOldPassword drops the secondary password kept by a rotation, over
+// the pool logged in with the new one
+func (m *Manager) discardOldPassword(connectionName string) error {
+	db, _, release, err := m.adminConnection(connectionName, true)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	query := "ALTER USER CURRENT_USER() DISCARD OLD PASSWORD"
+	if _, err := db.Exec(query); err != nil {
+		m.recordError(connectionName, query, err)
+		return err
+	}
+	return nil
+}
+
+// generatePassword returns a random password of length characters with at
+// least one of each passwordClasses class
+func generatePassword(length int) (string, error) {
+	alphabet := strings.Join(passwordClasses, "")
+	size := big.NewInt(int64(len(alphabet)))
+	for {
+		password := make([]byte, length)
+		for i := range password {
+			n, err := rand.Int(rand.Reader, size)
+			if err != nil {
+				return "", err
+			}
+			password[i] = alphabet[n.Int64()]
+		}
+		complete := true
+		for _, class := range passwordClasses {
+			if !strings.ContainsAny(string(password), class) {
+				complete = false
+				break
+			}
+		}
+		if complete {
+			return string(password), nil
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CallResult holds the result sets returned by a stored procedure call
+type CallResult struct {
+	ResultSets []*QueryResult `json:"result_sets"`
+	Count      int            `json:"count"`
+
+	// Approval is set when the call was queued for approval instead of run
+	Approval *PendingApproval `json:"approval,omitempty"`
+}
+
+// ListRoutines returns the stored procedures and functions in a database
+func (m *Manager) ListRoutines(connectionName, database string) (*QueryResult, error) {
+	return m.ExecuteQuery(connectionName, `SELECT ROUTINE_NAME, ROUTINE_TYPE, DATA_TYPE, SQL_DATA_ACCESS,
+		IS_DETERMINISTIC, SECURITY_TYPE, CREATED, LAST_ALTERED, ROUTINE_COMMENT
+		FROM information_schema.ROUTINES
+		WHERE ROUTINE_SCHEMA = COALESCE(?, DATABASE())
+		ORDER BY ROUTINE_TYPE, ROUTINE_NAME`, nullIfEmpty(database))
+}
+
+// DescribeRoutine returns the definition and parameters of a stored procedure or function
+func (m *Manager) DescribeRoutine(connectionName, database, name string) (map[string]interface{}, error) {
+	routine, err := m.ExecuteQuery(connectionName, `SELECT ROUTINE_NAME, ROUTINE_TYPE, DATA_TYPE, DTD_IDENTIFIER,
+		ROUTINE_DEFINITION, SQL_DATA_ACCESS, IS_DETERMINISTIC, SECURITY_TYPE, DEFINER,
+		CREATED, LAST_ALTERED, ROUTINE_COMMENT
+		FROM information_schema.ROUTINES
+		WHERE ROUTINE_SCHEMA = COALESCE(?, DATABASE()) AND ROUTINE_NAME = ?`, nullIfEmpty(database), name)
+	if err != nil {
+		return nil, err
+	}
+	if len(routine.Rows) == 0 {
+		return nil, fmt.Errorf("routine not found: %s", name)
+	}
+
+	params, err := m.ExecuteQuery(connectionName, `SELECT ORDINAL_POSITION, PARAMETER_MODE, PARAMETER_NAME, DTD_IDENTIFIER
+		FROM information_schema.PARAMETERS
+		WHERE SPECIFIC_SCHEMA = COALESCE(?, DATABASE()) AND SPECIFIC_NAME = ? AND ORDINAL_POSITION > 0
+		ORDER BY ORDINAL_POSITION`, nullIfEmpty(database), name)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"routine":    routine.Rows[0],
+		"parameters": params.Rows,
+	}, nil
+}
+
+// CallProcedure invokes a stored procedure with IN parameters and returns
+// all of its result sets. Procedures in protected schemas, such as
+// sys.execute_prepared_stmt, can run arbitrary statements and are refused.
+func (m *Manager) CallProcedure(connectionName, database, name string, args []interface{}) (*CallResult, error) {
+	return m.callProcedure(connectionName, database, name, args, false)
+}
+
+// callProcedure runs CallProcedure, queueing the call on require_approval
+// connections unless it was already approved
+func (m *Manager) callProcedure(connectionName, database, name string, args []interface{}, approved bool) (*CallResult, error) {
+	db, connConfig, err := m.GetConnection(connectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Procedures can modify data, so they are treated as writes
+	if err := checkReplica(connectionName, connConfig); err != nil {
+		return nil, err
+	}
+	if connConfig.ReadOnly {
+		return nil, fmt.Errorf("connection '%s' is read-only, CALL is not allowed", connectionName)
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("procedure name is required")
+	}
+	if schema := databaseOrDefault(database, connConfig); protectedSchemas[strings.ToLower(schema)] {
+		return nil, fmt.Errorf("procedures in database '%s' cannot be called", schema)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")
+	query := fmt.Sprintf("CALL %s(%s)", QualifiedName(database, name), placeholders)
+
+	if isSensitiveQuery(query) {
+		return nil, fmt.Errorf("access to sensitive MySQL metadata is not allowed")
+	}
+	if err := checkBlockedPatterns(connectionName, connConfig, query); err != nil {
+		return nil, err
+	}
+
+	// Hold risky statements until a human approves them
+	if !approved && needsApproval(connConfig, QueryTypeCall) {
+		pending, err := m.requestApproval(connectionName, connConfig, query, QueryTypeCall, func() (interface{}, error) {
+			return m.callProcedure(connectionName, database, name, args, true)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &CallResult{ResultSets: make([]*QueryResult, 0), Approval: pending}, nil
+	}
+
+	release, err := m.acquireSlot(connectionName)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	defer m.invalidateCache(connectionName)
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		m.recordError(connectionName, query, err)
+		return nil, fmt.Errorf("query execution failed: %w", err)
+	}
+	defer rows.Close()
+
+	result := &CallResult{ResultSets: make([]*QueryResult, 0)}
+	for {
+		columns, err := rows.Columns()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get columns: %w", err)
+		}
+
+		// The final status result of a CALL has no columns
+		if len(columns) > 0 {
+			queryResult, err := scanRows(rows, connConfig.MaxRows)
+			if err != nil {
+				return nil, err
+			}
+			queryResult.Connection = connectionName
+			result.ResultSets = append(result.ResultSets, queryResult)
+		}
+
+		if !rows.NextResultSet() {
+			break
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		m.recordError(connectionName, query, err)
+		return nil, fmt.Errorf("query execution failed: %w", err)
+	}
+
+	result.Count = len(result.ResultSets)
+	return result, nil
+}
+
+// nullIfEmpty returns nil for an empty string so it binds as SQL NULL
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
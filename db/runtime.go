@@ -0,0 +1,108 @@
+package db
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"mysql-golang-mcp/config"
+)
+
+// RuntimeConnection is a connection added by add_connection while the server
+// runs. Password may be a ${VAR} reference, which is kept unexpanded when
+// the connection is persisted.
+type RuntimeConnection struct {
+	Name     string
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Database string
+	ReadOnly bool
+}
+
+// AddConnectionResult is the response of AddConnection
+type AddConnectionResult struct {
+	Connection string      `json:"connection"`
+	Server     *ServerInfo `json:"server,omitempty"`
+	LatencyMs  int64       `json:"latency_ms"`
+	Persisted  bool        `json:"persisted"`
+	File       string      `json:"file,omitempty"`
+}
+
+// AddConnection tests a connection and registers it, in memory only unless
+// persist is set, in which case it is also written to
+// runtime_connections_file and loaded again at startup. Requires
+// allow_runtime_connections.
+func (m *Manager) AddConnection(rc RuntimeConnection, persist bool) (*AddConnectionResult, error) {
+	if !m.config.AllowRuntimeConnections {
+		return nil, fmt.Errorf("runtime connections are disabled; set allow_runtime_connections in config to use add_connection")
+	}
+
+	// Settings as they would appear in a config file, before defaults apply
+	entry := map[string]interface{}{
+		"host":      rc.Host,
+		"user":      rc.User,
+		"database":  rc.Database,
+		"read_only": rc.ReadOnly,
+	}
+	if rc.Port != 0 {
+		entry["port"] = rc.Port
+	}
+	if rc.Password != "" {
+		entry["password"] = rc.Password
+	}
+
+	connConfig := &config.ConnectionConfig{
+		Host:     rc.Host,
+		Port:     rc.Port,
+		User:     rc.User,
+		Password: rc.Password,
+		Database: rc.Database,
+		ReadOnly: rc.ReadOnly,
+	}
+
+	m.runtimeConnectionsMu.Lock()
+	if _, exists := m.runtimeConnections[rc.Name]; exists {
+		m.runtimeConnectionsMu.Unlock()
+		return nil, fmt.Errorf("connection '%s' already exists", rc.Name)
+	}
+	if err := m.config.PrepareRuntimeConnection(rc.Name, connConfig); err != nil {
+		m.runtimeConnectionsMu.Unlock()
+		return nil, err
+	}
+	m.runtimeConnections[rc.Name] = connConfig
+	m.runtimeConnectionsMu.Unlock()
+
+	// Opening the pool tests the connection; one that fails is not kept
+	start := time.Now()
+	if _, _, err := m.GetConnection(rc.Name); err != nil {
+		m.runtimeConnectionsMu.Lock()
+		delete(m.runtimeConnections, rc.Name)
+		m.runtimeConnectionsMu.Unlock()
+		return nil, err
+	}
+	result := &AddConnectionResult{
+		Connection: rc.Name,
+		Server:     m.cachedServerInfo(rc.Name),
+		LatencyMs:  time.Since(start).Milliseconds(),
+	}
+	slog.Info("connection added", "connection", rc.Name, "host", connConfig.Host, "database", connConfig.Database, "read_only", connConfig.ReadOnly)
+
+	if persist {
+		if err := m.config.SaveRuntimeConnection(rc.Name, entry); err != nil {
+			return nil, fmt.Errorf("connection '%s' was added for this session but not persisted: %w", rc.Name, err)
+		}
+		result.Persisted = true
+		result.File = m.config.RuntimeConnectionsFile
+	}
+	return result, nil
+}
+
+// isRuntimeConnection reports whether a connection was added by add_connection
+func (m *Manager) isRuntimeConnection(name string) bool {
+	m.runtimeConnectionsMu.Lock()
+	defer m.runtimeConnectionsMu.Unlock()
+	_, exists := m.runtimeConnections[name]
+	return exists
+}
@@ -0,0 +1,215 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultSamplePerGroup and DefaultSampleGroups are the sample_representative defaults
+const (
+	DefaultSamplePerGroup = 3
+	DefaultSampleGroups   = 10
+)
+
+// MaxSamplePerGroup and MaxSampleGroups cap a stratified sample
+const (
+	MaxSamplePerGroup = 20
+	MaxSampleGroups   = 50
+)
+
+// sampleGroupColumn tags each sampled row with the index of its group
+const sampleGroupColumn = "_sample_group"
+
+// timeBuckets maps a time bucket to the DATE_FORMAT pattern of its start and
+// its length as an INTERVAL
+var timeBuckets = map[string]struct{ start, interval string }{
+	"hour":  {"DATE_FORMAT(%s, '%%Y-%%m-%%d %%H:00:00')", "1 HOUR"},
+	"day":   {"DATE_FORMAT(%s, '%%Y-%%m-%%d')", "1 DAY"},
+	"week":  {"DATE_FORMAT(DATE_SUB(%[1]s, INTERVAL WEEKDAY(%[1]s) DAY), '%%Y-%%m-%%d')", "1 WEEK"},
+	"month": {"DATE_FORMAT(%s, '%%Y-%%m-01')", "1 MONTH"},
+	"year":  {"DATE_FORMAT(%s, '%%Y-01-01')", "1 YEAR"},
+}
+
+// SampleOptions selects how sample_representative stratifies a table
+type SampleOptions struct {
+	// Column is the column whose distinct values, or time buckets, form the groups
+	Column string
+
+	// TimeBucket groups a DATE, DATETIME or TIMESTAMP column by hour, day,
+	// week, month, or year instead of by distinct value
+	TimeBucket string
+
+	PerGroup  int
+	MaxGroups int
+
+	// Columns are the columns returned for each row (defaults to all)
+	Columns []string
+}
+
+// SampleGroup is one stratum of a sample with its share of the table
+type SampleGroup struct {
+	Key          interface{}              `json:"key"`
+	TotalRows    int64                    `json:"total_rows"`
+	SharePercent float64                  `json:"share_percent"`
+	Rows         []map[string]interface{} `json:"rows"`
+}
+
+// RepresentativeSample is a stratified sample of a table
+type RepresentativeSample struct {
+	Table      string `json:"table"`
+	Column     string `json:"column"`
+	TimeBucket string `json:"time_bucket,omitempty"`
+
+	TotalRows   int64 `json:"total_rows"`
+	TotalGroups int64 `json:"total_groups"`
+
+	// OtherRows counts rows in groups beyond max_groups, which are not sampled
+	OtherRows int64 `json:"other_rows"`
+
+	Groups    []SampleGroup `json:"groups"`
+	Truncated bool          `json:"truncated,omitempty"`
+}
+
+// SampleRepresentative returns a few rows from each group of a table instead
+// of the first N rows, so a small sample still shows how the data is
+// distributed. Groups are the most frequent distinct values of a column, or
+// the most recent time buckets of a temporal column, each reported with its
+// row count and share of the table. Counting the groups reads the whole
+// column; rows within a group are the first found, not a random pick.
+func (m *Manager) SampleRepresentative(connectionName, database, table string, opts SampleOptions) (*RepresentativeSample, error) {
+	if opts.PerGroup <= 0 {
+		opts.PerGroup = DefaultSamplePerGroup
+	}
+	opts.PerGroup = min(opts.PerGroup, MaxSamplePerGroup)
+	if opts.MaxGroups <= 0 {
+		opts.MaxGroups = DefaultSampleGroups
+	}
+	opts.MaxGroups = min(opts.MaxGroups, MaxSampleGroups)
+
+	_, connConfig, err := m.GetConnection(connectionName)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkProtectedTable(databaseOrDefault(database, connConfig), table); err != nil {
+		return nil, err
+	}
+
+	columns, err := m.TableColumns(connectionName, database, table)
+	if err != nil {
+		return nil, err
+	}
+	strata, err := selectColumns(columns, []string{opts.Column})
+	if err != nil {
+		return nil, err
+	}
+	column := strata[0]
+	if len(opts.Columns) > 0 {
+		if columns, err = selectColumns(columns, opts.Columns); err != nil {
+			return nil, err
+		}
+	}
+
+	quotedColumn := QuoteIdentifier(column.Name)
+	key := quotedColumn
+	var bucket struct{ start, interval string }
+	if opts.TimeBucket != "" {
+		var ok bool
+		if bucket, ok = timeBuckets[strings.ToLower(opts.TimeBucket)]; !ok {
+			return nil, fmt.Errorf("time_bucket must be one of hour, day, week, month, year, got %q", opts.TimeBucket)
+		}
+		switch column.DataType {
+		case "date", "datetime", "timestamp":
+		default:
+			return nil, fmt.Errorf("time_bucket needs a DATE, DATETIME or TIMESTAMP column; %s is %s", column.Name, column.DataType)
+		}
+		key = fmt.Sprintf(bucket.start, quotedColumn)
+	} else {
+		switch column.DataType {
+		case "datetime", "timestamp":
+			return nil, fmt.Errorf("%s is %s; set time_bucket to group it by hour, day, week, month, or year", column.Name, column.DataType)
+		case "float", "double", "real", "json", "geometry", "point", "linestring", "polygon",
+			"tinyblob", "blob", "mediumblob", "longblob":
+			return nil, fmt.Errorf("cannot group by %s column %s; choose a column with discrete values", column.DataType, column.Name)
+		}
+	}
+
+	source := QualifiedName(database, table)
+	sample := &RepresentativeSample{Table: table, Column: column.Name, TimeBucket: strings.ToLower(opts.TimeBucket)}
+
+	totals, err := m.ExecuteQuery(connectionName, fmt.Sprintf(
+		"SELECT COUNT(*) AS total_rows, COUNT(DISTINCT %[1]s) + COALESCE(MAX(%[1]s IS NULL), 0) AS total_groups FROM %[2]s", key, source))
+	if err != nil {
+		return nil, err
+	}
+	if len(totals.Rows) > 0 {
+		sample.TotalRows = int64Value(totals.Rows[0]["total_rows"])
+		sample.TotalGroups = int64Value(totals.Rows[0]["total_groups"])
+	}
+
+	// Distinct values are sampled most frequent first; time buckets most recent first
+	order := "count DESC, group_key"
+	if opts.TimeBucket != "" {
+		order = "group_key DESC"
+	}
+	groups, err := m.ExecuteQuery(connectionName, fmt.Sprintf(
+		"SELECT %s AS group_key, COUNT(*) AS count FROM %s GROUP BY group_key ORDER BY %s LIMIT %d",
+		key, source, order, opts.MaxGroups))
+	if err != nil {
+		return nil, err
+	}
+
+	sample.OtherRows = sample.TotalRows
+	sample.Groups = make([]SampleGroup, len(groups.Rows))
+	for i, row := range groups.Rows {
+		group := SampleGroup{Key: row["group_key"], TotalRows: int64Value(row["count"]), Rows: make([]map[string]interface{}, 0)}
+		if sample.TotalRows > 0 {
+			group.SharePercent = roundTo(float64(group.TotalRows)*100/float64(sample.TotalRows), 2)
+		}
+		sample.OtherRows -= group.TotalRows
+		sample.Groups[i] = group
+	}
+	if len(sample.Groups) == 0 {
+		return sample, nil
+	}
+
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = QuoteIdentifier(col.Name)
+	}
+
+	// One UNION ALL reads every group's rows; time buckets are matched by
+	// range so an index on the column can be used
+	parts := make([]string, len(sample.Groups))
+	var args []interface{}
+	for i, group := range sample.Groups {
+		var condition string
+		switch {
+		case group.Key == nil:
+			condition = quotedColumn + " IS NULL"
+		case opts.TimeBucket != "":
+			condition = fmt.Sprintf("%[1]s >= ? AND %[1]s < ? + INTERVAL %[2]s", quotedColumn, bucket.interval)
+			args = append(args, group.Key, group.Key)
+		default:
+			condition = quotedColumn + " = ?"
+			args = append(args, group.Key)
+		}
+		parts[i] = fmt.Sprintf("(SELECT %d AS %s, %s FROM %s WHERE %s LIMIT %d)",
+			i, sampleGroupColumn, strings.Join(quoted, ", "), source, condition, opts.PerGroup)
+	}
+
+	rows, err := m.ExecuteQuery(connectionName, strings.Join(parts, " UNION ALL "), args...)
+	if err != nil {
+		return nil, err
+	}
+	sample.Truncated = rows.Truncated
+	for _, row := range rows.Rows {
+		i := int(int64Value(row[sampleGroupColumn]))
+		if i < 0 || i >= len(sample.Groups) {
+			continue
+		}
+		delete(row, sampleGroupColumn)
+		sample.Groups[i].Rows = append(sample.Groups[i].Rows, row)
+	}
+
+	return sample, nil
+}
@@ -0,0 +1,209 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ColumnType describes a result column using the driver's type metadata
+type ColumnType struct {
+	Name         string `json:"name"`
+	DatabaseType string `json:"database_type"`
+	Nullable     *bool  `json:"nullable,omitempty"`
+
+	// ScanType and Length are reported in raw mode only
+	ScanType string `json:"scan_type,omitempty"`
+	Length   *int64 `json:"length,omitempty"`
+
+	// JSONType is set in tool results on columns rendered per the output
+	// options: "string" for DECIMAL and "number|string" for BIGINT with
+	// safe_numbers, and the geometry_format for spatial columns
+	JSONType string `json:"json_type,omitempty"`
+}
+
+// ColumnarResult is a QueryResult with rows as value arrays in column order,
+// avoiding repeated key names for wide result sets
+type ColumnarResult struct {
+	Columns     []string        `json:"columns"`
+	ColumnTypes []ColumnType    `json:"column_types"`
+	Values      [][]interface{} `json:"values"`
+	Count       int             `json:"count"`
+	Truncated   bool            `json:"truncated"`
+	ExecutionMs int64           `json:"execution_ms"`
+	Warnings    []Warning       `json:"warnings,omitempty"`
+	Connection  string          `json:"connection,omitempty"`
+	Database    string          `json:"database,omitempty"`
+
+	SoftDeleteFiltered []string      `json:"soft_delete_filtered,omitempty"`
+	ProjectedTables    []string      `json:"projected_tables,omitempty"`
+	Lint               []LintFinding `json:"lint,omitempty"`
+	Elided             *Elision      `json:"elided,omitempty"`
+	DuplicatesRemoved  int           `json:"duplicates_removed,omitempty"`
+	Cached             bool          `json:"cached,omitempty"`
+
+	PrimaryKey *PrimaryKeyAnnotation `json:"primary_key,omitempty"`
+	Page       *KeysetPage           `json:"page,omitempty"`
+}
+
+// Columnar converts the result to the columnar layout
+func (r *QueryResult) Columnar() *ColumnarResult {
+	values := make([][]interface{}, len(r.Rows))
+	for i, row := range r.Rows {
+		values[i] = make([]interface{}, len(r.Columns))
+		for j, col := range r.Columns {
+			values[i][j] = row[col]
+		}
+	}
+
+	return &ColumnarResult{
+		Columns:     r.Columns,
+		ColumnTypes: r.ColumnTypes,
+		Values:      values,
+		Count:       r.Count,
+		Truncated:   r.Truncated,
+		ExecutionMs: r.ExecutionMs,
+		Warnings:    r.Warnings,
+		Connection:  r.Connection,
+		Database:    r.Database,
+
+		SoftDeleteFiltered: r.SoftDeleteFiltered,
+		ProjectedTables:    r.ProjectedTables,
+		Lint:               r.Lint,
+		Elided:             r.Elided,
+		DuplicatesRemoved:  r.DuplicatesRemoved,
+		Cached:             r.Cached,
+
+		PrimaryKey: r.PrimaryKey,
+		Page:       r.Page,
+	}
+}
+
+// scanRows reads the current result set into a QueryResult, stopping after maxRows rows.
+// Values are converted using column type metadata: integers and floats become JSON
+// numbers, DECIMAL stays a string (tagged by its column type), DATETIME/TIMESTAMP
+// become RFC3339 strings, DATE becomes YYYY-MM-DD, and NULL becomes null.
+func scanRows(rows *sql.Rows, maxRows int) (*QueryResult, error) {
+	result, dbTypes, err := newResultSet(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	rowCount := 0
+	for rows.Next() {
+		if rowCount >= maxRows {
+			// At least one more row exists beyond the limit
+			result.Truncated = true
+			break
+		}
+
+		row, err := scanRow(rows, result.Columns, dbTypes)
+		if err != nil {
+			return nil, err
+		}
+		result.Rows = append(result.Rows, row)
+		rowCount++
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	result.Count = rowCount
+	return result, nil
+}
+
+// newResultSet returns an empty QueryResult describing the columns of rows,
+// along with each column's database type name for convertValue
+func newResultSet(rows *sql.Rows) (*QueryResult, []string, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get columns: %w", err)
+	}
+
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get column types: %w", err)
+	}
+
+	result := &QueryResult{
+		Columns:     columns,
+		ColumnTypes: make([]ColumnType, len(colTypes)),
+		Rows:        make([]map[string]interface{}, 0),
+	}
+
+	dbTypes := make([]string, len(colTypes))
+	for i, ct := range colTypes {
+		dbTypes[i] = ct.DatabaseTypeName()
+		result.ColumnTypes[i] = ColumnType{Name: ct.Name(), DatabaseType: dbTypes[i]}
+		if nullable, ok := ct.Nullable(); ok {
+			result.ColumnTypes[i].Nullable = &nullable
+		}
+	}
+
+	return result, dbTypes, nil
+}
+
+// scanRow scans the current row into a map of converted values keyed by column name
+func scanRow(rows *sql.Rows, columns, dbTypes []string) (map[string]interface{}, error) {
+	values := make([]interface{}, len(columns))
+	valuePtrs := make([]interface{}, len(columns))
+	for i := range values {
+		valuePtrs[i] = &values[i]
+	}
+
+	if err := rows.Scan(valuePtrs...); err != nil {
+		return nil, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	row := make(map[string]interface{}, len(columns))
+	for i, col := range columns {
+		row[col] = convertValue(values[i], dbTypes[i])
+	}
+	return row, nil
+}
+
+// convertValue converts a scanned driver value into a JSON-friendly value for its column type
+func convertValue(val interface{}, dbType string) interface{} {
+	switch v := val.(type) {
+	case nil:
+		return nil
+	case time.Time:
+		if dbType == "DATE" {
+			return v.Format("2006-01-02")
+		}
+		return v.Format(time.RFC3339Nano)
+	case []byte:
+		s := string(v)
+		switch {
+		case isIntegerType(dbType):
+			if strings.HasPrefix(dbType, "UNSIGNED") {
+				if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+					return n
+				}
+			} else if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return n
+			}
+		case dbType == "FLOAT" || dbType == "DOUBLE":
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				return f
+			}
+		}
+		// DECIMAL, strings, and binary data are returned as strings
+		return s
+	default:
+		return v
+	}
+}
+
+// isIntegerType reports whether a driver database type name is an integer type
+func isIntegerType(dbType string) bool {
+	switch strings.TrimPrefix(dbType, "UNSIGNED ") {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "BIGINT", "YEAR":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,186 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ColumnInfo describes a table column from information_schema.COLUMNS
+type ColumnInfo struct {
+	Name             string  `json:"name"`
+	Position         int64   `json:"position"`
+	DataType         string  `json:"data_type"`
+	ColumnType       string  `json:"column_type"`
+	Nullable         bool    `json:"nullable"`
+	Key              string  `json:"key,omitempty"`
+	Extra            string  `json:"extra,omitempty"`
+	Default          *string `json:"default,omitempty"`
+	Comment          string  `json:"comment,omitempty"`
+	MaxLength        *int64  `json:"max_length,omitempty"`
+	NumericPrecision *int64  `json:"numeric_precision,omitempty"`
+	NumericScale     *int64  `json:"numeric_scale,omitempty"`
+	CharacterSet     string  `json:"character_set,omitempty"`
+	Collation        string  `json:"collation,omitempty"`
+
+	// GenerationExpression is the expression of a generated column
+	GenerationExpression string `json:"generation_expression,omitempty"`
+}
+
+// TableDescription is the structure of a table as returned by DescribeTable
+type TableDescription struct {
+	Database string       `json:"database,omitempty"`
+	Table    string       `json:"table"`
+	Columns  []ColumnInfo `json:"columns"`
+}
+
+// DescribeTable merges DESCRIBE with information_schema.COLUMNS. DESCRIBE
+// decides which columns are listed and in what order, since temporary
+// tables are missing from information_schema; their columns carry only what
+// DESCRIBE reports.
+func (m *Manager) DescribeTable(connectionName, database, table string) (*TableDescription, error) {
+	target := QuoteIdentifier(table)
+	if database != "" {
+		target = QuoteIdentifier(database) + "." + target
+	}
+	described, err := m.ExecuteQuery(connectionName, "DESCRIBE "+target)
+	if err != nil {
+		return nil, err
+	}
+
+	details := make(map[string]ColumnInfo)
+	if columns, err := m.TableColumns(connectionName, database, table); err == nil {
+		for _, col := range columns {
+			details[col.Name] = col
+		}
+	}
+
+	description := &TableDescription{Database: database, Table: table, Columns: make([]ColumnInfo, 0, len(described.Rows))}
+	if database == "" {
+		description.Database = described.Database
+	}
+	for i, row := range described.Rows {
+		name := stringValue(row["Field"])
+		col, ok := details[name]
+		if !ok {
+			col = ColumnInfo{
+				Name:       name,
+				Position:   int64(i + 1),
+				ColumnType: stringValue(row["Type"]),
+				Nullable:   stringValue(row["Null"]) == "YES",
+			}
+			if row["Default"] != nil {
+				def := stringValue(row["Default"])
+				col.Default = &def
+			}
+		}
+		col.Key = stringValue(row["Key"])
+		col.Extra = stringValue(row["Extra"])
+		description.Columns = append(description.Columns, col)
+	}
+
+	return description, nil
+}
+
+// TableColumns returns the columns of a table in ordinal order
+func (m *Manager) TableColumns(connectionName, database, table string) ([]ColumnInfo, error) {
+	queryResult, err := m.ExecuteQuery(connectionName, `SELECT COLUMN_NAME, ORDINAL_POSITION, DATA_TYPE, COLUMN_TYPE,
+		IS_NULLABLE, COLUMN_KEY, EXTRA, COLUMN_DEFAULT, COLUMN_COMMENT,
+		CHARACTER_MAXIMUM_LENGTH, NUMERIC_PRECISION, NUMERIC_SCALE,
+		CHARACTER_SET_NAME, COLLATION_NAME, GENERATION_EXPRESSION
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = COALESCE(?, DATABASE()) AND TABLE_NAME = ?
+		ORDER BY ORDINAL_POSITION`, nullIfEmpty(database), table)
+	if err != nil {
+		return nil, err
+	}
+	if len(queryResult.Rows) == 0 {
+		return nil, fmt.Errorf("table not found or has no columns: %s", table)
+	}
+
+	// MariaDB 10.2.7+ reports defaults as SQL literals: NULL and quoted strings
+	info := m.cachedServerInfo(connectionName)
+	mariaDB := info != nil && info.Flavor == "mariadb"
+
+	columns := make([]ColumnInfo, 0, len(queryResult.Rows))
+	for _, row := range queryResult.Rows {
+		col := ColumnInfo{
+			Name:             stringValue(row["COLUMN_NAME"]),
+			Position:         int64Value(row["ORDINAL_POSITION"]),
+			DataType:         stringValue(row["DATA_TYPE"]),
+			ColumnType:       stringValue(row["COLUMN_TYPE"]),
+			Nullable:         stringValue(row["IS_NULLABLE"]) == "YES",
+			Key:              stringValue(row["COLUMN_KEY"]),
+			Extra:            stringValue(row["EXTRA"]),
+			Comment:          stringValue(row["COLUMN_COMMENT"]),
+			MaxLength:        int64Ptr(row["CHARACTER_MAXIMUM_LENGTH"]),
+			NumericPrecision: int64Ptr(row["NUMERIC_PRECISION"]),
+			NumericScale:     int64Ptr(row["NUMERIC_SCALE"]),
+			CharacterSet:     stringValue(row["CHARACTER_SET_NAME"]),
+			Collation:        stringValue(row["COLLATION_NAME"]),
+
+			GenerationExpression: stringValue(row["GENERATION_EXPRESSION"]),
+		}
+		if row["COLUMN_DEFAULT"] != nil {
+			def := stringValue(row["COLUMN_DEFAULT"])
+			if mariaDB {
+				col.Default = mariaDBDefault(def)
+			} else {
+				col.Default = &def
+			}
+		}
+		columns = append(columns, col)
+	}
+
+	return columns, nil
+}
+
+// mariaDBDefault converts a MariaDB COLUMN_DEFAULT literal to the MySQL form:
+// NULL becomes nil and quoted strings are unquoted; expressions are kept as-is
+func mariaDBDefault(def string) *string {
+	if def == "NULL" {
+		return nil
+	}
+	if len(def) >= 2 && strings.HasPrefix(def, "'") && strings.HasSuffix(def, "'") {
+		def = strings.ReplaceAll(def[1:len(def)-1], "''", "'")
+	}
+	return &def
+}
+
+// stringValue converts a scanned value to a string
+func stringValue(v interface{}) string {
+	switch s := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return s
+	default:
+		return fmt.Sprint(s)
+	}
+}
+
+// int64Value converts a scanned numeric value to int64
+func int64Value(v interface{}) int64 {
+	switch n := v.(type) {
+	case int64:
+		return n
+	case uint64:
+		return int64(n)
+	case float64:
+		return int64(n)
+	case string:
+		var i int64
+		fmt.Sscan(n, &i)
+		return i
+	default:
+		return 0
+	}
+}
+
+// int64Ptr converts a scanned numeric value to *int64, preserving NULL as nil
+func int64Ptr(v interface{}) *int64 {
+	if v == nil {
+		return nil
+	}
+	n := int64Value(v)
+	return &n
+}
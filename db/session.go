@@ -0,0 +1,368 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultSessionIdleTimeout is how long an unused session stays open before it is closed
+const DefaultSessionIdleTimeout = 10 * time.Minute
+
+// session is a pinned server session kept across tool calls so TEMPORARY
+// tables created in it can be populated and queried step by step. Temporary
+// tables are visible only to their session and vanish when it closes.
+type session struct {
+	id         string
+	connection string
+	conn       *sql.Conn
+	release    func()
+	statements int
+
+	// tables are the temporary tables created in the session
+	tables map[string]bool
+
+	idleTimeout time.Duration
+	timer       *time.Timer
+	closed      bool
+	mu          sync.Mutex
+}
+
+// SessionInfo describes an open session
+type SessionInfo struct {
+	SessionID          string   `json:"session_id"`
+	Connection         string   `json:"connection"`
+	TempTables         []string `json:"temp_tables"`
+	Statements         int      `json:"statements"`
+	IdleTimeoutSeconds int      `json:"idle_timeout_seconds"`
+}
+
+// SessionStatementResult holds the outcome of one statement run in a session
+type SessionStatementResult struct {
+	SessionID  string       `json:"session_id"`
+	TempTables []string     `json:"temp_tables"`
+	Result     *QueryResult `json:"result,omitempty"`
+	Write      *WriteResult `json:"write,omitempty"`
+}
+
+// TempColumn is a column of a temporary table created from a definition
+type TempColumn struct {
+	Name string
+	Type string
+}
+
+// tempColumnTypePattern matches the plain column types accepted for temporary
+// table definitions, such as INT, BIGINT UNSIGNED, VARCHAR(255), DECIMAL(10, 2)
+var tempColumnTypePattern = regexp.MustCompile(`(?i)^[a-z]+(\s*\(\s*\d+(\s*,\s*\d+)?\s*\))?(\s+unsigned)?$`)
+
+// OpenSession pins a pooled connection for a temporary table workflow. The
+// session holds one of the connection's max_concurrent_queries slots until it
+// is closed or left idle for idleTimeout. Temporary tables are allowed on
+// read-only connections since they never touch real schemas.
+func (m *Manager) OpenSession(connectionName string, idleTimeout time.Duration) (*SessionInfo, error) {
+	db, _, err := m.GetConnection(connectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := m.acquireSlot(connectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		release()
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+
+	s := &session{
+		id:          newSessionID(),
+		connection:  connectionName,
+		conn:        conn,
+		release:     release,
+		tables:      make(map[string]bool),
+		idleTimeout: idleTimeout,
+	}
+	s.timer = time.AfterFunc(idleTimeout, func() {
+		slog.Info("session idle timeout", "session_id", s.id, "connection", connectionName)
+		m.CloseSession(s.id)
+	})
+
+	m.sessionsMu.Lock()
+	m.sessions[s.id] = s
+	m.sessionsMu.Unlock()
+
+	slog.Info("session opened", "session_id", s.id, "connection", connectionName)
+	return s.info(), nil
+}
+
+// CreateTempTable creates a TEMPORARY table in a session, either from a
+// SELECT (CREATE TEMPORARY TABLE ... AS SELECT) or from column definitions
+func (m *Manager) CreateTempTable(sessionID, table, selectSQL string, columns []TempColumn) (*SessionStatementResult, error) {
+	if table == "" {
+		return nil, fmt.Errorf("table name is required")
+	}
+	if (selectSQL == "") == (len(columns) == 0) {
+		return nil, fmt.Errorf("provide either sql or columns")
+	}
+
+	var query string
+	if selectSQL != "" {
+		if err := ValidateQueryType(selectSQL, QueryTypeSelect); err != nil {
+			return nil, fmt.Errorf("temporary table source must be a SELECT: %w", err)
+		}
+		query = fmt.Sprintf("CREATE TEMPORARY TABLE %s AS %s", QuoteIdentifier(table), selectSQL)
+	} else {
+		defs := make([]string, len(columns))
+		for i, col := range columns {
+			if col.Name == "" {
+				return nil, fmt.Errorf("column %d has no name", i)
+			}
+			if !tempColumnTypePattern.MatchString(strings.TrimSpace(col.Type)) {
+				return nil, fmt.Errorf("column '%s': unsupported type %q (use a plain type such as INT, VARCHAR(255), DECIMAL(10, 2), DATETIME)", col.Name, col.Type)
+			}
+			defs[i] = QuoteIdentifier(col.Name) + " " + strings.TrimSpace(col.Type)
+		}
+		query = fmt.Sprintf("CREATE TEMPORARY TABLE %s (%s)", QuoteIdentifier(table), strings.Join(defs, ", "))
+	}
+
+	return m.execInSession(sessionID, query, selectSQL, func(s *session) error {
+		if s.tables[table] {
+			return fmt.Errorf("temporary table already exists in session: %s", table)
+		}
+		return nil
+	}, func(s *session) {
+		s.tables[table] = true
+	})
+}
+
+// PopulateTempTable inserts the rows of a SELECT into one of the session's
+// temporary tables. The SELECT may read real tables and other temporary tables.
+func (m *Manager) PopulateTempTable(sessionID, table, selectSQL string, columns []string) (*SessionStatementResult, error) {
+	if table == "" {
+		return nil, fmt.Errorf("table name is required")
+	}
+	if err := ValidateQueryType(selectSQL, QueryTypeSelect); err != nil {
+		return nil, fmt.Errorf("rows must come from a SELECT: %w", err)
+	}
+
+	target := QuoteIdentifier(table)
+	if len(columns) > 0 {
+		quoted := make([]string, len(columns))
+		for i, col := range columns {
+			quoted[i] = QuoteIdentifier(col)
+		}
+		target += " (" + strings.Join(quoted, ", ") + ")"
+	}
+	query := fmt.Sprintf("INSERT INTO %s %s", target, selectSQL)
+
+	return m.execInSession(sessionID, query, selectSQL, func(s *session) error {
+		if !s.tables[table] {
+			return fmt.Errorf("%s is not a temporary table of this session; create it with create_temp_table first", table)
+		}
+		return nil
+	}, nil)
+}
+
+// QuerySession runs a SELECT in a session, where it can read the session's
+// temporary tables as well as real tables
+func (m *Manager) QuerySession(sessionID, query string) (*SessionStatementResult, error) {
+	if err := ValidateQueryType(query, QueryTypeSelect); err != nil {
+		return nil, err
+	}
+	return m.execInSession(sessionID, query, query, nil, nil)
+}
+
+// execInSession runs a statement on a session's pinned connection. SELECTs
+// return rows; anything else returns a write result. check runs before the
+// statement and done after it succeeds, both under the session lock.
+func (m *Manager) execInSession(sessionID, query, selectSQL string, check func(*session) error, done func(*session)) (*SessionStatementResult, error) {
+	s, err := m.lookupSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, connConfig, err := m.GetConnection(s.connection)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkReadOnlyStatement(s.connection, connConfig, query); err != nil {
+		return nil, err
+	}
+
+	// Block sensitive metadata queries
+	if isSensitiveQuery(query) {
+		return nil, fmt.Errorf("access to sensitive MySQL metadata is not allowed")
+	}
+	if err := checkBlockedPatterns(s.connection, connConfig, query); err != nil {
+		return nil, err
+	}
+
+	// Read tables with allowed_columns through a projection of those
+	// columns; the SELECT ends every statement that carries one
+	var projected []string
+	if selectSQL != "" && strings.HasSuffix(query, selectSQL) {
+		rewritten, tables, err := applyColumnAllowlist(connConfig, selectSQL)
+		if err != nil {
+			return nil, err
+		}
+		query = strings.TrimSuffix(query, selectSQL) + rewritten
+		selectSQL = rewritten
+		projected = tables
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, fmt.Errorf("unknown or expired session: %s", sessionID)
+	}
+	s.timer.Stop()
+	defer s.timer.Reset(s.idleTimeout)
+
+	if check != nil {
+		if err := check(s); err != nil {
+			return nil, err
+		}
+	}
+
+	// Refuse SELECTs whose estimated cost exceeds the connection's budget;
+	// EXPLAIN runs on the session so it can see the temporary tables
+	if connConfig.MaxEstimatedRowsExamined > 0 && selectSQL != "" {
+		if err := checkQueryCost(s.conn, s.connection, connConfig.MaxEstimatedRowsExamined, selectSQL); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx := context.Background()
+	result := &SessionStatementResult{SessionID: sessionID}
+	start := time.Now()
+
+	if DetectQueryType(query) == QueryTypeSelect {
+		rows, err := s.conn.QueryContext(ctx, limitExecutionTime(connConfig, m.cachedServerInfo(s.connection), query))
+		if err != nil {
+			m.recordError(s.connection, query, err)
+			return nil, fmt.Errorf("query execution failed: %w", executionTimeError(connConfig, columnAllowlistError(connConfig, projected, err)))
+		}
+		queryResult, err := scanRows(rows, connConfig.MaxRows)
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+		queryResult.ExecutionMs = time.Since(start).Milliseconds()
+		queryResult.Warnings = fetchWarnings(s.conn)
+		queryResult.Connection = s.connection
+		queryResult.Database = connConfig.Database
+		queryResult.ProjectedTables = projected
+		result.Result = queryResult
+	} else {
+		execResult, err := s.conn.ExecContext(ctx, query)
+		if err != nil {
+			m.recordError(s.connection, query, err)
+			return nil, fmt.Errorf("query execution failed: %w", columnAllowlistError(connConfig, projected, err))
+		}
+		rowsAffected, _ := execResult.RowsAffected()
+		result.Write = &WriteResult{
+			RowsAffected: rowsAffected,
+			ExecutionMs:  time.Since(start).Milliseconds(),
+			Warnings:     fetchWarnings(s.conn),
+			Connection:   s.connection,
+			Database:     connConfig.Database,
+		}
+	}
+
+	if done != nil {
+		done(s)
+	}
+	s.statements++
+	result.TempTables = s.tableNames()
+	slog.Debug("statement executed", "connection", s.connection, "session_id", sessionID, "sql", query)
+	return result, nil
+}
+
+// CloseSession ends a session, dropping its temporary tables, and releases
+// its concurrency slot
+func (m *Manager) CloseSession(sessionID string) (*SessionInfo, error) {
+	m.sessionsMu.Lock()
+	s, exists := m.sessions[sessionID]
+	delete(m.sessions, sessionID)
+	m.sessionsMu.Unlock()
+	if !exists {
+		return nil, fmt.Errorf("unknown or expired session: %s", sessionID)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info := s.info()
+	s.timer.Stop()
+	s.closed = true
+
+	// Discard the session rather than returning it to the pool, so its
+	// temporary tables are dropped with it
+	s.conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	s.conn.Close()
+	s.release()
+
+	slog.Info("session closed", "session_id", sessionID, "connection", s.connection, "statements", s.statements)
+	return info, nil
+}
+
+// lookupSession returns an open session by ID
+func (m *Manager) lookupSession(sessionID string) (*session, error) {
+	m.sessionsMu.Lock()
+	s, exists := m.sessions[sessionID]
+	m.sessionsMu.Unlock()
+	if !exists {
+		return nil, fmt.Errorf("unknown or expired session: %s", sessionID)
+	}
+	return s, nil
+}
+
+// closeAllSessions closes every open session
+func (m *Manager) closeAllSessions() {
+	m.sessionsMu.Lock()
+	ids := make([]string, 0, len(m.sessions))
+	for id := range m.sessions {
+		ids = append(ids, id)
+	}
+	m.sessionsMu.Unlock()
+
+	for _, id := range ids {
+		m.CloseSession(id)
+	}
+}
+
+// info describes the session; the caller holds s.mu or owns s
+func (s *session) info() *SessionInfo {
+	return &SessionInfo{
+		SessionID:          s.id,
+		Connection:         s.connection,
+		TempTables:         s.tableNames(),
+		Statements:         s.statements,
+		IdleTimeoutSeconds: int(s.idleTimeout.Seconds()),
+	}
+}
+
+// tableNames returns the session's temporary tables in name order
+func (s *session) tableNames() []string {
+	names := make([]string, 0, len(s.tables))
+	for name := range s.tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newSessionID returns a random session identifier
+func newSessionID() string {
+	return "ses_" + randomHex(8)
+}
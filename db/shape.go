@@ -0,0 +1,194 @@
+package db
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Elision reports what was removed from a result to fit a token budget
+type Elision struct {
+	TokenBudget     int                    `json:"token_budget"`
+	OriginalTokens  int                    `json:"original_tokens"`
+	EstimatedTokens int                    `json:"estimated_tokens"`
+	NullColumns     []string               `json:"null_columns,omitempty"`
+	ConstantColumns map[string]interface{} `json:"constant_columns,omitempty"`
+	TruncatedCells  int                    `json:"truncated_cells,omitempty"`
+	CellLimit       int                    `json:"cell_limit,omitempty"`
+	RowsOmitted     int                    `json:"rows_omitted,omitempty"`
+}
+
+// truncationMarker is appended to text cells cut to fit a token budget
+const truncationMarker = "…"
+
+// cellLimits are the successively shorter lengths long text cells are cut to
+var cellLimits = []int{200, 80}
+
+// FitTokenBudget shrinks the result in place until estimate reports it fits
+// the budget, in order of least information lost: columns that are NULL in
+// every row are dropped, columns with the same value in every row are
+// dropped and their value reported once, long text cells are truncated, and
+// finally trailing rows are omitted. It returns nil when the result already fits.
+func (r *QueryResult) FitTokenBudget(budget int, estimate func(*QueryResult) int) *Elision {
+	original := estimate(r)
+	if original <= budget {
+		return nil
+	}
+	elision := &Elision{TokenBudget: budget, OriginalTokens: original}
+	r.Elided = elision
+
+	if len(r.Rows) > 0 {
+		var nullColumns []string
+		constantColumns := make(map[string]interface{})
+		for _, col := range r.Columns {
+			first := r.Rows[0][col]
+			allNull, constant := true, len(r.Rows) > 1
+			for _, row := range r.Rows {
+				if row[col] != nil {
+					allNull = false
+				}
+				if constant && !reflect.DeepEqual(row[col], first) {
+					constant = false
+				}
+			}
+			if allNull {
+				nullColumns = append(nullColumns, col)
+			} else if constant {
+				constantColumns[col], _ = truncateValue(first, cellLimits[len(cellLimits)-1])
+			}
+		}
+
+		drop := append([]string(nil), nullColumns...)
+		for col := range constantColumns {
+			drop = append(drop, col)
+		}
+		r.dropColumns(drop)
+		elision.NullColumns = nullColumns
+		if len(constantColumns) > 0 {
+			elision.ConstantColumns = constantColumns
+		}
+	}
+
+	for _, limit := range cellLimits {
+		if elision.EstimatedTokens = estimate(r); elision.EstimatedTokens <= budget {
+			return elision
+		}
+		// Cells cut at an earlier limit are cut again, so the last count covers all of them
+		if truncated := r.truncateCells(limit); truncated > 0 {
+			elision.TruncatedCells = truncated
+			elision.CellLimit = limit
+		}
+	}
+
+	if elision.EstimatedTokens = estimate(r); elision.EstimatedTokens <= budget {
+		return elision
+	}
+
+	// Keep the longest prefix of rows that fits
+	rows := r.Rows
+	lo, hi := 0, len(rows)
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		r.Rows, r.Count = rows[:mid], mid
+		if estimate(r) <= budget {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	r.Rows, r.Count = rows[:lo], lo
+	if omitted := len(rows) - lo; omitted > 0 {
+		elision.RowsOmitted = omitted
+		r.Truncated = true
+		if r.Page != nil {
+			r.Page.setNextKey(lo, true)
+		}
+	}
+	elision.EstimatedTokens = estimate(r)
+	return elision
+}
+
+// dropColumns removes the named columns from the column list, types and rows
+func (r *QueryResult) dropColumns(names []string) {
+	if len(names) == 0 {
+		return
+	}
+	drop := make(map[string]bool, len(names))
+	for _, name := range names {
+		drop[name] = true
+	}
+
+	columns := r.Columns[:0:0]
+	for _, col := range r.Columns {
+		if !drop[col] {
+			columns = append(columns, col)
+		}
+	}
+	columnTypes := r.ColumnTypes[:0:0]
+	for _, ct := range r.ColumnTypes {
+		if !drop[ct.Name] {
+			columnTypes = append(columnTypes, ct)
+		}
+	}
+	r.Columns, r.ColumnTypes = columns, columnTypes
+
+	for _, row := range r.Rows {
+		for name := range drop {
+			delete(row, name)
+		}
+	}
+}
+
+// truncateCells cuts string values longer than limit characters and returns
+// how many were cut
+func (r *QueryResult) truncateCells(limit int) int {
+	truncated := 0
+	for _, row := range r.Rows {
+		for col, v := range row {
+			if cut, ok := truncateValue(v, limit); ok {
+				row[col] = cut
+				truncated++
+			}
+		}
+	}
+	return truncated
+}
+
+// truncateValue cuts a string longer than limit characters and reports
+// whether it did; other values are returned unchanged
+func truncateValue(v interface{}, limit int) (interface{}, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return v, false
+	}
+	if runes := []rune(s); len(runes) > limit {
+		return string(runes[:limit]) + truncationMarker, true
+	}
+	return v, false
+}
+
+// RemoveDuplicateRows drops rows whose values in every column equal those of
+// an earlier row, keeping the first, and returns how many were dropped
+func (r *QueryResult) RemoveDuplicateRows() int {
+	seen := make(map[string]bool, len(r.Rows))
+	kept := r.Rows[:0:0]
+	for _, row := range r.Rows {
+		values := make([]interface{}, len(r.Columns))
+		for i, col := range r.Columns {
+			values[i] = row[col]
+		}
+		// Rows whose values cannot be encoded are kept
+		if key, err := json.Marshal(values); err == nil {
+			if seen[string(key)] {
+				continue
+			}
+			seen[string(key)] = true
+		}
+		kept = append(kept, row)
+	}
+
+	removed := len(r.Rows) - len(kept)
+	r.Rows = kept
+	r.Count = len(kept)
+	r.DuplicatesRemoved += removed
+	return removed
+}
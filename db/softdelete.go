@@ -0,0 +1,138 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"mysql-golang-mcp/config"
+)
+
+// identifierPart matches a bare or backtick-quoted identifier
+const identifierPart = "(?:`(?:[^`]|``)+`|\\w+)"
+
+// readTableRefPattern matches a table reference after FROM or JOIN, optionally database-qualified
+var readTableRefPattern = regexp.MustCompile(`(?i)\b(?:FROM|JOIN)\s+(` + identifierPart + `(?:\.` + identifierPart + `)?)`)
+
+// aliasPattern matches an alias (with or without AS) following a table reference
+var aliasPattern = regexp.MustCompile(`(?i)^\s+(AS\s+)?(` + identifierPart + `)`)
+
+// singleTableDeletePattern matches DELETE FROM <table> [WHERE ...] [ORDER BY ...] [LIMIT ...]
+var singleTableDeletePattern = regexp.MustCompile(`(?is)^\s*DELETE\s+(?:(?:LOW_PRIORITY|QUICK|IGNORE)\s+)*FROM\s+(` +
+	identifierPart + `(?:\.` + identifierPart + `)?)((?:\s+(?:WHERE|ORDER\s+BY|LIMIT)\b.*)?)$`)
+
+// notAliases are keywords that can follow a table reference without being its alias
+var notAliases = map[string]bool{
+	"WHERE": true, "JOIN": true, "INNER": true, "LEFT": true, "RIGHT": true, "CROSS": true,
+	"NATURAL": true, "STRAIGHT_JOIN": true, "ON": true, "USING": true, "GROUP": true,
+	"ORDER": true, "LIMIT": true, "HAVING": true, "UNION": true, "FOR": true, "LOCK": true,
+	"WINDOW": true, "INTO": true, "PARTITION": true, "USE": true, "IGNORE": true, "FORCE": true,
+}
+
+// softDeleteColumn returns the soft-delete column configured for a table, or ""
+func softDeleteColumn(connConfig *config.ConnectionConfig, table string) string {
+	for name, t := range connConfig.Tables {
+		if t != nil && strings.EqualFold(name, table) {
+			return t.SoftDeleteColumn
+		}
+	}
+	return ""
+}
+
+// tableName returns the unquoted table part of a possibly qualified reference
+func tableName(ref string) string {
+	parts := strings.SplitN(ref, ".", 2)
+	return unquoteIdentifier(parts[len(parts)-1])
+}
+
+// insideLiteral reports whether pos falls within one of the string literal spans
+func insideLiteral(spans [][]int, pos int) bool {
+	for _, span := range spans {
+		if pos >= span[0] && pos < span[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// applySoftDeleteFilter replaces each FROM/JOIN reference to a table with a
+// soft_delete_column by a derived table that excludes soft-deleted rows, keeping
+// the original alias (or the table name) so the rest of the query is unchanged.
+// MySQL merges such derived tables into the outer query, so indexes still apply.
+// It returns the rewritten query and the tables that were filtered.
+func applySoftDeleteFilter(connConfig *config.ConnectionConfig, query string) (string, []string) {
+	if len(connConfig.Tables) == 0 {
+		return query, nil
+	}
+
+	literals := stringLiteralPattern.FindAllStringIndex(query, -1)
+	var b strings.Builder
+	var filtered []string
+	last := 0
+
+	for _, match := range readTableRefPattern.FindAllStringSubmatchIndex(query, -1) {
+		refStart, refEnd := match[2], match[3]
+		if insideLiteral(literals, match[0]) {
+			continue
+		}
+		ref := query[refStart:refEnd]
+		table := tableName(ref)
+		column := softDeleteColumn(connConfig, table)
+		if column == "" {
+			continue
+		}
+
+		b.WriteString(query[last:refStart])
+		fmt.Fprintf(&b, "(SELECT * FROM %s WHERE %s IS NULL)", ref, QuoteIdentifier(column))
+
+		// A derived table needs an alias; reuse the table name when none is given
+		alias := aliasPattern.FindStringSubmatch(query[refEnd:])
+		if alias == nil || (alias[1] == "" && notAliases[strings.ToUpper(alias[2])]) {
+			b.WriteString(" AS " + QuoteIdentifier(table))
+		}
+		last = refEnd
+		filtered = append(filtered, table)
+	}
+
+	if len(filtered) == 0 {
+		return query, nil
+	}
+	b.WriteString(query[last:])
+	return b.String(), filtered
+}
+
+// rewriteSoftDelete turns a single-table DELETE on a table with a
+// soft_delete_column into an UPDATE that stamps the column. Rows that are
+// already soft-deleted keep their original timestamp. DELETEs on other tables
+// are returned unchanged; multi-table DELETEs touching a soft-delete table are
+// refused because they cannot be rewritten safely.
+func rewriteSoftDelete(connConfig *config.ConnectionConfig, query string) (string, bool, error) {
+	if len(connConfig.Tables) == 0 {
+		return query, false, nil
+	}
+
+	trimmed := strings.TrimRight(strings.TrimSpace(query), ";")
+	if match := singleTableDeletePattern.FindStringSubmatch(trimmed); match != nil {
+		ref, rest := match[1], match[2]
+		column := softDeleteColumn(connConfig, tableName(ref))
+		if column == "" {
+			return query, false, nil
+		}
+		col := QuoteIdentifier(column)
+		return fmt.Sprintf("UPDATE %s SET %s = COALESCE(%s, NOW())%s", ref, col, col, rest), true, nil
+	}
+
+	// Anything else (multi-table DELETE, DELETE ... USING, aliases) is only
+	// allowed if it does not mention a soft-delete table
+	masked := stringLiteralPattern.ReplaceAllString(query, "''")
+	for name, t := range connConfig.Tables {
+		if t == nil || t.SoftDeleteColumn == "" {
+			continue
+		}
+		pattern := regexp.MustCompile(`(?i)(^|[^\w$])` + regexp.QuoteMeta(name) + `($|[^\w$])`)
+		if pattern.MatchString(masked) {
+			return "", false, fmt.Errorf("soft_delete_mode can only rewrite single-table DELETE statements (DELETE FROM %s WHERE ...); table '%s' uses soft_delete_column '%s'", name, name, t.SoftDeleteColumn)
+		}
+	}
+	return query, false, nil
+}
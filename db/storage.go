@@ -0,0 +1,268 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultStorageGrowthDays is how far back storage_report looks for the
+// snapshot growth is measured from
+const DefaultStorageGrowthDays = 30
+
+// StorageSize is the size of a database or table. Rows and sizes are InnoDB
+// estimates from information_schema.TABLES.
+type StorageSize struct {
+	Rows       int64   `json:"rows"`
+	DataBytes  int64   `json:"data_bytes"`
+	IndexBytes int64   `json:"index_bytes"`
+	FreeBytes  int64   `json:"free_bytes"`
+	TotalBytes int64   `json:"total_bytes"`
+	TotalMB    float64 `json:"total_mb"`
+
+	// Growth is the change since the oldest snapshot in the growth window,
+	// when the connection has a storage_history_table holding one
+	Growth *StorageGrowth `json:"growth,omitempty"`
+}
+
+// StorageGrowth is the change in size since a snapshot. Since is empty for
+// a table with no snapshot in the window, whose whole size counts as growth.
+type StorageGrowth struct {
+	Since       string  `json:"since"`
+	Days        float64 `json:"days"`
+	Rows        int64   `json:"rows"`
+	TotalBytes  int64   `json:"total_bytes"`
+	BytesPerDay int64   `json:"bytes_per_day"`
+}
+
+// DatabaseStorage is the size of one database
+type DatabaseStorage struct {
+	Database string `json:"database"`
+	Tables   int    `json:"tables"`
+	StorageSize
+}
+
+// TableStorage is the size of one table
+type TableStorage struct {
+	Database string `json:"database"`
+	Table    string `json:"table"`
+	Engine   string `json:"engine"`
+	StorageSize
+}
+
+// StorageReport summarizes storage by database and lists the largest tables
+type StorageReport struct {
+	Connection string            `json:"connection"`
+	Total      StorageSize       `json:"total"`
+	Databases  []DatabaseStorage `json:"databases"`
+	Tables     []TableStorage    `json:"tables"`
+
+	// TablesTruncated is set when more tables exist than were listed
+	TablesTruncated bool `json:"tables_truncated,omitempty"`
+
+	// GrowthNote explains why growth is missing or partial
+	GrowthNote string `json:"growth_note,omitempty"`
+}
+
+// tableSnapshot is a table's size in a storage history snapshot
+type tableSnapshot struct {
+	capturedAt string
+	ageSeconds int64
+	rows       int64
+	totalBytes int64
+}
+
+// StorageReport returns the data, index and free space and row counts of
+// one database, or of every non-system database when database is empty,
+// with the largest limit tables. Growth is reported when the connection's
+// storage_history_table holds a snapshot from the last growthDays days: each
+// table is compared with its oldest snapshot in that window, and each
+// database and the total with the sum of their tables' snapshots.
+func (m *Manager) StorageReport(connectionName, database string, limit, growthDays int) (*StorageReport, error) {
+	_, connConfig, err := m.GetConnection(connectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	scope := "TABLE_SCHEMA NOT IN (" + systemSchemas + ")"
+	var args []interface{}
+	if database != "" {
+		scope = "TABLE_SCHEMA = ?"
+		args = append(args, database)
+	}
+	result, err := m.ExecuteQuery(connectionName, `SELECT TABLE_SCHEMA, TABLE_NAME, ENGINE, TABLE_ROWS,
+		DATA_LENGTH, INDEX_LENGTH, DATA_FREE
+		FROM information_schema.TABLES
+		WHERE `+scope+` AND TABLE_TYPE = 'BASE TABLE'
+		ORDER BY DATA_LENGTH + INDEX_LENGTH DESC, TABLE_SCHEMA, TABLE_NAME`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &StorageReport{Connection: connectionName, Databases: []DatabaseStorage{}, Tables: []TableStorage{}}
+	var snapshots map[string]tableSnapshot
+	if connConfig.StorageHistoryTable == "" {
+		report.GrowthNote = "MySQL keeps no size history; set storage_history_table on the connection to a table of periodic snapshots to report growth"
+	} else if snapshots, err = m.storageSnapshots(connectionName, connConfig.StorageHistoryTable, database, growthDays); err != nil {
+		report.GrowthNote = "growth unavailable: " + err.Error()
+	} else if len(snapshots) == 0 {
+		report.GrowthNote = fmt.Sprintf("%s holds no snapshot from the last %d days", connConfig.StorageHistoryTable, growthDays)
+	}
+
+	databases := make(map[string]*DatabaseStorage)
+	var totalSnapshot *tableSnapshot
+	dbSnapshots := make(map[string]*tableSnapshot)
+	for _, row := range result.Rows {
+		table := TableStorage{
+			Database: stringValue(row["TABLE_SCHEMA"]),
+			Table:    stringValue(row["TABLE_NAME"]),
+			Engine:   stringValue(row["ENGINE"]),
+			StorageSize: StorageSize{
+				Rows:       int64Value(row["TABLE_ROWS"]),
+				DataBytes:  int64Value(row["DATA_LENGTH"]),
+				IndexBytes: int64Value(row["INDEX_LENGTH"]),
+				FreeBytes:  int64Value(row["DATA_FREE"]),
+			},
+		}
+		table.setTotal()
+
+		// Tables created within the window grow from nothing
+		if len(snapshots) > 0 {
+			snapshot, ok := snapshots[strings.ToLower(table.Database+"."+table.Table)]
+			table.Growth = table.growthSince(snapshot, ok)
+			dbSnapshot := dbSnapshots[table.Database]
+			if dbSnapshot == nil {
+				dbSnapshot = &tableSnapshot{}
+				dbSnapshots[table.Database] = dbSnapshot
+			}
+			if totalSnapshot == nil {
+				totalSnapshot = &tableSnapshot{}
+			}
+			for _, s := range []*tableSnapshot{dbSnapshot, totalSnapshot} {
+				s.addSnapshot(snapshot, ok)
+			}
+		}
+
+		db := databases[table.Database]
+		if db == nil {
+			db = &DatabaseStorage{Database: table.Database}
+			databases[table.Database] = db
+		}
+		db.Tables++
+		db.add(table.StorageSize)
+		report.Total.add(table.StorageSize)
+
+		if len(report.Tables) < limit {
+			report.Tables = append(report.Tables, table)
+		} else {
+			report.TablesTruncated = true
+		}
+	}
+
+	for name, db := range databases {
+		db.setTotal()
+		if s := dbSnapshots[name]; s != nil {
+			db.Growth = db.growthSince(*s, true)
+		}
+		report.Databases = append(report.Databases, *db)
+	}
+	sort.Slice(report.Databases, func(i, j int) bool {
+		if report.Databases[i].TotalBytes != report.Databases[j].TotalBytes {
+			return report.Databases[i].TotalBytes > report.Databases[j].TotalBytes
+		}
+		return report.Databases[i].Database < report.Databases[j].Database
+	})
+	report.Total.setTotal()
+	if totalSnapshot != nil {
+		report.Total.Growth = report.Total.growthSince(*totalSnapshot, true)
+	}
+	return report, nil
+}
+
+// storageSnapshots returns each table's oldest snapshot from the last days
+// days in the history table, keyed by lowercase database.table. The table
+// needs captured_at, table_schema, table_name, table_rows, data_bytes and
+// index_bytes columns.
+func (m *Manager) storageSnapshots(connectionName, historyTable, database string, days int) (map[string]tableSnapshot, error) {
+	table := QuoteIdentifier(historyTable)
+	if parts := strings.SplitN(historyTable, ".", 2); len(parts) == 2 {
+		table = QualifiedName(parts[0], parts[1])
+	}
+
+	scope := ""
+	args := []interface{}{days}
+	if database != "" {
+		scope = " AND table_schema = ?"
+		args = append(args, database)
+	}
+	result, err := m.ExecuteQuery(connectionName, fmt.Sprintf(`SELECT h.table_schema, h.table_name, h.captured_at,
+		TIMESTAMPDIFF(SECOND, h.captured_at, NOW()) AS age_seconds,
+		h.table_rows, h.data_bytes + h.index_bytes AS total_bytes
+		FROM %s h
+		JOIN (SELECT table_schema, table_name, MIN(captured_at) AS captured_at
+			FROM %s
+			WHERE captured_at >= NOW() - INTERVAL ? DAY%s
+			GROUP BY table_schema, table_name) oldest
+		USING (table_schema, table_name, captured_at)`, table, table, scope), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make(map[string]tableSnapshot, len(result.Rows))
+	for _, row := range result.Rows {
+		key := strings.ToLower(stringValue(row["table_schema"]) + "." + stringValue(row["table_name"]))
+		snapshots[key] = tableSnapshot{
+			capturedAt: stringValue(row["captured_at"]),
+			ageSeconds: int64Value(row["age_seconds"]),
+			rows:       int64Value(row["table_rows"]),
+			totalBytes: int64Value(row["total_bytes"]),
+		}
+	}
+	return snapshots, nil
+}
+
+// addSnapshot adds a table's snapshot to a database or total snapshot, which
+// dates from its oldest table snapshot. A table without one adds nothing.
+func (s *tableSnapshot) addSnapshot(table tableSnapshot, exists bool) {
+	if !exists {
+		return
+	}
+	s.rows += table.rows
+	s.totalBytes += table.totalBytes
+	if table.ageSeconds > s.ageSeconds {
+		s.ageSeconds = table.ageSeconds
+		s.capturedAt = table.capturedAt
+	}
+}
+
+// growthSince returns the change from a snapshot, or from nothing when the
+// table has no snapshot in the window
+func (s *StorageSize) growthSince(snapshot tableSnapshot, exists bool) *StorageGrowth {
+	if !exists {
+		return &StorageGrowth{Rows: s.Rows, TotalBytes: s.TotalBytes}
+	}
+	growth := &StorageGrowth{
+		Since:      snapshot.capturedAt,
+		Days:       roundTo(float64(snapshot.ageSeconds)/86400, 2),
+		Rows:       s.Rows - snapshot.rows,
+		TotalBytes: s.TotalBytes - snapshot.totalBytes,
+	}
+	if snapshot.ageSeconds > 0 {
+		growth.BytesPerDay = growth.TotalBytes * 86400 / snapshot.ageSeconds
+	}
+	return growth
+}
+
+// add adds a table's rows and sizes
+func (s *StorageSize) add(table StorageSize) {
+	s.Rows += table.Rows
+	s.DataBytes += table.DataBytes
+	s.IndexBytes += table.IndexBytes
+	s.FreeBytes += table.FreeBytes
+}
+
+// setTotal sets the total of data and index size, in bytes and MB
+func (s *StorageSize) setTotal() {
+	s.TotalBytes = s.DataBytes + s.IndexBytes
+	s.TotalMB = roundTo(float64(s.TotalBytes)/1024/1024, 2)
+}
@@ -0,0 +1,157 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// CallInfo identifies the tool call a statement runs for
+type CallInfo struct {
+	Tool      string
+	Client    string
+	RequestID string
+}
+
+// NewCallInfo returns the call info of a tool call, with a new random
+// request ID
+func NewCallInfo(tool, client string) *CallInfo {
+	return &CallInfo{Tool: tool, Client: client, RequestID: randomHex(8)}
+}
+
+type callInfoKey struct{}
+
+// WithCallInfo returns a context carrying the tool call its statements run for
+func WithCallInfo(ctx context.Context, info *CallInfo) context.Context {
+	return context.WithValue(ctx, callInfoKey{}, info)
+}
+
+// CallInfoFromContext returns the tool call in ctx, or nil outside a tool call
+func CallInfoFromContext(ctx context.Context) *CallInfo {
+	info, _ := ctx.Value(callInfoKey{}).(*CallInfo)
+	return info
+}
+
+// statementContext returns the context to run a call's statements with: it
+// keeps ctx's values, such as its call info and trace span, but not its
+// cancellation, so a statement is never cut off halfway by the client
+func statementContext(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return context.WithoutCancel(ctx)
+}
+
+// statementTagger renders the statement_tag comment of a connection
+type statementTagger struct {
+	template   string
+	connection string
+}
+
+// tag renders the comment prepended to a statement run with ctx. Fields that
+// are unknown, as for statements run outside a tool call, render as "-".
+func (t *statementTagger) tag(ctx context.Context) string {
+	tool, client, requestID := "-", "-", "-"
+	if info := CallInfoFromContext(ctx); info != nil {
+		tool, client, requestID = tagValue(info.Tool), tagValue(info.Client), tagValue(info.RequestID)
+	}
+	return "/* " + strings.NewReplacer(
+		"{connection}", tagValue(t.connection),
+		"{tool}", tool,
+		"{client}", client,
+		"{request_id}", requestID,
+	).Replace(t.template) + " */ "
+}
+
+// tagValue makes a value safe to place inside a SQL comment, keeping only
+// letters, digits and _ . : @ -
+func tagValue(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case strings.ContainsRune("_.:@-", r):
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
+
+// openTaggedDSN opens a connection pool whose statements are all prefixed
+// with the statement_tag comment
+func openTaggedDSN(dsn string, tagger *statementTagger) (*sql.DB, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(&taggingConnector{Connector: connector, tagger: tagger}), nil
+}
+
+// taggingConnector hands out driver connections that tag their statements
+type taggingConnector struct {
+	driver.Connector
+	tagger *statementTagger
+}
+
+// Connect opens a driver connection and wraps it
+func (c *taggingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mc, ok := conn.(mysqlConn)
+	if !ok {
+		conn.Close()
+		return nil, fmt.Errorf("statement_tag: unexpected driver connection type %T", conn)
+	}
+	return &taggedConn{mysqlConn: mc, tagger: c.tagger}, nil
+}
+
+// mysqlConn is the set of driver interfaces the MySQL driver's connections
+// implement; the tagged wrapper keeps all of them
+type mysqlConn interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.ExecerContext
+	driver.QueryerContext
+	driver.Pinger
+	driver.SessionResetter
+	driver.Validator
+	driver.NamedValueChecker
+}
+
+// taggedConn prefixes every statement sent on a driver connection with the
+// statement_tag comment
+type taggedConn struct {
+	mysqlConn
+	tagger *statementTagger
+}
+
+func (c *taggedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.mysqlConn.Prepare(c.tagger.tag(context.Background()) + query)
+}
+
+func (c *taggedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.mysqlConn.PrepareContext(ctx, c.tagger.tag(ctx)+query)
+}
+
+func (c *taggedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.mysqlConn.ExecContext(ctx, c.tagger.tag(ctx)+query, args)
+}
+
+func (c *taggedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.mysqlConn.QueryContext(ctx, c.tagger.tag(ctx)+query, args)
+}
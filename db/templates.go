@@ -0,0 +1,242 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxLearnedQueries is the number of query templates kept; when full, the
+// least recently used template makes room for a new one
+const maxLearnedQueries = 500
+
+// DefaultSuggestedQueries is the number of templates suggest_queries returns by default
+const DefaultSuggestedQueries = 10
+
+// LearnedQuery is a SELECT shape that ran successfully through mysql_select,
+// with its literals replaced by ? so it can be reused with other values
+type LearnedQuery struct {
+	Connection  string    `json:"connection"`
+	Database    string    `json:"database"`
+	Template    string    `json:"template"`
+	Fingerprint string    `json:"fingerprint"`
+	Tables      []string  `json:"tables"`
+	Uses        int64     `json:"uses"`
+	LastRows    int       `json:"last_rows"`
+	FirstUsed   time.Time `json:"first_used"`
+	LastUsed    time.Time `json:"last_used"`
+}
+
+// QuerySuggestions is the response of SuggestQueries
+type QuerySuggestions struct {
+	Connection string         `json:"connection"`
+	Table      string         `json:"table,omitempty"`
+	Queries    []LearnedQuery `json:"queries"`
+	Count      int            `json:"count"`
+}
+
+// parameterizeQuery replaces the literals of a statement with ? and drops its
+// comments and extra whitespace, keeping the case of everything else
+func parameterizeQuery(query string) string {
+	template := fingerprintTokenPattern.ReplaceAllStringFunc(query, func(token string) string {
+		switch {
+		case token[0] == '`':
+			return token
+		case strings.HasPrefix(token, "/*"), strings.HasPrefix(token, "--"), token[0] == '#':
+			return " "
+		default:
+			return "?"
+		}
+	})
+	template = whitespacePattern.ReplaceAllString(template, " ")
+	return strings.TrimRight(strings.TrimSpace(template), "; ")
+}
+
+// learnQuery records a SELECT that succeeded, keyed by its fingerprint on
+// the connection and default database, and saves the library when the server
+// has a query_library_file
+func (m *Manager) learnQuery(connectionName, database, query string, rows int) {
+	if DetectQueryType(query) != QueryTypeSelect {
+		return
+	}
+	if database == "" {
+		if connConfig, ok := m.lookupConnection(connectionName); ok {
+			database = connConfig.Database
+		}
+	}
+
+	var tables []string
+	for _, ref := range ExtractTableRefs(query) {
+		db := ref.Database
+		if db == "" {
+			db = database
+		}
+		tables = append(tables, db+"."+ref.Table)
+	}
+	if len(tables) == 0 {
+		return
+	}
+
+	fingerprint := Fingerprint(query)
+	key := connectionName + "\x00" + database + "\x00" + fingerprint
+	now := time.Now()
+
+	m.learnedMu.Lock()
+	defer m.learnedMu.Unlock()
+	m.loadLearnedQueries()
+
+	q, ok := m.learned[key]
+	if !ok {
+		if len(m.learned) >= maxLearnedQueries {
+			m.evictLearnedQuery()
+		}
+		q = &LearnedQuery{Connection: connectionName, Database: database, Fingerprint: fingerprint, FirstUsed: now}
+		m.learned[key] = q
+	}
+	q.Template = parameterizeQuery(query)
+	q.Tables = tables
+	q.Uses++
+	q.LastRows = rows
+	q.LastUsed = now
+
+	m.saveLearnedQueries()
+}
+
+// evictLearnedQuery drops the least recently used template; learnedMu must be held
+func (m *Manager) evictLearnedQuery() {
+	var oldestKey string
+	var oldest time.Time
+	for key, q := range m.learned {
+		if oldestKey == "" || q.LastUsed.Before(oldest) {
+			oldestKey, oldest = key, q.LastUsed
+		}
+	}
+	delete(m.learned, oldestKey)
+}
+
+// SuggestQueries returns the query templates learned on a connection, most
+// used first. With table, only templates reading it are returned; table may
+// be qualified as database.table, and otherwise matches in any database.
+func (m *Manager) SuggestQueries(connectionName, table string, limit int) (*QuerySuggestions, error) {
+	if _, ok := m.lookupConnection(connectionName); !ok {
+		return nil, fmt.Errorf("unknown connection: %s", connectionName)
+	}
+	if limit <= 0 {
+		limit = DefaultSuggestedQueries
+	}
+
+	m.learnedMu.Lock()
+	m.loadLearnedQueries()
+	var queries []LearnedQuery
+	for _, q := range m.learned {
+		if q.Connection != connectionName || (table != "" && !readsTable(q.Tables, table)) {
+			continue
+		}
+		copied := *q
+		copied.Tables = append([]string(nil), q.Tables...)
+		queries = append(queries, copied)
+	}
+	m.learnedMu.Unlock()
+
+	sort.Slice(queries, func(i, j int) bool {
+		if queries[i].Uses != queries[j].Uses {
+			return queries[i].Uses > queries[j].Uses
+		}
+		return queries[i].LastUsed.After(queries[j].LastUsed)
+	})
+	if len(queries) > limit {
+		queries = queries[:limit]
+	}
+
+	suggestions := &QuerySuggestions{Connection: connectionName, Table: table, Queries: queries, Count: len(queries)}
+	if suggestions.Queries == nil {
+		suggestions.Queries = []LearnedQuery{}
+	}
+	return suggestions, nil
+}
+
+// readsTable reports whether one of a template's database.table names is
+// table, which matches any database unless it is qualified
+func readsTable(tables []string, table string) bool {
+	for _, t := range tables {
+		if strings.EqualFold(t, table) {
+			return true
+		}
+		if !strings.Contains(table, ".") && strings.EqualFold(t[strings.LastIndex(t, ".")+1:], table) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadLearnedQueries reads the query_library_file the first time the library
+// is used; learnedMu must be held. A missing file starts an empty library.
+func (m *Manager) loadLearnedQueries() {
+	if m.learnedLoaded {
+		return
+	}
+	m.learnedLoaded = true
+
+	path := m.config.QueryLibraryFile
+	if path == "" {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Warn("failed to read query library", "path", path, "error", err)
+		}
+		return
+	}
+	var queries []*LearnedQuery
+	if err := json.Unmarshal(data, &queries); err != nil {
+		slog.Warn("failed to parse query library", "path", path, "error", err)
+		return
+	}
+	for _, q := range queries {
+		m.learned[q.Connection+"\x00"+q.Database+"\x00"+q.Fingerprint] = q
+	}
+}
+
+// saveLearnedQueries writes the library to the query_library_file through a
+// temporary file renamed over it; learnedMu must be held. Failures are
+// logged, since learning never fails a query.
+func (m *Manager) saveLearnedQueries() {
+	path := m.config.QueryLibraryFile
+	if path == "" {
+		return
+	}
+
+	queries := make([]*LearnedQuery, 0, len(m.learned))
+	for _, q := range m.learned {
+		queries = append(queries, q)
+	}
+	sort.Slice(queries, func(i, j int) bool { return queries[i].FirstUsed.Before(queries[j].FirstUsed) })
+	data, err := json.MarshalIndent(queries, "", "  ")
+	if err != nil {
+		slog.Warn("failed to encode query library", "error", err)
+		return
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		slog.Warn("failed to save query library", "path", path, "error", err)
+		return
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		slog.Warn("failed to save query library", "path", path, "error", err)
+	}
+}
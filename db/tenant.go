@@ -0,0 +1,101 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"mysql-golang-mcp/config"
+)
+
+// tenantIDPattern limits string tenant ids to characters valid in an
+// unquoted schema name
+var tenantIDPattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// qualifierPattern matches qualified names, capturing their first part: the
+// database of db.table, or the table or alias of table.column
+var qualifierPattern = regexp.MustCompile("(?:^|[^A-Za-z0-9_$.`])(`(?:[^`]|``)+`|[A-Za-z_$][A-Za-z0-9_$]*)\\s*\\.\\s*(?:`(?:[^`]|``)+`|[A-Za-z0-9_$]+|\\*)")
+
+// TenantDatabase returns the schema of a tenant on a connection with
+// tenant_database_pattern
+func (m *Manager) TenantDatabase(connectionName, tenantID string) (string, error) {
+	connConfig, exists := m.lookupConnection(connectionName)
+	if !exists {
+		return "", fmt.Errorf("unknown connection: %s", connectionName)
+	}
+	return tenantDatabase(connectionName, connConfig, tenantID)
+}
+
+// tenantDatabase fills the connection's tenant_database_pattern with a tenant id
+func tenantDatabase(connectionName string, connConfig *config.ConnectionConfig, tenantID string) (string, error) {
+	pattern := connConfig.TenantDatabasePattern
+	if pattern == "" {
+		return "", fmt.Errorf("connection '%s' has no tenant_database_pattern; set it in config to use tenant_id", connectionName)
+	}
+
+	if strings.Contains(pattern, "%d") {
+		if _, err := strconv.ParseUint(tenantID, 10, 64); err != nil {
+			return "", fmt.Errorf("invalid tenant_id %q: connection '%s' uses numeric tenant ids", tenantID, connectionName)
+		}
+		return strings.Replace(pattern, "%d", tenantID, 1), nil
+	}
+	if !tenantIDPattern.MatchString(tenantID) {
+		return "", fmt.Errorf("invalid tenant_id %q: only letters, digits and underscores are allowed", tenantID)
+	}
+	return strings.Replace(pattern, "%s", tenantID, 1), nil
+}
+
+// tenantStatement resolves the tenant's schema for a statement and checks
+// that the statement stays inside it. Only SELECT, INSERT, UPDATE and DELETE
+// can run for a tenant; any qualifier naming another database on the server,
+// whether another tenant's or a shared or system schema, is refused.
+// Unqualified tables resolve to the tenant's schema, the default database.
+func (m *Manager) tenantStatement(db *sql.DB, connectionName string, connConfig *config.ConnectionConfig, tenantID, database, query string) (string, error) {
+	tenantDB, err := tenantDatabase(connectionName, connConfig, tenantID)
+	if err != nil {
+		return "", err
+	}
+	if database != "" && !strings.EqualFold(database, tenantDB) {
+		return "", fmt.Errorf("tenant_id and database cannot be combined")
+	}
+	if err := ValidateQueryType(query, QueryTypeSelect, QueryTypeInsert, QueryTypeUpdate, QueryTypeDelete); err != nil {
+		return "", err
+	}
+
+	// Comments are masked too, so tenant_2/**/.orders is still seen as qualified
+	var qualifiers []string
+	for _, match := range qualifierPattern.FindAllStringSubmatch(maskCommentsAndLiterals(query), -1) {
+		qualifiers = append(qualifiers, unquoteIdentifier(match[1]))
+	}
+	if len(qualifiers) == 0 {
+		return tenantDB, nil
+	}
+
+	// Aliases and table names qualify columns too, so only qualifiers that
+	// name a database on the server are refused
+	rows, err := db.Query("SELECT SCHEMA_NAME FROM information_schema.SCHEMATA")
+	if err != nil {
+		return "", fmt.Errorf("failed to list databases for tenant check: %w", err)
+	}
+	defer rows.Close()
+	schemas := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return "", fmt.Errorf("failed to list databases for tenant check: %w", err)
+		}
+		schemas[strings.ToLower(name)] = true
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("failed to list databases for tenant check: %w", err)
+	}
+
+	for _, q := range qualifiers {
+		if schemas[strings.ToLower(q)] && !strings.EqualFold(q, tenantDB) {
+			return "", fmt.Errorf("tenant %s may only use database '%s'; the statement references '%s'", tenantID, tenantDB, q)
+		}
+	}
+	return tenantDB, nil
+}
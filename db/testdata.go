@@ -0,0 +1,444 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTestDataRows is the number of rows generate_test_data inserts
+	// into each table by default
+	DefaultTestDataRows = 10
+
+	// MaxTestDataRows caps the rows generate_test_data inserts into each table
+	MaxTestDataRows = 1000
+
+	// testDataParentRows caps the referenced keys read to pick foreign key values from
+	testDataParentRows = 1000
+
+	// testDataRetries is how many times a row is generated again when it
+	// repeats a unique combination of foreign key values
+	testDataRetries = 20
+)
+
+// TestDataOptions holds per-call settings for GenerateTestData
+type TestDataOptions struct {
+	// Rows is the number of rows to insert into each table
+	Rows int
+
+	// Seed makes the generated values repeatable; 0 picks a seed, which is
+	// returned in the result
+	Seed int64
+
+	// Context carries the caller's trace span to the inserts
+	Context context.Context
+}
+
+// TestDataResult reports the rows generate_test_data inserted
+type TestDataResult struct {
+	Connection string `json:"connection"`
+	Database   string `json:"database,omitempty"`
+	Seed       int64  `json:"seed"`
+
+	// Tables are in the order they were filled, referenced tables first
+	Tables []GeneratedTable `json:"tables"`
+}
+
+// GeneratedTable reports the rows generated for one table
+type GeneratedTable struct {
+	Table        string `json:"table"`
+	RowsInserted int64  `json:"rows_inserted"`
+
+	// SkippedColumns are left to the server: AUTO_INCREMENT and generated columns
+	SkippedColumns []string `json:"skipped_columns,omitempty"`
+
+	// ForeignKeys lists the referenced columns values were drawn from
+	ForeignKeys []string `json:"foreign_keys,omitempty"`
+
+	Notes    []string      `json:"notes,omitempty"`
+	Warnings []BulkWarning `json:"warnings,omitempty"`
+}
+
+// testDataForeignKey is a foreign key of a table being filled, with the
+// referenced rows its values are drawn from
+type testDataForeignKey struct {
+	columns    []string
+	refTable   string
+	refColumns []string
+	nullable   bool
+	parents    []map[string]interface{}
+}
+
+// testDataTable generates the rows of one table
+type testDataTable struct {
+	name        string
+	columns     []ColumnInfo
+	foreignKeys []*testDataForeignKey
+	fkColumns   map[string]bool
+
+	// unique columns get values no other row has: the next integers after
+	// the column's current maximum in sequences, or a suffix on strings
+	unique    map[string]bool
+	sequences map[string]int64
+
+	// uniqueSets are unique indexes made only of foreign key columns; rows
+	// repeating one of their combinations are generated again
+	uniqueSets [][]string
+
+	result GeneratedTable
+}
+
+// GenerateTestData inserts rows of fake data into tables, filling referenced
+// tables before the tables that reference them so foreign keys can point at
+// real rows. Values follow each column's type, ENUM and SET values, length
+// and unique indexes, and look realistic where the column name says what it
+// holds (email, first_name, city, price, ...). Only dev and staging
+// connections are accepted: a connection must be tagged with an environment
+// other than prod.
+func (m *Manager) GenerateTestData(connectionName, database string, tables []string, opts TestDataOptions) (*TestDataResult, error) {
+	_, connConfig, err := m.GetConnection(connectionName)
+	if err != nil {
+		return nil, err
+	}
+	switch connConfig.Environment {
+	case "prod":
+		return nil, fmt.Errorf("connection '%s' is a prod connection; test data can only be generated on dev and staging connections", connectionName)
+	case "":
+		return nil, fmt.Errorf("connection '%s' has no environment; set environment to dev or staging to generate test data on it", connectionName)
+	}
+
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("at least one table is required")
+	}
+	listed := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		if listed[table] {
+			return nil, fmt.Errorf("table %s is listed more than once", table)
+		}
+		listed[table] = true
+	}
+	if opts.Rows <= 0 {
+		opts.Rows = DefaultTestDataRows
+	}
+	if opts.Rows > MaxTestDataRows {
+		return nil, fmt.Errorf("rows must be at most %d", MaxTestDataRows)
+	}
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano()
+	}
+
+	deps, err := m.ForeignKeyDependencies(connectionName, database)
+	if err != nil {
+		return nil, err
+	}
+	ordered, cyclic := SortByDependencies(tables, deps)
+
+	result := &TestDataResult{Connection: connectionName, Database: database, Seed: opts.Seed, Tables: []GeneratedTable{}}
+	fake := newFakeData(opts.Seed)
+	for _, name := range append(ordered, cyclic...) {
+		table, err := m.testDataTable(connectionName, database, name)
+		if err != nil {
+			return nil, testDataError(result, name, err)
+		}
+		rows, err := table.generate(fake, opts.Rows)
+		if err != nil {
+			return nil, testDataError(result, name, err)
+		}
+		if len(rows) > 0 {
+			inserted, err := m.InsertRows(connectionName, database, name, rows, InsertOptions{Context: opts.Context})
+			if err != nil {
+				return nil, testDataError(result, name, err)
+			}
+			table.result.RowsInserted = inserted.RowsInserted
+			table.result.Warnings = inserted.Warnings
+		}
+		if slices.Contains(cyclic, name) {
+			table.result.Notes = append(table.result.Notes, "part of a foreign key cycle; foreign keys into tables of the cycle filled later only reference their existing rows")
+		}
+		result.Tables = append(result.Tables, table.result)
+	}
+	return result, nil
+}
+
+// testDataError reports a failure filling a table along with the tables
+// already filled, whose rows stay inserted
+func testDataError(result *TestDataResult, table string, err error) error {
+	if len(result.Tables) == 0 {
+		return fmt.Errorf("failed to generate test data for %s: %w", table, err)
+	}
+	filled := make([]string, len(result.Tables))
+	for i, t := range result.Tables {
+		filled[i] = fmt.Sprintf("%s (%d rows)", t.Table, t.RowsInserted)
+	}
+	return fmt.Errorf("failed to generate test data for %s: %w; rows already inserted into %s were kept (seed %d)",
+		table, err, strings.Join(filled, ", "), result.Seed)
+}
+
+// testDataTable reads what generating rows for a table needs: its columns,
+// unique indexes, foreign keys and the rows those keys can reference
+func (m *Manager) testDataTable(connectionName, database, name string) (*testDataTable, error) {
+	columns, err := m.TableColumns(connectionName, database, name)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := m.GetIndexes(connectionName, database, name)
+	if err != nil {
+		return nil, err
+	}
+	foreignKeys, err := m.testDataForeignKeys(connectionName, database, name)
+	if err != nil {
+		return nil, err
+	}
+
+	table := &testDataTable{
+		name:        name,
+		foreignKeys: foreignKeys,
+		fkColumns:   make(map[string]bool),
+		unique:      make(map[string]bool),
+		sequences:   make(map[string]int64),
+		result:      GeneratedTable{Table: name},
+	}
+	skipped := make(map[string]bool)
+	nullable := make(map[string]bool)
+	for _, col := range columns {
+		extra := strings.ToUpper(col.Extra)
+		if strings.Contains(extra, "AUTO_INCREMENT") || col.GenerationExpression != "" ||
+			strings.Contains(extra, "VIRTUAL GENERATED") || strings.Contains(extra, "STORED GENERATED") {
+			skipped[col.Name] = true
+			table.result.SkippedColumns = append(table.result.SkippedColumns, col.Name)
+			continue
+		}
+		nullable[col.Name] = col.Nullable
+		table.columns = append(table.columns, col)
+	}
+
+	for _, fk := range foreignKeys {
+		fk.nullable = true
+		for _, col := range fk.columns {
+			table.fkColumns[col] = true
+			fk.nullable = fk.nullable && nullable[col]
+		}
+		table.result.ForeignKeys = append(table.result.ForeignKeys, fmt.Sprintf("(%s) -> %s (%s)",
+			strings.Join(fk.columns, ", "), fk.refTable, strings.Join(fk.refColumns, ", ")))
+
+		if len(fk.parents) > 0 {
+			continue
+		}
+		if !fk.nullable {
+			return nil, fmt.Errorf("%s references %s, which has no rows; add rows to %s or list it in tables", name, fk.refTable, fk.refTable)
+		}
+		table.result.Notes = append(table.result.Notes, fmt.Sprintf("%s has no rows, so %s was left NULL", fk.refTable, strings.Join(fk.columns, ", ")))
+	}
+
+	for _, idx := range indexes {
+		if !idx.Unique {
+			continue
+		}
+		var free []string
+		covered := false
+		for _, col := range idx.Columns {
+			// A server-generated column such as AUTO_INCREMENT keeps the index unique
+			covered = covered || skipped[col]
+			if !table.fkColumns[col] {
+				free = append(free, col)
+			}
+		}
+		switch {
+		case covered:
+		case len(free) > 0:
+			table.unique[free[0]] = true
+		default:
+			table.uniqueSets = append(table.uniqueSets, idx.Columns)
+		}
+	}
+
+	for _, col := range table.columns {
+		if !table.unique[col.Name] || !isNumericType(col.DataType) {
+			continue
+		}
+		result, err := m.ExecuteQuery(connectionName, fmt.Sprintf("SELECT COALESCE(MAX(%s), 0) AS max_value FROM %s",
+			QuoteIdentifier(col.Name), QualifiedName(database, name)))
+		if err != nil {
+			return nil, err
+		}
+		table.sequences[col.Name] = int64Value(result.Rows[0]["max_value"]) + 1
+	}
+	return table, nil
+}
+
+// testDataForeignKeys reads a table's foreign keys and a sample of the
+// distinct keys each one can reference
+func (m *Manager) testDataForeignKeys(connectionName, database, table string) ([]*testDataForeignKey, error) {
+	result, err := m.ExecuteQuery(connectionName, `SELECT CONSTRAINT_NAME, COLUMN_NAME,
+		REFERENCED_TABLE_SCHEMA, REFERENCED_TABLE_NAME, REFERENCED_COLUMN_NAME
+		FROM information_schema.KEY_COLUMN_USAGE
+		WHERE TABLE_SCHEMA = COALESCE(?, DATABASE()) AND TABLE_NAME = ?
+		AND REFERENCED_TABLE_NAME IS NOT NULL
+		ORDER BY CONSTRAINT_NAME, ORDINAL_POSITION`, nullIfEmpty(database), table)
+	if err != nil {
+		return nil, err
+	}
+
+	// One row per constraint column, in constraint order
+	var foreignKeys []*testDataForeignKey
+	var refDatabases []string
+	var constraint string
+	for _, row := range result.Rows {
+		if name := stringValue(row["CONSTRAINT_NAME"]); len(foreignKeys) == 0 || name != constraint {
+			constraint = name
+			foreignKeys = append(foreignKeys, &testDataForeignKey{refTable: stringValue(row["REFERENCED_TABLE_NAME"])})
+			refDatabases = append(refDatabases, stringValue(row["REFERENCED_TABLE_SCHEMA"]))
+		}
+		fk := foreignKeys[len(foreignKeys)-1]
+		fk.columns = append(fk.columns, stringValue(row["COLUMN_NAME"]))
+		fk.refColumns = append(fk.refColumns, stringValue(row["REFERENCED_COLUMN_NAME"]))
+	}
+
+	for i, fk := range foreignKeys {
+		quoted := make([]string, len(fk.refColumns))
+		conditions := make([]string, len(fk.refColumns))
+		for j, col := range fk.refColumns {
+			quoted[j] = QuoteIdentifier(col)
+			conditions[j] = quoted[j] + " IS NOT NULL"
+		}
+		parents, err := m.ExecuteQuery(connectionName, fmt.Sprintf("SELECT DISTINCT %s FROM %s WHERE %s LIMIT %d",
+			strings.Join(quoted, ", "), QualifiedName(refDatabases[i], fk.refTable), strings.Join(conditions, " AND "), testDataParentRows))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the keys of %s: %w", fk.refTable, err)
+		}
+		fk.parents = parents.Rows
+	}
+	return foreignKeys, nil
+}
+
+// generate builds n rows, or fewer when the referenced tables run out of
+// distinct combinations for a unique index of foreign key columns
+func (t *testDataTable) generate(fake *fakeData, n int) ([]map[string]interface{}, error) {
+	seen := make([]map[string]bool, len(t.uniqueSets))
+	for i := range seen {
+		seen[i] = make(map[string]bool)
+	}
+	// Mixed into unique strings so separate runs do not produce the same values
+	token := fake.hex(4)
+
+	rows := make([]map[string]interface{}, 0, n)
+	for len(rows) < n {
+		var row map[string]interface{}
+		for attempt := 0; ; attempt++ {
+			var err error
+			if row, err = t.row(fake, token+strconv.Itoa(len(rows)+1)); err != nil {
+				return nil, err
+			}
+			if set := t.repeatedSet(row, seen); set == nil {
+				break
+			} else if attempt == testDataRetries {
+				t.result.Notes = append(t.result.Notes, fmt.Sprintf("stopped after %d rows: the referenced tables have too few distinct values for the unique key (%s)",
+					len(rows), strings.Join(set, ", ")))
+				return rows, nil
+			}
+		}
+		for i, set := range t.uniqueSets {
+			seen[i][rowKey(row, set)] = true
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// row generates one row; suffix makes the values of unique string columns
+// distinct from the other rows
+func (t *testDataTable) row(fake *fakeData, suffix string) (map[string]interface{}, error) {
+	row := make(map[string]interface{}, len(t.columns))
+	for _, fk := range t.foreignKeys {
+		if len(fk.parents) == 0 || (fk.nullable && fake.rng.Intn(10) == 0) {
+			for _, col := range fk.columns {
+				row[col] = nil
+			}
+			continue
+		}
+		parent := fk.parents[fake.rng.Intn(len(fk.parents))]
+		for i, col := range fk.columns {
+			row[col] = parent[fk.refColumns[i]]
+		}
+	}
+
+	for _, col := range t.columns {
+		if t.fkColumns[col.Name] {
+			continue
+		}
+		if next, ok := t.sequences[col.Name]; ok {
+			t.sequences[col.Name] = next + 1
+			row[col.Name] = next
+			continue
+		}
+		if col.Nullable && !t.unique[col.Name] && fake.rng.Intn(10) == 0 {
+			row[col.Name] = nil
+			continue
+		}
+		value, err := fake.value(col)
+		if err != nil && !col.Nullable {
+			return nil, err
+		}
+		if s, ok := value.(string); ok && t.unique[col.Name] && isTextType(col.DataType) && !isUUIDColumn(col) {
+			if value, err = uniqueText(s, suffix, maxLength(col)); err != nil {
+				return nil, fmt.Errorf("column %s: %w", col.Name, err)
+			}
+		}
+		row[col.Name] = value
+	}
+	return row, nil
+}
+
+// repeatedSet returns the first unique set of foreign key columns whose
+// values in row were already generated; rows with a NULL in a set never repeat
+func (t *testDataTable) repeatedSet(row map[string]interface{}, seen []map[string]bool) []string {
+	for i, set := range t.uniqueSets {
+		hasNull := false
+		for _, col := range set {
+			hasNull = hasNull || row[col] == nil
+		}
+		if !hasNull && seen[i][rowKey(row, set)] {
+			return set
+		}
+	}
+	return nil
+}
+
+// uniqueText makes a generated string unique by adding suffix, as a +tag
+// on an email address, shortening the string to fit maxLen
+func uniqueText(s, suffix string, maxLen int) (string, error) {
+	base, domain, separator := s, "", "-"
+	if local, rest, ok := strings.Cut(s, "@"); ok {
+		base, domain, separator = local, "@"+rest, "+"
+	}
+	if maxLen <= 0 {
+		return base + separator + suffix + domain, nil
+	}
+	if room := maxLen - len(domain) - len(separator) - len(suffix); room > 0 {
+		return truncateRunes(base, room) + separator + suffix + domain, nil
+	}
+	if len(suffix) > maxLen {
+		return "", fmt.Errorf("too short for distinct generated values")
+	}
+	return suffix, nil
+}
+
+func isNumericType(dataType string) bool {
+	switch dataType {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint", "decimal", "numeric", "float", "double", "real":
+		return true
+	}
+	return false
+}
+
+func isTextType(dataType string) bool {
+	switch dataType {
+	case "char", "varchar", "tinytext", "text", "mediumtext", "longtext":
+		return true
+	}
+	return false
+}
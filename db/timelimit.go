@@ -0,0 +1,76 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+
+	"mysql-golang-mcp/config"
+)
+
+// leadingSelectPattern matches the SELECT keyword a statement starts with
+var leadingSelectPattern = regexp.MustCompile(`(?i)^\s*SELECT\b`)
+
+// executionTimeHintPattern matches a MAX_EXECUTION_TIME hint in an optimizer hint comment
+var executionTimeHintPattern = regexp.MustCompile(`(?i)\bMAX_EXECUTION_TIME\s*\(\s*(\d+)\s*\)`)
+
+// limitExecutionTime makes the server abort a SELECT that runs longer than
+// the connection's max_execution_time_ms, even when the client's timeout or
+// cancellation never reaches it. MySQL gets a MAX_EXECUTION_TIME optimizer
+// hint after the first SELECT, merged into a hint comment already there and
+// lowering a larger limit the statement sets itself. MariaDB, which has no
+// such hint, runs the statement under SET STATEMENT max_statement_time.
+func limitExecutionTime(connConfig *config.ConnectionConfig, info *ServerInfo, query string) string {
+	limit := connConfig.MaxExecutionTimeMs
+	if limit <= 0 || DetectQueryType(query) != QueryTypeSelect {
+		return query
+	}
+	if info != nil && info.Flavor == "mariadb" {
+		seconds := strconv.FormatFloat(float64(limit)/1000, 'f', -1, 64)
+		return "SET STATEMENT max_statement_time=" + seconds + " FOR " + query
+	}
+
+	loc := leadingSelectPattern.FindStringIndex(query)
+	if loc == nil {
+		return query
+	}
+	end := loc[1]
+	rest := query[end:]
+	trimmed := strings.TrimLeft(rest, " \t\r\n")
+	if !strings.HasPrefix(trimmed, "/*+") {
+		return fmt.Sprintf("%s /*+ MAX_EXECUTION_TIME(%d) */%s", query[:end], limit, rest)
+	}
+
+	// Only the first hint comment after SELECT is read, so the limit joins it
+	start := end + len(rest) - len(trimmed) + len("/*+")
+	length := strings.Index(query[start:], "*/")
+	if length < 0 {
+		return query
+	}
+	hint := query[start : start+length]
+	if match := executionTimeHintPattern.FindStringSubmatchIndex(hint); match != nil {
+		if n, err := strconv.Atoi(hint[match[2]:match[3]]); err == nil && n > 0 && n <= limit {
+			return query
+		}
+		return query[:start] + hint[:match[2]] + strconv.Itoa(limit) + query[start+match[3]:]
+	}
+	return fmt.Sprintf("%s MAX_EXECUTION_TIME(%d)%s", query[:start], limit, query[start:])
+}
+
+// executionTimeError explains a SELECT aborted by the server for running
+// longer than the connection's max_execution_time_ms
+func executionTimeError(connConfig *config.ConnectionConfig, err error) error {
+	var mysqlErr *mysql.MySQLError
+	if connConfig.MaxExecutionTimeMs <= 0 || !errors.As(err, &mysqlErr) {
+		return err
+	}
+	// 3024 is MySQL's ER_QUERY_TIMEOUT, 1969 MariaDB's ER_STATEMENT_TIMEOUT
+	if mysqlErr.Number != 3024 && mysqlErr.Number != 1969 {
+		return err
+	}
+	return fmt.Errorf("%w; the connection's max_execution_time_ms is %d, narrow the query or add a LIMIT", err, connConfig.MaxExecutionTimeMs)
+}
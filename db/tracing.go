@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer creates statement spans from the global tracer provider, which
+// drops them unless tracing is configured
+var tracer = otel.Tracer("mysql-golang-mcp/db")
+
+// startStatementSpan starts a span for a statement on a connection, as a
+// child of the span in ctx when there is one. The statement is recorded by
+// its fingerprint, so no values reach the tracing backend.
+func startStatementSpan(ctx context.Context, connectionName, query, fingerprint string) trace.Span {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	operation := GetQueryTypeLabel(DetectQueryType(query))
+	_, span := tracer.Start(ctx, operation, trace.WithSpanKind(trace.SpanKindClient))
+	if span.IsRecording() {
+		span.SetAttributes(
+			attribute.String("db.system", "mysql"),
+			attribute.String("db.operation.name", operation),
+			attribute.String("db.query.text", fingerprint),
+			attribute.String("mysql_mcp.connection", connectionName),
+		)
+	}
+	return span
+}
+
+// endStatementSpan records the rows a statement returned or changed and its
+// error, then ends its span
+func endStatementSpan(span trace.Span, rows int64, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		span.SetAttributes(attribute.Int64("mysql_mcp.rows", rows))
+	}
+	span.End()
+}
@@ -0,0 +1,352 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// transaction is an open transaction driven across tool calls. It is rolled
+// back automatically if not committed within the connection's
+// transaction_timeout_seconds.
+type transaction struct {
+	id         string
+	connection string
+	tx         *sql.Tx
+	release    func()
+	started    time.Time
+	timeout    time.Duration
+	statements int
+
+	// expired is set once the timeout has rolled the transaction back; the
+	// entry is kept so the next call can report why the transaction is gone
+	expired bool
+	done    bool
+	timer   *time.Timer
+	mu      sync.Mutex
+}
+
+// TransactionInfo describes a newly started transaction
+type TransactionInfo struct {
+	TransactionID  string    `json:"transaction_id"`
+	Connection     string    `json:"connection"`
+	TimeoutSeconds int       `json:"timeout_seconds"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+// TransactionStatementResult holds the outcome of one statement run inside a transaction
+type TransactionStatementResult struct {
+	TransactionID string       `json:"transaction_id"`
+	Result        *QueryResult `json:"result,omitempty"`
+	Write         *WriteResult `json:"write,omitempty"`
+}
+
+// TransactionOutcome reports how a transaction ended
+type TransactionOutcome struct {
+	TransactionID string `json:"transaction_id"`
+	Committed     bool   `json:"committed"`
+	RolledBack    bool   `json:"rolled_back"`
+	Statements    int    `json:"statements"`
+	DurationMs    int64  `json:"duration_ms"`
+}
+
+// BeginTransaction starts a transaction that subsequent ExecuteInTransaction
+// calls run inside. The transaction holds one of the connection's
+// max_concurrent_queries slots until it is committed, rolled back, or expires.
+func (m *Manager) BeginTransaction(connectionName string) (*TransactionInfo, error) {
+	db, connConfig, err := m.GetConnection(connectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := m.acquireSlot(connectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		release()
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	t := &transaction{
+		id:         newTransactionID(),
+		connection: connectionName,
+		tx:         tx,
+		release:    release,
+		started:    time.Now(),
+		timeout:    time.Duration(connConfig.TransactionTimeoutSeconds) * time.Second,
+	}
+	t.timer = time.AfterFunc(t.timeout, func() { m.expireTransaction(t) })
+
+	m.transactionsMu.Lock()
+	m.transactions[t.id] = t
+	m.transactionsMu.Unlock()
+
+	slog.Info("transaction started", "transaction_id", t.id, "connection", connectionName)
+
+	return &TransactionInfo{
+		TransactionID:  t.id,
+		Connection:     connectionName,
+		TimeoutSeconds: connConfig.TransactionTimeoutSeconds,
+		ExpiresAt:      t.started.Add(t.timeout),
+	}, nil
+}
+
+// ExecuteInTransaction runs a SELECT, INSERT, UPDATE, or DELETE inside an open transaction
+func (m *Manager) ExecuteInTransaction(transactionID, query string) (*TransactionStatementResult, error) {
+	t, err := m.lookupTransaction(transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	run := m.startStatement(context.Background(), t.connection, query)
+	result, err := m.executeInTransaction(t, query)
+	var rows int64
+	if result != nil && result.Result != nil {
+		rows = int64(result.Result.Count)
+	} else if result != nil && result.Write != nil {
+		rows = result.Write.RowsAffected
+	}
+	m.endStatement(run, rows, err)
+	return result, err
+}
+
+// executeInTransaction runs a statement for ExecuteInTransaction
+func (m *Manager) executeInTransaction(t *transaction, query string) (*TransactionStatementResult, error) {
+	db, connConfig, err := m.GetConnection(t.connection)
+	if err != nil {
+		return nil, err
+	}
+
+	// DDL and transaction control statements would implicitly commit or end the transaction
+	if err := ValidateQueryType(query, QueryTypeSelect, QueryTypeInsert, QueryTypeUpdate, QueryTypeDelete); err != nil {
+		return nil, err
+	}
+
+	// Check read-only mode
+	if !isReadOnlyQuery(query) {
+		if err := checkReplica(t.connection, connConfig); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkReadOnlyStatement(t.connection, connConfig, query); err != nil {
+		return nil, err
+	}
+	if connConfig.ReadOnly && !isReadOnlyQuery(query) {
+		return nil, fmt.Errorf("connection '%s' is read-only, write operations are not allowed", t.connection)
+	}
+
+	// Block sensitive metadata queries
+	if isSensitiveQuery(query) {
+		return nil, fmt.Errorf("access to sensitive MySQL metadata is not allowed")
+	}
+	if err := checkBlockedPatterns(t.connection, connConfig, query); err != nil {
+		return nil, err
+	}
+	if err := checkQualifiedWrite(t.connection, connConfig, query); err != nil {
+		return nil, err
+	}
+
+	// Statements that need approval cannot wait inside an open transaction
+	queryType := DetectQueryType(query)
+	if needsApproval(connConfig, queryType) {
+		return nil, fmt.Errorf("connection '%s' requires approval for %s statements, which cannot run inside a transaction; use the write tools instead", t.connection, GetQueryTypeLabel(queryType))
+	}
+
+	// Read tables with allowed_columns through a projection of those columns
+	var projected []string
+	if queryType == QueryTypeSelect {
+		if query, projected, err = applyColumnAllowlist(connConfig, query); err != nil {
+			return nil, err
+		}
+	}
+
+	// Refuse SELECTs whose estimated cost exceeds the connection's budget
+	if connConfig.MaxEstimatedRowsExamined > 0 && queryType == QueryTypeSelect {
+		if err := checkQueryCost(db, t.connection, connConfig.MaxEstimatedRowsExamined, query); err != nil {
+			return nil, err
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.expired || t.done {
+		return nil, m.transactionGoneError(t)
+	}
+
+	result := &TransactionStatementResult{TransactionID: t.id}
+	start := time.Now()
+
+	if queryType == QueryTypeSelect {
+		rows, err := t.tx.QueryContext(context.Background(), limitExecutionTime(connConfig, m.cachedServerInfo(t.connection), query))
+		if err != nil {
+			m.recordError(t.connection, query, err)
+			return nil, fmt.Errorf("query execution failed: %w", executionTimeError(connConfig, columnAllowlistError(connConfig, projected, err)))
+		}
+		queryResult, err := scanRows(rows, connConfig.MaxRows)
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+		queryResult.ExecutionMs = time.Since(start).Milliseconds()
+		queryResult.Warnings = fetchWarnings(t.tx)
+		queryResult.Connection = t.connection
+		queryResult.Database = connConfig.Database
+		queryResult.ProjectedTables = projected
+		result.Result = queryResult
+	} else {
+		execResult, err := t.tx.ExecContext(context.Background(), query)
+		if err != nil {
+			m.recordError(t.connection, query, err)
+			return nil, fmt.Errorf("query execution failed: %w", err)
+		}
+		rowsAffected, _ := execResult.RowsAffected()
+		lastInsertID, _ := execResult.LastInsertId()
+		result.Write = &WriteResult{
+			RowsAffected: rowsAffected,
+			LastInsertID: lastInsertID,
+			Warning:      riskWarning(t.connection, connConfig),
+			ExecutionMs:  time.Since(start).Milliseconds(),
+			Warnings:     fetchWarnings(t.tx),
+			Connection:   t.connection,
+			Database:     connConfig.Database,
+		}
+	}
+
+	t.statements++
+	slog.Debug("statement executed", "connection", t.connection, "transaction_id", t.id, "sql", query)
+	return result, nil
+}
+
+// CommitTransaction commits an open transaction
+func (m *Manager) CommitTransaction(transactionID string) (*TransactionOutcome, error) {
+	return m.endTransaction(transactionID, true)
+}
+
+// RollbackTransaction rolls back an open transaction
+func (m *Manager) RollbackTransaction(transactionID string) (*TransactionOutcome, error) {
+	return m.endTransaction(transactionID, false)
+}
+
+// endTransaction commits or rolls back a transaction and releases its slot
+func (m *Manager) endTransaction(transactionID string, commit bool) (*TransactionOutcome, error) {
+	t, err := m.lookupTransaction(transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.expired || t.done {
+		return nil, m.transactionGoneError(t)
+	}
+
+	t.timer.Stop()
+	t.done = true
+	defer t.release()
+
+	m.transactionsMu.Lock()
+	delete(m.transactions, transactionID)
+	m.transactionsMu.Unlock()
+
+	outcome := &TransactionOutcome{
+		TransactionID: transactionID,
+		Statements:    t.statements,
+		DurationMs:    time.Since(t.started).Milliseconds(),
+	}
+
+	if commit {
+		if err := t.tx.Commit(); err != nil {
+			return nil, fmt.Errorf("commit failed: %w", err)
+		}
+		m.invalidateCache(t.connection)
+		outcome.Committed = true
+	} else {
+		if err := t.tx.Rollback(); err != nil {
+			return nil, fmt.Errorf("rollback failed: %w", err)
+		}
+		outcome.RolledBack = true
+	}
+
+	slog.Info("transaction ended", "transaction_id", transactionID, "connection", t.connection, "committed", commit, "statements", t.statements)
+	return outcome, nil
+}
+
+// expireTransaction rolls back a transaction that outlived its timeout
+func (m *Manager) expireTransaction(t *transaction) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.done {
+		return
+	}
+
+	t.tx.Rollback()
+	t.release()
+	t.expired = true
+	t.done = true
+
+	slog.Warn("transaction expired and was rolled back", "transaction_id", t.id, "connection", t.connection, "statements", t.statements)
+}
+
+// lookupTransaction returns an open transaction by ID. An expired transaction is
+// reported once with a "transaction expired" error and then forgotten.
+func (m *Manager) lookupTransaction(transactionID string) (*transaction, error) {
+	m.transactionsMu.Lock()
+	t, exists := m.transactions[transactionID]
+	m.transactionsMu.Unlock()
+	if !exists {
+		return nil, fmt.Errorf("unknown transaction: %s", transactionID)
+	}
+
+	t.mu.Lock()
+	expired := t.expired
+	t.mu.Unlock()
+	if expired {
+		m.transactionsMu.Lock()
+		delete(m.transactions, transactionID)
+		m.transactionsMu.Unlock()
+		return nil, m.transactionGoneError(t)
+	}
+	return t, nil
+}
+
+// transactionGoneError explains why a transaction can no longer be used
+func (m *Manager) transactionGoneError(t *transaction) error {
+	if t.expired {
+		return fmt.Errorf("transaction expired: %s was not committed within %ds and was rolled back automatically (%d statements discarded); begin a new transaction and retry",
+			t.id, int(t.timeout.Seconds()), t.statements)
+	}
+	return fmt.Errorf("transaction %s has already ended", t.id)
+}
+
+// rollbackAllTransactions rolls back every open transaction
+func (m *Manager) rollbackAllTransactions() {
+	m.transactionsMu.Lock()
+	open := make([]*transaction, 0, len(m.transactions))
+	for _, t := range m.transactions {
+		open = append(open, t)
+	}
+	m.transactions = make(map[string]*transaction)
+	m.transactionsMu.Unlock()
+
+	for _, t := range open {
+		t.mu.Lock()
+		if !t.done {
+			t.timer.Stop()
+			t.tx.Rollback()
+			t.release()
+			t.done = true
+		}
+		t.mu.Unlock()
+	}
+}
+
+// newTransactionID returns a random transaction identifier
+func newTransactionID() string {
+	return "txn_" + randomHex(8)
+}
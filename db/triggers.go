@@ -0,0 +1,104 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TriggerDefinition describes a trigger to create with CreateTrigger
+type TriggerDefinition struct {
+	Name  string
+	Table string
+
+	// Timing is BEFORE or AFTER and Event is INSERT, UPDATE, or DELETE
+	Timing string
+	Event  string
+
+	// Body is the statement run FOR EACH ROW; use BEGIN ... END for several
+	// statements. No DELIMITER is needed since it is sent as one statement.
+	Body string
+}
+
+// ListTriggers returns the triggers in a database, optionally only those on one table
+func (m *Manager) ListTriggers(connectionName, database, table string) (*QueryResult, error) {
+	return m.ExecuteQuery(connectionName, `SELECT TRIGGER_NAME, EVENT_OBJECT_TABLE, ACTION_TIMING, EVENT_MANIPULATION,
+		ACTION_ORDER, DEFINER, CREATED
+		FROM information_schema.TRIGGERS
+		WHERE TRIGGER_SCHEMA = COALESCE(?, DATABASE())
+		AND (? IS NULL OR EVENT_OBJECT_TABLE = ?)
+		ORDER BY EVENT_OBJECT_TABLE, ACTION_TIMING, EVENT_MANIPULATION, ACTION_ORDER`,
+		nullIfEmpty(database), nullIfEmpty(table), nullIfEmpty(table))
+}
+
+// DescribeTrigger returns a trigger's metadata and its SHOW CREATE TRIGGER statement
+func (m *Manager) DescribeTrigger(connectionName, database, name string) (map[string]interface{}, error) {
+	trigger, err := m.ExecuteQuery(connectionName, `SELECT TRIGGER_NAME, EVENT_OBJECT_TABLE, ACTION_TIMING,
+		EVENT_MANIPULATION, ACTION_ORDER, ACTION_STATEMENT, DEFINER, SQL_MODE, CREATED
+		FROM information_schema.TRIGGERS
+		WHERE TRIGGER_SCHEMA = COALESCE(?, DATABASE()) AND TRIGGER_NAME = ?`, nullIfEmpty(database), name)
+	if err != nil {
+		return nil, err
+	}
+	if len(trigger.Rows) == 0 {
+		return nil, fmt.Errorf("trigger not found: %s", name)
+	}
+
+	create, err := m.ExecuteQuery(connectionName, "SHOW CREATE TRIGGER "+QualifiedName(database, name))
+	if err != nil {
+		return nil, err
+	}
+	if len(create.Rows) == 0 {
+		return nil, fmt.Errorf("trigger not found: %s", name)
+	}
+
+	return map[string]interface{}{
+		"trigger":              trigger.Rows[0],
+		"create_statement":     create.Rows[0]["SQL Original Statement"],
+		"character_set_client": create.Rows[0]["character_set_client"],
+		"collation_connection": create.Rows[0]["collation_connection"],
+	}, nil
+}
+
+// CreateTrigger creates a row-level trigger. Requires allow_ddl on the
+// connection, and approval on require_approval connections.
+func (m *Manager) CreateTrigger(connectionName, database string, def TriggerDefinition) (*WriteResult, error) {
+	if def.Name == "" {
+		return nil, fmt.Errorf("trigger name is required")
+	}
+	if def.Table == "" {
+		return nil, fmt.Errorf("table is required")
+	}
+	timing := strings.ToUpper(strings.TrimSpace(def.Timing))
+	if timing != "BEFORE" && timing != "AFTER" {
+		return nil, fmt.Errorf("timing must be BEFORE or AFTER, got %q", def.Timing)
+	}
+	event := strings.ToUpper(strings.TrimSpace(def.Event))
+	if event != "INSERT" && event != "UPDATE" && event != "DELETE" {
+		return nil, fmt.Errorf("event must be INSERT, UPDATE, or DELETE, got %q", def.Event)
+	}
+	body := strings.TrimRight(strings.TrimSpace(def.Body), "; \t\r\n")
+	if body == "" {
+		return nil, fmt.Errorf("trigger body is required")
+	}
+	if strings.HasPrefix(strings.ToUpper(body), "DELIMITER") {
+		return nil, fmt.Errorf("trigger body must not contain DELIMITER; pass the body as a single statement or BEGIN ... END block")
+	}
+	if isSensitiveQuery(body) {
+		return nil, fmt.Errorf("access to sensitive MySQL metadata is not allowed")
+	}
+
+	query := fmt.Sprintf("CREATE TRIGGER %s %s %s ON %s FOR EACH ROW %s",
+		QualifiedName(database, def.Name), timing, event, QualifiedName(database, def.Table), body)
+	return m.execDDL(connectionName, "trigger management", database, query, WriteOptions{})
+}
+
+// DropTrigger drops a trigger. Requires allow_ddl on the connection, and
+// approval on require_approval connections.
+func (m *Manager) DropTrigger(connectionName, database, name string) (*WriteResult, error) {
+	if name == "" {
+		return nil, fmt.Errorf("trigger name is required")
+	}
+
+	query := "DROP TRIGGER " + QualifiedName(database, name)
+	return m.execDDL(connectionName, "trigger management", database, query, WriteOptions{})
+}
@@ -0,0 +1,289 @@
+package db
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// UndoResult reports how a backup was restored
+type UndoResult struct {
+	BackupID     string    `json:"backup_id"`
+	Table        string    `json:"table"`
+	Operation    string    `json:"operation"`
+	RowsRestored int64     `json:"rows_restored"`
+	RowsMissing  int       `json:"rows_missing,omitempty"`
+	ExecutionMs  int64     `json:"execution_ms"`
+	Warning      string    `json:"warning,omitempty"`
+	Warnings     []Warning `json:"warnings,omitempty"`
+}
+
+// LoadBackup reads a snapshot taken on a connection by its backup ID
+func (m *Manager) LoadBackup(connectionName, backupID string) (*Backup, error) {
+	_, connConfig, err := m.GetConnection(connectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload []byte
+	if connConfig.BackupTable != "" {
+		result, err := m.ExecuteQuery(connectionName,
+			fmt.Sprintf("SELECT payload FROM %s WHERE backup_id = ?", backupTableName(connConfig)), backupID)
+		if err != nil {
+			return nil, err
+		}
+		if len(result.Rows) == 0 {
+			return nil, fmt.Errorf("backup not found: %s", backupID)
+		}
+		payload = []byte(stringValue(result.Rows[0]["payload"]))
+	} else {
+		payload, err = findBackupLine(connConfig.BackupFile, backupID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Keep numbers as json.Number so BIGINT values survive the round trip
+	var backup Backup
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	if err := decoder.Decode(&backup); err != nil {
+		return nil, fmt.Errorf("failed to parse backup %s: %w", backupID, err)
+	}
+	if backup.Connection != connectionName {
+		return nil, fmt.Errorf("backup %s was taken on connection '%s', not '%s'", backupID, backup.Connection, connectionName)
+	}
+	return &backup, nil
+}
+
+// findBackupLine returns the JSONL line holding the given backup ID
+func findBackupLine(path, backupID string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("backup not found: %s", backupID)
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	needle := []byte(`"backup_id":"` + backupID + `"`)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<30)
+	for scanner.Scan() {
+		if bytes.Contains(scanner.Bytes(), needle) {
+			return append([]byte(nil), scanner.Bytes()...), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read backup file: %w", err)
+	}
+	return nil, fmt.Errorf("backup not found: %s", backupID)
+}
+
+// UndoWrite restores the rows captured by a backup in a single transaction.
+// Deleted rows are re-inserted; updated rows are written back by primary key.
+// Changes made to those rows after the original write are overwritten.
+func (m *Manager) UndoWrite(connectionName, backupID string) (*UndoResult, error) {
+	backup, err := m.LoadBackup(connectionName, backupID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Look up the primary key and generated columns before taking a slot;
+	// TableColumns needs one too
+	columns, err := m.TableColumns(connectionName, backup.Database, backup.Table)
+	if err != nil {
+		return nil, err
+	}
+	var keyColumns []string
+	generated := make(map[string]bool)
+	for _, col := range columns {
+		if col.Key == "PRI" {
+			keyColumns = append(keyColumns, col.Name)
+		}
+		if isGeneratedColumn(col.Extra) {
+			generated[col.Name] = true
+		}
+	}
+	if backup.Operation == "UPDATE" && len(keyColumns) == 0 {
+		return nil, fmt.Errorf("table %s has no primary key, so updated rows cannot be matched for undo", backup.Table)
+	}
+
+	// Generated columns are computed by the server and cannot be written
+	var writable []string
+	for _, col := range backup.Columns {
+		if !generated[col] {
+			writable = append(writable, col)
+		}
+	}
+
+	db, connConfig, err := m.GetConnection(connectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := m.acquireSlot(connectionName)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if err := checkReplica(connectionName, connConfig); err != nil {
+		return nil, err
+	}
+	if connConfig.ReadOnly {
+		return nil, fmt.Errorf("connection '%s' is read-only, write operations are not allowed", connectionName)
+	}
+
+	rows, err := decodeBackupRows(backup)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	table := QualifiedName(backup.Database, backup.Table)
+	result := &UndoResult{BackupID: backupID, Table: backup.Table, Operation: backup.Operation}
+	start := time.Now()
+
+	for _, row := range rows {
+		var query string
+		var args []interface{}
+
+		switch backup.Operation {
+		case "DELETE":
+			quoted := make([]string, len(writable))
+			for i, col := range writable {
+				quoted[i] = QuoteIdentifier(col)
+				args = append(args, row[col])
+			}
+			query = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(quoted, ", "),
+				strings.TrimSuffix(strings.Repeat("?, ", len(quoted)), ", "))
+		case "UPDATE":
+			assignments := make([]string, len(writable))
+			for i, col := range writable {
+				assignments[i] = QuoteIdentifier(col) + " = ?"
+				args = append(args, row[col])
+			}
+			for _, col := range keyColumns {
+				args = append(args, row[col])
+			}
+			query = fmt.Sprintf("UPDATE %s SET %s WHERE %s", table, strings.Join(assignments, ", "), strings.Join(conditionsFor(keyColumns), " AND "))
+		default:
+			return nil, fmt.Errorf("backup %s has unsupported operation %q", backupID, backup.Operation)
+		}
+
+		if err := checkBlockedPatterns(connectionName, connConfig, query); err != nil {
+			return nil, err
+		}
+		if err := checkQualifiedWrite(connectionName, connConfig, query); err != nil {
+			return nil, err
+		}
+		execResult, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			m.recordError(connectionName, query, err)
+			return nil, fmt.Errorf("undo failed, nothing was restored: %w", err)
+		}
+		result.Warnings = append(result.Warnings, fetchWarnings(tx)...)
+
+		// MySQL reports 0 affected rows both when the row already holds the
+		// backed-up values and when no row matched (deleted, or key changed)
+		affected, _ := execResult.RowsAffected()
+		if backup.Operation == "UPDATE" && affected == 0 {
+			var matches int
+			keyArgs := args[len(writable):]
+			err := tx.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", table, strings.Join(conditionsFor(keyColumns), " AND ")), keyArgs...).Scan(&matches)
+			if err != nil {
+				return nil, fmt.Errorf("undo failed, nothing was restored: %w", err)
+			}
+			if matches == 0 {
+				result.RowsMissing++
+				continue
+			}
+		}
+		result.RowsRestored++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+	m.invalidateCache(connectionName)
+	result.ExecutionMs = time.Since(start).Milliseconds()
+	if result.RowsMissing > 0 {
+		result.Warning = fmt.Sprintf("%d rows from the backup no longer exist (deleted, or their primary key changed) and were not restored", result.RowsMissing)
+	}
+
+	slog.Info("write undone", "connection", connectionName, "backup_id", backupID, "table", backup.Table, "rows_restored", result.RowsRestored, "rows_missing", result.RowsMissing)
+	return result, nil
+}
+
+// isGeneratedColumn reports whether a column's EXTRA marks it as a generated
+// column (not DEFAULT_GENERATED, which only means an expression default)
+func isGeneratedColumn(extra string) bool {
+	extra = strings.ToUpper(extra)
+	return strings.Contains(extra, "VIRTUAL GENERATED") || strings.Contains(extra, "STORED GENERATED") || strings.Contains(extra, "PERSISTENT GENERATED")
+}
+
+// conditionsFor renders null-safe equality conditions for the given columns
+func conditionsFor(columns []string) []string {
+	conditions := make([]string, len(columns))
+	for i, col := range columns {
+		conditions[i] = QuoteIdentifier(col) + " <=> ?"
+	}
+	return conditions
+}
+
+// decodeBackupRows converts snapshot values back into statement arguments:
+// base64 binary columns are decoded and RFC3339 timestamps are formatted as
+// DATETIME literals
+func decodeBackupRows(backup *Backup) ([]map[string]interface{}, error) {
+	binary := make(map[string]bool, len(backup.BinaryColumns))
+	for _, col := range backup.BinaryColumns {
+		binary[col] = true
+	}
+	types := make(map[string]string, len(backup.ColumnTypes))
+	for _, ct := range backup.ColumnTypes {
+		types[ct.Name] = ct.DatabaseType
+	}
+
+	rows := make([]map[string]interface{}, len(backup.Rows))
+	for i, row := range backup.Rows {
+		decoded := make(map[string]interface{}, len(row))
+		for col, v := range row {
+			s, isString := v.(string)
+			switch {
+			case !isString:
+				decoded[col] = v
+			case binary[col]:
+				b, err := base64.StdEncoding.DecodeString(s)
+				if err != nil {
+					return nil, fmt.Errorf("backup %s: invalid base64 in column %s: %w", backup.BackupID, col, err)
+				}
+				decoded[col] = b
+			case types[col] == "DATETIME" || types[col] == "TIMESTAMP":
+				t, err := time.Parse(time.RFC3339Nano, s)
+				if err != nil {
+					return nil, fmt.Errorf("backup %s: invalid timestamp in column %s: %w", backup.BackupID, col, err)
+				}
+				decoded[col] = t.UTC().Format("2006-01-02 15:04:05.999999")
+			default:
+				decoded[col] = s
+			}
+		}
+		rows[i] = decoded
+	}
+	return rows, nil
+}
@@ -0,0 +1,296 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"mysql-golang-mcp/config"
+)
+
+// grantablePrivileges are the privileges grant_privileges accepts. Privileges
+// that let an account escalate (GRANT OPTION, SUPER, FILE, CREATE USER) are
+// left out, and grants are always scoped to a database or table.
+var grantablePrivileges = map[string]bool{
+	"ALL PRIVILEGES": true, "SELECT": true, "INSERT": true, "UPDATE": true, "DELETE": true,
+	"CREATE": true, "DROP": true, "ALTER": true, "INDEX": true, "REFERENCES": true,
+	"CREATE VIEW": true, "SHOW VIEW": true, "TRIGGER": true, "EVENT": true, "EXECUTE": true,
+	"CREATE ROUTINE": true, "ALTER ROUTINE": true, "CREATE TEMPORARY TABLES": true, "LOCK TABLES": true,
+}
+
+// tablePrivileges are the privileges that can be granted on a single table
+var tablePrivileges = map[string]bool{
+	"ALL PRIVILEGES": true, "SELECT": true, "INSERT": true, "UPDATE": true, "DELETE": true,
+	"CREATE": true, "DROP": true, "ALTER": true, "INDEX": true, "REFERENCES": true,
+	"CREATE VIEW": true, "SHOW VIEW": true, "TRIGGER": true,
+}
+
+// userColumns are the mysql.user columns list_users returns; the password
+// hash columns are never read out
+var userColumns = []string{
+	"User", "Host", "plugin", "account_locked", "password_expired", "password_last_changed",
+	"password_lifetime", "is_role", "default_role",
+	"max_questions", "max_updates", "max_connections", "max_user_connections",
+}
+
+// grantSecretPattern matches the password hash or secret a GRANT statement
+// can carry on older servers and MariaDB
+var grantSecretPattern = regexp.MustCompile(`(?i)\b(PASSWORD|USING|AS)(\s*\(?\s*)'(?:[^'\\]|\\.|'')*'`)
+
+// grantWildcardEscaper escapes the wildcards of a database name in GRANT
+var grantWildcardEscaper = strings.NewReplacer("_", `\_`, "%", `\%`)
+
+// UserAccount names a MySQL account, user@host
+type UserAccount struct {
+	User string
+	Host string
+}
+
+// String renders the account as a quoted 'user'@'host'
+func (a UserAccount) String() string {
+	return quoteStringLiteral(a.User) + "@" + quoteStringLiteral(a.Host)
+}
+
+// validate checks the account and defaults its host to %, any host
+func (a *UserAccount) validate() error {
+	if a.User == "" {
+		return fmt.Errorf("user is required")
+	}
+	if a.Host == "" {
+		a.Host = "%"
+	}
+	return nil
+}
+
+// AccountChange is the result of create_user or grant_privileges. Statement
+// is the statement run, with any password redacted.
+type AccountChange struct {
+	Account   string `json:"account"`
+	Statement string `json:"statement"`
+	Warning   string `json:"warning,omitempty"`
+
+	// Approval is set instead of a result when the statement was queued for approval
+	Approval *PendingApproval `json:"approval,omitempty"`
+}
+
+// UserList holds accounts from mysql.user without their password hashes,
+// and the grants of the accounts matching a user filter
+type UserList struct {
+	Connection string                   `json:"connection"`
+	Users      []map[string]interface{} `json:"users"`
+	Count      int                      `json:"count"`
+	Grants     map[string][]string      `json:"grants,omitempty"`
+}
+
+// adminConnection returns a connection and a held concurrency slot for the
+// user management tools, refusing connections without allow_admin and, for
+// changes, read-only connections and replicas
+func (m *Manager) adminConnection(connectionName string, change bool) (*sql.DB, *config.ConnectionConfig, func(), error) {
+	db, connConfig, err := m.GetConnection(connectionName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if !connConfig.AllowAdmin {
+		return nil, nil, nil, fmt.Errorf("connection '%s' does not allow user management; set allow_admin: true in config to enable create_user, grant_privileges, list_users and rotate_password", connectionName)
+	}
+	if change {
+		if err := checkReplica(connectionName, connConfig); err != nil {
+			return nil, nil, nil, err
+		}
+		if connConfig.ReadOnly {
+			return nil, nil, nil, fmt.Errorf("connection '%s' is read-only, user management changes are not allowed", connectionName)
+		}
+	}
+
+	release, err := m.acquireSlot(connectionName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return db, connConfig, release, nil
+}
+
+// CreateUser creates an account identified by password. Requires allow_admin
+// on the connection.
+func (m *Manager) CreateUser(connectionName string, account UserAccount, password string) (*AccountChange, error) {
+	if err := account.validate(); err != nil {
+		return nil, err
+	}
+	if password == "" {
+		return nil, fmt.Errorf("password is required")
+	}
+	query := fmt.Sprintf("CREATE USER %s IDENTIFIED BY %s", account, quoteStringLiteral(password))
+	redacted := fmt.Sprintf("CREATE USER %s IDENTIFIED BY '[redacted]'", account)
+	return m.changeAccount(connectionName, account, query, redacted, WriteOptions{})
+}
+
+// GrantPrivileges grants privileges on a database, or one of its tables, to
+// an account. Requires allow_admin on the connection.
+func (m *Manager) GrantPrivileges(connectionName string, account UserAccount, privileges []string, database, table string) (*AccountChange, error) {
+	if err := account.validate(); err != nil {
+		return nil, err
+	}
+	if database == "" {
+		return nil, fmt.Errorf("database is required; global privileges cannot be granted")
+	}
+	if strings.Contains(systemSchemas, quoteStringLiteral(strings.ToLower(database))) {
+		return nil, fmt.Errorf("privileges on the system schema %s cannot be granted", database)
+	}
+	if len(privileges) == 0 {
+		return nil, fmt.Errorf("at least one privilege is required")
+	}
+
+	normalized := make([]string, 0, len(privileges))
+	for _, p := range privileges {
+		privilege := strings.ToUpper(strings.Join(strings.Fields(p), " "))
+		if privilege == "ALL" {
+			privilege = "ALL PRIVILEGES"
+		}
+		if !grantablePrivileges[privilege] {
+			return nil, fmt.Errorf("privilege %q cannot be granted; allowed: %s", p, strings.Join(sortedKeys(grantablePrivileges), ", "))
+		}
+		if table != "" && !tablePrivileges[privilege] {
+			return nil, fmt.Errorf("privilege %s cannot be granted on a table", privilege)
+		}
+		normalized = append(normalized, privilege)
+	}
+
+	// _ and % are wildcards in a database-level grant's database name
+	target := QuoteIdentifier(grantWildcardEscaper.Replace(database)) + ".*"
+	if table != "" {
+		target = QualifiedName(database, table)
+	}
+	query := fmt.Sprintf("GRANT %s ON %s TO %s", strings.Join(normalized, ", "), target, account)
+	return m.changeAccount(connectionName, account, query, query, WriteOptions{})
+}
+
+// changeAccount runs a user management statement, recording and checking it
+// in its redacted form so no password reaches logs, error history or the
+// approval webhook. It waits for approval on require_approval connections.
+func (m *Manager) changeAccount(connectionName string, account UserAccount, query, redacted string, opts WriteOptions) (*AccountChange, error) {
+	db, connConfig, release, err := m.adminConnection(connectionName, true)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if err := checkBlockedPatterns(connectionName, connConfig, redacted); err != nil {
+		return nil, err
+	}
+
+	// Hold risky statements until a human approves them
+	queryType := DetectQueryType(redacted)
+	if !opts.approved && needsApproval(connConfig, queryType) {
+		pending, err := m.requestApproval(connectionName, connConfig, redacted, queryType, func() (interface{}, error) {
+			return m.changeAccount(connectionName, account, query, redacted, WriteOptions{approved: true})
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &AccountChange{Account: account.String(), Statement: redacted, Approval: pending}, nil
+	}
+
+	if _, err := db.Exec(query); err != nil {
+		m.recordError(connectionName, redacted, err)
+		return nil, fmt.Errorf("query execution failed: %w", err)
+	}
+
+	return &AccountChange{Account: account.String(), Statement: redacted, Warning: riskWarning(connectionName, connConfig)}, nil
+}
+
+// ListUsers returns the accounts in mysql.user without their password hashes.
+// When user is set only its accounts are listed, optionally only the one on
+// host, with each account's SHOW GRANTS. Requires allow_admin on the connection.
+func (m *Manager) ListUsers(connectionName, user, host string) (*UserList, error) {
+	db, connConfig, release, err := m.adminConnection(connectionName, false)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Only the listed columns are read, so password hashes never leave the
+	// server; which of them exist depends on the flavor and version
+	columnRows, err := db.Query(`SELECT COLUMN_NAME FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = 'mysql' AND TABLE_NAME = 'user' ORDER BY ORDINAL_POSITION`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the columns of mysql.user: %w", err)
+	}
+	var columns []string
+	for columnRows.Next() {
+		var column string
+		if err := columnRows.Scan(&column); err != nil {
+			columnRows.Close()
+			return nil, fmt.Errorf("failed to read the columns of mysql.user: %w", err)
+		}
+		if hasColumn(userColumns, column) {
+			columns = append(columns, QuoteIdentifier(column))
+		}
+	}
+	err = columnRows.Err()
+	columnRows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the columns of mysql.user: %w", err)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("mysql.user has none of the columns list_users returns")
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM mysql.user WHERE (? = '' OR User = ?) AND (? = '' OR Host = ?) ORDER BY User, Host", strings.Join(columns, ", "))
+	rows, err := db.Query(query, user, user, host, host)
+	if err != nil {
+		m.recordError(connectionName, query, err)
+		return nil, fmt.Errorf("query execution failed: %w", err)
+	}
+	result, err := scanRows(rows, connConfig.MaxRows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	list := &UserList{Connection: connectionName, Users: result.Rows}
+	if list.Users == nil {
+		list.Users = make([]map[string]interface{}, 0)
+	}
+	list.Count = len(list.Users)
+
+	if user == "" {
+		return list, nil
+	}
+	list.Grants = make(map[string][]string, len(list.Users))
+	for _, row := range list.Users {
+		account := UserAccount{User: stringValue(row["User"]), Host: stringValue(row["Host"])}
+		query := "SHOW GRANTS FOR " + account.String()
+		grants, err := db.Query(query)
+		if err != nil {
+			m.recordError(connectionName, query, err)
+			return nil, fmt.Errorf("query execution failed: %w", err)
+		}
+		var statements []string
+		for grants.Next() {
+			var grant string
+			if err := grants.Scan(&grant); err != nil {
+				grants.Close()
+				return nil, err
+			}
+			statements = append(statements, grantSecretPattern.ReplaceAllString(grant, "$1$2'[redacted]'"))
+		}
+		err = grants.Err()
+		grants.Close()
+		if err != nil {
+			return nil, err
+		}
+		list.Grants[account.String()] = statements
+	}
+	return list, nil
+}
+
+// sortedKeys returns the keys of a set in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
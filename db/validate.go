@@ -0,0 +1,45 @@
+package db
+
+import (
+	"sync"
+	"time"
+)
+
+// ConnectionCheck is the result of validating one configured connection
+type ConnectionCheck struct {
+	Name      string      `json:"name"`
+	OK        bool        `json:"ok"`
+	Error     string      `json:"error,omitempty"`
+	Server    *ServerInfo `json:"server,omitempty"`
+	LatencyMs int64       `json:"latency_ms"`
+}
+
+// ValidateConnections connects to every configured connection in parallel and
+// reports per-connection success or failure with the server version. Successful
+// pools stay open, so validation also warms them up.
+func (m *Manager) ValidateConnections() []ConnectionCheck {
+	names := m.ConnectionNames()
+	checks := make([]ConnectionCheck, len(names))
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+
+			check := ConnectionCheck{Name: name}
+			start := time.Now()
+			if _, _, err := m.GetConnection(name); err != nil {
+				check.Error = err.Error()
+			} else {
+				check.OK = true
+				check.Server = m.cachedServerInfo(name)
+			}
+			check.LatencyMs = time.Since(start).Milliseconds()
+			checks[i] = check
+		}(i, name)
+	}
+
+	wg.Wait()
+	return checks
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 )
 
@@ -25,6 +26,7 @@ const (
 	QueryTypeRevoke
 	QueryTypeSet
 	QueryTypeUse
+	QueryTypeCall
 )
 
 // DetectQueryType analyzes a SQL query and returns its type
@@ -52,6 +54,7 @@ func DetectQueryType(query string) QueryType {
 		{"REVOKE", QueryTypeRevoke},
 		{"SET", QueryTypeSet},
 		{"USE", QueryTypeUse},
+		{"CALL", QueryTypeCall},
 	}
 
 	for _, pm := range prefixMap {
@@ -104,6 +107,7 @@ func GetQueryTypeLabel(qt QueryType) string {
 		QueryTypeRevoke:   "REVOKE",
 		QueryTypeSet:      "SET",
 		QueryTypeUse:      "USE",
+		QueryTypeCall:     "CALL",
 	}
 
 	if label, ok := labels[qt]; ok {
@@ -122,6 +126,59 @@ func IsReadOnlyQueryType(qt QueryType) bool {
 	}
 }
 
+// readOnlyViolations match statements that read, and so pass the read-only
+// prefix check, but lock rows or write files on the server
+var readOnlyViolations = []struct {
+	pattern     *regexp.Regexp
+	description string
+}{
+	{regexp.MustCompile(`(?i)\bFOR\s+(?:UPDATE|SHARE)\b|\bLOCK\s+IN\s+SHARE\s+MODE\b`), "locking reads (FOR UPDATE, FOR SHARE, LOCK IN SHARE MODE)"},
+	{regexp.MustCompile(`(?i)\bINTO\s+(?:OUTFILE|DUMPFILE)\b`), "SELECT ... INTO OUTFILE and INTO DUMPFILE"},
+	{regexp.MustCompile(`(?i)^\s*HANDLER\b`), "HANDLER statements"},
+}
+
+// commentOrLiteralPattern matches, leftmost first, quoted identifiers,
+// string literals and comments, so a comment inside a string is not a
+// comment and a quote inside a comment does not start a string
+var commentOrLiteralPattern = regexp.MustCompile("(?s)`[^`]*`" +
+	`|'(?:[^'\\]|\\.|'')*'|"(?:[^"\\]|\\.|"")*"` +
+	`|/\*.*?\*/|--\s[^\n]*|#[^\n]*`)
+
+// executableCommentPattern matches the opening of a /*! ... */ comment, whose
+// body MySQL runs, with its optional version number
+var executableCommentPattern = regexp.MustCompile(`^/\*!\d*`)
+
+// maskCommentsAndLiterals blanks out string literals like maskLiterals and
+// replaces comments by a space, so keywords split by a comment
+// (FOR/**/UPDATE) are still adjacent. The body of an executable comment
+// (/*! ... */) is kept, since the server runs it.
+func maskCommentsAndLiterals(query string) string {
+	return commentOrLiteralPattern.ReplaceAllStringFunc(query, func(token string) string {
+		switch {
+		case token[0] == '`':
+			return token
+		case token[0] == '\'' || token[0] == '"':
+			return token[:1] + strings.Repeat("x", len(token)-2) + token[:1]
+		case executableCommentPattern.MatchString(token):
+			return " " + strings.TrimSuffix(executableCommentPattern.ReplaceAllString(token, ""), "*/") + " "
+		default:
+			return " "
+		}
+	})
+}
+
+// readOnlyViolation describes the locking or file-writing clause that makes
+// a statement unfit for a read-only connection, or returns "" when it has none
+func readOnlyViolation(query string) string {
+	masked := maskCommentsAndLiterals(query)
+	for _, v := range readOnlyViolations {
+		if v.pattern.MatchString(masked) {
+			return v.description
+		}
+	}
+	return ""
+}
+
 // IsDangerousQueryType returns true if the query type is dangerous (DDL)
 func IsDangerousQueryType(qt QueryType) bool {
 	switch qt {
@@ -131,3 +188,49 @@ func IsDangerousQueryType(qt QueryType) bool {
 		return false
 	}
 }
+
+// TableRef identifies a table referenced by a query
+type TableRef struct {
+	Database string
+	Table    string
+}
+
+// tableRefPattern matches identifiers following FROM, JOIN, UPDATE, INTO and TABLE keywords
+var tableRefPattern = regexp.MustCompile("(?i)\\b(?:FROM|JOIN|UPDATE|INTO|TABLE)\\s+((?:`[^`]+`|[A-Za-z0-9_$]+)(?:\\s*\\.\\s*(?:`[^`]+`|[A-Za-z0-9_$]+))?)")
+
+// ExtractTableRefs returns the tables referenced by a query. This is a best-effort
+// lexical scan: comma-separated FROM lists and derived tables are not fully resolved.
+func ExtractTableRefs(query string) []TableRef {
+	seen := make(map[TableRef]bool)
+	var refs []TableRef
+
+	for _, match := range tableRefPattern.FindAllStringSubmatch(query, -1) {
+		parts := strings.SplitN(match[1], ".", 2)
+		ref := TableRef{Table: unquoteIdentifier(parts[0])}
+		if len(parts) == 2 {
+			ref = TableRef{Database: unquoteIdentifier(parts[0]), Table: unquoteIdentifier(parts[1])}
+		}
+
+		// Skip keywords that can follow FROM/INTO in valid SQL
+		switch strings.ToUpper(ref.Table) {
+		case "SELECT", "DUAL", "OUTFILE", "DUMPFILE":
+			continue
+		}
+
+		if !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+
+	return refs
+}
+
+// unquoteIdentifier strips surrounding whitespace and backticks from an identifier
+func unquoteIdentifier(name string) string {
+	name = strings.TrimSpace(name)
+	if len(name) >= 2 && strings.HasPrefix(name, "`") && strings.HasSuffix(name, "`") {
+		name = strings.ReplaceAll(name[1:len(name)-1], "``", "`")
+	}
+	return name
+}
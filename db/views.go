@@ -0,0 +1,79 @@
+package db
+
+import (
+	"fmt"
+)
+
+// ListViews returns the views in a database
+func (m *Manager) ListViews(connectionName, database string) (*QueryResult, error) {
+	return m.ExecuteQuery(connectionName, `SELECT TABLE_NAME, IS_UPDATABLE, CHECK_OPTION, SECURITY_TYPE, DEFINER
+		FROM information_schema.VIEWS
+		WHERE TABLE_SCHEMA = COALESCE(?, DATABASE())
+		ORDER BY TABLE_NAME`, nullIfEmpty(database))
+}
+
+// DescribeView returns the SHOW CREATE VIEW output and columns of a view
+func (m *Manager) DescribeView(connectionName, database, name string) (map[string]interface{}, error) {
+	target := QualifiedName(database, name)
+
+	create, err := m.ExecuteQuery(connectionName, "SHOW CREATE VIEW "+target)
+	if err != nil {
+		return nil, err
+	}
+	if len(create.Rows) == 0 {
+		return nil, fmt.Errorf("view not found: %s", name)
+	}
+
+	columns, err := m.ExecuteQuery(connectionName, "DESCRIBE "+target)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"name":                 name,
+		"create_statement":     create.Rows[0]["Create View"],
+		"character_set_client": create.Rows[0]["character_set_client"],
+		"collation_connection": create.Rows[0]["collation_connection"],
+		"columns":              columns.Rows,
+	}, nil
+}
+
+// CreateOrReplaceView creates or replaces a view from a SELECT statement.
+// Requires allow_ddl on the connection.
+func (m *Manager) CreateOrReplaceView(connectionName, database, name, selectSQL string) (*WriteResult, error) {
+	db, connConfig, release, err := m.ddlConnection(connectionName, "view management")
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if name == "" {
+		return nil, fmt.Errorf("view name is required")
+	}
+	if err := ValidateQueryType(selectSQL, QueryTypeSelect); err != nil {
+		return nil, fmt.Errorf("view definition must be a SELECT: %w", err)
+	}
+	if isSensitiveQuery(selectSQL) {
+		return nil, fmt.Errorf("access to sensitive MySQL metadata is not allowed")
+	}
+
+	query := fmt.Sprintf("CREATE OR REPLACE VIEW %s AS %s", QualifiedName(database, name), selectSQL)
+	if err := checkBlockedPatterns(connectionName, connConfig, query); err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(query); err != nil {
+		m.recordError(connectionName, query, err)
+		return nil, fmt.Errorf("query execution failed: %w", err)
+	}
+	m.invalidateCache(connectionName)
+
+	return &WriteResult{Warning: riskWarning(connectionName, connConfig)}, nil
+}
+
+// QualifiedName returns a quoted, optionally schema-qualified object name
+func QualifiedName(database, name string) string {
+	if database != "" {
+		return QuoteIdentifier(database) + "." + QuoteIdentifier(name)
+	}
+	return QuoteIdentifier(name)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"mysql-golang-mcp/config"
+)
+
+// starterConnection is the connection written by mysql-mcp init
+type starterConnection struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	User     string `json:"user"`
+	Password string `json:"password"`
+	Database string `json:"database,omitempty"`
+	ReadOnly bool   `json:"read_only"`
+}
+
+// runInit implements `mysql-mcp init`: it asks for one connection's details
+// and writes a starter config to the platform's user config directory, where
+// the server finds it without --config. The password is written as a ${VAR}
+// reference so no secret is stored in the file.
+func runInit(args []string, in io.Reader, out io.Writer) error {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	path := fs.String("path", config.DefaultConfigPath(), "Where to write the config")
+	force := fs.Bool("force", false, "Overwrite an existing config")
+	fs.Parse(args)
+
+	if _, err := os.Stat(*path); err == nil && !*force {
+		return fmt.Errorf("%s already exists; use --force to overwrite it", *path)
+	}
+
+	reader := bufio.NewReader(in)
+	ask := func(prompt, def string) (string, error) {
+		if def != "" {
+			fmt.Fprintf(out, "%s [%s]: ", prompt, def)
+		} else {
+			fmt.Fprintf(out, "%s: ", prompt)
+		}
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		if answer := strings.TrimSpace(line); answer != "" {
+			return answer, nil
+		}
+		return def, nil
+	}
+
+	fmt.Fprintln(out, "Creating a mysql-mcp config with one connection. Press Enter to accept a default.")
+	name, err := ask("Connection name", "local")
+	if err != nil {
+		return err
+	}
+	conn := starterConnection{}
+	if conn.Host, err = ask("Host", "127.0.0.1"); err != nil {
+		return err
+	}
+	port, err := ask("Port", "3306")
+	if err != nil {
+		return err
+	}
+	if conn.Port, err = strconv.Atoi(port); err != nil || conn.Port < 1 || conn.Port > 65535 {
+		return fmt.Errorf("invalid port: %s", port)
+	}
+	if conn.User, err = ask("User", "root"); err != nil {
+		return err
+	}
+	passwordVar, err := ask("Environment variable holding the password", "MYSQL_PASSWORD")
+	if err != nil {
+		return err
+	}
+	passwordVar = strings.Trim(passwordVar, "${}")
+	conn.Password = "${" + passwordVar + "}"
+	if conn.Database, err = ask("Default database (optional)", ""); err != nil {
+		return err
+	}
+	readOnly, err := ask("Read-only (y/n)", "y")
+	if err != nil {
+		return err
+	}
+	conn.ReadOnly = !strings.HasPrefix(strings.ToLower(readOnly), "n")
+
+	data, err := json.MarshalIndent(map[string]interface{}{
+		"connections": map[string]starterConnection{name: conn},
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(*path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(*path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	fmt.Fprintf(out, "\nWrote %s\n", *path)
+	check := "mysql-mcp --check"
+	if *path != config.DefaultConfigPath() {
+		check = fmt.Sprintf("mysql-mcp --config %s --check", *path)
+	}
+	fmt.Fprintf(out, "Set %s before starting the server, then check the connection with:\n  %s\n", passwordVar, check)
+	return nil
+}
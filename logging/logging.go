@@ -0,0 +1,82 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/auth"
+	"mysql-golang-mcp/config"
+	"mysql-golang-mcp/db"
+)
+
+// Setup installs the default structured logger. With no log section configured,
+// log output is discarded: stdout carries the stdio transport and must stay clean.
+// The returned file is nil when logging is disabled; closing it is always safe.
+func Setup(cfg *config.LogConfig) (*RotatingFile, error) {
+	if cfg == nil || cfg.Path == "" {
+		slog.SetDefault(slog.New(slog.NewJSONHandler(io.Discard, nil)))
+		return nil, nil
+	}
+
+	file, err := NewRotatingFile(cfg.Path, int64(cfg.MaxSizeMB)*1024*1024, cfg.MaxFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	slog.SetDefault(slog.New(slog.NewJSONHandler(file, &slog.HandlerOptions{Level: level})))
+	return file, nil
+}
+
+// ToolCallMiddleware logs every tool call with its duration and outcome
+func ToolCallMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := next(ctx, request)
+
+		attrs := []any{
+			"tool", request.Params.Name,
+			"duration_ms", time.Since(start).Milliseconds(),
+		}
+		if connection, ok := request.Params.Arguments["connection"].(string); ok {
+			attrs = append(attrs, "connection", connection)
+		}
+		if id := auth.FromContext(ctx); id != nil {
+			attrs = append(attrs, "client", id.Client, "role", string(id.Role))
+		}
+		if info := db.CallInfoFromContext(ctx); info != nil {
+			attrs = append(attrs, "request_id", info.RequestID)
+		}
+
+		switch {
+		case err != nil:
+			slog.Error("tool call failed", append(attrs, "error", err.Error())...)
+		case result != nil && result.IsError:
+			slog.Warn("tool call returned error", append(attrs, "error", toolResultText(result))...)
+		default:
+			slog.Info("tool call", attrs...)
+		}
+
+		return result, err
+	}
+}
+
+// toolResultText returns the text of the first text content in a tool result
+func toolResultText(result *mcp.CallToolResult) string {
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			return text.Text
+		}
+	}
+	return ""
+}
@@ -1,58 +1,235 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
-
-	"github.com/mark3labs/mcp-go/server"
+	"strings"
 
 	"mysql-golang-mcp/config"
 	"mysql-golang-mcp/db"
-	"mysql-golang-mcp/tools"
+	"mysql-golang-mcp/logging"
+	"mysql-golang-mcp/mcpserver"
+	"mysql-golang-mcp/tracing"
 )
 
-const (
-	serverName    = "mysql-mcp"
-	serverVersion = "1.0.0"
-)
+const usage = `Usage: mysql-mcp [command] [flags]
 
-func main() {
-	// Parse command line flags
-	configPath := flag.String("config", "", "Path to config.json file")
-	flag.Parse()
+Commands:
+  serve            Run the MCP server (default when no command is given)
+  init             Write a starter config to the user config directory
+  validate-config  Validate the config and print it resolved, with secrets redacted
+  check            Connect to every configured connection and report the results
+  query            Run a read-only query: query --connection NAME --sql SQL
+  schema dump      Print CREATE TABLE statements: schema dump --connection NAME
+
+Run mysql-mcp <command> -h for a command's flags.
+`
 
-	// Get config path
-	cfgPath := config.GetConfigPath(*configPath)
+func main() {
+	command, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
 
-	// Load configuration
-	cfg, err := config.LoadConfig(cfgPath)
+	var err error
+	switch command {
+	case "serve":
+		err = runServe(args)
+	case "init":
+		err = runInit(args, os.Stdin, os.Stdout)
+	case "validate-config":
+		err = runValidateConfig(args)
+	case "check":
+		err = runCheck(args)
+	case "query":
+		err = runQuery(args, os.Stdout)
+	case "schema":
+		err = runSchema(args, os.Stdout)
+	case "help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n%s", command, usage)
+		os.Exit(2)
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+}
+
+// configFlags are the --config and --config-dir flags shared by every command
+type configFlags struct {
+	path *string
+	dir  *string
+}
+
+// addConfigFlags registers the config flags on a command's flag set
+func addConfigFlags(fs *flag.FlagSet) *configFlags {
+	return &configFlags{
+		path: fs.String("config", "", "Path to config file (JSON or YAML)"),
+		dir:  fs.String("config-dir", "", "Directory of JSON and YAML config files to merge, instead of --config"),
+	}
+}
+
+// load loads the config from a directory of files or a single file, returning
+// it with the path it was read from
+func (f *configFlags) load() (*config.Config, string, error) {
+	if *f.dir != "" {
+		cfg, err := config.LoadConfigDir(*f.dir)
+		if err != nil {
+			return nil, "", fmt.Errorf("loading config: %w", err)
+		}
+		return cfg, *f.dir, nil
+	}
+
+	path := config.GetConfigPath(*f.path)
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("loading config: %w", err)
+	}
+	return cfg, path, nil
+}
+
+// loadWithLogging loads the config and sets up file logging; the returned
+// file must be closed by the caller
+func (f *configFlags) loadWithLogging() (*config.Config, string, *logging.RotatingFile, error) {
+	cfg, path, err := f.load()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	// Set up file logging (stdout is reserved for the stdio transport)
+	logFile, err := logging.Setup(cfg.Log)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("setting up logging: %w", err)
+	}
+	return cfg, path, logFile, nil
+}
+
+// runServe implements `mysql-mcp serve`, the default command
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	cf := addConfigFlags(fs)
+	transport := fs.String("transport", "stdio", "Transport to serve: stdio or http")
+	check := fs.Bool("check", false, "Connect to every configured connection, report the results, and exit (same as the check command)")
+	validateConfig := fs.Bool("validate-config", false, "Validate the config, print it resolved with secrets redacted, and exit without connecting (same as the validate-config command)")
+	fs.Parse(args)
+
+	if *validateConfig {
+		return validateAndPrint(cf)
+	}
+	if *check {
+		return checkConnections(cf)
+	}
+
+	cfg, cfgPath, logFile, err := cf.loadWithLogging()
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	shutdownTracing, err := tracing.Setup(cfg.Tracing, mcpserver.Version)
+	if err != nil {
+		return fmt.Errorf("setting up tracing: %w", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	// Create the server and its connection manager
+	srv := mcpserver.New(cfg)
+	defer srv.Close()
+
+	if err := srv.ValidateTransport(mcpserver.Transport(*transport)); err != nil {
+		return err
+	}
+
+	// Catch misconfigured connections at boot rather than at the first tool call
+	if cfg.ValidateOnStartup {
+		reportConnectionChecks(srv.Manager().ValidateConnections(), os.Stderr)
+	}
+
+	srv.RegisterAll()
+
+	slog.Info("server starting", "version", mcpserver.Version, "config", cfgPath, "transport", *transport, "connections", len(cfg.Connections))
+	if err := srv.Serve(mcpserver.Transport(*transport)); err != nil {
+		slog.Error("server error", "error", err.Error())
+		return fmt.Errorf("server error: %w", err)
+	}
+	slog.Info("server stopped")
+	return nil
+}
+
+// runValidateConfig implements `mysql-mcp validate-config`
+func runValidateConfig(args []string) error {
+	fs := flag.NewFlagSet("validate-config", flag.ExitOnError)
+	cf := addConfigFlags(fs)
+	fs.Parse(args)
+	return validateAndPrint(cf)
+}
+
+// validateAndPrint loads the config without connecting and prints what it
+// resolved to, with secrets redacted
+func validateAndPrint(cf *configFlags) error {
+	cfg, _, err := cf.load()
+	if err != nil {
+		return err
+	}
+
+	resolved, err := json.MarshalIndent(cfg.Redacted(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("printing config: %w", err)
+	}
+	fmt.Printf("%s\n", resolved)
+	return nil
+}
+
+// runCheck implements `mysql-mcp check`
+func runCheck(args []string) error {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	cf := addConfigFlags(fs)
+	fs.Parse(args)
+	return checkConnections(cf)
+}
+
+// checkConnections validates every connection and fails if any of them fails
+func checkConnections(cf *configFlags) error {
+	cfg, _, logFile, err := cf.loadWithLogging()
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
 
-	// Create connection manager
 	manager := db.NewManager(cfg)
 	defer manager.Close()
 
-	// Create MCP server
-	s := server.NewMCPServer(serverName, serverVersion)
-
-	// Register tools
-	tools.RegisterConnectionsTool(s, manager)
-	tools.RegisterQueryTool(s, manager) // Deprecated, kept for backward compatibility
-	tools.RegisterSchemaTool(s, manager)
-	tools.RegisterIndexesTool(s, manager)
+	if !reportConnectionChecks(manager.ValidateConnections(), os.Stdout) {
+		return fmt.Errorf("one or more connections failed")
+	}
+	return nil
+}
 
-	// Register new segregated tools
-	tools.RegisterReadTool(s, manager)   // mysql_select
-	tools.RegisterWriteTools(s, manager) // mysql_insert, mysql_update, mysql_delete, mysql_alter, mysql_execute
-	tools.RegisterUnsafeTool(s, manager) // mysql_execute_unsafe
+// reportConnectionChecks writes one line per connection check and logs it,
+// returning whether every connection succeeded
+func reportConnectionChecks(checks []db.ConnectionCheck, w io.Writer) bool {
+	allOK := true
+	for _, c := range checks {
+		if !c.OK {
+			allOK = false
+			fmt.Fprintf(w, "FAIL  %s: %s\n", c.Name, c.Error)
+			slog.Error("connection check failed", "connection", c.Name, "error", c.Error)
+			continue
+		}
 
-	// Run with stdio transport
-	if err := server.ServeStdio(s); err != nil {
-		fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
-		os.Exit(1)
+		version := "unknown version"
+		if c.Server != nil {
+			version = c.Server.Flavor + " " + c.Server.Version
+		}
+		fmt.Fprintf(w, "OK    %s: %s (%dms)\n", c.Name, version, c.LatencyMs)
+		slog.Info("connection check passed", "connection", c.Name, "version", version, "latency_ms", c.LatencyMs)
 	}
+	return allOK
 }
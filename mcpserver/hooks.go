@@ -0,0 +1,106 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Hook observes every tool call, built-in or custom, without wrapping each
+// handler. Hooks run after client roles are checked, in the order they were added.
+type Hook interface {
+	// BeforeExecute runs before the tool handler. Returning an error refuses
+	// the call: the client receives the error as the tool result and the
+	// handler is not run.
+	BeforeExecute(ctx context.Context, request mcp.CallToolRequest) error
+
+	// AfterExecute runs after the handler returns a successful result
+	AfterExecute(ctx context.Context, request mcp.CallToolRequest, result *mcp.CallToolResult, duration time.Duration)
+
+	// OnError runs when the handler fails or returns an error result, and
+	// when a hook refuses the call
+	OnError(ctx context.Context, request mcp.CallToolRequest, err error)
+}
+
+// HookFuncs adapts plain functions to a Hook; nil functions are skipped
+type HookFuncs struct {
+	Before func(ctx context.Context, request mcp.CallToolRequest) error
+	After  func(ctx context.Context, request mcp.CallToolRequest, result *mcp.CallToolResult, duration time.Duration)
+	OnErr  func(ctx context.Context, request mcp.CallToolRequest, err error)
+}
+
+// BeforeExecute calls Before when set
+func (h HookFuncs) BeforeExecute(ctx context.Context, request mcp.CallToolRequest) error {
+	if h.Before == nil {
+		return nil
+	}
+	return h.Before(ctx, request)
+}
+
+// AfterExecute calls After when set
+func (h HookFuncs) AfterExecute(ctx context.Context, request mcp.CallToolRequest, result *mcp.CallToolResult, duration time.Duration) {
+	if h.After != nil {
+		h.After(ctx, request, result, duration)
+	}
+}
+
+// OnError calls OnErr when set
+func (h HookFuncs) OnError(ctx context.Context, request mcp.CallToolRequest, err error) {
+	if h.OnErr != nil {
+		h.OnErr(ctx, request, err)
+	}
+}
+
+// Use adds hooks that run around every tool call. Add them before Serve.
+func (s *Server) Use(hooks ...Hook) {
+	s.hooks = append(s.hooks, hooks...)
+}
+
+// hookMiddleware runs the server's hooks around a tool handler
+func (s *Server) hookMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if len(s.hooks) == 0 {
+			return next(ctx, request)
+		}
+
+		for _, hook := range s.hooks {
+			if err := hook.BeforeExecute(ctx, request); err != nil {
+				for _, h := range s.hooks {
+					h.OnError(ctx, request, err)
+				}
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		start := time.Now()
+		result, err := next(ctx, request)
+		duration := time.Since(start)
+
+		failure := err
+		if failure == nil && result != nil && result.IsError {
+			failure = errors.New(resultText(result))
+		}
+		for _, hook := range s.hooks {
+			if failure != nil {
+				hook.OnError(ctx, request, failure)
+			} else {
+				hook.AfterExecute(ctx, request, result, duration)
+			}
+		}
+
+		return result, err
+	}
+}
+
+// resultText returns the text of the first text content in a tool result
+func resultText(result *mcp.CallToolResult) string {
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			return text.Text
+		}
+	}
+	return ""
+}
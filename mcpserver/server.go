@@ -0,0 +1,444 @@
+// Package mcpserver assembles the MySQL MCP server so it can be embedded in
+// other Go programs: build a Server from a config, register the built-in
+// tools and any of your own, and serve it over stdio or HTTP.
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/auth"
+	"mysql-golang-mcp/config"
+	"mysql-golang-mcp/db"
+	"mysql-golang-mcp/logging"
+	"mysql-golang-mcp/tools"
+	"mysql-golang-mcp/tracing"
+)
+
+// Name and Version identify the server to MCP clients
+const (
+	Name    = "mysql-mcp"
+	Version = "1.0.0"
+)
+
+// Transport selects how Serve talks to MCP clients
+type Transport string
+
+const (
+	// TransportStdio serves a single client over stdin/stdout
+	TransportStdio Transport = "stdio"
+
+	// TransportHTTP serves authenticated clients over HTTP (SSE) at
+	// cfg.HTTP.Addr, enforcing each API key's role and connections
+	TransportHTTP Transport = "http"
+)
+
+// Server is a MySQL MCP server: a connection Manager and the MCP server its
+// tools are registered on
+type Server struct {
+	cfg     *config.Config
+	manager *db.Manager
+	mcp     *server.MCPServer
+
+	// transport is set by Serve; roles are only enforced over HTTP
+	transport Transport
+
+	// hooks run around every tool call (see Use)
+	hooks []Hook
+}
+
+// New creates a server for cfg with no tools registered. Call RegisterAll to
+// add the built-in tools, AddTool or MCPServer to add your own, and Use to add
+// hooks. Extra options are passed to the underlying MCP server.
+func New(cfg *config.Config, opts ...server.ServerOption) *Server {
+	s := &Server{
+		cfg:     cfg,
+		manager: db.NewManager(cfg),
+	}
+
+	// Over HTTP every client is authenticated and its role gates which tools
+	// and connections it may use
+	opts = append([]server.ServerOption{
+		server.WithToolHandlerMiddleware(callInfoMiddleware),
+		server.WithToolHandlerMiddleware(s.connectionMiddleware),
+		server.WithToolHandlerMiddleware(tracing.ToolCallMiddleware),
+		server.WithToolHandlerMiddleware(logging.ToolCallMiddleware),
+		server.WithToolHandlerMiddleware(s.roleMiddleware),
+		server.WithToolHandlerMiddleware(s.hookMiddleware),
+		server.WithToolHandlerMiddleware(s.mysqlUserMiddleware),
+		server.WithToolFilter(s.roleFilter),
+		server.WithToolFilter(s.connectionFilter),
+	}, opts...)
+	s.mcp = server.NewMCPServer(Name, Version, opts...)
+	return s
+}
+
+// Manager returns the server's connection manager, for use by custom tools
+func (s *Server) Manager() *db.Manager {
+	return s.manager
+}
+
+// MCPServer returns the underlying MCP server, for registering custom tools,
+// prompts, and resources
+func (s *Server) MCPServer() *server.MCPServer {
+	return s.mcp
+}
+
+// AddTool registers a custom tool. Over HTTP, tools not listed in the role
+// table are only available to admin clients.
+func (s *Server) AddTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	s.mcp.AddTool(tool, handler)
+}
+
+// RegisterAll registers the built-in tools and prompts, leaving out the raw
+// SQL tools when the config sets disable_raw_sql
+func (s *Server) RegisterAll() {
+	m := s.mcp
+	manager := s.manager
+
+	tools.RegisterConnectionsTool(m, manager)
+	tools.RegisterSchemaTool(m, manager)
+	tools.RegisterCatalogTools(m, manager) // get_table_sizes, storage_report, get_column_search, get_charset_info, check_charsets
+	tools.RegisterIndexesTool(m, manager)
+	tools.RegisterExplainErrorTool(m, manager)
+	tools.RegisterModelsTool(m, manager)
+	tools.RegisterProfileTool(m, manager)
+	tools.RegisterSampleTool(m, manager)
+	tools.RegisterDiagnosticsTools(m, manager) // diagnose_locks, get_last_deadlock, show_activity, kill_query, top_queries, binlog_events
+
+	// Register raw SQL tools unless the deployment only allows structured queries
+	if !s.cfg.DisableRawSQL {
+		tools.RegisterQueryTool(m, manager)            // Deprecated, kept for backward compatibility
+		tools.RegisterReadTool(m, manager)             // mysql_select
+		tools.RegisterSuggestQueriesTool(m, manager)   // suggest_queries
+		tools.RegisterFederatedTool(m, manager)        // mysql_select_multi
+		tools.RegisterDiffTool(m, manager)             // diff_queries
+		tools.RegisterLintTool(m, manager)             // lint_query
+		tools.RegisterExplainTool(m, manager)          // mysql_explain
+		tools.RegisterOptimizerTraceTool(m, manager)   // optimizer_trace
+		tools.RegisterRecommendIndexesTool(m, manager) // recommend_indexes
+		tools.RegisterCursorTools(m, manager)          // open_cursor, fetch_cursor, close_cursor
+		tools.RegisterSessionTools(m, manager)         // open_session, create_temp_table, populate_temp_table, session_query, close_session
+		tools.RegisterWriteTools(m, manager)           // mysql_insert, mysql_update, mysql_delete, mysql_alter, mysql_execute, flush_writes
+		tools.RegisterOnlineAlterTool(m, manager)      // online_alter
+		tools.RegisterUnsafeTool(m, manager)           // mysql_execute_unsafe
+		tools.RegisterTransactionTools(m, manager)     // begin_transaction, transaction_execute, commit_transaction, rollback_transaction
+	}
+
+	// Register structured tools
+	tools.RegisterStructuredTools(m, manager) // mysql_select_structured, mysql_update_structured, mysql_delete_structured, mysql_write_by_pk, json_extract
+	tools.RegisterBulkTools(m, manager)       // mysql_insert_rows, generate_test_data, copy_table_data
+	tools.RegisterFulltextTools(m, manager)   // list_fulltext_indexes, search_text
+	tools.RegisterDocumentTools(m, manager)   // list_collections, find_documents
+	tools.RegisterHistoryTool(m, manager)     // row_history
+	tools.RegisterUndoTool(m, manager)        // undo_last_write
+	tools.RegisterApprovalTools(m, manager)   // approve_pending, reject_pending
+
+	// Register schema object tools
+	tools.RegisterRoutineTools(m, manager)   // list_routines, describe_routine, mysql_call
+	tools.RegisterViewTools(m, manager)      // list_views, describe_view, create_or_replace_view
+	tools.RegisterTriggerTools(m, manager)   // list_triggers, get_trigger, create_trigger, drop_trigger
+	tools.RegisterEventTools(m, manager)     // list_events, describe_event
+	tools.RegisterPartitionTools(m, manager) // get_partitions, alter_partitions
+	tools.RegisterCommentTools(m, manager)   // set_table_comment, set_column_comment
+
+	// Register account management tools
+	tools.RegisterUserTools(m, manager) // list_users, create_user, grant_privileges, rotate_password
+
+	// Register the runtime connection tool only when the config allows it
+	if s.cfg.AllowRuntimeConnections {
+		tools.RegisterAddConnectionTool(m, manager) // add_connection
+	}
+
+	// Register prompts for guided workflows
+	tools.RegisterPrompts(m, manager)
+}
+
+// Serve serves MCP clients over the transport until it fails or, for stdio,
+// until the client disconnects. Approval callbacks are served alongside when
+// the config has an approval section.
+func (s *Server) Serve(transport Transport) error {
+	if err := s.ValidateTransport(transport); err != nil {
+		return err
+	}
+	s.transport = transport
+
+	if transport == TransportHTTP {
+		authenticator := auth.NewAuthenticator(s.cfg.HTTP)
+		sse := server.NewSSEServer(s.mcp, server.WithHTTPContextFunc(authenticator.ContextFunc))
+
+		// Approval callbacks authenticate with their per-request token, not an API key
+		mux := http.NewServeMux()
+		mux.Handle("/", authenticator.Middleware(sse))
+		if s.cfg.Approval != nil {
+			mux.Handle("POST /approvals/{id}/{decision}", approvalHandler(s.manager))
+		}
+		httpServer := &http.Server{Addr: s.cfg.HTTP.Addr, Handler: mux}
+		return httpServer.ListenAndServe()
+	}
+
+	// The stdio transport has no HTTP listener, so serve approval callbacks separately
+	if s.cfg.Approval != nil && s.cfg.Approval.CallbackAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("POST /approvals/{id}/{decision}", approvalHandler(s.manager))
+		go func() {
+			if err := http.ListenAndServe(s.cfg.Approval.CallbackAddr, mux); err != nil {
+				slog.Error("approval callback server error", "error", err.Error())
+			}
+		}()
+	}
+
+	return server.ServeStdio(s.mcp)
+}
+
+// ValidateTransport reports whether the config can be served over transport,
+// so callers can fail before connecting
+func (s *Server) ValidateTransport(transport Transport) error {
+	switch transport {
+	case TransportStdio:
+		return nil
+	case TransportHTTP:
+		if s.cfg.HTTP == nil || len(s.cfg.HTTP.APIKeys) == 0 {
+			return fmt.Errorf("the http transport requires at least one entry in http.api_keys")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown transport %q (use stdio or http)", transport)
+	}
+}
+
+// Close rolls back open transactions, closes cursors and sessions, and closes
+// every connection pool
+func (s *Server) Close() {
+	s.manager.Close()
+}
+
+// callInfoMiddleware gives every tool call a request ID and records the
+// call in its context, for the statement_tag comment, logs and traces
+func callInfoMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var client string
+		if id := auth.FromContext(ctx); id != nil {
+			client = id.Client
+		}
+		return next(db.WithCallInfo(ctx, db.NewCallInfo(request.Params.Name, client)), request)
+	}
+}
+
+// noDefaultConnectionTools take no connection argument, or treat a missing
+// one as every connection, so the default connection is never filled in
+var noDefaultConnectionTools = map[string]bool{
+	"list_connections":    true,
+	"add_connection":      true,
+	"remove_connection":   true,
+	"connection_health":   true,
+	"top_queries":         true,
+	"flush_writes":        true,
+	"mysql_select_multi":  true,
+	"diff_queries":        true,
+	"fetch_cursor":        true,
+	"close_cursor":        true,
+	"create_temp_table":   true,
+	"populate_temp_table": true,
+	"session_query":       true,
+	"close_session":       true,
+	"transaction_execute": true,
+}
+
+// connectionParams are the arguments naming a single connection
+var connectionParams = []string{"connection", "left_connection", "right_connection", "source_connection", "target_connection"}
+
+// connectionMiddleware points connection arguments given as aliases at the
+// connections they stand for, and fills in the default connection when a
+// call omits it. It runs before the role checks and logging, so both see
+// the configured connection names. Names containing "@" are refused: those
+// are the connections mysqlUserMiddleware derives for a client's MySQL
+// account, which callers must never name themselves.
+func (s *Server) connectionMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := make(map[string]interface{}, len(request.Params.Arguments)+1)
+		for k, v := range request.Params.Arguments {
+			arguments[k] = v
+		}
+
+		for _, param := range connectionParams {
+			if name, ok := arguments[param].(string); ok && strings.Contains(name, "@") {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid connection name '%s'", name)), nil
+			}
+		}
+		if names, ok := arguments["connections"].([]interface{}); ok {
+			for _, n := range names {
+				if name, ok := n.(string); ok && strings.Contains(name, "@") {
+					return mcp.NewToolResultError(fmt.Sprintf("invalid connection name '%s'", name)), nil
+				}
+			}
+		}
+
+		for _, param := range connectionParams {
+			if name, ok := arguments[param].(string); ok && name != "" {
+				arguments[param] = s.manager.ResolveConnection(name)
+			}
+		}
+		if names, ok := arguments["connections"].([]interface{}); ok {
+			resolved := make([]interface{}, len(names))
+			for i, n := range names {
+				resolved[i] = n
+				if name, ok := n.(string); ok && name != "" {
+					resolved[i] = s.manager.ResolveConnection(name)
+				}
+			}
+			arguments["connections"] = resolved
+		}
+
+		if name, _ := arguments["connection"].(string); name == "" && !noDefaultConnectionTools[request.Params.Name] {
+			if defaultName := s.manager.DefaultConnection(); defaultName != "" {
+				arguments["connection"] = defaultName
+			}
+		}
+
+		request.Params.Arguments = arguments
+		return next(ctx, request)
+	}
+}
+
+// connectionFilter lists the connection argument as optional when there is a
+// default connection, since connectionMiddleware fills it in
+func (s *Server) connectionFilter(ctx context.Context, available []mcp.Tool) []mcp.Tool {
+	if s.manager.DefaultConnection() == "" {
+		return available
+	}
+	filtered := make([]mcp.Tool, len(available))
+	for i, tool := range available {
+		filtered[i] = tool
+		required := make([]string, 0, len(tool.InputSchema.Required))
+		for _, param := range tool.InputSchema.Required {
+			if param != "connection" {
+				required = append(required, param)
+			}
+		}
+		filtered[i].InputSchema.Required = required
+	}
+	return filtered
+}
+
+// roleMiddleware enforces client roles on tool calls when serving over HTTP.
+// The stdio transport is single-client and is not subject to roles.
+func (s *Server) roleMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	checked := auth.ToolMiddleware(next)
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.transport == TransportHTTP {
+			return checked(ctx, request)
+		}
+		return next(ctx, request)
+	}
+}
+
+// connectionManagementTools act on the configured connections themselves,
+// so they are never pointed at a client's own MySQL account
+var connectionManagementTools = map[string]bool{
+	"connection_health":    true,
+	"reset_connection":     true,
+	"reconnect_connection": true,
+	"remove_connection":    true,
+	"rotate_credentials":   true,
+	"rotate_password":      true,
+}
+
+// mysqlUserMiddleware runs the statements of an HTTP client that sent its
+// own MySQL account on that account, by pointing the call's connection
+// arguments at connections logged in as it. It runs after the role checks,
+// which see the configured connection names.
+func (s *Server) mysqlUserMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id := auth.FromContext(ctx)
+		if id == nil || id.MySQLUser == nil || connectionManagementTools[request.Params.Name] {
+			return next(ctx, request)
+		}
+
+		asUser := func(name string) (string, error) {
+			if !id.MySQLUser.AppliesTo(name) {
+				return name, nil
+			}
+			return s.manager.UserConnection(name, id.Client, id.MySQLUser.User, id.MySQLUser.Password)
+		}
+
+		arguments := make(map[string]interface{}, len(request.Params.Arguments))
+		for k, v := range request.Params.Arguments {
+			arguments[k] = v
+		}
+		for _, param := range connectionParams {
+			if name, ok := arguments[param].(string); ok && name != "" {
+				derived, err := asUser(name)
+				if err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+				arguments[param] = derived
+			}
+		}
+
+		// mysql_select_multi defaults to every connection, so name them to
+		// run each as the client's account
+		names, ok := arguments["connections"].([]interface{})
+		if !ok && request.Params.Name == "mysql_select_multi" {
+			for _, name := range s.manager.ConnectionNames() {
+				if id.CanUseConnection(name) {
+					names = append(names, name)
+				}
+			}
+		}
+		if names != nil {
+			derived := make([]interface{}, len(names))
+			for i, n := range names {
+				derived[i] = n
+				if name, ok := n.(string); ok && name != "" {
+					renamed, err := asUser(name)
+					if err != nil {
+						return mcp.NewToolResultError(err.Error()), nil
+					}
+					derived[i] = renamed
+				}
+			}
+			arguments["connections"] = derived
+		}
+
+		request.Params.Arguments = arguments
+		return next(ctx, request)
+	}
+}
+
+// roleFilter hides the tools a client's role cannot call when serving over HTTP
+func (s *Server) roleFilter(ctx context.Context, available []mcp.Tool) []mcp.Tool {
+	if s.transport == TransportHTTP {
+		return auth.ToolFilter(ctx, available)
+	}
+	return available
+}
+
+// approvalHandler serves approval webhook callbacks at
+// POST /approvals/{id}/approve or /reject, authenticated by the token query
+// parameter sent in the webhook payload
+func approvalHandler(manager *db.Manager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		decision := r.PathValue("decision")
+		if decision != "approve" && decision != "reject" {
+			http.NotFound(w, r)
+			return
+		}
+
+		outcome, err := manager.DecideApprovalCallback(r.PathValue("id"), r.URL.Query().Get("token"), decision == "approve")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(outcome)
+	})
+}
@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/auth"
+	"mysql-golang-mcp/db"
+)
+
+// RegisterApprovalTools registers the approve_pending and reject_pending tools
+func RegisterApprovalTools(s *server.MCPServer, manager *db.Manager) {
+	registerDecisionTool(s, manager, "approve_pending",
+		"Approve a statement queued on a connection with require_approval and run it, returning its result. Deciding an already decided statement returns the original outcome. High risk - do not auto-accept.",
+		manager.ApprovePending)
+	registerDecisionTool(s, manager, "reject_pending",
+		"Reject a statement queued on a connection with require_approval so it never runs.",
+		manager.RejectPending)
+}
+
+// registerDecisionTool registers a tool that decides a queued statement by approval ID
+func registerDecisionTool(s *server.MCPServer, manager *db.Manager, name, description string, decide func(approvalID, decidedBy string) (*db.ApprovalOutcome, error)) {
+	tool := mcp.NewTool(name,
+		mcp.WithDescription(description),
+		mcp.WithString("approval_id",
+			mcp.Required(),
+			mcp.Description("Approval ID returned when the statement was queued"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		approvalID, ok := request.Params.Arguments["approval_id"].(string)
+		if !ok || approvalID == "" {
+			return mcp.NewToolResultError("approval_id parameter is required"), nil
+		}
+
+		decidedBy := name
+		if id := auth.FromContext(ctx); id != nil {
+			decidedBy = id.Client
+		}
+
+		outcome, err := decide(approvalID, decidedBy)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", outcome)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
@@ -0,0 +1,297 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// RegisterBulkTools registers the bulk data tools
+func RegisterBulkTools(s *server.MCPServer, manager *db.Manager) {
+	registerInsertRowsTool(s, manager)
+	registerGenerateTestDataTool(s, manager)
+	registerCopyTableDataTool(s, manager)
+}
+
+// registerInsertRowsTool registers the mysql_insert_rows tool
+func registerInsertRowsTool(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("mysql_insert_rows",
+		mcp.WithDescription("Insert many rows into a table using batched multi-row INSERTs. Batch sizes adapt to row width and the server's max_allowed_packet, optionally capped by batch_size. With upsert, existing rows are updated through ON DUPLICATE KEY UPDATE. Sends MCP progress notifications after each batch when the client provides a progress token. Medium risk - consider before auto-accepting."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("table",
+			mcp.Required(),
+			mcp.Description("Table to insert into"),
+		),
+		mcp.WithArray("rows",
+			mcp.Required(),
+			mcp.Description("Rows to insert, as objects mapping column name to value. All rows must have the same columns."),
+			mcp.Items(map[string]any{"type": "object"}),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+		mcp.WithNumber("batch_size",
+			mcp.Description("Maximum rows per INSERT statement (batches also stay under max_allowed_packet)"),
+		),
+		mcp.WithBoolean("upsert",
+			mcp.Description("Update rows that collide on a primary or unique key with ON DUPLICATE KEY UPDATE instead of failing"),
+		),
+		mcp.WithArray("update_columns",
+			mcp.Description("Columns to update on a duplicate key when upsert is true (defaults to every inserted column)"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithBoolean("continue_on_error",
+			mcp.Description("Record a failed batch and continue with the next one instead of stopping (default false)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		table, ok := request.Params.Arguments["table"].(string)
+		if !ok || table == "" {
+			return mcp.NewToolResultError("table parameter is required"), nil
+		}
+
+		rawRows, ok := request.Params.Arguments["rows"].([]interface{})
+		if !ok || len(rawRows) == 0 {
+			return mcp.NewToolResultError("rows parameter is required"), nil
+		}
+
+		rows := make([]map[string]interface{}, 0, len(rawRows))
+		for _, r := range rawRows {
+			row, ok := r.(map[string]interface{})
+			if !ok {
+				return mcp.NewToolResultError("each row must be an object mapping column name to value"), nil
+			}
+			rows = append(rows, row)
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+
+		opts := db.InsertOptions{Context: ctx}
+		if size, ok := request.Params.Arguments["batch_size"].(float64); ok {
+			if size < 1 {
+				return mcp.NewToolResultError("batch_size must be at least 1"), nil
+			}
+			opts.BatchSize = int(size)
+		}
+		opts.Upsert, _ = request.Params.Arguments["upsert"].(bool)
+		if raw, ok := request.Params.Arguments["update_columns"].([]interface{}); ok {
+			if !opts.Upsert {
+				return mcp.NewToolResultError("update_columns requires upsert"), nil
+			}
+			for _, c := range raw {
+				col, ok := c.(string)
+				if !ok || col == "" {
+					return mcp.NewToolResultError("update_columns must be a list of column names"), nil
+				}
+				opts.UpdateColumns = append(opts.UpdateColumns, col)
+			}
+		}
+		opts.ContinueOnError, _ = request.Params.Arguments["continue_on_error"].(bool)
+
+		if progress := progressReporter(ctx, request); progress != nil {
+			opts.Progress = func(done, total int) {
+				progress(float64(done), float64(total), fmt.Sprintf("%d of %d rows", done, total))
+			}
+		}
+
+		insertResult, err := manager.InsertRows(connection, database, table, rows, opts)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", insertResult)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+// registerGenerateTestDataTool registers the generate_test_data tool
+func registerGenerateTestDataTool(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("generate_test_data",
+		mcp.WithDescription("Insert rows of realistic fake data into tables, reading each table's column types, ENUM values, unique indexes and foreign keys. Referenced tables are filled first and foreign keys point at existing rows. Only runs on connections tagged environment dev or staging. Medium risk - consider before auto-accepting."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithArray("tables",
+			mcp.Required(),
+			mcp.Description("Tables to fill; they are filled in foreign key order whatever order they are listed in"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithNumber("rows",
+			mcp.Description(fmt.Sprintf("Rows to insert into each table (default: %d, max: %d)", db.DefaultTestDataRows, db.MaxTestDataRows)),
+		),
+		mcp.WithNumber("seed",
+			mcp.Description("Seed for the generated values, to repeat a run; one is picked and returned when not provided"),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		rawTables, ok := request.Params.Arguments["tables"].([]interface{})
+		if !ok || len(rawTables) == 0 {
+			return mcp.NewToolResultError("tables parameter is required"), nil
+		}
+		tables := make([]string, 0, len(rawTables))
+		for _, t := range rawTables {
+			table, ok := t.(string)
+			if !ok || table == "" {
+				return mcp.NewToolResultError("tables must be a list of table names"), nil
+			}
+			tables = append(tables, table)
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+
+		opts := db.TestDataOptions{Context: ctx}
+		if rows, ok := request.Params.Arguments["rows"].(float64); ok {
+			if rows < 1 {
+				return mcp.NewToolResultError("rows must be at least 1"), nil
+			}
+			opts.Rows = int(rows)
+		}
+		if seed, ok := request.Params.Arguments["seed"].(float64); ok {
+			opts.Seed = int64(seed)
+		}
+
+		testData, err := manager.GenerateTestData(connection, database, tables, opts)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", testData)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+// registerCopyTableDataTool registers the copy_table_data tool
+func registerCopyTableDataTool(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("copy_table_data",
+		mcp.WithDescription("Copy a table's rows from one connection to another, such as a scrubbed copy of a prod table on a dev connection. Rows are read page by page in primary key order and written with batched INSERTs; columns with anonymize rules in the source connection's config are hashed, replaced with fake values or nulled before they are written. Never writes to prod connections. Sends MCP progress notifications after each page when the client provides a progress token. Medium risk - consider before auto-accepting."),
+		mcp.WithString("source_connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("target_connection",
+			mcp.Required(),
+			mcp.Description("Named connection to copy the rows to; must not be a prod connection"),
+		),
+		mcp.WithString("table",
+			mcp.Required(),
+			mcp.Description("Table to copy; it must have a primary key"),
+		),
+		mcp.WithString("target_table",
+			mcp.Description("Table to copy into (defaults to table). Only columns it shares with the source table are copied"),
+		),
+		mcp.WithString("source_database",
+			mcp.Description("Database of the source table (uses the source connection's default if not provided)"),
+		),
+		mcp.WithString("target_database",
+			mcp.Description("Database of the target table (uses the target connection's default if not provided)"),
+		),
+		mcp.WithArray("filters",
+			mcp.Description("Only copy source rows matching these conditions, combined by AND, as {field, op, value} objects"),
+			mcp.Items(filterItems),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum rows to copy (default: all)"),
+		),
+		mcp.WithNumber("batch_size",
+			mcp.Description(fmt.Sprintf("Rows read from the source per page (default: %d, max: %d)", db.DefaultCopyBatchRows, db.MaxCopyBatchRows)),
+		),
+		mcp.WithBoolean("upsert",
+			mcp.Description("Update target rows that collide on a primary or unique key instead of failing (default false)"),
+		),
+		mcp.WithNumber("seed",
+			mcp.Description("Seed for faker values, to repeat a copy; one is picked and returned when not provided"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		source, ok := request.Params.Arguments["source_connection"].(string)
+		if !ok || source == "" {
+			return mcp.NewToolResultError("source_connection parameter is required"), nil
+		}
+
+		target, ok := request.Params.Arguments["target_connection"].(string)
+		if !ok || target == "" {
+			return mcp.NewToolResultError("target_connection parameter is required"), nil
+		}
+
+		table, ok := request.Params.Arguments["table"].(string)
+		if !ok || table == "" {
+			return mcp.NewToolResultError("table parameter is required"), nil
+		}
+
+		filters, err := parseFilters(request.Params.Arguments)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		opts := db.CopyTableOptions{Filters: filters, Context: ctx}
+		opts.TargetTable, _ = request.Params.Arguments["target_table"].(string)
+		opts.SourceDatabase, _ = request.Params.Arguments["source_database"].(string)
+		opts.TargetDatabase, _ = request.Params.Arguments["target_database"].(string)
+		opts.Upsert, _ = request.Params.Arguments["upsert"].(bool)
+		if limit, ok := request.Params.Arguments["limit"].(float64); ok {
+			if limit < 1 {
+				return mcp.NewToolResultError("limit must be at least 1"), nil
+			}
+			opts.Limit = int64(limit)
+		}
+		if size, ok := request.Params.Arguments["batch_size"].(float64); ok {
+			if size < 1 {
+				return mcp.NewToolResultError("batch_size must be at least 1"), nil
+			}
+			opts.BatchSize = int(size)
+		}
+		if seed, ok := request.Params.Arguments["seed"].(float64); ok {
+			opts.Seed = int64(seed)
+		}
+
+		if progress := progressReporter(ctx, request); progress != nil {
+			opts.Progress = func(copied int64) {
+				progress(float64(copied), float64(opts.Limit), fmt.Sprintf("%d rows copied", copied))
+			}
+		}
+
+		copyResult, err := manager.CopyTableData(source, target, table, opts)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", copyResult)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
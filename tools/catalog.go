@@ -0,0 +1,245 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// RegisterCatalogTools registers the information_schema helper tools, which
+// answer common metadata questions without free-form SQL
+func RegisterCatalogTools(s *server.MCPServer, manager *db.Manager) {
+	registerGetTableSizes(s, manager)
+	registerStorageReport(s, manager)
+	registerGetColumnSearch(s, manager)
+	registerGetCharsetInfo(s, manager)
+	registerCheckCharsets(s, manager)
+}
+
+func registerGetTableSizes(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("get_table_sizes",
+		mcp.WithDescription("List the largest tables in a database with estimated row counts and data, index, and total size, largest first. Values are estimates from information_schema.TABLES. Safe for auto-accept in MCP clients."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum tables to return (default: 50)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+
+		limit := 50
+		if l, ok := request.Params.Arguments["limit"].(float64); ok {
+			if l < 1 {
+				return mcp.NewToolResultError("limit must be at least 1"), nil
+			}
+			limit = int(l)
+		}
+
+		queryResult, err := manager.TableSizes(connection, database, limit)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", queryResult)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerStorageReport(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("storage_report",
+		mcp.WithDescription("Summarize storage in one call: data, index and free space and row counts per database and in total, and the largest tables. When the connection has a storage_history_table of periodic size snapshots, each entry also shows its growth over the last growth_days days. Values are estimates from information_schema.TABLES. Safe for auto-accept in MCP clients."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database to report on (default: every database except the system schemas)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum tables to list (default: 50)"),
+		),
+		mcp.WithNumber("growth_days",
+			mcp.Description(fmt.Sprintf("How many days back growth is measured from, using the oldest snapshot in that window (default: %d)", db.DefaultStorageGrowthDays)),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+
+		limit := 50
+		if l, ok := request.Params.Arguments["limit"].(float64); ok {
+			if l < 1 {
+				return mcp.NewToolResultError("limit must be at least 1"), nil
+			}
+			limit = int(l)
+		}
+
+		growthDays := db.DefaultStorageGrowthDays
+		if d, ok := request.Params.Arguments["growth_days"].(float64); ok {
+			if d < 1 {
+				return mcp.NewToolResultError("growth_days must be at least 1"), nil
+			}
+			growthDays = int(d)
+		}
+
+		report, err := manager.StorageReport(connection, database, limit, growthDays)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", report)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerGetColumnSearch(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("get_column_search",
+		mcp.WithDescription("Find which tables contain a column, matching the column name case-insensitively as a substring (or exactly). Useful for locating foreign keys by convention, e.g. every table with a customer_id. Safe for auto-accept in MCP clients."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("column",
+			mcp.Required(),
+			mcp.Description("Column name or part of one to search for"),
+		),
+		mcp.WithBoolean("exact",
+			mcp.Description("Match the whole column name instead of a substring (default: false)"),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+		mcp.WithBoolean("all_databases",
+			mcp.Description("Search every database except the system schemas (default: false)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		column, ok := request.Params.Arguments["column"].(string)
+		if !ok || column == "" {
+			return mcp.NewToolResultError("column parameter is required"), nil
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+		exact, _ := request.Params.Arguments["exact"].(bool)
+		allDatabases, _ := request.Params.Arguments["all_databases"].(bool)
+
+		queryResult, err := manager.SearchColumns(connection, database, column, exact, allDatabases)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", queryResult)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerGetCharsetInfo(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("get_charset_info",
+		mcp.WithDescription("Show character set and collation settings: server and connection defaults, the database default, each table's collation (flagging tables that differ from the database default), and optionally the text columns of one table. Useful for diagnosing \"Illegal mix of collations\" and encoding problems. Safe for auto-accept in MCP clients."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+		mcp.WithString("table",
+			mcp.Description("Table whose column character sets and collations to include"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+		table, _ := request.Params.Arguments["table"].(string)
+
+		info, err := manager.CharsetInfo(connection, database, table)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", info)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerCheckCharsets(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("check_charsets",
+		mcp.WithDescription("Report everything in a database that does not use utf8mb4: the session character sets, the database default, table collations, and text columns. Non-utf8mb4 columns cannot store emoji or most multilingual text, and a latin1 session silently corrupts it. Safe for auto-accept in MCP clients."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+
+		report, err := manager.CheckCharsets(connection, database)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", report)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
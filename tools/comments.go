@@ -0,0 +1,129 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// RegisterCommentTools registers the table and column comment editing tools
+func RegisterCommentTools(s *server.MCPServer, manager *db.Manager) {
+	registerSetTableComment(s, manager)
+	registerSetColumnComment(s, manager)
+}
+
+func registerSetTableComment(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("set_table_comment",
+		mcp.WithDescription("Set the comment of a table, replacing any existing one, to document it in the schema. Returns the previous comment and the ALTER TABLE statement run. Only available on connections with allow_ddl enabled. High risk - do not auto-accept."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("table",
+			mcp.Required(),
+			mcp.Description("Table name"),
+		),
+		mcp.WithString("comment",
+			mcp.Required(),
+			mcp.Description("The new comment; an empty string removes the comment"),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		table, ok := request.Params.Arguments["table"].(string)
+		if !ok || table == "" {
+			return mcp.NewToolResultError("table parameter is required"), nil
+		}
+
+		comment, ok := request.Params.Arguments["comment"].(string)
+		if !ok {
+			return mcp.NewToolResultError("comment parameter is required"), nil
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+
+		change, err := manager.SetTableComment(connection, database, table, comment)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", change)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerSetColumnComment(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("set_column_comment",
+		mcp.WithDescription("Set the comment of a column, replacing any existing one, to document it in the schema. The column's type, nullability, default and other attributes are kept: the ALTER TABLE ... MODIFY COLUMN statement restates the definition from SHOW CREATE TABLE with only the comment changed. Returns the previous comment and the statement run. Only available on connections with allow_ddl enabled. High risk - do not auto-accept."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("table",
+			mcp.Required(),
+			mcp.Description("Table name"),
+		),
+		mcp.WithString("column",
+			mcp.Required(),
+			mcp.Description("Column name"),
+		),
+		mcp.WithString("comment",
+			mcp.Required(),
+			mcp.Description("The new comment; an empty string removes the comment"),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		table, ok := request.Params.Arguments["table"].(string)
+		if !ok || table == "" {
+			return mcp.NewToolResultError("table parameter is required"), nil
+		}
+
+		column, ok := request.Params.Arguments["column"].(string)
+		if !ok || column == "" {
+			return mcp.NewToolResultError("column parameter is required"), nil
+		}
+
+		comment, ok := request.Params.Arguments["comment"].(string)
+		if !ok {
+			return mcp.NewToolResultError("comment parameter is required"), nil
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+
+		change, err := manager.SetColumnComment(connection, database, table, column, comment)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", change)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
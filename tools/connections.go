@@ -2,28 +2,285 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 
+	"mysql-golang-mcp/auth"
 	"mysql-golang-mcp/db"
 )
 
-// RegisterConnectionsTool registers the list_connections tool
+// RegisterConnectionsTool registers the connection listing and management tools
 func RegisterConnectionsTool(s *server.MCPServer, manager *db.Manager) {
 	tool := mcp.NewTool("list_connections",
-		mcp.WithDescription("List all configured database connections with their read-only status"),
+		mcp.WithDescription("List all configured database connections with their read-only status, environment, description, and risk tier"),
 	)
 
 	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		connections := manager.ListConnections()
 
-		result, err := json.MarshalIndent(connections, "", "  ")
+		// Only show HTTP clients the connections they are permitted to use
+		if id := auth.FromContext(ctx); id != nil {
+			permitted := connections[:0]
+			for _, conn := range connections {
+				if name, _ := conn["name"].(string); id.CanUseConnection(name) {
+					permitted = append(permitted, conn)
+				}
+			}
+			connections = permitted
+		}
+
+		result, err := formatResult(manager, "", connections)
 		if err != nil {
 			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
 		}
 
-		return mcp.NewToolResultText(string(result)), nil
+		return mcp.NewToolResultText(result), nil
 	})
+
+	registerConnectionHealthTool(s, manager)
+	registerResetConnectionTool(s, manager)
+	registerReconnectConnectionTool(s, manager)
+	registerRemoveConnectionTool(s, manager)
+	registerRotateCredentialsTool(s, manager)
+}
+
+// registerConnectionHealthTool registers the connection_health tool
+func registerConnectionHealthTool(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("connection_health",
+		mcp.WithDescription("Show each connection's pool: whether it is open, when it was last used, how often it was closed for being idle, and its open, in-use and idle connections, waits, and connections closed for idle time or max lifetime. Pools unused for idle_timeout_seconds are closed and reopened on next use. Safe for auto-accept in MCP clients."),
+		mcp.WithString("connection",
+			mcp.Description("Only report this named connection (default: all)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, _ := request.Params.Arguments["connection"].(string)
+		health, err := manager.PoolHealth(connection)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		// Only show HTTP clients the connections they are permitted to use
+		if id := auth.FromContext(ctx); id != nil {
+			permitted := health[:0]
+			for _, pool := range health {
+				if id.CanUseConnection(pool.Connection) {
+					permitted = append(permitted, pool)
+				}
+			}
+			health = permitted
+		}
+
+		result, err := formatResult(manager, "", health)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+// registerResetConnectionTool registers the reset_connection tool
+func registerResetConnectionTool(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("reset_connection",
+		mcp.WithDescription("Drain and rebuild the connection pool for a single named connection (e.g. after a failover or credential rotation). Queries already running are allowed to finish. Other connections are not affected."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description("The named connection to reset (from config)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		resetResult, err := manager.ResetConnection(connection)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", resetResult)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+// registerReconnectConnectionTool registers the reconnect_connection tool
+func registerReconnectConnectionTool(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("reconnect_connection",
+		mcp.WithDescription("Tear down a stale or misbehaving connection pool and open a new one, without waiting for statements running on it: its pending write batch is committed, open transactions are rolled back, and cursors and sessions are closed. Also restores a connection taken out of service by remove_connection. Use reset_connection to let running statements finish instead. Medium risk - consider before auto-accepting."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description("The named connection to reconnect"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		teardown, err := manager.ReconnectConnection(connection)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", teardown)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+// registerRemoveConnectionTool registers the remove_connection tool
+func registerRemoveConnectionTool(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("remove_connection",
+		mcp.WithDescription("Tear down a connection's pool and take the connection out of service until reconnect_connection restores it: its pending write batch is committed, open transactions are rolled back, and cursors and sessions are closed. A connection added with add_connection is forgotten and deleted from runtime_connections_file. Connections derived from it for clients' MySQL accounts are torn down too. Medium risk - consider before auto-accepting."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description("The named connection to remove"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		teardown, err := manager.RemoveConnection(connection)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", teardown)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+// registerRotateCredentialsTool registers the rotate_credentials tool
+func registerRotateCredentialsTool(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("rotate_credentials",
+		mcp.WithDescription("Re-read the credentials for a named connection from their secret references (environment variables or password_file) and rebuild its pool with the new values. Use after a scheduled password rotation."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description("The named connection whose credentials were rotated (from config)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		rotateResult, err := manager.RotateCredentials(connection)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", rotateResult)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+// RegisterAddConnectionTool registers the add_connection tool, for configs
+// with allow_runtime_connections
+func RegisterAddConnectionTool(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("add_connection",
+		mcp.WithDescription("Connect to a new MySQL database while the server runs: the connection is tested and, if it works, registered under name for every other tool to use. It is kept in memory only, unless persist is set. Arguments are taken literally; ${VAR} references are only allowed for the environment variables the config lists in runtime_connection_env_vars. Medium risk - consider before auto-accepting."),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name tools will use for the connection; must not match an existing connection or alias"),
+		),
+		mcp.WithString("host",
+			mcp.Required(),
+			mcp.Description("MySQL server hostname"),
+		),
+		mcp.WithNumber("port",
+			mcp.Description("MySQL server port (default: 3306)"),
+		),
+		mcp.WithString("user",
+			mcp.Required(),
+			mcp.Description("Database username"),
+		),
+		mcp.WithString("password",
+			mcp.Description("Database password, or a ${VAR} reference to a variable listed in runtime_connection_env_vars"),
+		),
+		mcp.WithString("database",
+			mcp.Required(),
+			mcp.Description("Default database name"),
+		),
+		mcp.WithBoolean("read_only",
+			mcp.Description("Only allow reads on the connection (default: true)"),
+		),
+		mcp.WithBoolean("persist",
+			mcp.Description("Also save the connection to runtime_connections_file, so it is loaded again at startup (default: false)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		rc := db.RuntimeConnection{ReadOnly: true}
+		required := []struct {
+			param string
+			value *string
+		}{{"name", &rc.Name}, {"host", &rc.Host}, {"user", &rc.User}, {"database", &rc.Database}}
+		for _, r := range required {
+			v, ok := request.Params.Arguments[r.param].(string)
+			if !ok || v == "" {
+				return mcp.NewToolResultError(r.param + " parameter is required"), nil
+			}
+			*r.value = v
+		}
+		rc.Password, _ = request.Params.Arguments["password"].(string)
+		if port, ok := request.Params.Arguments["port"].(float64); ok {
+			if port < 1 || port > 65535 {
+				return mcp.NewToolResultError("port must be between 1 and 65535"), nil
+			}
+			rc.Port = int(port)
+		}
+		if readOnly, ok := request.Params.Arguments["read_only"].(bool); ok {
+			rc.ReadOnly = readOnly
+		}
+		persist, _ := request.Params.Arguments["persist"].(bool)
+
+		addResult, err := manager.AddConnection(rc, persist)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", addResult)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+// connectionDescription describes the connection parameter, including each
+// connection's environment and risk tier so models can apply caution per connection
+func connectionDescription(manager *db.Manager) string {
+	description := "The named connection to use (from config). Available: " + manager.ConnectionSummary() + ". Take extra care with high-risk (prod) connections."
+	if name := manager.DefaultConnection(); name != "" {
+		description += " Defaults to " + name + " when omitted."
+	}
+	return description
 }
@@ -0,0 +1,143 @@
+package tools
+
+import (
+	"context"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// maxCursorIdleTimeout caps the per-cursor idle timeout a client may request
+const maxCursorIdleTimeout = time.Hour
+
+// RegisterCursorTools registers the open_cursor, fetch_cursor, and close_cursor tools
+func RegisterCursorTools(s *server.MCPServer, manager *db.Manager) {
+	registerOpenCursor(s, manager)
+	registerFetchCursor(s, manager)
+	registerCloseCursor(s, manager)
+}
+
+func registerOpenCursor(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("open_cursor",
+		mcp.WithDescription("Run a SELECT and keep its result set open on the server so it can be read in batches with fetch_cursor, instead of re-running the query with OFFSET. The cursor holds a connection slot until it is exhausted, closed with close_cursor, or left idle past its timeout. Safe for auto-accept in MCP clients."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("sql",
+			mcp.Required(),
+			mcp.Description("The SELECT query to execute"),
+		),
+		mcp.WithNumber("idle_timeout_seconds",
+			mcp.Description("Close the cursor after this many seconds without a fetch (default: 300, max: 3600)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		sql, ok := request.Params.Arguments["sql"].(string)
+		if !ok || sql == "" {
+			return mcp.NewToolResultError("sql parameter is required"), nil
+		}
+
+		idleTimeout := db.DefaultCursorIdleTimeout
+		if seconds, ok := request.Params.Arguments["idle_timeout_seconds"].(float64); ok {
+			if seconds < 1 {
+				return mcp.NewToolResultError("idle_timeout_seconds must be at least 1"), nil
+			}
+			idleTimeout = min(time.Duration(seconds)*time.Second, maxCursorIdleTimeout)
+		}
+
+		cursorInfo, err := manager.OpenCursor(connection, sql, idleTimeout)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", cursorInfo)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerFetchCursor(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("fetch_cursor",
+		mcp.WithDescription("Fetch the next batch of rows from a cursor opened with open_cursor. has_more reports whether rows remain; the cursor closes automatically after the last batch. Safe for auto-accept in MCP clients."),
+		mcp.WithString("cursor_id",
+			mcp.Required(),
+			mcp.Description("Cursor ID returned by open_cursor"),
+		),
+		mcp.WithNumber("batch_size",
+			mcp.Description("Rows to fetch (default and cap: the connection's max_rows)"),
+		),
+		withOutputFormat(),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cursorID, ok := request.Params.Arguments["cursor_id"].(string)
+		if !ok || cursorID == "" {
+			return mcp.NewToolResultError("cursor_id parameter is required"), nil
+		}
+
+		batchSize := 0
+		if size, ok := request.Params.Arguments["batch_size"].(float64); ok {
+			if size < 1 {
+				return mcp.NewToolResultError("batch_size must be at least 1"), nil
+			}
+			batchSize = int(size)
+		}
+
+		batch, err := manager.FetchCursor(cursorID, batchSize)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		outputFormat, _ := request.Params.Arguments["output_format"].(string)
+		result, err := formatResult(manager, outputFormat, batch)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerCloseCursor(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("close_cursor",
+		mcp.WithDescription("Close a cursor opened with open_cursor and release its connection slot. Safe for auto-accept in MCP clients."),
+		mcp.WithString("cursor_id",
+			mcp.Required(),
+			mcp.Description("Cursor ID returned by open_cursor"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cursorID, ok := request.Params.Arguments["cursor_id"].(string)
+		if !ok || cursorID == "" {
+			return mcp.NewToolResultError("cursor_id parameter is required"), nil
+		}
+
+		if err := manager.CloseCursor(cursorID); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", map[string]interface{}{
+			"cursor_id": cursorID,
+			"closed":    true,
+		})
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
@@ -0,0 +1,268 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// RegisterDiagnosticsTools registers the server diagnostics tools
+func RegisterDiagnosticsTools(s *server.MCPServer, manager *db.Manager) {
+	registerDiagnoseLocks(s, manager)
+	registerGetLastDeadlock(s, manager)
+	registerShowActivity(s, manager)
+	registerKillQuery(s, manager)
+	registerTopQueries(s, manager)
+	registerBinlogEvents(s, manager)
+}
+
+func registerDiagnoseLocks(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("diagnose_locks",
+		mcp.WithDescription("Diagnose InnoDB lock contention: current lock waits, blocking chains from each blocked transaction to its root blocker, and the oldest open transactions. Use when queries hang."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		diagnosis, err := manager.DiagnoseLocks(connection)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", diagnosis)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerGetLastDeadlock(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("get_last_deadlock",
+		mcp.WithDescription("Get the most recent InnoDB deadlock: the transactions involved, their statements, the locks each held and waited for, and which one was rolled back. Use when a write failed with a deadlock error."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		report, err := manager.LastDeadlock(connection)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", report)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerShowActivity(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("show_activity",
+		mcp.WithDescription("Show statements currently running on the server (query, state, duration) from performance_schema.processlist. User and host are redacted unless the connection allows them. Safe alternative to SHOW PROCESSLIST."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithBoolean("include_idle",
+			mcp.Description("Include idle (Sleep) connections (default: false)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum entries to return, longest-running first (default: 50)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		includeIdle, _ := request.Params.Arguments["include_idle"].(bool)
+
+		limit := 50
+		if l, ok := request.Params.Arguments["limit"].(float64); ok && l >= 1 {
+			limit = int(l)
+		}
+
+		activity, err := manager.ShowActivity(connection, includeIdle, limit)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", activity.Rows)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerKillQuery(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("kill_query",
+		mcp.WithDescription("Terminate the statement running on a server thread (KILL QUERY), leaving the connection open. Only available on connections with allow_kill_query enabled. High risk - do not auto-accept."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithNumber("process_id",
+			mcp.Required(),
+			mcp.Description("The process id (from show_activity) whose statement should be killed"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		processID, ok := request.Params.Arguments["process_id"].(float64)
+		if !ok {
+			return mcp.NewToolResultError("process_id parameter is required"), nil
+		}
+
+		if err := manager.KillQuery(connection, int64(processID)); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", map[string]interface{}{
+			"process_id": int64(processID),
+			"killed":     true,
+		})
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerTopQueries(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("top_queries",
+		mcp.WithDescription("Show which statement shapes this server has run most and which are slow. Statements are grouped by fingerprint (literals replaced by ?) with their calls, errors, total/avg/max time, and rows, like performance_schema digests but only for this server, since it started. Use to find the slowest or most repeated queries an agent issues."),
+		mcp.WithString("connection",
+			mcp.Description("Only report statements on this connection (defaults to all)"),
+		),
+		mcp.WithString("order_by",
+			mcp.Description("Sort by total_time (default), avg_time, max_time, calls, errors, or rows"),
+			mcp.Enum(db.TopQueryOrders...),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum fingerprints to return (default: 10)"),
+		),
+		mcp.WithBoolean("reset",
+			mcp.Description("Clear the statistics after reading them, to measure from now on (default: false)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, _ := request.Params.Arguments["connection"].(string)
+		orderBy, _ := request.Params.Arguments["order_by"].(string)
+		reset, _ := request.Params.Arguments["reset"].(bool)
+
+		limit := 10
+		if l, ok := request.Params.Arguments["limit"].(float64); ok {
+			if l < 1 {
+				return mcp.NewToolResultError("limit must be at least 1"), nil
+			}
+			limit = int(l)
+		}
+
+		report, err := manager.TopQueries(connection, orderBy, limit, reset)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", report)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerBinlogEvents(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("binlog_events",
+		mcp.WithDescription("Read the server's binary log to investigate recent writes (SHOW BINLOG EVENTS), a page at a time, or list the binary log files with list_logs (SHOW BINARY LOGS). By default events are read from the start of the file the server is writing now; pass next_position as position to continue. Read-only, but the binary log holds every database's writes, so it is only available on connections with allow_binlog enabled."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithBoolean("list_logs",
+			mcp.Description("List the binary log files with their sizes and the current file and position instead of reading events (default: false)"),
+		),
+		mcp.WithString("log_name",
+			mcp.Description("Binary log file to read, e.g. binlog.000042 (default: the file being written now)"),
+		),
+		mcp.WithNumber("position",
+			mcp.Description("Position of the first event to read, such as a previous page's next_position (default: the start of the file)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum events to return, capped by the connection's max_rows (default: %d)", db.DefaultBinlogEventLimit)),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		var payload interface{}
+		if listLogs, _ := request.Params.Arguments["list_logs"].(bool); listLogs {
+			logs, err := manager.ListBinaryLogs(connection)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			payload = logs
+		} else {
+			logName, _ := request.Params.Arguments["log_name"].(string)
+			position, _ := request.Params.Arguments["position"].(float64)
+
+			limit := db.DefaultBinlogEventLimit
+			if l, ok := request.Params.Arguments["limit"].(float64); ok {
+				if l < 1 {
+					return mcp.NewToolResultError("limit must be at least 1"), nil
+				}
+				limit = int(l)
+			}
+
+			events, err := manager.ReadBinlogEvents(connection, logName, int64(position), limit)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			payload = events
+		}
+
+		result, err := formatResult(manager, "", payload)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// RegisterDiffTool registers the diff_queries tool
+func RegisterDiffTool(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("diff_queries",
+		mcp.WithDescription("Run two SELECT queries (on the same or different connections), match rows by key columns, and return the rows added, removed, and changed between them. Useful for verifying a migration or comparing staging and production data for a specific entity. Only SELECT queries are allowed. Safe for auto-accept in MCP clients."),
+		mcp.WithString("left_connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("left_sql",
+			mcp.Required(),
+			mcp.Description("The SELECT query for the left (baseline) side"),
+		),
+		mcp.WithString("right_connection",
+			mcp.Description("Named connection for the right side (defaults to left_connection)"),
+		),
+		mcp.WithString("right_sql",
+			mcp.Description("The SELECT query for the right side (defaults to left_sql, to compare the same query across connections)"),
+		),
+		mcp.WithArray("key_columns",
+			mcp.Required(),
+			mcp.Description("Columns that identify a row on both sides, e.g. [\"id\"]. Key values must be unique within each result."),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		leftConnection, ok := request.Params.Arguments["left_connection"].(string)
+		if !ok || leftConnection == "" {
+			return mcp.NewToolResultError("left_connection parameter is required"), nil
+		}
+
+		leftSQL, ok := request.Params.Arguments["left_sql"].(string)
+		if !ok || leftSQL == "" {
+			return mcp.NewToolResultError("left_sql parameter is required"), nil
+		}
+
+		rightConnection, _ := request.Params.Arguments["right_connection"].(string)
+		if rightConnection == "" {
+			rightConnection = leftConnection
+		}
+
+		rightSQL, _ := request.Params.Arguments["right_sql"].(string)
+		if rightSQL == "" {
+			rightSQL = leftSQL
+		}
+
+		if rightConnection == leftConnection && rightSQL == leftSQL {
+			return mcp.NewToolResultError("right_connection or right_sql must differ from the left side"), nil
+		}
+
+		// Validate that both are SELECT queries
+		for _, sql := range []string{leftSQL, rightSQL} {
+			if err := db.ValidateQueryType(sql, db.QueryTypeSelect); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		var keyColumns []string
+		raw, _ := request.Params.Arguments["key_columns"].([]interface{})
+		for _, c := range raw {
+			col, ok := c.(string)
+			if !ok || col == "" {
+				return mcp.NewToolResultError("key_columns must be a list of column names"), nil
+			}
+			keyColumns = append(keyColumns, col)
+		}
+		if len(keyColumns) == 0 {
+			return mcp.NewToolResultError("key_columns parameter is required"), nil
+		}
+
+		diff, err := manager.DiffQueries(
+			db.DiffSide{Connection: leftConnection, Query: leftSQL},
+			db.DiffSide{Connection: rightConnection, Query: rightSQL},
+			keyColumns,
+		)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", diff)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
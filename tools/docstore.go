@@ -0,0 +1,143 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// RegisterDocumentTools registers the Document Store collection tools
+func RegisterDocumentTools(s *server.MCPServer, manager *db.Manager) {
+	registerListCollections(s, manager)
+	registerFindDocuments(s, manager)
+}
+
+func registerListCollections(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("list_collections",
+		mcp.WithDescription("List the MySQL Document Store (X DevAPI) collections in a database with their estimated document counts. Collections are tables of JSON documents; query them with find_documents. Safe for auto-accept in MCP clients."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database (schema) name (uses connection default if not provided)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+
+		queryResult, err := manager.ListCollections(connection, database)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", queryResult)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerFindDocuments(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("find_documents",
+		mcp.WithDescription("Find documents in a MySQL Document Store collection and return them as JSON. Filters, sort terms and fields are JSON paths into the document (such as \"address.city\"); values are compared as JSON, so 1 and \"1\" differ. SQL is built and parameterized server-side. Safe for auto-accept in MCP clients."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("collection",
+			mcp.Required(),
+			mcp.Description("Collection to search"),
+		),
+		mcp.WithArray("filters",
+			mcp.Description("Conditions combined with AND, as {field, op, value} objects where field is a JSON path. IN/NOT IN/BETWEEN take a list value; IS NULL matches a missing or null field."),
+			mcp.Items(filterSchema(db.FilterOps)),
+		),
+		mcp.WithArray("fields",
+			mcp.Description("JSON paths to return; each document is reduced to its _id and these fields (defaults to whole documents)"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithArray("order_by",
+			mcp.Description("Sort terms such as \"created_at DESC\" or \"address.city\""),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum documents to return (default: %d, capped at the connection's max_rows)", db.DefaultDocumentLimit)),
+		),
+		mcp.WithNumber("offset",
+			mcp.Description("Documents to skip, for paging (default: 0)"),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database (schema) name (uses connection default if not provided)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		collection, ok := request.Params.Arguments["collection"].(string)
+		if !ok || collection == "" {
+			return mcp.NewToolResultError("collection parameter is required"), nil
+		}
+
+		q := db.DocumentQuery{Collection: collection}
+		q.Database, _ = request.Params.Arguments["database"].(string)
+
+		var err error
+		if q.Filters, err = parseFilters(request.Params.Arguments); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if q.OrderBy, err = parseOrderBy(request.Params.Arguments); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if raw, ok := request.Params.Arguments["fields"].([]interface{}); ok {
+			for _, f := range raw {
+				field, ok := f.(string)
+				if !ok || field == "" {
+					return mcp.NewToolResultError("fields must be a list of JSON paths"), nil
+				}
+				q.Fields = append(q.Fields, field)
+			}
+		}
+		if limit, ok := request.Params.Arguments["limit"].(float64); ok {
+			if limit < 1 {
+				return mcp.NewToolResultError("limit must be at least 1"), nil
+			}
+			q.Limit = int(limit)
+		}
+		if offset, ok := request.Params.Arguments["offset"].(float64); ok {
+			if offset < 0 {
+				return mcp.NewToolResultError("offset must not be negative"), nil
+			}
+			q.Offset = int(offset)
+		}
+
+		opts := db.QueryOptions{Cache: true, Progress: statementProgress(ctx, request), Context: ctx}
+		documents, err := manager.FindDocuments(connection, q, opts)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", documents)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
@@ -0,0 +1,45 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// RegisterExplainErrorTool registers the explain_error tool
+func RegisterExplainErrorTool(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("explain_error",
+		mcp.WithDescription("Explain a MySQL error previously returned by another tool: likely causes, the statement that failed (from recent history), relevant server variables, and suggested next tool calls"),
+		mcp.WithString("error",
+			mcp.Required(),
+			mcp.Description("The MySQL error number (e.g. 1213) or the full error message"),
+		),
+		mcp.WithString("connection",
+			mcp.Description("The named connection the error came from (used to look up history and server variables)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		errorInput, ok := request.Params.Arguments["error"].(string)
+		if !ok || errorInput == "" {
+			return mcp.NewToolResultError("error parameter is required"), nil
+		}
+
+		connection, _ := request.Params.Arguments["connection"].(string)
+
+		explanation, err := manager.ExplainError(connection, errorInput)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", explanation)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
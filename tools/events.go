@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// RegisterEventTools registers the event scheduler inspection tools
+func RegisterEventTools(s *server.MCPServer, manager *db.Manager) {
+	registerListEvents(s, manager)
+	registerDescribeEvent(s, manager)
+}
+
+func registerListEvents(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("list_events",
+		mcp.WithDescription("List scheduled events in a database and whether the event scheduler is running. Events often explain data that changes with no client writing it."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+
+		events, err := manager.ListEvents(connection, database)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", events)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerDescribeEvent(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("describe_event",
+		mcp.WithDescription("Get a scheduled event's schedule, body, last run, and SHOW CREATE EVENT statement"),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("event",
+			mcp.Required(),
+			mcp.Description("Event name"),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		event, ok := request.Params.Arguments["event"].(string)
+		if !ok || event == "" {
+			return mcp.NewToolResultError("event parameter is required"), nil
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+
+		eventInfo, err := manager.DescribeEvent(connection, database, event)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", eventInfo)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// RegisterExplainTool registers the mysql_explain tool
+func RegisterExplainTool(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("mysql_explain",
+		mcp.WithDescription("Show the execution plan of a SELECT query without running it, from EXPLAIN FORMAT=JSON. With format tree or mermaid the plan is rendered as an indented ASCII tree or a Mermaid flowchart, which are easier to read than the JSON for complex joins; full table scans are marked. Only SELECT queries are allowed. Safe for auto-accept in MCP clients."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("sql",
+			mcp.Required(),
+			mcp.Description("The SELECT query to explain"),
+		),
+		mcp.WithString("format",
+			mcp.Description("json (the plan as MySQL returns it), tree (an indented ASCII tree) or mermaid (a Mermaid flowchart). Default: json"),
+			mcp.Enum(db.ExplainFormats...),
+		),
+		withDatabase(),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		sql, ok := request.Params.Arguments["sql"].(string)
+		if !ok || sql == "" {
+			return mcp.NewToolResultError("sql parameter is required"), nil
+		}
+
+		format, _ := request.Params.Arguments["format"].(string)
+		if format == "" {
+			format = "json"
+		}
+		if !slices.Contains(db.ExplainFormats, format) {
+			return mcp.NewToolResultError(fmt.Sprintf("format must be one of %s", strings.Join(db.ExplainFormats, ", "))), nil
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+		plan, err := manager.Explain(connection, database, sql)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		// The renderings are returned as plain text, where JSON would escape their line breaks
+		switch format {
+		case "tree":
+			return mcp.NewToolResultText(plan.Tree()), nil
+		case "mermaid":
+			return mcp.NewToolResultText(plan.Mermaid()), nil
+		}
+
+		result, err := formatResult(manager, "", plan)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+// RegisterOptimizerTraceTool registers the optimizer_trace tool
+func RegisterOptimizerTraceTool(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("optimizer_trace",
+		mcp.WithDescription("Trace how the optimizer planned a SELECT, for tuning beyond what EXPLAIN shows. Enables optimizer_trace on a session, runs EXPLAIN for the query (or the query itself with execute, discarding its rows), and summarizes information_schema.OPTIMIZER_TRACE: the final join order, how many join orders were considered and pruned, and per table the indexes ruled out for range access, the range scans costed, and the access paths weighed while joining, with their rows, cost and whether they were chosen. Only SELECT queries are allowed. Safe for auto-accept in MCP clients."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("sql",
+			mcp.Required(),
+			mcp.Description("The SELECT query to trace"),
+		),
+		mcp.WithBoolean("execute",
+			mcp.Description("Run the query itself instead of EXPLAIN, tracing execution decisions too; rows are read and discarded. Default: false"),
+		),
+		mcp.WithBoolean("include_trace",
+			mcp.Description("Also return the full trace document, which can be large. Default: false"),
+		),
+		withDatabase(),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		sql, ok := request.Params.Arguments["sql"].(string)
+		if !ok || sql == "" {
+			return mcp.NewToolResultError("sql parameter is required"), nil
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+		execute, _ := request.Params.Arguments["execute"].(bool)
+		includeTrace, _ := request.Params.Arguments["include_trace"].(bool)
+		trace, err := manager.OptimizerTrace(connection, database, sql, execute, includeTrace)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", trace)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
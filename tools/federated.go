@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// RegisterFederatedTool registers the mysql_select_multi tool
+func RegisterFederatedTool(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("mysql_select_multi",
+		mcp.WithDescription("Run the same SELECT query against several named connections (or all of them) in parallel and return results keyed by connection. Useful for comparing data across environments or shards. Only SELECT queries are allowed. Safe for auto-accept in MCP clients."),
+		mcp.WithString("sql",
+			mcp.Required(),
+			mcp.Description("The SELECT query to execute on each connection"),
+		),
+		mcp.WithArray("connections",
+			mcp.Description("Named connections to query (from config). Omit to query every configured connection."),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		withOutputFormat(),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		sql, ok := request.Params.Arguments["sql"].(string)
+		if !ok || sql == "" {
+			return mcp.NewToolResultError("sql parameter is required"), nil
+		}
+
+		// Validate that this is a SELECT query
+		if err := db.ValidateQueryType(sql, db.QueryTypeSelect); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		var connections []string
+		if raw, ok := request.Params.Arguments["connections"].([]interface{}); ok {
+			for _, c := range raw {
+				name, ok := c.(string)
+				if !ok || name == "" {
+					return mcp.NewToolResultError("connections must be a list of connection names"), nil
+				}
+				connections = append(connections, name)
+			}
+		}
+
+		results, err := manager.ExecuteQueryMulti(connections, sql)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		outputFormat, _ := request.Params.Arguments["output_format"].(string)
+		result, err := formatResult(manager, outputFormat, results)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
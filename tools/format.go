@@ -0,0 +1,186 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mysql-golang-mcp/config"
+	"mysql-golang-mcp/db"
+)
+
+// withOutputFormat adds the per-call output_format parameter to row-returning tools
+func withOutputFormat() mcp.ToolOption {
+	return mcp.WithString("output_format",
+		mcp.Description("Result rendering: pretty (indented), compact (no whitespace), or columnar (column list plus value arrays, fewest tokens for wide tables). Defaults to the server's output_format setting."),
+		mcp.Enum(config.OutputFormats...),
+	)
+}
+
+// withTokenBudget adds the per-call token_budget parameter to row-returning tools
+func withTokenBudget() mcp.ToolOption {
+	return mcp.WithNumber("token_budget",
+		mcp.Description("Approximate maximum size of the result in LLM tokens. When exceeded, all-NULL and constant columns are dropped, long text cells truncated, and then rows omitted; the elided field reports what was removed."),
+	)
+}
+
+// withParseJSON adds the per-call parse_json parameter to row-returning tools
+func withParseJSON() mcp.ToolOption {
+	return mcp.WithBoolean("parse_json",
+		mcp.Description("Return JSON column values as nested JSON instead of strings (default: false)"),
+	)
+}
+
+// withDistinctRows adds the per-call distinct_rows parameter to SELECT tools
+func withDistinctRows() mcp.ToolOption {
+	return mcp.WithBoolean("distinct_rows",
+		mcp.Description("Remove duplicate rows from the result after it is read, keeping the first of each, and report how many were removed in duplicates_removed. Unlike SELECT DISTINCT it does not change the query or its LIMIT (default: false)"),
+	)
+}
+
+// withIncludePK adds the per-call include_pk parameter to SELECT tools
+func withIncludePK() mcp.ToolOption {
+	return mcp.WithBoolean("include_pk",
+		mcp.Description("Add a _pk field to each row with the table's primary key values, so follow-up writes can target exact rows. Single-table SELECTs only, and the primary key columns must be selected (default: false)"),
+	)
+}
+
+// includePK applies the include_pk argument to the result of a SELECT run with
+// the given default database
+func includePK(manager *db.Manager, arguments map[string]interface{}, connection, database, query string, r *db.QueryResult) {
+	if include, _ := arguments["include_pk"].(bool); include {
+		manager.AnnotatePrimaryKeys(connection, database, query, r)
+	}
+}
+
+// distinctRows applies the distinct_rows argument to a query result
+func distinctRows(arguments map[string]interface{}, r *db.QueryResult) {
+	if distinct, _ := arguments["distinct_rows"].(bool); distinct {
+		r.RemoveDuplicateRows()
+	}
+}
+
+// parseJSON applies the parse_json argument to a query result
+func parseJSON(arguments map[string]interface{}, r *db.QueryResult) {
+	if parse, _ := arguments["parse_json"].(bool); parse {
+		r.ParseJSONColumns()
+	}
+}
+
+// fitTokenBudget applies the token_budget argument to a query result, estimating
+// tokens from its rendering in the given output format
+func fitTokenBudget(manager *db.Manager, format string, arguments map[string]interface{}, r *db.QueryResult) error {
+	budget, ok := arguments["token_budget"].(float64)
+	if !ok {
+		return nil
+	}
+	if budget < 1 {
+		return fmt.Errorf("token_budget must be at least 1")
+	}
+	r.FitTokenBudget(int(budget), func(r *db.QueryResult) int {
+		rendered, _ := formatResult(manager, format, r)
+		return estimateTokens(rendered)
+	})
+	return nil
+}
+
+// estimateTokens approximates the token count of rendered JSON at about four
+// characters per token
+func estimateTokens(rendered string) int {
+	return (len(rendered) + 3) / 4
+}
+
+// formatResult marshals a tool result using the requested output format, or the
+// configured default when format is empty. Columnar applies to query results;
+// other values are rendered compactly in columnar mode.
+func formatResult(manager *db.Manager, format string, v interface{}) (string, error) {
+	if format == "" {
+		format = manager.OutputFormat()
+	}
+	v = forOutput(v, manager.OutputOptions())
+
+	switch format {
+	case "pretty":
+		result, err := json.MarshalIndent(v, "", "  ")
+		return string(result), err
+	case "compact":
+		result, err := json.Marshal(v)
+		return string(result), err
+	case "columnar":
+		switch r := v.(type) {
+		case *db.QueryResult:
+			v = r.Columnar()
+		case *db.CursorBatch:
+			v = r.Columnar()
+		case map[string]*db.FederatedResult:
+			columnar := make(map[string]interface{}, len(r))
+			for name, fr := range r {
+				if fr.Result != nil {
+					columnar[name] = map[string]interface{}{"result": fr.Result.Columnar()}
+				} else {
+					columnar[name] = fr
+				}
+			}
+			v = columnar
+		}
+		result, err := json.Marshal(v)
+		return string(result), err
+	default:
+		return "", fmt.Errorf("output_format must be one of %s", strings.Join(config.OutputFormats, ", "))
+	}
+}
+
+// forOutput renders the row values of results carrying rows per the
+// output options (safe_numbers, geometry_format), without changing the originals
+func forOutput(v interface{}, opts db.OutputOptions) interface{} {
+	switch r := v.(type) {
+	case *db.QueryResult:
+		return r.ForOutput(opts)
+	case *db.CursorInfo:
+		return r.ForOutput(opts)
+	case *db.CursorBatch:
+		return r.ForOutput(opts)
+	case *jsonExtractResult:
+		extract := *r
+		extract.QueryResult = r.QueryResult.ForOutput(opts)
+		return &extract
+	case *db.WriteResult:
+		if r == nil || r.Returning == nil {
+			return r
+		}
+		write := *r
+		write.Returning = r.Returning.ForOutput(opts)
+		return &write
+	case *db.UnsafeResult:
+		unsafe := *r
+		unsafe.QueryResult = r.QueryResult.ForOutput(opts)
+		unsafe.WriteResult, _ = forOutput(r.WriteResult, opts).(*db.WriteResult)
+		return &unsafe
+	case *db.SessionStatementResult:
+		statement := *r
+		statement.Result = r.Result.ForOutput(opts)
+		statement.Write, _ = forOutput(r.Write, opts).(*db.WriteResult)
+		return &statement
+	case *db.TransactionStatementResult:
+		statement := *r
+		statement.Result = r.Result.ForOutput(opts)
+		statement.Write, _ = forOutput(r.Write, opts).(*db.WriteResult)
+		return &statement
+	case *db.CallResult:
+		call := *r
+		call.ResultSets = make([]*db.QueryResult, len(r.ResultSets))
+		for i, rs := range r.ResultSets {
+			call.ResultSets[i] = rs.ForOutput(opts)
+		}
+		return &call
+	case map[string]*db.FederatedResult:
+		rendered := make(map[string]*db.FederatedResult, len(r))
+		for name, fr := range r {
+			rendered[name] = &db.FederatedResult{Result: fr.Result.ForOutput(opts), Error: fr.Error}
+		}
+		return rendered
+	}
+	return v
+}
@@ -0,0 +1,154 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// RegisterFulltextTools registers the list_fulltext_indexes and search_text tools
+func RegisterFulltextTools(s *server.MCPServer, manager *db.Manager) {
+	registerListFulltextIndexesTool(s, manager)
+	registerSearchTextTool(s, manager)
+}
+
+// registerListFulltextIndexesTool registers the list_fulltext_indexes tool
+func registerListFulltextIndexesTool(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("list_fulltext_indexes",
+		mcp.WithDescription("List the FULLTEXT indexes of a database, or of one table, with their columns in index order. Columns with a FULLTEXT index can be searched with search_text instead of a slow LIKE '%term%' scan. Safe for auto-accept in MCP clients."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("table",
+			mcp.Description("Only list the indexes of this table"),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		table, _ := request.Params.Arguments["table"].(string)
+		database, _ := request.Params.Arguments["database"].(string)
+
+		indexes, err := manager.FulltextIndexes(connection, database, table)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", indexes)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+// registerSearchTextTool registers the search_text tool
+func registerSearchTextTool(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("search_text",
+		mcp.WithDescription("Search a table's FULLTEXT index with MATCH ... AGAINST and return the matching rows, most relevant first, with their relevance in _score. Uses the index instead of scanning every row as LIKE '%term%' does; see list_fulltext_indexes for the searchable columns. SQL is built and parameterized server-side. Safe for auto-accept in MCP clients."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("table",
+			mcp.Required(),
+			mcp.Description("Table to search"),
+		),
+		mcp.WithString("query",
+			mcp.Required(),
+			mcp.Description("Text to search for. In boolean mode, operators apply: +word (required), -word (excluded), word* (prefix), \"exact phrase\""),
+		),
+		mcp.WithString("mode",
+			mcp.Description("natural (natural language relevance, the default), boolean (operators in the query), or query_expansion (a second pass with words from the best matches, for short queries)"),
+			mcp.Enum(db.TextSearchModes...),
+		),
+		mcp.WithArray("match_columns",
+			mcp.Description("Columns of the FULLTEXT index to search; MATCH must name all columns of one index. Defaults to the table's only FULLTEXT index"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithArray("columns",
+			mcp.Description("Columns to return (defaults to all)"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithArray("filters",
+			mcp.Description("Further conditions combined with the search by AND, as {field, op, value} objects"),
+			mcp.Items(filterItems),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum rows to return (default: %d, capped at the connection's max_rows)", db.DefaultTextSearchLimit)),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+		withTenant(),
+		mcp.WithBoolean("include_deleted",
+			mcp.Description("Include soft-deleted rows on connections with soft_delete_mode (default: false)"),
+		),
+		withOutputFormat(),
+		withTokenBudget(),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		text, ok := request.Params.Arguments["query"].(string)
+		if !ok || text == "" {
+			return mcp.NewToolResultError("query parameter is required"), nil
+		}
+
+		q, err := parseStructuredQuery(request.Params.Arguments)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if q.Database, err = tenantDatabase(manager, connection, request.Params.Arguments); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		search := db.TextSearch{StructuredQuery: q, Text: text}
+		search.Mode, _ = request.Params.Arguments["mode"].(string)
+		search.IncludeDeleted, _ = request.Params.Arguments["include_deleted"].(bool)
+		if raw, ok := request.Params.Arguments["match_columns"].([]interface{}); ok {
+			for _, c := range raw {
+				col, ok := c.(string)
+				if !ok || col == "" {
+					return mcp.NewToolResultError("match_columns must be a list of column names"), nil
+				}
+				search.MatchColumns = append(search.MatchColumns, col)
+			}
+		}
+
+		opts := db.QueryOptions{MaxRows: q.Limit, Cache: true, Progress: statementProgress(ctx, request), Context: ctx}
+		queryResult, err := manager.SearchText(connection, search, opts)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		outputFormat, _ := request.Params.Arguments["output_format"].(string)
+		if err := fitTokenBudget(manager, outputFormat, request.Params.Arguments, queryResult); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, outputFormat, queryResult)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// RegisterHistoryTool registers the row_history tool
+func RegisterHistoryTool(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("row_history",
+		mcp.WithDescription("Show the prior versions of one row, oldest first, and with at, the row as it was at that time. Versions come from the table's history table under the connection's row_history convention (or its history_table), or from MariaDB system versioning for system-versioned tables. Safe for auto-accept in MCP clients."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("table",
+			mcp.Required(),
+			mcp.Description("Table name"),
+		),
+		mcp.WithObject("key",
+			mcp.Required(),
+			mcp.Description("The row, as an object mapping every primary key column to its value (e.g. {\"id\": 42})"),
+		),
+		mcp.WithString("at",
+			mcp.Description("Point in time to show the row at, such as \"2024-06-01 14:30:00\" or \"2024-06-01\", in the server's session time zone"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum versions to return, the most recent (default: %d)", db.DefaultRowHistoryLimit)),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		table, ok := request.Params.Arguments["table"].(string)
+		if !ok || table == "" {
+			return mcp.NewToolResultError("table parameter is required"), nil
+		}
+
+		key, ok := request.Params.Arguments["key"].(map[string]interface{})
+		if !ok || len(key) == 0 {
+			return mcp.NewToolResultError("key must be an object of primary key column to value"), nil
+		}
+
+		limit := db.DefaultRowHistoryLimit
+		if l, ok := request.Params.Arguments["limit"].(float64); ok {
+			if l < 1 {
+				return mcp.NewToolResultError("limit must be at least 1"), nil
+			}
+			limit = int(l)
+		}
+
+		at, _ := request.Params.Arguments["at"].(string)
+		database, _ := request.Params.Arguments["database"].(string)
+
+		history, err := manager.RowHistory(connection, database, table, key, at, limit)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", history)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
@@ -2,8 +2,6 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -17,7 +15,7 @@ func RegisterIndexesTool(s *server.MCPServer, manager *db.Manager) {
 		mcp.WithDescription("Get indexes for a table including index name, columns, and uniqueness"),
 		mcp.WithString("connection",
 			mcp.Required(),
-			mcp.Description("The named connection to use (from config)"),
+			mcp.Description(connectionDescription(manager)),
 		),
 		mcp.WithString("table",
 			mcp.Required(),
@@ -41,61 +39,66 @@ func RegisterIndexesTool(s *server.MCPServer, manager *db.Manager) {
 
 		database, _ := request.Params.Arguments["database"].(string)
 
-		var query string
-		if database != "" {
-			query = fmt.Sprintf("SHOW INDEX FROM `%s`.`%s`", database, table)
-		} else {
-			query = fmt.Sprintf("SHOW INDEX FROM `%s`", table)
+		indexes, err := manager.GetIndexes(connection, database, table)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		queryResult, err := manager.ExecuteQuery(connection, query)
+		result, err := formatResult(manager, "", indexes)
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
 		}
 
-		// Format indexes in a more readable structure
-		indexMap := make(map[string]map[string]interface{})
-		for _, row := range queryResult.Rows {
-			keyName, _ := row["Key_name"].(string)
-			if keyName == "" {
-				continue
-			}
+		return mcp.NewToolResultText(result), nil
+	})
+}
 
-			if _, exists := indexMap[keyName]; !exists {
-				nonUnique := row["Non_unique"]
-				isUnique := false
-				switch v := nonUnique.(type) {
-				case int64:
-					isUnique = v == 0
-				case string:
-					isUnique = v == "0"
-				}
-
-				indexMap[keyName] = map[string]interface{}{
-					"name":    keyName,
-					"unique":  isUnique,
-					"columns": []string{},
-				}
-			}
+// RegisterRecommendIndexesTool registers the recommend_indexes tool
+func RegisterRecommendIndexesTool(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("recommend_indexes",
+		mcp.WithDescription("Propose CREATE INDEX statements for one or more SELECT queries without running them. Each query's EXPLAIN plan is checked for full scans, full index scans and filesorts, the conditions on those tables are compared with their existing indexes, and each proposed index comes with a rationale and the optimizer's estimate of the rows it saves. The statements are not executed. Only SELECT queries are allowed. Safe for auto-accept in MCP clients."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithArray("queries",
+			mcp.Required(),
+			mcp.Description("The SELECT queries to recommend indexes for; indexes serving several queries are proposed once"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		withDatabase(),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
 
-			colName, _ := row["Column_name"].(string)
-			if colName != "" {
-				cols := indexMap[keyName]["columns"].([]string)
-				indexMap[keyName]["columns"] = append(cols, colName)
+		var queries []string
+		raw, _ := request.Params.Arguments["queries"].([]interface{})
+		for _, q := range raw {
+			query, ok := q.(string)
+			if !ok || query == "" {
+				return mcp.NewToolResultError("queries must be a list of SELECT statements"), nil
 			}
+			queries = append(queries, query)
+		}
+		if len(queries) == 0 {
+			return mcp.NewToolResultError("queries parameter is required"), nil
 		}
 
-		// Convert map to slice
-		indexes := make([]map[string]interface{}, 0, len(indexMap))
-		for _, idx := range indexMap {
-			indexes = append(indexes, idx)
+		database, _ := request.Params.Arguments["database"].(string)
+		advice, err := manager.RecommendIndexes(connection, database, queries)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		result, err := json.MarshalIndent(indexes, "", "  ")
+		result, err := formatResult(manager, "", advice)
 		if err != nil {
 			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
 		}
 
-		return mcp.NewToolResultText(string(result)), nil
+		return mcp.NewToolResultText(result), nil
 	})
 }
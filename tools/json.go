@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// jsonExtractResult is the json_extract response: the rows plus the
+// expression used, so it can be reused in hand-written queries
+type jsonExtractResult struct {
+	*db.QueryResult
+	Path       string `json:"path"`
+	Expression string `json:"expression"`
+	SQL        string `json:"sql"`
+}
+
+// registerJSONExtract registers the json_extract tool
+func registerJSONExtract(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("json_extract",
+		mcp.WithDescription("Extract a path from a JSON column using structured arguments, building the JSON_EXTRACT expression server-side. Returns the extracted values alongside any requested columns, plus the equivalent -> / ->> expression for reuse in mysql_select. Safe for auto-accept in MCP clients."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("table",
+			mcp.Required(),
+			mcp.Description("Table to select from"),
+		),
+		mcp.WithString("column",
+			mcp.Required(),
+			mcp.Description("JSON column to extract from"),
+		),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("JSON path such as $.address.city, $.items[0].sku or $.tags[*]; a path without a leading $ is taken from the document root (address.city)"),
+		),
+		mcp.WithBoolean("unquote",
+			mcp.Description("Return strings unquoted like ->> (default: true). Set false to get JSON values like ->, returned as nested JSON."),
+		),
+		mcp.WithString("alias",
+			mcp.Description("Name of the extracted column in the result (defaults to the path)"),
+		),
+		mcp.WithArray("columns",
+			mcp.Description("Other columns to return with each value, such as the primary key"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithArray("filters",
+			mcp.Description("Conditions combined with AND, as {field, op, value} objects, as in mysql_select_structured"),
+			mcp.Items(filterItems),
+		),
+		mcp.WithArray("order_by",
+			mcp.Description("Sort terms such as \"created_at DESC\" or \"name\""),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum rows to return (capped at the connection's max_rows)"),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+		mcp.WithBoolean("include_deleted",
+			mcp.Description("Include soft-deleted rows on connections with soft_delete_mode (default: false)"),
+		),
+		withOutputFormat(),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		column, ok := request.Params.Arguments["column"].(string)
+		if !ok || column == "" {
+			return mcp.NewToolResultError("column parameter is required"), nil
+		}
+
+		path, ok := request.Params.Arguments["path"].(string)
+		if !ok || path == "" {
+			return mcp.NewToolResultError("path parameter is required"), nil
+		}
+
+		q, err := parseStructuredQuery(request.Params.Arguments)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		extract := db.JSONExtract{StructuredQuery: q, Column: column, Path: path, Unquote: true}
+		if unquote, ok := request.Params.Arguments["unquote"].(bool); ok {
+			extract.Unquote = unquote
+		}
+		extract.Alias, _ = request.Params.Arguments["alias"].(string)
+
+		query, args, err := db.BuildJSONExtract(extract)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		includeDeleted, _ := request.Params.Arguments["include_deleted"].(bool)
+		opts := db.QueryOptions{MaxRows: q.Limit, ExcludeSoftDeleted: !includeDeleted, Progress: statementProgress(ctx, request), Context: ctx}
+		queryResult, err := manager.ExecuteQueryWithOptions(connection, query, opts, args...)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		queryResult.ParseJSONColumns()
+
+		outputFormat, _ := request.Params.Arguments["output_format"].(string)
+		result, err := formatResult(manager, outputFormat, &jsonExtractResult{
+			QueryResult: queryResult,
+			Path:        db.NormalizeJSONPath(path),
+			Expression:  extract.Expression(),
+			SQL:         query,
+		})
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// RegisterLintTool registers the lint_query tool
+func RegisterLintTool(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("lint_query",
+		mcp.WithDescription("Check a SELECT query for common anti-patterns without running it: SELECT *, missing LIMIT, cartesian joins, implicit type conversions on string columns, and functions or leading wildcards that prevent index use. Returns structured findings with suggestions. Only SELECT queries are allowed. Safe for auto-accept in MCP clients."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("sql",
+			mcp.Required(),
+			mcp.Description("The SELECT query to lint"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		sql, ok := request.Params.Arguments["sql"].(string)
+		if !ok || sql == "" {
+			return mcp.NewToolResultError("sql parameter is required"), nil
+		}
+
+		report, err := manager.LintQuery(connection, sql)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", report)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
@@ -0,0 +1,298 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// RegisterModelsTool registers the generate_models tool
+func RegisterModelsTool(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("generate_models",
+		mcp.WithDescription("Generate model definitions from table schemas: Go structs (with db/json tags), TypeScript interfaces, or SQLAlchemy models"),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithArray("tables",
+			mcp.Required(),
+			mcp.Description("Tables to generate models for"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithString("language",
+			mcp.Required(),
+			mcp.Description("Output format"),
+			mcp.Enum("go", "typescript", "sqlalchemy"),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		rawTables, ok := request.Params.Arguments["tables"].([]interface{})
+		if !ok || len(rawTables) == 0 {
+			return mcp.NewToolResultError("tables parameter is required"), nil
+		}
+
+		language, _ := request.Params.Arguments["language"].(string)
+		var generate func(table string, columns []db.ColumnInfo) string
+		switch language {
+		case "go":
+			generate = generateGoStruct
+		case "typescript":
+			generate = generateTypeScriptInterface
+		case "sqlalchemy":
+			generate = generateSQLAlchemyModel
+		default:
+			return mcp.NewToolResultError("language must be one of go, typescript, sqlalchemy"), nil
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+
+		models := make([]string, 0, len(rawTables))
+		for _, t := range rawTables {
+			table, ok := t.(string)
+			if !ok || table == "" {
+				return mcp.NewToolResultError("tables must be a list of table names"), nil
+			}
+
+			columns, err := manager.TableColumns(connection, database, table)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			models = append(models, generate(table, columns))
+		}
+
+		return mcp.NewToolResultText(modelsHeader(language, models) + strings.Join(models, "\n")), nil
+	})
+}
+
+// modelsHeader returns the imports needed by the generated models
+func modelsHeader(language string, models []string) string {
+	body := strings.Join(models, "\n")
+	switch language {
+	case "go":
+		var imports []string
+		if strings.Contains(body, "json.RawMessage") {
+			imports = append(imports, `"encoding/json"`)
+		}
+		if strings.Contains(body, "time.Time") {
+			imports = append(imports, `"time"`)
+		}
+		if len(imports) == 0 {
+			return ""
+		}
+		return "import (\n\t" + strings.Join(imports, "\n\t") + "\n)\n\n"
+	case "sqlalchemy":
+		return "from sqlalchemy import Column, BigInteger, Boolean, Date, DateTime, Enum, Float, Integer, JSON, LargeBinary, Numeric, SmallInteger, String, Text, Time\n" +
+			"from sqlalchemy.orm import declarative_base\n\nBase = declarative_base()\n\n"
+	default:
+		return ""
+	}
+}
+
+// generateGoStruct renders a table as a Go struct with db and json tags
+func generateGoStruct(table string, columns []db.ColumnInfo) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %s maps the %s table\ntype %s struct {\n", pascalCase(table), table, pascalCase(table))
+	for _, col := range columns {
+		goType := goTypeFor(col)
+		if col.Nullable && !strings.HasPrefix(goType, "[]") && goType != "json.RawMessage" {
+			goType = "*" + goType
+		}
+		fmt.Fprintf(&b, "\t%s %s `db:\"%s\" json:\"%s\"`", pascalCase(col.Name), goType, col.Name, col.Name)
+		if col.Comment != "" {
+			fmt.Fprintf(&b, " // %s", col.Comment)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// goTypeFor maps a MySQL column to a Go type
+func goTypeFor(col db.ColumnInfo) string {
+	unsigned := strings.Contains(col.ColumnType, "unsigned")
+	switch col.DataType {
+	case "tinyint":
+		if strings.HasPrefix(col.ColumnType, "tinyint(1)") {
+			return "bool"
+		}
+		if unsigned {
+			return "uint8"
+		}
+		return "int8"
+	case "smallint":
+		if unsigned {
+			return "uint16"
+		}
+		return "int16"
+	case "mediumint", "int", "integer":
+		if unsigned {
+			return "uint32"
+		}
+		return "int32"
+	case "bigint":
+		if unsigned {
+			return "uint64"
+		}
+		return "int64"
+	case "float":
+		return "float32"
+	case "double", "real":
+		return "float64"
+	case "date", "datetime", "timestamp":
+		return "time.Time"
+	case "json":
+		return "json.RawMessage"
+	case "binary", "varbinary", "blob", "tinyblob", "mediumblob", "longblob", "bit":
+		return "[]byte"
+	default:
+		// decimal is kept as string to avoid precision loss
+		return "string"
+	}
+}
+
+// generateTypeScriptInterface renders a table as a TypeScript interface
+func generateTypeScriptInterface(table string, columns []db.ColumnInfo) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "/** Maps the %s table */\nexport interface %s {\n", table, pascalCase(table))
+	for _, col := range columns {
+		tsType := tsTypeFor(col)
+		if col.Nullable {
+			tsType += " | null"
+		}
+		if col.Comment != "" {
+			fmt.Fprintf(&b, "  /** %s */\n", col.Comment)
+		}
+		fmt.Fprintf(&b, "  %s: %s;\n", col.Name, tsType)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// tsTypeFor maps a MySQL column to a TypeScript type
+func tsTypeFor(col db.ColumnInfo) string {
+	switch col.DataType {
+	case "tinyint":
+		if strings.HasPrefix(col.ColumnType, "tinyint(1)") {
+			return "boolean"
+		}
+		return "number"
+	case "smallint", "mediumint", "int", "integer", "float", "double", "real", "year":
+		return "number"
+	case "bigint":
+		// BIGINT may exceed Number.MAX_SAFE_INTEGER
+		return "number | string"
+	case "json":
+		return "unknown"
+	case "enum":
+		return enumValues(col.ColumnType, " | ", "'")
+	default:
+		// decimal, dates, text, and binary are serialized as strings
+		return "string"
+	}
+}
+
+// generateSQLAlchemyModel renders a table as a SQLAlchemy declarative model
+func generateSQLAlchemyModel(table string, columns []db.ColumnInfo) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "class %s(Base):\n    __tablename__ = %q\n\n", pascalCase(table), table)
+	for _, col := range columns {
+		args := []string{sqlAlchemyTypeFor(col)}
+		if col.Key == "PRI" {
+			args = append(args, "primary_key=True")
+		}
+		if strings.Contains(col.Extra, "auto_increment") {
+			args = append(args, "autoincrement=True")
+		}
+		if !col.Nullable && col.Key != "PRI" {
+			args = append(args, "nullable=False")
+		}
+		if col.Comment != "" {
+			args = append(args, fmt.Sprintf("comment=%q", col.Comment))
+		}
+		fmt.Fprintf(&b, "    %s = Column(%s)\n", col.Name, strings.Join(args, ", "))
+	}
+	return b.String()
+}
+
+// sqlAlchemyTypeFor maps a MySQL column to a SQLAlchemy column type
+func sqlAlchemyTypeFor(col db.ColumnInfo) string {
+	switch col.DataType {
+	case "tinyint":
+		if strings.HasPrefix(col.ColumnType, "tinyint(1)") {
+			return "Boolean"
+		}
+		return "SmallInteger"
+	case "smallint":
+		return "SmallInteger"
+	case "mediumint", "int", "integer":
+		return "Integer"
+	case "bigint":
+		return "BigInteger"
+	case "float", "double", "real":
+		return "Float"
+	case "decimal", "numeric":
+		if col.NumericPrecision != nil && col.NumericScale != nil {
+			return fmt.Sprintf("Numeric(%d, %d)", *col.NumericPrecision, *col.NumericScale)
+		}
+		return "Numeric"
+	case "char", "varchar":
+		if col.MaxLength != nil {
+			return fmt.Sprintf("String(%d)", *col.MaxLength)
+		}
+		return "String"
+	case "text", "tinytext", "mediumtext", "longtext":
+		return "Text"
+	case "date":
+		return "Date"
+	case "datetime", "timestamp":
+		return "DateTime"
+	case "time":
+		return "Time"
+	case "json":
+		return "JSON"
+	case "enum":
+		return "Enum(" + enumValues(col.ColumnType, ", ", "'") + ")"
+	case "binary", "varbinary", "blob", "tinyblob", "mediumblob", "longblob", "bit":
+		return "LargeBinary"
+	default:
+		return "String"
+	}
+}
+
+// enumValues extracts the quoted values of an enum('a','b') column type joined by sep
+func enumValues(columnType, sep, quote string) string {
+	inner := strings.TrimSuffix(strings.TrimPrefix(columnType, "enum("), ")")
+	values := strings.Split(inner, ",")
+	for i, v := range values {
+		values[i] = quote + strings.Trim(strings.TrimSpace(v), "'") + quote
+	}
+	return strings.Join(values, sep)
+}
+
+// pascalCase converts snake_case identifiers to PascalCase, upper-casing common initialisms
+func pascalCase(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' || r == ' ' })
+	for i, p := range parts {
+		switch strings.ToLower(p) {
+		case "id", "url", "uuid", "ip", "api", "json", "sql":
+			parts[i] = strings.ToUpper(p)
+		default:
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
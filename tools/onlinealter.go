@@ -0,0 +1,54 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// RegisterOnlineAlterTool registers the online_alter tool
+func RegisterOnlineAlterTool(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("online_alter",
+		mcp.WithDescription("Run an ALTER TABLE through gh-ost or pt-online-schema-change, which copy the table in the background and swap it in, instead of locking it with a native ALTER. Use it for large production tables. Reports copy progress while it runs and returns the tool's output with the impact analysis of the change. Only available on connections with online_schema_change configured. High risk - do not auto-accept."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("sql",
+			mcp.Required(),
+			mcp.Description("The ALTER TABLE statement to run, e.g. ALTER TABLE shop.orders ADD COLUMN note TEXT"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Only validate the change with the tool, without copying or swapping the table (default: false)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		sql, ok := request.Params.Arguments["sql"].(string)
+		if !ok || sql == "" {
+			return mcp.NewToolResultError("sql parameter is required"), nil
+		}
+
+		dryRun, _ := request.Params.Arguments["dry_run"].(bool)
+
+		alterResult, err := manager.OnlineAlter(connection, sql, dryRun, db.WriteOptions{Progress: statementProgress(ctx, request), Context: ctx})
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", alterResult)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
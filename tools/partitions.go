@@ -0,0 +1,135 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// RegisterPartitionTools registers the partition inspection and maintenance tools
+func RegisterPartitionTools(s *server.MCPServer, manager *db.Manager) {
+	registerGetPartitions(s, manager)
+	registerAlterPartitions(s, manager)
+}
+
+func registerGetPartitions(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("get_partitions",
+		mcp.WithDescription("Get a table's partitioning method and expression and each partition's bound, estimated row count, and size, with suggested DROP/ADD PARTITION statements for RANGE partitioning that alter_partitions can run"),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("table",
+			mcp.Required(),
+			mcp.Description("Table name"),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		table, ok := request.Params.Arguments["table"].(string)
+		if !ok || table == "" {
+			return mcp.NewToolResultError("table parameter is required"), nil
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+
+		partitions, err := manager.Partitions(connection, database, table)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", partitions)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerAlterPartitions(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("alter_partitions",
+		mcp.WithDescription("Drop or add partitions of a RANGE or LIST partitioned table. Dropping a partition deletes its rows. Only available on connections with allow_ddl enabled. High risk - do not auto-accept."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("table",
+			mcp.Required(),
+			mcp.Description("Partitioned table name"),
+		),
+		mcp.WithString("action",
+			mcp.Required(),
+			mcp.Description("drop removes partitions and their rows; add creates a partition"),
+			mcp.Enum("drop", "add"),
+		),
+		mcp.WithArray("partitions",
+			mcp.Description("Partitions to drop (action drop)"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithString("partition",
+			mcp.Description("Name of the partition to add (action add)"),
+		),
+		mcp.WithArray("values",
+			mcp.Description("Bound of the partition to add: the LESS THAN value(s) for RANGE (e.g. [739252] or [\"2025-01-01\"]) or the value list for LIST. Use \"MAXVALUE\" for a catch-all RANGE partition."),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		table, ok := request.Params.Arguments["table"].(string)
+		if !ok || table == "" {
+			return mcp.NewToolResultError("table parameter is required"), nil
+		}
+
+		action, ok := request.Params.Arguments["action"].(string)
+		if !ok || action == "" {
+			return mcp.NewToolResultError("action parameter is required"), nil
+		}
+
+		change := db.PartitionChange{Action: action}
+		if raw, ok := request.Params.Arguments["partitions"].([]interface{}); ok {
+			for _, p := range raw {
+				name, ok := p.(string)
+				if !ok || name == "" {
+					return mcp.NewToolResultError("partitions must be a list of partition names"), nil
+				}
+				change.Partitions = append(change.Partitions, name)
+			}
+		}
+		change.Partition, _ = request.Params.Arguments["partition"].(string)
+		change.Values, _ = request.Params.Arguments["values"].([]interface{})
+
+		database, _ := request.Params.Arguments["database"].(string)
+
+		writeResult, err := manager.AlterPartitions(connection, database, table, change)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", writeResult)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
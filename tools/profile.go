@@ -0,0 +1,80 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// RegisterProfileTool registers the profile_table tool
+func RegisterProfileTool(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("profile_table",
+		mcp.WithDescription("Profile a table's data shape in one call: per-column null %, distinct count, min/max, average length, and top 5 values, computed over a bounded sample of rows. Safe for auto-accept in MCP clients."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("table",
+			mcp.Required(),
+			mcp.Description("Table to profile"),
+		),
+		mcp.WithArray("columns",
+			mcp.Description("Columns to profile (defaults to all)"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithNumber("sample_size",
+			mcp.Description("Rows to sample (default: 10000, max: 100000). The first rows in table order are read, not a random sample."),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		table, ok := request.Params.Arguments["table"].(string)
+		if !ok || table == "" {
+			return mcp.NewToolResultError("table parameter is required"), nil
+		}
+
+		var columns []string
+		if raw, ok := request.Params.Arguments["columns"].([]interface{}); ok {
+			for _, c := range raw {
+				col, ok := c.(string)
+				if !ok || col == "" {
+					return mcp.NewToolResultError("columns must be a list of column names"), nil
+				}
+				columns = append(columns, col)
+			}
+		}
+
+		sampleSize := 0
+		if size, ok := request.Params.Arguments["sample_size"].(float64); ok {
+			if size < 1 {
+				return mcp.NewToolResultError("sample_size must be at least 1"), nil
+			}
+			sampleSize = int(size)
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+
+		profile, err := manager.ProfileTable(connection, database, table, columns, sampleSize)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", profile)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
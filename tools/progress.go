@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// progressFunc reports progress on a long-running tool call
+type progressFunc func(progress, total float64, message string)
+
+// progressReporter returns a function that sends MCP progress notifications
+// for the request. Clients opt in by sending a progress token; without one
+// it returns nil.
+func progressReporter(ctx context.Context, request mcp.CallToolRequest) progressFunc {
+	if request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+		return nil
+	}
+	token := request.Params.Meta.ProgressToken
+	srv := server.ServerFromContext(ctx)
+	if srv == nil {
+		return nil
+	}
+
+	return func(progress, total float64, message string) {
+		params := map[string]any{
+			"progressToken": token,
+			"progress":      progress,
+		}
+		if total > 0 {
+			params["total"] = total
+		}
+		if message != "" {
+			params["message"] = message
+		}
+		if err := srv.SendNotificationToClient(ctx, "notifications/progress", params); err != nil {
+			slog.Debug("progress notification failed", "error", err)
+		}
+	}
+}
+
+// statementProgress returns a callback that turns reports on a long-running
+// statement into progress notifications, or nil when the client did not ask
+// for progress. Progress is the elapsed time in seconds since stage work
+// estimates are not available for every stage.
+func statementProgress(ctx context.Context, request mcp.CallToolRequest) func(db.StatementProgress) {
+	progress := progressReporter(ctx, request)
+	if progress == nil {
+		return nil
+	}
+	return func(p db.StatementProgress) {
+		message := fmt.Sprintf("running for %.0fs", float64(p.ElapsedMs)/1000)
+		if p.Stage != "" {
+			message += ", " + p.Stage
+		}
+		if p.WorkEstimated > 0 {
+			message += fmt.Sprintf(" (%d%%)", p.WorkCompleted*100/p.WorkEstimated)
+		}
+		progress(float64(p.ElapsedMs)/1000, 0, message)
+	}
+}
@@ -0,0 +1,239 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/auth"
+	"mysql-golang-mcp/db"
+)
+
+// RegisterPrompts registers MCP prompts for common database workflows
+func RegisterPrompts(s *server.MCPServer, manager *db.Manager) {
+	registerAnalyzeSlowQueryPrompt(s, manager)
+	registerDesignIndexPrompt(s, manager)
+	registerGenerateMigrationPrompt(s, manager)
+}
+
+// registerAnalyzeSlowQueryPrompt registers the analyze_slow_query prompt
+func registerAnalyzeSlowQueryPrompt(s *server.MCPServer, manager *db.Manager) {
+	prompt := mcp.NewPrompt("analyze_slow_query",
+		mcp.WithPromptDescription("Analyze why a query is slow using its EXPLAIN plan, table definitions, and indexes"),
+		mcp.WithArgument("connection",
+			mcp.RequiredArgument(),
+			mcp.ArgumentDescription("The named connection to use (from config)"),
+		),
+		mcp.WithArgument("sql",
+			mcp.RequiredArgument(),
+			mcp.ArgumentDescription("The slow query to analyze"),
+		),
+	)
+
+	s.AddPrompt(prompt, func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		connection := request.Params.Arguments["connection"]
+		sql := request.Params.Arguments["sql"]
+		if connection == "" || sql == "" {
+			return nil, fmt.Errorf("connection and sql arguments are required")
+		}
+		connection, runAs, err := promptConnection(ctx, manager, connection)
+		if err != nil {
+			return nil, err
+		}
+
+		var b strings.Builder
+		b.WriteString("Analyze why the following MySQL query is slow and propose concrete fixes.\n\n")
+		fmt.Fprintf(&b, "Connection: %s\n\nQuery:\n```sql\n%s\n```\n\n", connection, sql)
+		writeExplainContext(&b, manager, runAs, sql)
+		writeSchemaContext(&b, manager, runAs, db.ExtractTableRefs(sql))
+		b.WriteString(`Instructions:
+1. Walk through the EXPLAIN plan and identify full scans, filesorts, temporary tables, and poor join order.
+2. Relate each problem to the table definitions and existing indexes above.
+3. Propose specific fixes (index changes, query rewrites) ordered by expected impact.
+4. Use mysql_select or get_indexes to verify assumptions before recommending writes.`)
+
+		return mcp.NewGetPromptResult("Slow query analysis", []mcp.PromptMessage{
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(b.String())),
+		}), nil
+	})
+}
+
+// registerDesignIndexPrompt registers the design_index_for_query prompt
+func registerDesignIndexPrompt(s *server.MCPServer, manager *db.Manager) {
+	prompt := mcp.NewPrompt("design_index_for_query",
+		mcp.WithPromptDescription("Design the best index for a query given the current table definitions and indexes"),
+		mcp.WithArgument("connection",
+			mcp.RequiredArgument(),
+			mcp.ArgumentDescription("The named connection to use (from config)"),
+		),
+		mcp.WithArgument("sql",
+			mcp.RequiredArgument(),
+			mcp.ArgumentDescription("The query to design an index for"),
+		),
+	)
+
+	s.AddPrompt(prompt, func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		connection := request.Params.Arguments["connection"]
+		sql := request.Params.Arguments["sql"]
+		if connection == "" || sql == "" {
+			return nil, fmt.Errorf("connection and sql arguments are required")
+		}
+		connection, runAs, err := promptConnection(ctx, manager, connection)
+		if err != nil {
+			return nil, err
+		}
+
+		var b strings.Builder
+		b.WriteString("Design the most effective index (or indexes) for the following MySQL query.\n\n")
+		fmt.Fprintf(&b, "Connection: %s\n\nQuery:\n```sql\n%s\n```\n\n", connection, sql)
+		writeExplainContext(&b, manager, runAs, sql)
+		writeSchemaContext(&b, manager, runAs, db.ExtractTableRefs(sql))
+		b.WriteString(`Instructions:
+1. Identify equality predicates, range predicates, join columns, ORDER BY and GROUP BY columns.
+2. Order index columns: equality first, then range/sort, and consider covering indexes.
+3. Check whether an existing index already serves the query or can be extended instead of adding a new one.
+4. Output the exact ALTER TABLE ... ADD INDEX statement(s) with a short rationale and the expected EXPLAIN change.
+5. Do not execute the DDL; present it for review (it can be run with mysql_alter once approved).`)
+
+		return mcp.NewGetPromptResult("Index design", []mcp.PromptMessage{
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(b.String())),
+		}), nil
+	})
+}
+
+// registerGenerateMigrationPrompt registers the generate_migration prompt
+func registerGenerateMigrationPrompt(s *server.MCPServer, manager *db.Manager) {
+	prompt := mcp.NewPrompt("generate_migration",
+		mcp.WithPromptDescription("Generate forward and rollback migration SQL for a described schema change"),
+		mcp.WithArgument("connection",
+			mcp.RequiredArgument(),
+			mcp.ArgumentDescription("The named connection to use (from config)"),
+		),
+		mcp.WithArgument("change",
+			mcp.RequiredArgument(),
+			mcp.ArgumentDescription("Description of the desired schema change"),
+		),
+		mcp.WithArgument("tables",
+			mcp.ArgumentDescription("Comma-separated list of tables affected by the change"),
+		),
+	)
+
+	s.AddPrompt(prompt, func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		connection := request.Params.Arguments["connection"]
+		change := request.Params.Arguments["change"]
+		if connection == "" || change == "" {
+			return nil, fmt.Errorf("connection and change arguments are required")
+		}
+		connection, runAs, err := promptConnection(ctx, manager, connection)
+		if err != nil {
+			return nil, err
+		}
+
+		var refs []db.TableRef
+		for _, name := range strings.Split(request.Params.Arguments["tables"], ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			parts := strings.SplitN(name, ".", 2)
+			if len(parts) == 2 {
+				refs = append(refs, db.TableRef{Database: parts[0], Table: parts[1]})
+			} else {
+				refs = append(refs, db.TableRef{Table: name})
+			}
+		}
+
+		var b strings.Builder
+		b.WriteString("Generate a MySQL migration for the following schema change.\n\n")
+		fmt.Fprintf(&b, "Connection: %s\n\nRequested change:\n%s\n\n", connection, change)
+		writeSchemaContext(&b, manager, runAs, refs)
+		b.WriteString(`Instructions:
+1. Produce an "up" migration and a matching "down" (rollback) migration.
+2. Preserve existing data; include backfill statements where new NOT NULL columns are added.
+3. Call out statements that lock or copy large tables, and prefer ALGORITHM=INSTANT/INPLACE where possible.
+4. Do not execute the migration; present it for review (ALTER statements can be run with mysql_alter once approved).`)
+
+		return mcp.NewGetPromptResult("Migration generation", []mcp.PromptMessage{
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(b.String())),
+		}), nil
+	})
+}
+
+// promptConnection resolves a prompt's connection argument the way the tool
+// middleware does for tool calls, since prompts bypass it: aliases are
+// resolved, the client's grants are checked, and statements run as the
+// client's own MySQL account when it sent one. It returns the configured
+// name and the name of the connection to run statements on.
+func promptConnection(ctx context.Context, manager *db.Manager, name string) (string, string, error) {
+	if strings.Contains(name, "@") {
+		return "", "", fmt.Errorf("invalid connection name '%s'", name)
+	}
+	name = manager.ResolveConnection(name)
+	if err := auth.CheckConnection(ctx, name); err != nil {
+		return "", "", err
+	}
+	id := auth.FromContext(ctx)
+	if id == nil || id.MySQLUser == nil || !id.MySQLUser.AppliesTo(name) {
+		return name, name, nil
+	}
+	runAs, err := manager.UserConnection(name, id.Client, id.MySQLUser.User, id.MySQLUser.Password)
+	if err != nil {
+		return "", "", err
+	}
+	return name, runAs, nil
+}
+
+// writeExplainContext appends the EXPLAIN plan for a SELECT query to the prompt
+func writeExplainContext(b *strings.Builder, manager *db.Manager, connection, sql string) {
+	if db.DetectQueryType(sql) != db.QueryTypeSelect {
+		b.WriteString("EXPLAIN plan: not available (only SELECT queries are explained)\n\n")
+		return
+	}
+
+	explain, err := manager.ExecuteQuery(connection, "EXPLAIN "+sql)
+	if err != nil {
+		fmt.Fprintf(b, "EXPLAIN plan: unavailable (%s)\n\n", err.Error())
+		return
+	}
+
+	plan, err := json.MarshalIndent(explain.Rows, "", "  ")
+	if err != nil {
+		fmt.Fprintf(b, "EXPLAIN plan: unavailable (%s)\n\n", err.Error())
+		return
+	}
+	fmt.Fprintf(b, "EXPLAIN plan:\n```json\n%s\n```\n\n", plan)
+}
+
+// writeSchemaContext appends SHOW CREATE TABLE output for each referenced table to the prompt
+func writeSchemaContext(b *strings.Builder, manager *db.Manager, connection string, refs []db.TableRef) {
+	if len(refs) == 0 {
+		b.WriteString("Table definitions: no tables could be identified; use describe_table to inspect them.\n\n")
+		return
+	}
+
+	b.WriteString("Table definitions:\n")
+	for _, ref := range refs {
+		target := db.QualifiedName(ref.Database, ref.Table)
+
+		queryResult, err := manager.ExecuteQuery(connection, "SHOW CREATE TABLE "+target)
+		if err != nil {
+			fmt.Fprintf(b, "- %s: unavailable (%s)\n", target, err.Error())
+			continue
+		}
+		if len(queryResult.Rows) == 0 {
+			fmt.Fprintf(b, "- %s: not found\n", target)
+			continue
+		}
+
+		ddl, _ := queryResult.Rows[0]["Create Table"].(string)
+		if ddl == "" {
+			ddl, _ = queryResult.Rows[0]["Create View"].(string)
+		}
+		fmt.Fprintf(b, "```sql\n%s\n```\n", ddl)
+	}
+	b.WriteString("\n")
+}
@@ -2,7 +2,6 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -28,12 +27,16 @@ This tool is deprecated. Please use the specific tools instead:
 For read-only connections, only SELECT/SHOW/DESCRIBE/EXPLAIN queries are allowed.`),
 		mcp.WithString("connection",
 			mcp.Required(),
-			mcp.Description("The named connection to use (from config)"),
+			mcp.Description(connectionDescription(manager)),
 		),
 		mcp.WithString("sql",
 			mcp.Required(),
 			mcp.Description("The SQL query to execute"),
 		),
+		mcp.WithNumber("max_rows",
+			mcp.Description("Maximum rows to return (capped at the connection's max_rows). Use a small value for previews."),
+		),
+		withOutputFormat(),
 	)
 
 	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -47,16 +50,25 @@ For read-only connections, only SELECT/SHOW/DESCRIBE/EXPLAIN queries are allowed
 			return mcp.NewToolResultError("sql parameter is required"), nil
 		}
 
-		queryResult, err := manager.ExecuteQuery(connection, sql)
+		opts := db.QueryOptions{Progress: statementProgress(ctx, request), Context: ctx}
+		if maxRows, ok := request.Params.Arguments["max_rows"].(float64); ok {
+			if maxRows < 1 {
+				return mcp.NewToolResultError("max_rows must be at least 1"), nil
+			}
+			opts.MaxRows = int(maxRows)
+		}
+
+		queryResult, err := manager.ExecuteQueryWithOptions(connection, sql, opts)
 		if err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		result, err := json.MarshalIndent(queryResult, "", "  ")
+		outputFormat, _ := request.Params.Arguments["output_format"].(string)
+		result, err := formatResult(manager, outputFormat, queryResult)
 		if err != nil {
 			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
 		}
 
-		return mcp.NewToolResultText(string(result)), nil
+		return mcp.NewToolResultText(result), nil
 	})
 }
@@ -2,7 +2,6 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -13,15 +12,35 @@ import (
 // RegisterReadTool registers the mysql_select tool for read operations
 func RegisterReadTool(s *server.MCPServer, manager *db.Manager) {
 	tool := mcp.NewTool("mysql_select",
-		mcp.WithDescription("Execute a SELECT query against the MySQL database. Only SELECT queries are allowed. Safe for auto-accept in MCP clients."),
+		mcp.WithDescription("Execute a SELECT query against the MySQL database. Only SELECT queries are allowed. On connections with lint_selects, the result includes lint findings. Safe for auto-accept in MCP clients."),
 		mcp.WithString("connection",
 			mcp.Required(),
-			mcp.Description("The named connection to use (from config)"),
+			mcp.Description(connectionDescription(manager)),
 		),
 		mcp.WithString("sql",
 			mcp.Required(),
 			mcp.Description("The SELECT query to execute"),
 		),
+		mcp.WithNumber("max_rows",
+			mcp.Description("Maximum rows to return (capped at the connection's max_rows). Use a small value for previews."),
+		),
+		mcp.WithBoolean("include_deleted",
+			mcp.Description("Include soft-deleted rows on connections with soft_delete_mode (default: false)"),
+		),
+		mcp.WithBoolean("raw",
+			mcp.Description("Return each value exactly as the driver delivered it: Go type, byte length, NULL flag, hex bytes, and text when valid UTF-8, plus each column's scan type. For debugging charset/encoding issues (default: false)"),
+		),
+		mcp.WithString("paginate_by",
+			mcp.Description("Page through the result by keyset instead of OFFSET: a unique, non-NULL column of the select list, optionally followed by ASC or DESC. It replaces the query's ORDER BY, and its LIMIT (or max_rows) is the page size. A LIMIT ... OFFSET is converted to the key of the row before the page. The result's page.next_key is the after value for the next page"),
+		),
+		withAfter(),
+		withDatabase(),
+		withTenant(),
+		withDistinctRows(),
+		withIncludePK(),
+		withParseJSON(),
+		withOutputFormat(),
+		withTokenBudget(),
 	)
 
 	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -40,16 +59,61 @@ func RegisterReadTool(s *server.MCPServer, manager *db.Manager) {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		queryResult, err := manager.ExecuteQuery(connection, sql)
+		includeDeleted, _ := request.Params.Arguments["include_deleted"].(bool)
+		opts := db.QueryOptions{ExcludeSoftDeleted: !includeDeleted, Lint: true, Cache: true, Learn: true, Progress: statementProgress(ctx, request), Context: ctx}
+		var err error
+		if opts.Database, err = tenantDatabase(manager, connection, request.Params.Arguments); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		opts.Tenant = tenantArgument(request.Params.Arguments)
+		opts.Raw, _ = request.Params.Arguments["raw"].(bool)
+		if include, _ := request.Params.Arguments["include_pk"].(bool); include && opts.Raw {
+			return mcp.NewToolResultError("include_pk cannot be combined with raw"), nil
+		}
+		if maxRows, ok := request.Params.Arguments["max_rows"].(float64); ok {
+			if maxRows < 1 {
+				return mcp.NewToolResultError("max_rows must be at least 1"), nil
+			}
+			opts.MaxRows = int(maxRows)
+		}
+
+		var queryResult *db.QueryResult
+		if paginateBy, _ := request.Params.Arguments["paginate_by"].(string); paginateBy != "" {
+			if opts.Raw {
+				return mcp.NewToolResultError("paginate_by cannot be combined with raw"), nil
+			}
+			queryResult, err = manager.SelectPage(connection, sql, paginateBy, request.Params.Arguments["after"], opts)
+		} else if request.Params.Arguments["after"] != nil {
+			return mcp.NewToolResultError("after requires paginate_by"), nil
+		} else {
+			queryResult, err = manager.ExecuteQueryWithOptions(connection, sql, opts)
+		}
 		if err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
+		distinctRows(request.Params.Arguments, queryResult)
+		includePK(manager, request.Params.Arguments, connection, opts.Database, sql, queryResult)
+		parseJSON(request.Params.Arguments, queryResult)
+
+		outputFormat, _ := request.Params.Arguments["output_format"].(string)
+		if err := fitTokenBudget(manager, outputFormat, request.Params.Arguments, queryResult); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
 
-		result, err := json.MarshalIndent(queryResult, "", "  ")
+		result, err := formatResult(manager, outputFormat, queryResult)
 		if err != nil {
 			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
 		}
 
-		return mcp.NewToolResultText(string(result)), nil
+		return mcp.NewToolResultText(result), nil
 	})
 }
+
+// withAfter adds the after parameter, which takes a paginate_by key of any type
+func withAfter() mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		t.InputSchema.Properties["after"] = map[string]any{
+			"description": "With paginate_by, return the rows after this key: the page.next_key of the previous page",
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// RegisterRoutineTools registers the stored procedure and function tools
+func RegisterRoutineTools(s *server.MCPServer, manager *db.Manager) {
+	registerListRoutines(s, manager)
+	registerDescribeRoutine(s, manager)
+	registerCallTool(s, manager)
+}
+
+func registerListRoutines(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("list_routines",
+		mcp.WithDescription("List stored procedures and functions in a database"),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+
+		queryResult, err := manager.ListRoutines(connection, database)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", queryResult.Rows)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerDescribeRoutine(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("describe_routine",
+		mcp.WithDescription("Get the definition, characteristics, and parameters of a stored procedure or function"),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Procedure or function name"),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		name, ok := request.Params.Arguments["name"].(string)
+		if !ok || name == "" {
+			return mcp.NewToolResultError("name parameter is required"), nil
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+
+		routine, err := manager.DescribeRoutine(connection, database, name)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", routine)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+// registerCallTool registers the mysql_call tool
+func registerCallTool(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("mysql_call",
+		mcp.WithDescription("Invoke a stored procedure with IN parameters and return all of its result sets. Procedures may modify data, so this is not allowed on read-only connections. High risk - do not auto-accept."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("procedure",
+			mcp.Required(),
+			mcp.Description("Stored procedure name"),
+		),
+		mcp.WithArray("params",
+			mcp.Description("Positional IN parameter values, in declaration order"),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		procedure, ok := request.Params.Arguments["procedure"].(string)
+		if !ok || procedure == "" {
+			return mcp.NewToolResultError("procedure parameter is required"), nil
+		}
+
+		params, _ := request.Params.Arguments["params"].([]interface{})
+		database, _ := request.Params.Arguments["database"].(string)
+
+		callResult, err := manager.CallProcedure(connection, database, procedure, params)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", callResult)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// RegisterSampleTool registers the sample_representative tool
+func RegisterSampleTool(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("sample_representative",
+		mcp.WithDescription("Get a stratified sample of a table: a few rows from each of the most frequent values of a column, or from each of the most recent time buckets of a date column, with every group's row count and share of the table. Gives a truer picture of the data than LIMIT N for the same row budget. Safe for auto-accept in MCP clients."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("table",
+			mcp.Required(),
+			mcp.Description("Table to sample"),
+		),
+		mcp.WithString("column",
+			mcp.Required(),
+			mcp.Description("Column whose distinct values (or time buckets, with time_bucket) form the groups, e.g. status or created_at"),
+		),
+		mcp.WithString("time_bucket",
+			mcp.Description("Group a DATE, DATETIME or TIMESTAMP column by period instead of by value; the most recent buckets are sampled"),
+			mcp.Enum("hour", "day", "week", "month", "year"),
+		),
+		mcp.WithNumber("per_group",
+			mcp.Description("Rows to return from each group (default: 3, max: 20)"),
+		),
+		mcp.WithNumber("max_groups",
+			mcp.Description("Groups to sample (default: 10, max: 50); rows in the remaining groups are counted in other_rows"),
+		),
+		mcp.WithArray("columns",
+			mcp.Description("Columns to return for each row (defaults to all)"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		table, ok := request.Params.Arguments["table"].(string)
+		if !ok || table == "" {
+			return mcp.NewToolResultError("table parameter is required"), nil
+		}
+
+		column, ok := request.Params.Arguments["column"].(string)
+		if !ok || column == "" {
+			return mcp.NewToolResultError("column parameter is required"), nil
+		}
+
+		opts := db.SampleOptions{Column: column}
+		opts.TimeBucket, _ = request.Params.Arguments["time_bucket"].(string)
+
+		if perGroup, ok := request.Params.Arguments["per_group"].(float64); ok {
+			if perGroup < 1 {
+				return mcp.NewToolResultError("per_group must be at least 1"), nil
+			}
+			opts.PerGroup = int(perGroup)
+		}
+
+		if maxGroups, ok := request.Params.Arguments["max_groups"].(float64); ok {
+			if maxGroups < 1 {
+				return mcp.NewToolResultError("max_groups must be at least 1"), nil
+			}
+			opts.MaxGroups = int(maxGroups)
+		}
+
+		if raw, ok := request.Params.Arguments["columns"].([]interface{}); ok {
+			for _, c := range raw {
+				col, ok := c.(string)
+				if !ok || col == "" {
+					return mcp.NewToolResultError("columns must be a list of column names"), nil
+				}
+				opts.Columns = append(opts.Columns, col)
+			}
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+
+		sample, err := manager.SampleRepresentative(connection, database, table, opts)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", sample)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
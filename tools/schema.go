@@ -2,7 +2,6 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -16,6 +15,8 @@ func RegisterSchemaTool(s *server.MCPServer, manager *db.Manager) {
 	registerListDatabases(s, manager)
 	registerListTables(s, manager)
 	registerDescribeTable(s, manager)
+	registerDescribeDatabase(s, manager)
+	registerGetCreateStatement(s, manager)
 }
 
 func registerListDatabases(s *server.MCPServer, manager *db.Manager) {
@@ -23,7 +24,7 @@ func registerListDatabases(s *server.MCPServer, manager *db.Manager) {
 		mcp.WithDescription("List all accessible databases"),
 		mcp.WithString("connection",
 			mcp.Required(),
-			mcp.Description("The named connection to use (from config)"),
+			mcp.Description(connectionDescription(manager)),
 		),
 	)
 
@@ -48,12 +49,12 @@ func registerListDatabases(s *server.MCPServer, manager *db.Manager) {
 			}
 		}
 
-		result, err := json.MarshalIndent(databases, "", "  ")
+		result, err := formatResult(manager, "", databases)
 		if err != nil {
 			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
 		}
 
-		return mcp.NewToolResultText(string(result)), nil
+		return mcp.NewToolResultText(result), nil
 	})
 }
 
@@ -62,7 +63,7 @@ func registerListTables(s *server.MCPServer, manager *db.Manager) {
 		mcp.WithDescription("List all tables in a database"),
 		mcp.WithString("connection",
 			mcp.Required(),
-			mcp.Description("The named connection to use (from config)"),
+			mcp.Description(connectionDescription(manager)),
 		),
 		mcp.WithString("database",
 			mcp.Description("Database name (uses connection default if not provided)"),
@@ -99,21 +100,21 @@ func registerListTables(s *server.MCPServer, manager *db.Manager) {
 			}
 		}
 
-		result, err := json.MarshalIndent(tables, "", "  ")
+		result, err := formatResult(manager, "", tables)
 		if err != nil {
 			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
 		}
 
-		return mcp.NewToolResultText(string(result)), nil
+		return mcp.NewToolResultText(result), nil
 	})
 }
 
 func registerDescribeTable(s *server.MCPServer, manager *db.Manager) {
 	tool := mcp.NewTool("describe_table",
-		mcp.WithDescription("Get the schema/structure of a table including columns, types, and keys"),
+		mcp.WithDescription("Get the schema/structure of a table: columns in ordinal order with types, keys, defaults, comments, character set and collation, numeric precision and scale, and generated-column expressions"),
 		mcp.WithString("connection",
 			mcp.Required(),
-			mcp.Description("The named connection to use (from config)"),
+			mcp.Description(connectionDescription(manager)),
 		),
 		mcp.WithString("table",
 			mcp.Required(),
@@ -137,23 +138,150 @@ func registerDescribeTable(s *server.MCPServer, manager *db.Manager) {
 
 		database, _ := request.Params.Arguments["database"].(string)
 
-		var query string
-		if database != "" {
-			query = fmt.Sprintf("DESCRIBE `%s`.`%s`", database, table)
-		} else {
-			query = fmt.Sprintf("DESCRIBE `%s`", table)
+		description, err := manager.DescribeTable(connection, database, table)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		queryResult, err := manager.ExecuteQuery(connection, query)
+		result, err := formatResult(manager, "", description)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+// defaultDictionaryTokenBudget is the describe_database token budget when the
+// caller does not set one
+const defaultDictionaryTokenBudget = 8000
+
+func registerDescribeDatabase(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("describe_database",
+		mcp.WithDescription("Get a compact data dictionary of a whole database in one call: every table and view with its estimated row count and comment, its columns as \"name type [PK|UNIQUE] [NULL] [AUTO_INCREMENT] -- comment\", and the foreign key relationships between tables. Sized to fit a token budget, so use it to learn a schema instead of calling describe_table for each table. Safe for auto-accept in MCP clients."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+		mcp.WithNumber("token_budget",
+			mcp.Description("Approximate maximum size of the result in LLM tokens (default: 8000). When exceeded, long comments are cut and then the columns of the last tables are left out; the elided field reports what was removed."),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+
+		budget := defaultDictionaryTokenBudget
+		if b, ok := request.Params.Arguments["token_budget"].(float64); ok {
+			if b < 1 {
+				return mcp.NewToolResultError("token_budget must be at least 1"), nil
+			}
+			budget = int(b)
+		}
+
+		dictionary, err := manager.DescribeDatabase(connection, database)
 		if err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
+		dictionary.FitTokenBudget(budget, func(d *db.DataDictionary) int {
+			rendered, _ := formatResult(manager, "", d)
+			return estimateTokens(rendered)
+		})
+
+		result, err := formatResult(manager, "", dictionary)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerGetCreateStatement(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("get_create_statement",
+		mcp.WithDescription("Get SHOW CREATE TABLE output for one or more tables, optionally ordered so referenced tables come before the tables with foreign keys to them. Unlike describe_table, this preserves defaults, charset, and constraints."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithArray("tables",
+			mcp.Description("Tables to include (defaults to every base table in the database)"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithBoolean("dependency_order",
+			mcp.Description("Sort tables in foreign-key dependency order (default: false)"),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+		dependencyOrder, _ := request.Params.Arguments["dependency_order"].(bool)
+
+		var tables []string
+		if raw, ok := request.Params.Arguments["tables"].([]interface{}); ok {
+			for _, t := range raw {
+				table, ok := t.(string)
+				if !ok || table == "" {
+					return mcp.NewToolResultError("tables must be a list of table names"), nil
+				}
+				tables = append(tables, table)
+			}
+		}
+
+		if len(tables) == 0 {
+			all, err := manager.BaseTables(connection, database)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			tables = all
+		}
+
+		var cyclic []string
+		if dependencyOrder {
+			deps, err := manager.ForeignKeyDependencies(connection, database)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			tables, cyclic = db.SortByDependencies(tables, deps)
+		}
+
+		statements := make([]db.CreateStatement, 0, len(tables))
+		for _, table := range tables {
+			ddl, err := manager.ShowCreateTable(connection, database, table)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			statements = append(statements, db.CreateStatement{Table: table, Statement: ddl})
+		}
+
+		output := map[string]interface{}{
+			"statements": statements,
+		}
+		if len(cyclic) > 0 {
+			output["cyclic_dependencies"] = cyclic
+		}
 
-		result, err := json.MarshalIndent(queryResult.Rows, "", "  ")
+		result, err := formatResult(manager, "", output)
 		if err != nil {
 			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
 		}
 
-		return mcp.NewToolResultText(string(result)), nil
+		return mcp.NewToolResultText(result), nil
 	})
 }
@@ -0,0 +1,257 @@
+package tools
+
+import (
+	"context"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// maxSessionIdleTimeout caps the per-session idle timeout a client may request
+const maxSessionIdleTimeout = time.Hour
+
+// RegisterSessionTools registers the temporary table session tools
+func RegisterSessionTools(s *server.MCPServer, manager *db.Manager) {
+	registerOpenSession(s, manager)
+	registerCreateTempTable(s, manager)
+	registerPopulateTempTable(s, manager)
+	registerSessionQuery(s, manager)
+	registerCloseSession(s, manager)
+}
+
+func registerOpenSession(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("open_session",
+		mcp.WithDescription("Open a server session for staging intermediate results in TEMPORARY tables across several tool calls. Temporary tables are private to the session and dropped when it closes, so real schemas are never touched. The session holds a connection slot until closed with close_session or left idle past its timeout."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithNumber("idle_timeout_seconds",
+			mcp.Description("Close the session after this many seconds without a call (default: 600, max: 3600)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		idleTimeout := db.DefaultSessionIdleTimeout
+		if seconds, ok := request.Params.Arguments["idle_timeout_seconds"].(float64); ok {
+			if seconds < 1 {
+				return mcp.NewToolResultError("idle_timeout_seconds must be at least 1"), nil
+			}
+			idleTimeout = min(time.Duration(seconds)*time.Second, maxSessionIdleTimeout)
+		}
+
+		sessionInfo, err := manager.OpenSession(connection, idleTimeout)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", sessionInfo)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerCreateTempTable(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("create_temp_table",
+		mcp.WithDescription("Create a TEMPORARY table in a session, either from a SELECT or from column definitions"),
+		mcp.WithString("session_id",
+			mcp.Required(),
+			mcp.Description("Session ID returned by open_session"),
+		),
+		mcp.WithString("table",
+			mcp.Required(),
+			mcp.Description("Temporary table name"),
+		),
+		mcp.WithString("sql",
+			mcp.Description("SELECT whose result becomes the table's columns and rows (CREATE TEMPORARY TABLE ... AS SELECT)"),
+		),
+		mcp.WithArray("columns",
+			mcp.Description("Column definitions for an empty table, as objects with name and type (e.g. {\"name\": \"total\", \"type\": \"DECIMAL(12, 2)\"}); use instead of sql"),
+			mcp.Items(map[string]any{"type": "object"}),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		sessionID, ok := request.Params.Arguments["session_id"].(string)
+		if !ok || sessionID == "" {
+			return mcp.NewToolResultError("session_id parameter is required"), nil
+		}
+
+		table, ok := request.Params.Arguments["table"].(string)
+		if !ok || table == "" {
+			return mcp.NewToolResultError("table parameter is required"), nil
+		}
+
+		sql, _ := request.Params.Arguments["sql"].(string)
+
+		var columns []db.TempColumn
+		if raw, ok := request.Params.Arguments["columns"].([]interface{}); ok {
+			for _, c := range raw {
+				def, ok := c.(map[string]interface{})
+				if !ok {
+					return mcp.NewToolResultError("each column must be an object with name and type"), nil
+				}
+				name, _ := def["name"].(string)
+				typ, _ := def["type"].(string)
+				columns = append(columns, db.TempColumn{Name: name, Type: typ})
+			}
+		}
+
+		stmtResult, err := manager.CreateTempTable(sessionID, table, sql, columns)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", stmtResult)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerPopulateTempTable(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("populate_temp_table",
+		mcp.WithDescription("Insert the rows of a SELECT into a temporary table of the session. The SELECT may read real tables and the session's other temporary tables."),
+		mcp.WithString("session_id",
+			mcp.Required(),
+			mcp.Description("Session ID returned by open_session"),
+		),
+		mcp.WithString("table",
+			mcp.Required(),
+			mcp.Description("Temporary table created with create_temp_table"),
+		),
+		mcp.WithString("sql",
+			mcp.Required(),
+			mcp.Description("SELECT producing the rows to insert"),
+		),
+		mcp.WithArray("columns",
+			mcp.Description("Columns the SELECT's values go into, in order (defaults to all columns)"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		sessionID, ok := request.Params.Arguments["session_id"].(string)
+		if !ok || sessionID == "" {
+			return mcp.NewToolResultError("session_id parameter is required"), nil
+		}
+
+		table, ok := request.Params.Arguments["table"].(string)
+		if !ok || table == "" {
+			return mcp.NewToolResultError("table parameter is required"), nil
+		}
+
+		sql, ok := request.Params.Arguments["sql"].(string)
+		if !ok || sql == "" {
+			return mcp.NewToolResultError("sql parameter is required"), nil
+		}
+
+		var columns []string
+		if raw, ok := request.Params.Arguments["columns"].([]interface{}); ok {
+			for _, c := range raw {
+				col, ok := c.(string)
+				if !ok || col == "" {
+					return mcp.NewToolResultError("columns must be a list of column names"), nil
+				}
+				columns = append(columns, col)
+			}
+		}
+
+		stmtResult, err := manager.PopulateTempTable(sessionID, table, sql, columns)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", stmtResult)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerSessionQuery(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("session_query",
+		mcp.WithDescription("Run a SELECT in a session, where it can join the session's temporary tables with real tables"),
+		mcp.WithString("session_id",
+			mcp.Required(),
+			mcp.Description("Session ID returned by open_session"),
+		),
+		mcp.WithString("sql",
+			mcp.Required(),
+			mcp.Description("The SELECT query to execute"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		sessionID, ok := request.Params.Arguments["session_id"].(string)
+		if !ok || sessionID == "" {
+			return mcp.NewToolResultError("session_id parameter is required"), nil
+		}
+
+		sql, ok := request.Params.Arguments["sql"].(string)
+		if !ok || sql == "" {
+			return mcp.NewToolResultError("sql parameter is required"), nil
+		}
+
+		stmtResult, err := manager.QuerySession(sessionID, sql)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", stmtResult)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerCloseSession(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("close_session",
+		mcp.WithDescription("Close a session opened with open_session, dropping its temporary tables and releasing its connection slot"),
+		mcp.WithString("session_id",
+			mcp.Required(),
+			mcp.Description("Session ID returned by open_session"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		sessionID, ok := request.Params.Arguments["session_id"].(string)
+		if !ok || sessionID == "" {
+			return mcp.NewToolResultError("session_id parameter is required"), nil
+		}
+
+		sessionInfo, err := manager.CloseSession(sessionID)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", map[string]interface{}{
+			"session_id":          sessionID,
+			"closed":              true,
+			"dropped_temp_tables": sessionInfo.TempTables,
+			"statements":          sessionInfo.Statements,
+		})
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
@@ -0,0 +1,428 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// RegisterStructuredTools registers the structured query builder tools, which
+// build parameterized SQL server-side instead of accepting raw SQL
+func RegisterStructuredTools(s *server.MCPServer, manager *db.Manager) {
+	registerSelectStructured(s, manager)
+	registerUpdateStructured(s, manager)
+	registerDeleteStructured(s, manager)
+	registerWriteByPK(s, manager)
+	registerJSONExtract(s, manager)
+}
+
+// filterItems is the JSON schema for a single filter triple
+var filterItems = filterSchema(append(append([]string(nil), db.FilterOps...), db.SpatialFilterOps...))
+
+// filterSchema returns the JSON schema of a filter triple accepting ops
+func filterSchema(ops []string) map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"field": map[string]any{"type": "string"},
+			"op":    map[string]any{"type": "string", "enum": ops},
+			"value": map[string]any{},
+		},
+		"required": []string{"field", "op"},
+	}
+}
+
+func registerSelectStructured(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("mysql_select_structured",
+		mcp.WithDescription("Select rows using structured arguments (table, columns, filters, order_by, limit). SQL is built and parameterized server-side. Safe for auto-accept in MCP clients."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("table",
+			mcp.Required(),
+			mcp.Description("Table to select from"),
+		),
+		mcp.WithArray("columns",
+			mcp.Description("Columns to return (defaults to all)"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithArray("filters",
+			mcp.Description("Conditions combined with AND, as {field, op, value} objects. IN/NOT IN/BETWEEN take a list value; IS NULL/IS NOT NULL take no value. On geometry columns, INTERSECTS/CONTAINS/WITHIN take a WKT string or {wkt|geojson, srid}, and WITHIN DISTANCE takes {wkt|geojson, srid, distance}."),
+			mcp.Items(filterItems),
+		),
+		mcp.WithArray("order_by",
+			mcp.Description("Sort terms such as \"created_at DESC\" or \"name\""),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum rows to return (capped at the connection's max_rows)"),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+		withTenant(),
+		mcp.WithBoolean("include_deleted",
+			mcp.Description("Include soft-deleted rows on connections with soft_delete_mode (default: false)"),
+		),
+		withDistinctRows(),
+		withIncludePK(),
+		withParseJSON(),
+		withOutputFormat(),
+		withTokenBudget(),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		q, err := parseStructuredQuery(request.Params.Arguments)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if q.Database, err = tenantDatabase(manager, connection, request.Params.Arguments); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		query, args, err := db.BuildSelect(q)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		includeDeleted, _ := request.Params.Arguments["include_deleted"].(bool)
+		opts := db.QueryOptions{MaxRows: q.Limit, ExcludeSoftDeleted: !includeDeleted, Cache: true, Progress: statementProgress(ctx, request), Context: ctx}
+		queryResult, err := manager.ExecuteQueryWithOptions(connection, query, opts, args...)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		distinctRows(request.Params.Arguments, queryResult)
+		includePK(manager, request.Params.Arguments, connection, q.Database, query, queryResult)
+		parseJSON(request.Params.Arguments, queryResult)
+
+		outputFormat, _ := request.Params.Arguments["output_format"].(string)
+		if err := fitTokenBudget(manager, outputFormat, request.Params.Arguments, queryResult); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, outputFormat, queryResult)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerUpdateStructured(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("mysql_update_structured",
+		mcp.WithDescription("Update rows using structured arguments (table, set, filters, order_by, limit). At least one filter is required. SQL is built and parameterized server-side. High risk - do not auto-accept."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("table",
+			mcp.Required(),
+			mcp.Description("Table to update"),
+		),
+		mcp.WithObject("set",
+			mcp.Required(),
+			mcp.Description("Columns to update, mapping column name to new value"),
+		),
+		mcp.WithArray("filters",
+			mcp.Required(),
+			mcp.Description("Conditions combined with AND, as {field, op, value} objects"),
+			mcp.Items(filterItems),
+		),
+		mcp.WithArray("order_by",
+			mcp.Description("Sort terms such as \"created_at DESC\" (used with limit)"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum rows to update"),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+		withTenant(),
+		withBackup(),
+		withReturnChanges(),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		set, ok := request.Params.Arguments["set"].(map[string]interface{})
+		if !ok || len(set) == 0 {
+			return mcp.NewToolResultError("set parameter is required"), nil
+		}
+
+		q, err := parseStructuredQuery(request.Params.Arguments)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if q.Database, err = tenantDatabase(manager, connection, request.Params.Arguments); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		query, args, err := db.BuildUpdate(q, set)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		writeResult, err := manager.ExecuteWriteWithOptions(connection, query, args, writeOptions(ctx, request), db.QueryTypeUpdate)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", writeResult)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerWriteByPK(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("mysql_write_by_pk",
+		mcp.WithDescription("Update or delete exactly the rows identified by their primary key values, such as the _pk fields from a select run with include_pk. One parameterized statement per key runs in a single transaction, and nothing is changed if any key matches no row. High risk - do not auto-accept."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("table",
+			mcp.Required(),
+			mcp.Description("Table to write"),
+		),
+		mcp.WithString("operation",
+			mcp.Required(),
+			mcp.Description("update or delete"),
+			mcp.Enum("update", "delete"),
+		),
+		mcp.WithArray("keys",
+			mcp.Required(),
+			mcp.Description("Rows to write, each an object mapping every primary key column to its value (e.g. [{\"id\": 42}])"),
+			mcp.Items(map[string]any{"type": "object"}),
+		),
+		mcp.WithObject("set",
+			mcp.Description("Columns to update, mapping column name to new value (update only, required)"),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+		withTenant(),
+		withBackup(),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		table, ok := request.Params.Arguments["table"].(string)
+		if !ok || table == "" {
+			return mcp.NewToolResultError("table parameter is required"), nil
+		}
+
+		var queryType db.QueryType
+		switch operation, _ := request.Params.Arguments["operation"].(string); strings.ToLower(operation) {
+		case "update":
+			queryType = db.QueryTypeUpdate
+		case "delete":
+			queryType = db.QueryTypeDelete
+		default:
+			return mcp.NewToolResultError("operation must be update or delete"), nil
+		}
+
+		rawKeys, ok := request.Params.Arguments["keys"].([]interface{})
+		if !ok || len(rawKeys) == 0 {
+			return mcp.NewToolResultError("keys parameter is required"), nil
+		}
+		keys := make([]map[string]interface{}, len(rawKeys))
+		for i, k := range rawKeys {
+			key, ok := k.(map[string]interface{})
+			if !ok || len(key) == 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("key %d must be an object of primary key column to value", i+1)), nil
+			}
+			keys[i] = key
+		}
+
+		set, _ := request.Params.Arguments["set"].(map[string]interface{})
+		database, err := tenantDatabase(manager, connection, request.Params.Arguments)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		opts := writeOptions(ctx, request)
+		writeResult, err := manager.WriteByPrimaryKey(connection, database, table, queryType, keys, set, opts)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", writeResult)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerDeleteStructured(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("mysql_delete_structured",
+		mcp.WithDescription("Delete rows using structured arguments (table, filters, order_by, limit). At least one filter is required. SQL is built and parameterized server-side. High risk - do not auto-accept."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("table",
+			mcp.Required(),
+			mcp.Description("Table to delete from"),
+		),
+		mcp.WithArray("filters",
+			mcp.Required(),
+			mcp.Description("Conditions combined with AND, as {field, op, value} objects"),
+			mcp.Items(filterItems),
+		),
+		mcp.WithArray("order_by",
+			mcp.Description("Sort terms such as \"created_at DESC\" (used with limit)"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum rows to delete"),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+		withTenant(),
+		withBackup(),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		q, err := parseStructuredQuery(request.Params.Arguments)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if q.Database, err = tenantDatabase(manager, connection, request.Params.Arguments); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		query, args, err := db.BuildDelete(q)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		writeResult, err := manager.ExecuteWriteWithOptions(connection, query, args, writeOptions(ctx, request), db.QueryTypeDelete)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", writeResult)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+// parseStructuredQuery extracts the shared structured query arguments
+func parseStructuredQuery(arguments map[string]interface{}) (db.StructuredQuery, error) {
+	q := db.StructuredQuery{}
+
+	table, ok := arguments["table"].(string)
+	if !ok || table == "" {
+		return q, fmt.Errorf("table parameter is required")
+	}
+	q.Table = table
+	q.Database, _ = arguments["database"].(string)
+
+	if raw, ok := arguments["columns"].([]interface{}); ok {
+		for _, c := range raw {
+			col, ok := c.(string)
+			if !ok || col == "" {
+				return q, fmt.Errorf("columns must be a list of column names")
+			}
+			q.Columns = append(q.Columns, col)
+		}
+	}
+
+	var err error
+	if q.Filters, err = parseFilters(arguments); err != nil {
+		return q, err
+	}
+	if q.OrderBy, err = parseOrderBy(arguments); err != nil {
+		return q, err
+	}
+
+	if limit, ok := arguments["limit"].(float64); ok {
+		if limit < 1 {
+			return q, fmt.Errorf("limit must be at least 1")
+		}
+		q.Limit = int(limit)
+	}
+
+	return q, nil
+}
+
+// parseFilters extracts the filters argument as {field, op, value} objects
+func parseFilters(arguments map[string]interface{}) ([]db.Filter, error) {
+	raw, _ := arguments["filters"].([]interface{})
+	filters := make([]db.Filter, 0, len(raw))
+	for _, f := range raw {
+		obj, ok := f.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("each filter must be an object with field, op, and value")
+		}
+		field, _ := obj["field"].(string)
+		op, _ := obj["op"].(string)
+		filters = append(filters, db.Filter{Field: field, Op: op, Value: obj["value"]})
+	}
+	return filters, nil
+}
+
+// parseOrderBy extracts the order_by argument, given as terms like "name" or
+// "created_at DESC"
+func parseOrderBy(arguments map[string]interface{}) ([]db.OrderBy, error) {
+	raw, _ := arguments["order_by"].([]interface{})
+	terms := make([]db.OrderBy, 0, len(raw))
+	for _, o := range raw {
+		term, ok := o.(string)
+		if !ok {
+			return nil, fmt.Errorf("order_by must be a list of strings like \"column DESC\"")
+		}
+		parts := strings.Fields(term)
+		if len(parts) == 0 || len(parts) > 2 {
+			return nil, fmt.Errorf("invalid order_by term: %q", term)
+		}
+		orderBy := db.OrderBy{Column: parts[0]}
+		if len(parts) == 2 {
+			switch strings.ToUpper(parts[1]) {
+			case "ASC":
+			case "DESC":
+				orderBy.Desc = true
+			default:
+				return nil, fmt.Errorf("invalid order_by direction in %q (use ASC or DESC)", term)
+			}
+		}
+		terms = append(terms, orderBy)
+	}
+	return terms, nil
+}
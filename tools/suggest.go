@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// RegisterSuggestQueriesTool registers the suggest_queries tool, which
+// returns the query templates learned from successful mysql_select calls
+func RegisterSuggestQueriesTool(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("suggest_queries",
+		mcp.WithDescription("Suggest SELECT query shapes that ran successfully on a connection before, most used first. Every successful mysql_select is learned as a template with its literals replaced by ?, so it can be reused with other values. Use before writing a query against a table to start from a shape that is known to work. Safe for auto-accept in MCP clients."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("table",
+			mcp.Description("Only suggest queries reading this table, optionally qualified as database.table (defaults to all tables)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum templates to return (default: 10)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+		table, _ := request.Params.Arguments["table"].(string)
+
+		limit := db.DefaultSuggestedQueries
+		if l, ok := request.Params.Arguments["limit"].(float64); ok {
+			if l < 1 {
+				return mcp.NewToolResultError("limit must be at least 1"), nil
+			}
+			limit = int(l)
+		}
+
+		suggestions, err := manager.SuggestQueries(connection, table, limit)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", suggestions)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
@@ -0,0 +1,42 @@
+package tools
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"mysql-golang-mcp/db"
+)
+
+// withTenant adds the tenant_id parameter to tools that run in one database
+func withTenant() mcp.ToolOption {
+	return mcp.WithString("tenant_id",
+		mcp.Description("Tenant to run as, on connections with tenant_database_pattern: the statement runs in that tenant's schema and is refused if it references any other database. Cannot be combined with database."),
+	)
+}
+
+// tenantArgument returns the tenant_id argument, which clients may send as a number
+func tenantArgument(arguments map[string]interface{}) string {
+	switch v := arguments["tenant_id"].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// tenantDatabase returns the database a tool runs in: the tenant's schema
+// when tenant_id is given, otherwise the database argument
+func tenantDatabase(manager *db.Manager, connection string, arguments map[string]interface{}) (string, error) {
+	database, _ := arguments["database"].(string)
+	tenant := tenantArgument(arguments)
+	if tenant == "" {
+		return database, nil
+	}
+	if database != "" {
+		return "", fmt.Errorf("tenant_id and database cannot be combined")
+	}
+	return manager.TenantDatabase(connection, tenant)
+}
@@ -0,0 +1,119 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// RegisterTransactionTools registers the begin/execute/commit/rollback transaction tools
+func RegisterTransactionTools(s *server.MCPServer, manager *db.Manager) {
+	registerBeginTransaction(s, manager)
+	registerTransactionExecute(s, manager)
+	registerEndTransaction(s, manager, "commit_transaction",
+		"Commit a transaction opened with begin_transaction. High risk - do not auto-accept.",
+		manager.CommitTransaction)
+	registerEndTransaction(s, manager, "rollback_transaction",
+		"Roll back a transaction opened with begin_transaction, discarding its changes. Safe for auto-accept in MCP clients.",
+		manager.RollbackTransaction)
+}
+
+func registerBeginTransaction(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("begin_transaction",
+		mcp.WithDescription("Start a transaction and return its transaction_id for use with transaction_execute, commit_transaction, and rollback_transaction. Transactions not committed within the connection's transaction_timeout_seconds (default 60) are rolled back automatically. Medium risk - consider before auto-accepting."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		info, err := manager.BeginTransaction(connection)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", info)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerTransactionExecute(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("transaction_execute",
+		mcp.WithDescription("Run a SELECT, INSERT, UPDATE, or DELETE inside a transaction opened with begin_transaction. Changes are not visible to others until commit_transaction. High risk - do not auto-accept."),
+		mcp.WithString("transaction_id",
+			mcp.Required(),
+			mcp.Description("Transaction ID returned by begin_transaction"),
+		),
+		mcp.WithString("sql",
+			mcp.Required(),
+			mcp.Description("The SELECT, INSERT, UPDATE, or DELETE statement to execute"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		transactionID, ok := request.Params.Arguments["transaction_id"].(string)
+		if !ok || transactionID == "" {
+			return mcp.NewToolResultError("transaction_id parameter is required"), nil
+		}
+
+		sql, ok := request.Params.Arguments["sql"].(string)
+		if !ok || sql == "" {
+			return mcp.NewToolResultError("sql parameter is required"), nil
+		}
+
+		statementResult, err := manager.ExecuteInTransaction(transactionID, sql)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", statementResult)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+// registerEndTransaction registers a tool that ends a transaction with the given function
+func registerEndTransaction(s *server.MCPServer, manager *db.Manager, name, description string, end func(string) (*db.TransactionOutcome, error)) {
+	tool := mcp.NewTool(name,
+		mcp.WithDescription(description),
+		mcp.WithString("transaction_id",
+			mcp.Required(),
+			mcp.Description("Transaction ID returned by begin_transaction"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		transactionID, ok := request.Params.Arguments["transaction_id"].(string)
+		if !ok || transactionID == "" {
+			return mcp.NewToolResultError("transaction_id parameter is required"), nil
+		}
+
+		outcome, err := end(transactionID)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", outcome)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
@@ -0,0 +1,214 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// RegisterTriggerTools registers the trigger inspection and management tools
+func RegisterTriggerTools(s *server.MCPServer, manager *db.Manager) {
+	registerListTriggers(s, manager)
+	registerGetTrigger(s, manager)
+	registerCreateTrigger(s, manager)
+	registerDropTrigger(s, manager)
+}
+
+func registerListTriggers(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("list_triggers",
+		mcp.WithDescription("List triggers in a database, optionally only those on one table. Triggers often explain unexpected side effects of writes."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("table",
+			mcp.Description("Only list triggers on this table"),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		table, _ := request.Params.Arguments["table"].(string)
+		database, _ := request.Params.Arguments["database"].(string)
+
+		queryResult, err := manager.ListTriggers(connection, database, table)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", queryResult.Rows)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerGetTrigger(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("get_trigger",
+		mcp.WithDescription("Get a trigger's timing, event, body, and SHOW CREATE TRIGGER statement"),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("trigger",
+			mcp.Required(),
+			mcp.Description("Trigger name"),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		trigger, ok := request.Params.Arguments["trigger"].(string)
+		if !ok || trigger == "" {
+			return mcp.NewToolResultError("trigger parameter is required"), nil
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+
+		triggerInfo, err := manager.DescribeTrigger(connection, database, trigger)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", triggerInfo)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerCreateTrigger(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("create_trigger",
+		mcp.WithDescription("Create a row-level trigger on a table. Only available on connections with allow_ddl enabled. High risk - do not auto-accept."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("trigger",
+			mcp.Required(),
+			mcp.Description("Trigger name"),
+		),
+		mcp.WithString("table",
+			mcp.Required(),
+			mcp.Description("Table the trigger fires on"),
+		),
+		mcp.WithString("timing",
+			mcp.Required(),
+			mcp.Description("When the trigger fires relative to the row change"),
+			mcp.Enum("BEFORE", "AFTER"),
+		),
+		mcp.WithString("event",
+			mcp.Required(),
+			mcp.Description("The row change that fires the trigger"),
+			mcp.Enum("INSERT", "UPDATE", "DELETE"),
+		),
+		mcp.WithString("body",
+			mcp.Required(),
+			mcp.Description("Statement run for each row, e.g. SET NEW.updated_at = NOW(); wrap several statements in BEGIN ... END without DELIMITER"),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		def := db.TriggerDefinition{}
+		def.Name, _ = request.Params.Arguments["trigger"].(string)
+		def.Table, _ = request.Params.Arguments["table"].(string)
+		def.Timing, _ = request.Params.Arguments["timing"].(string)
+		def.Event, _ = request.Params.Arguments["event"].(string)
+		def.Body, _ = request.Params.Arguments["body"].(string)
+		if def.Name == "" {
+			return mcp.NewToolResultError("trigger parameter is required"), nil
+		}
+		if def.Table == "" {
+			return mcp.NewToolResultError("table parameter is required"), nil
+		}
+		if def.Body == "" {
+			return mcp.NewToolResultError("body parameter is required"), nil
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+
+		writeResult, err := manager.CreateTrigger(connection, database, def)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", writeResult)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerDropTrigger(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("drop_trigger",
+		mcp.WithDescription("Drop a trigger. Only available on connections with allow_ddl enabled. High risk - do not auto-accept."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("trigger",
+			mcp.Required(),
+			mcp.Description("Trigger name to drop"),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		trigger, ok := request.Params.Arguments["trigger"].(string)
+		if !ok || trigger == "" {
+			return mcp.NewToolResultError("trigger parameter is required"), nil
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+
+		writeResult, err := manager.DropTrigger(connection, database, trigger)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", writeResult)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// RegisterUndoTool registers the undo_last_write tool
+func RegisterUndoTool(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("undo_last_write",
+		mcp.WithDescription("Restore the rows changed by an UPDATE or DELETE that was run with a backup, using the backup_id from its result. Deleted rows are re-inserted and updated rows are written back by primary key, all in one transaction. Limitations: any change made to those rows after the original write is overwritten; updated rows whose primary key changed or that were since deleted are reported as missing, not restored; re-inserting deleted rows fails if a row with the same key was created since; UPDATE undo requires a primary key; side effects such as triggers and ON DELETE CASCADE deletes are not reversed. High risk - do not auto-accept."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("backup_id",
+			mcp.Required(),
+			mcp.Description("Backup ID from the backup field of a write result"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		backupID, ok := request.Params.Arguments["backup_id"].(string)
+		if !ok || backupID == "" {
+			return mcp.NewToolResultError("backup_id parameter is required"), nil
+		}
+
+		undoResult, err := manager.UndoWrite(connection, backupID)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", undoResult)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
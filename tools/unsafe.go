@@ -2,7 +2,6 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -30,7 +29,7 @@ Use cases:
 NEVER auto-accept this tool. Always review queries carefully.`),
 		mcp.WithString("connection",
 			mcp.Required(),
-			mcp.Description("The named connection to use (from config)"),
+			mcp.Description(connectionDescription(manager)),
 		),
 		mcp.WithString("sql",
 			mcp.Required(),
@@ -49,16 +48,16 @@ NEVER auto-accept this tool. Always review queries carefully.`),
 			return mcp.NewToolResultError("sql parameter is required"), nil
 		}
 
-		unsafeResult, err := manager.ExecuteUnsafe(connection, sql)
+		unsafeResult, err := manager.ExecuteUnsafeContext(ctx, connection, sql)
 		if err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		result, err := json.MarshalIndent(unsafeResult, "", "  ")
+		result, err := formatResult(manager, "", unsafeResult)
 		if err != nil {
 			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
 		}
 
-		return mcp.NewToolResultText(string(result)), nil
+		return mcp.NewToolResultText(result), nil
 	})
 }
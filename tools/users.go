@@ -0,0 +1,220 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// RegisterUserTools registers the MySQL account management tools, which are
+// only available on connections with allow_admin enabled
+func RegisterUserTools(s *server.MCPServer, manager *db.Manager) {
+	registerListUsers(s, manager)
+	registerCreateUser(s, manager)
+	registerGrantPrivileges(s, manager)
+	registerRotatePassword(s, manager)
+}
+
+// withAccount adds the user and host parameters naming a MySQL account
+func withAccount(required bool) []mcp.ToolOption {
+	user := []mcp.PropertyOption{mcp.Description("Account user name")}
+	if required {
+		user = append(user, mcp.Required())
+	}
+	return []mcp.ToolOption{
+		mcp.WithString("user", user...),
+		mcp.WithString("host",
+			mcp.Description("Host the account connects from, e.g. 10.0.% (default: %, any host)"),
+		),
+	}
+}
+
+func registerListUsers(s *server.MCPServer, manager *db.Manager) {
+	options := []mcp.ToolOption{
+		mcp.WithDescription("List MySQL accounts from mysql.user with their authentication plugin, lock and password expiry status and resource limits; password hashes are never returned. With user, only that user's accounts are listed, with their grants. Only available on connections with allow_admin enabled."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+	}
+	tool := mcp.NewTool("list_users", append(options, withAccount(false)...)...)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		user, _ := request.Params.Arguments["user"].(string)
+		host, _ := request.Params.Arguments["host"].(string)
+		if host != "" && user == "" {
+			return mcp.NewToolResultError("host requires user"), nil
+		}
+
+		users, err := manager.ListUsers(connection, user, host)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", users)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerCreateUser(s *server.MCPServer, manager *db.Manager) {
+	options := []mcp.ToolOption{
+		mcp.WithDescription("Create a MySQL account identified by a password (CREATE USER). The statement is built server-side and returned with the password redacted; the new account has no privileges until grant_privileges is used. Only available on connections with allow_admin enabled. High risk - do not auto-accept."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+	}
+	options = append(options, withAccount(true)...)
+	options = append(options, mcp.WithString("password",
+		mcp.Required(),
+		mcp.Description("Password for the account"),
+	))
+	tool := mcp.NewTool("create_user", options...)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		account := db.UserAccount{}
+		account.User, _ = request.Params.Arguments["user"].(string)
+		account.Host, _ = request.Params.Arguments["host"].(string)
+		if account.User == "" {
+			return mcp.NewToolResultError("user parameter is required"), nil
+		}
+
+		password, ok := request.Params.Arguments["password"].(string)
+		if !ok || password == "" {
+			return mcp.NewToolResultError("password parameter is required"), nil
+		}
+
+		change, err := manager.CreateUser(connection, account, password)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", change)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerGrantPrivileges(s *server.MCPServer, manager *db.Manager) {
+	options := []mcp.ToolOption{
+		mcp.WithDescription("Grant privileges on a database, or one of its tables, to a MySQL account (GRANT). Global privileges, the system schemas and privileges that allow escalation (GRANT OPTION, SUPER, FILE, CREATE USER) are refused. Only available on connections with allow_admin enabled. High risk - do not auto-accept."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+	}
+	options = append(options, withAccount(true)...)
+	options = append(options,
+		mcp.WithArray("privileges",
+			mcp.Required(),
+			mcp.Description("Privileges to grant, e.g. [\"SELECT\", \"INSERT\"] or [\"ALL PRIVILEGES\"]"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithString("database",
+			mcp.Required(),
+			mcp.Description("Database the privileges apply to"),
+		),
+		mcp.WithString("table",
+			mcp.Description("Only grant on this table of the database"),
+		),
+	)
+	tool := mcp.NewTool("grant_privileges", options...)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		account := db.UserAccount{}
+		account.User, _ = request.Params.Arguments["user"].(string)
+		account.Host, _ = request.Params.Arguments["host"].(string)
+		if account.User == "" {
+			return mcp.NewToolResultError("user parameter is required"), nil
+		}
+
+		var privileges []string
+		raw, _ := request.Params.Arguments["privileges"].([]interface{})
+		for _, p := range raw {
+			privilege, ok := p.(string)
+			if !ok || privilege == "" {
+				return mcp.NewToolResultError("privileges must be a list of privilege names"), nil
+			}
+			privileges = append(privileges, privilege)
+		}
+		if len(privileges) == 0 {
+			return mcp.NewToolResultError("privileges parameter is required"), nil
+		}
+
+		database, ok := request.Params.Arguments["database"].(string)
+		if !ok || database == "" {
+			return mcp.NewToolResultError("database parameter is required"), nil
+		}
+		table, _ := request.Params.Arguments["table"].(string)
+
+		change, err := manager.GrantPrivileges(connection, account, privileges, database, table)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", change)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerRotatePassword(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("rotate_password",
+		mcp.WithDescription("Rotate the MySQL password of a connection's own account: generate a new password, change it with ALTER USER, store it through the connection's password_rotation command (e.g. a secrets manager) and password_file, and rebuild the pool with it. The password is never returned; if it cannot be stored the change is reverted. On MySQL 8.0.14+ the old password keeps working as the secondary password unless discard_old_password is set. Only available on connections with allow_admin and password_rotation configured. High risk - do not auto-accept."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithBoolean("discard_old_password",
+			mcp.Description("Discard the old password once the pool has reconnected, instead of keeping it as the secondary password for other clients still using it (default: false)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+		discardOld, _ := request.Params.Arguments["discard_old_password"].(bool)
+
+		rotation, err := manager.RotatePassword(connection, discardOld)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", rotation)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
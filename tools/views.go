@@ -0,0 +1,146 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"mysql-golang-mcp/db"
+)
+
+// RegisterViewTools registers the view inspection and management tools
+func RegisterViewTools(s *server.MCPServer, manager *db.Manager) {
+	registerListViews(s, manager)
+	registerDescribeView(s, manager)
+	registerCreateOrReplaceView(s, manager)
+}
+
+func registerListViews(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("list_views",
+		mcp.WithDescription("List all views in a database"),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+
+		queryResult, err := manager.ListViews(connection, database)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", queryResult.Rows)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerDescribeView(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("describe_view",
+		mcp.WithDescription("Get a view's definition (SHOW CREATE VIEW) and its columns"),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("view",
+			mcp.Required(),
+			mcp.Description("View name to describe"),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		view, ok := request.Params.Arguments["view"].(string)
+		if !ok || view == "" {
+			return mcp.NewToolResultError("view parameter is required"), nil
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+
+		viewInfo, err := manager.DescribeView(connection, database, view)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", viewInfo)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+func registerCreateOrReplaceView(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("create_or_replace_view",
+		mcp.WithDescription("Create a view, or replace an existing one, from a SELECT statement. Only available on connections with allow_ddl enabled. High risk - do not auto-accept."),
+		mcp.WithString("connection",
+			mcp.Required(),
+			mcp.Description(connectionDescription(manager)),
+		),
+		mcp.WithString("view",
+			mcp.Required(),
+			mcp.Description("View name to create or replace"),
+		),
+		mcp.WithString("sql",
+			mcp.Required(),
+			mcp.Description("The SELECT statement defining the view"),
+		),
+		mcp.WithString("database",
+			mcp.Description("Database name (uses connection default if not provided)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, ok := request.Params.Arguments["connection"].(string)
+		if !ok || connection == "" {
+			return mcp.NewToolResultError("connection parameter is required"), nil
+		}
+
+		view, ok := request.Params.Arguments["view"].(string)
+		if !ok || view == "" {
+			return mcp.NewToolResultError("view parameter is required"), nil
+		}
+
+		sql, ok := request.Params.Arguments["sql"].(string)
+		if !ok || sql == "" {
+			return mcp.NewToolResultError("sql parameter is required"), nil
+		}
+
+		database, _ := request.Params.Arguments["database"].(string)
+
+		writeResult, err := manager.CreateOrReplaceView(connection, database, view, sql)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", writeResult)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
@@ -2,7 +2,7 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
+	"fmt"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -17,20 +17,63 @@ func RegisterWriteTools(s *server.MCPServer, manager *db.Manager) {
 	registerDeleteTool(s, manager)
 	registerAlterTool(s, manager)
 	registerExecuteTool(s, manager)
+	registerFlushWritesTool(s, manager)
+}
+
+// withBackup adds the per-call backup parameter to tools that run UPDATE or DELETE
+func withBackup() mcp.ToolOption {
+	return mcp.WithBoolean("backup",
+		mcp.Description("Snapshot the rows an UPDATE or DELETE will change before running it and return a backup reference. Defaults to the connection's backup_before_write setting."),
+	)
+}
+
+// withReturnChanges adds the per-call return_changes parameter to tools that run UPDATE
+func withReturnChanges() mcp.ToolOption {
+	return mcp.WithBoolean("return_changes",
+		mcp.Description("Read the rows an UPDATE changes before and after it, in one transaction, and return each changed row's primary key with the before and after values of the columns that changed. Needs a single-table UPDATE on a table with a primary key, and changing at most backup_max_rows rows."),
+	)
+}
+
+// withDatabase adds the per-call database parameter to raw SQL tools
+func withDatabase() mcp.ToolOption {
+	return mcp.WithString("database",
+		mcp.Description("Default database for unqualified table names in this statement, on the same server (uses connection default if not provided)"),
+	)
+}
+
+// writeOptions reads per-call write options from the tool arguments
+func writeOptions(ctx context.Context, request mcp.CallToolRequest) db.WriteOptions {
+	opts := db.WriteOptions{Progress: statementProgress(ctx, request), Context: ctx}
+	opts.Database, _ = request.Params.Arguments["database"].(string)
+	opts.Tenant = tenantArgument(request.Params.Arguments)
+	if backup, ok := request.Params.Arguments["backup"].(bool); ok {
+		opts.Backup = &backup
+	}
+	opts.ReturnChanges, _ = request.Params.Arguments["return_changes"].(bool)
+	return opts
 }
 
 // registerInsertTool registers the mysql_insert tool
 func registerInsertTool(s *server.MCPServer, manager *db.Manager) {
 	tool := mcp.NewTool("mysql_insert",
-		mcp.WithDescription("Execute an INSERT query against the MySQL database. Only INSERT queries are allowed. Medium risk - consider before auto-accepting."),
+		mcp.WithDescription("Execute an INSERT query against the MySQL database. Only INSERT queries are allowed. On connections with write_batch_seconds, consecutive INSERTs are coalesced into one transaction that is committed once no INSERT arrives for that window (see batch in the result); call flush_writes to commit it sooner. Medium risk - consider before auto-accepting."),
 		mcp.WithString("connection",
 			mcp.Required(),
-			mcp.Description("The named connection to use (from config)"),
+			mcp.Description(connectionDescription(manager)),
 		),
 		mcp.WithString("sql",
 			mcp.Required(),
 			mcp.Description("The INSERT query to execute"),
 		),
+		mcp.WithBoolean("return_rows",
+			mcp.Description("Read the inserted rows back in the INSERT's transaction and return them as returning, with generated defaults, timestamps and AUTO_INCREMENT values. Rows are found by the AUTO_INCREMENT values the INSERT generated unless return_keys is given. Skips the write batch."),
+		),
+		mcp.WithArray("return_keys",
+			mcp.Description("Unique key values of the inserted rows to read back, each an object mapping column to value (e.g. [{\"sku\": \"A-100\"}]). Implies return_rows; needed for tables without AUTO_INCREMENT and for ON DUPLICATE KEY UPDATE."),
+			mcp.Items(map[string]any{"type": "object"}),
+		),
+		withDatabase(),
+		withTenant(),
 	)
 
 	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -44,17 +87,31 @@ func registerInsertTool(s *server.MCPServer, manager *db.Manager) {
 			return mcp.NewToolResultError("sql parameter is required"), nil
 		}
 
-		writeResult, err := manager.ExecuteWrite(connection, sql, db.QueryTypeInsert)
+		opts := writeOptions(ctx, request)
+		opts.Batch = true
+		opts.ReturnRows, _ = request.Params.Arguments["return_rows"].(bool)
+		if rawKeys, ok := request.Params.Arguments["return_keys"].([]interface{}); ok && len(rawKeys) > 0 {
+			opts.ReturnRows = true
+			opts.ReturnKeys = make([]map[string]interface{}, len(rawKeys))
+			for i, k := range rawKeys {
+				key, ok := k.(map[string]interface{})
+				if !ok || len(key) == 0 {
+					return mcp.NewToolResultError(fmt.Sprintf("return_keys entry %d must be an object of column to value", i+1)), nil
+				}
+				opts.ReturnKeys[i] = key
+			}
+		}
+		writeResult, err := manager.ExecuteWriteWithOptions(connection, sql, nil, opts, db.QueryTypeInsert)
 		if err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		result, err := json.MarshalIndent(writeResult, "", "  ")
+		result, err := formatResult(manager, "", writeResult)
 		if err != nil {
 			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
 		}
 
-		return mcp.NewToolResultText(string(result)), nil
+		return mcp.NewToolResultText(result), nil
 	})
 }
 
@@ -64,12 +121,16 @@ func registerUpdateTool(s *server.MCPServer, manager *db.Manager) {
 		mcp.WithDescription("Execute an UPDATE query against the MySQL database. Only UPDATE queries are allowed. High risk - do not auto-accept."),
 		mcp.WithString("connection",
 			mcp.Required(),
-			mcp.Description("The named connection to use (from config)"),
+			mcp.Description(connectionDescription(manager)),
 		),
 		mcp.WithString("sql",
 			mcp.Required(),
 			mcp.Description("The UPDATE query to execute"),
 		),
+		withBackup(),
+		withReturnChanges(),
+		withDatabase(),
+		withTenant(),
 	)
 
 	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -83,32 +144,35 @@ func registerUpdateTool(s *server.MCPServer, manager *db.Manager) {
 			return mcp.NewToolResultError("sql parameter is required"), nil
 		}
 
-		writeResult, err := manager.ExecuteWrite(connection, sql, db.QueryTypeUpdate)
+		writeResult, err := manager.ExecuteWriteWithOptions(connection, sql, nil, writeOptions(ctx, request), db.QueryTypeUpdate)
 		if err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		result, err := json.MarshalIndent(writeResult, "", "  ")
+		result, err := formatResult(manager, "", writeResult)
 		if err != nil {
 			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
 		}
 
-		return mcp.NewToolResultText(string(result)), nil
+		return mcp.NewToolResultText(result), nil
 	})
 }
 
 // registerDeleteTool registers the mysql_delete tool
 func registerDeleteTool(s *server.MCPServer, manager *db.Manager) {
 	tool := mcp.NewTool("mysql_delete",
-		mcp.WithDescription("Execute a DELETE query against the MySQL database. Only DELETE queries are allowed. High risk - do not auto-accept."),
+		mcp.WithDescription("Execute a DELETE query against the MySQL database. Only DELETE queries are allowed. On connections with soft_delete_mode, single-table DELETEs on tables with a soft_delete_column are rewritten into an UPDATE that sets the column (see rewritten_sql in the result). High risk - do not auto-accept."),
 		mcp.WithString("connection",
 			mcp.Required(),
-			mcp.Description("The named connection to use (from config)"),
+			mcp.Description(connectionDescription(manager)),
 		),
 		mcp.WithString("sql",
 			mcp.Required(),
 			mcp.Description("The DELETE query to execute"),
 		),
+		withBackup(),
+		withDatabase(),
+		withTenant(),
 	)
 
 	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -122,32 +186,35 @@ func registerDeleteTool(s *server.MCPServer, manager *db.Manager) {
 			return mcp.NewToolResultError("sql parameter is required"), nil
 		}
 
-		writeResult, err := manager.ExecuteWrite(connection, sql, db.QueryTypeDelete)
+		writeResult, err := manager.ExecuteWriteWithOptions(connection, sql, nil, writeOptions(ctx, request), db.QueryTypeDelete)
 		if err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		result, err := json.MarshalIndent(writeResult, "", "  ")
+		result, err := formatResult(manager, "", writeResult)
 		if err != nil {
 			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
 		}
 
-		return mcp.NewToolResultText(string(result)), nil
+		return mcp.NewToolResultText(result), nil
 	})
 }
 
 // registerAlterTool registers the mysql_alter tool
 func registerAlterTool(s *server.MCPServer, manager *db.Manager) {
 	tool := mcp.NewTool("mysql_alter",
-		mcp.WithDescription("Execute an ALTER TABLE query against the MySQL database. Only ALTER queries are allowed. High risk - do not auto-accept. Still blocks DROP DATABASE, CREATE DATABASE, GRANT, REVOKE."),
+		mcp.WithDescription("Execute an ALTER TABLE query against the MySQL database. Only ALTER queries are allowed. The table's size, whether the server will rebuild or copy it, and the views, foreign keys and triggers depending on it are analyzed first and returned as impact; changes that are not instant are refused on tables above the connection's alter_max_table_mb unless force is set. High risk - do not auto-accept. Still blocks DROP DATABASE, CREATE DATABASE, GRANT, REVOKE."),
 		mcp.WithString("connection",
 			mcp.Required(),
-			mcp.Description("The named connection to use (from config)"),
+			mcp.Description(connectionDescription(manager)),
 		),
 		mcp.WithString("sql",
 			mcp.Required(),
 			mcp.Description("The ALTER query to execute"),
 		),
+		mcp.WithBoolean("force",
+			mcp.Description("Run the ALTER even though the impact analysis refuses it for the size of the table (default: false)"),
+		),
 	)
 
 	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -161,17 +228,23 @@ func registerAlterTool(s *server.MCPServer, manager *db.Manager) {
 			return mcp.NewToolResultError("sql parameter is required"), nil
 		}
 
-		writeResult, err := manager.ExecuteAlter(connection, sql)
+		force, _ := request.Params.Arguments["force"].(bool)
+		writeResult, err := manager.ExecuteAlterWithOptions(connection, sql, db.WriteOptions{Progress: statementProgress(ctx, request), Context: ctx, Force: force})
 		if err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		result, err := json.MarshalIndent(writeResult, "", "  ")
+		result, err := formatResult(manager, "", writeResult)
 		if err != nil {
 			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
 		}
 
-		return mcp.NewToolResultText(string(result)), nil
+		// A refused ALTER is an error, with the analysis explaining why
+		if writeResult.Impact != nil && writeResult.Impact.Blocked {
+			return mcp.NewToolResultError(writeResult.Impact.BlockReason + "\n" + result), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
 	})
 }
 
@@ -181,12 +254,16 @@ func registerExecuteTool(s *server.MCPServer, manager *db.Manager) {
 		mcp.WithDescription("Execute an INSERT, UPDATE, or DELETE query against the MySQL database. High risk - do not auto-accept."),
 		mcp.WithString("connection",
 			mcp.Required(),
-			mcp.Description("The named connection to use (from config)"),
+			mcp.Description(connectionDescription(manager)),
 		),
 		mcp.WithString("sql",
 			mcp.Required(),
 			mcp.Description("The INSERT, UPDATE, or DELETE query to execute"),
 		),
+		withBackup(),
+		withReturnChanges(),
+		withDatabase(),
+		withTenant(),
 	)
 
 	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -200,16 +277,42 @@ func registerExecuteTool(s *server.MCPServer, manager *db.Manager) {
 			return mcp.NewToolResultError("sql parameter is required"), nil
 		}
 
-		writeResult, err := manager.ExecuteWrite(connection, sql, db.QueryTypeInsert, db.QueryTypeUpdate, db.QueryTypeDelete)
+		writeResult, err := manager.ExecuteWriteWithOptions(connection, sql, nil, writeOptions(ctx, request), db.QueryTypeInsert, db.QueryTypeUpdate, db.QueryTypeDelete)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result, err := formatResult(manager, "", writeResult)
+		if err != nil {
+			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(result), nil
+	})
+}
+
+// registerFlushWritesTool registers the flush_writes tool
+func registerFlushWritesTool(s *server.MCPServer, manager *db.Manager) {
+	tool := mcp.NewTool("flush_writes",
+		mcp.WithDescription("Commit the INSERTs mysql_insert has batched on connections with write_batch_seconds now, instead of waiting for the batch window, and report batches that failed to commit since the last call, whose INSERTs must be retried. Call after the last INSERT of a series when its rows must be visible right away. Only commits INSERTs that already ran. Safe for auto-accept in MCP clients."),
+		mcp.WithString("connection",
+			mcp.Description("Only flush this connection (defaults to all)"),
+		),
+	)
+
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connection, _ := request.Params.Arguments["connection"].(string)
+
+		flushed, err := manager.FlushWrites(connection)
 		if err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		result, err := json.MarshalIndent(writeResult, "", "  ")
+		result, err := formatResult(manager, "", flushed)
 		if err != nil {
 			return mcp.NewToolResultError("failed to format result: " + err.Error()), nil
 		}
 
-		return mcp.NewToolResultText(string(result)), nil
+		return mcp.NewToolResultText(result), nil
 	})
 }
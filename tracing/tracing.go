@@ -0,0 +1,116 @@
+// Package tracing exports OpenTelemetry spans for tool calls and the
+// statements they run, so MCP database activity shows up in an existing
+// tracing backend.
+package tracing
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"mysql-golang-mcp/auth"
+	"mysql-golang-mcp/config"
+	"mysql-golang-mcp/db"
+)
+
+// tracer creates tool call spans from the global tracer provider
+var tracer = otel.Tracer("mysql-golang-mcp/tools")
+
+// Setup installs a global tracer provider that exports spans over OTLP/HTTP.
+// With no tracing section configured, spans are dropped. The returned
+// function flushes buffered spans and must be called before exiting.
+func Setup(cfg *config.TracingConfig, version string) (func(context.Context) error, error) {
+	if cfg == nil {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	opts := []otlptracehttp.Option{otlptracehttp.WithHeaders(cfg.Headers)}
+	if strings.Contains(cfg.Endpoint, "://") {
+		opts = append(opts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+	} else {
+		opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+	}
+	exporter, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", cfg.ServiceName),
+		attribute.String("service.version", version),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(*cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	// Export failures go to the log file; stdout carries the stdio transport
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		slog.Warn("trace export failed", "error", err.Error())
+	}))
+	return provider.Shutdown, nil
+}
+
+// ToolCallMiddleware records a span for every tool call. Statements the tool
+// runs through the connection manager are recorded as its children.
+func ToolCallMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, span := tracer.Start(ctx, "tools/call "+request.Params.Name, trace.WithSpanKind(trace.SpanKindServer))
+		defer span.End()
+
+		if span.IsRecording() {
+			span.SetAttributes(
+				attribute.String("mcp.method.name", "tools/call"),
+				attribute.String("gen_ai.tool.name", request.Params.Name),
+			)
+			if connection, ok := request.Params.Arguments["connection"].(string); ok {
+				span.SetAttributes(attribute.String("mysql_mcp.connection", connection))
+			}
+			if id := auth.FromContext(ctx); id != nil {
+				span.SetAttributes(attribute.String("mysql_mcp.client", id.Client), attribute.String("mysql_mcp.role", string(id.Role)))
+			}
+			if info := db.CallInfoFromContext(ctx); info != nil {
+				span.SetAttributes(attribute.String("mysql_mcp.request_id", info.RequestID))
+			}
+		}
+
+		result, err := next(ctx, request)
+		switch {
+		case err != nil:
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		case result != nil && result.IsError:
+			span.SetStatus(codes.Error, toolResultText(result))
+		}
+		return result, err
+	}
+}
+
+// toolResultText returns the text of the first text content in a tool result
+func toolResultText(result *mcp.CallToolResult) string {
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			return text.Text
+		}
+	}
+	return ""
+}